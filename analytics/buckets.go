@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"fmt"
+	"time"
+)
+
+// Supported time buckets
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+// Range is a half-open reporting period [From, To) split into buckets
+type Range struct {
+	From     time.Time
+	To       time.Time
+	Bucket   string
+	Location *time.Location
+}
+
+// Validate checks the bucket name and that the range is not empty
+func (r Range) Validate() error {
+	switch r.Bucket {
+	case BucketDay, BucketWeek, BucketMonth:
+	default:
+		return fmt.Errorf("bucket must be one of: %s %s %s", BucketDay, BucketWeek, BucketMonth)
+	}
+	if !r.From.Before(r.To) {
+		return fmt.Errorf("from must be before to")
+	}
+	return nil
+}
+
+// Contains reports whether t falls inside the range
+func (r Range) Contains(t time.Time) bool {
+	return !t.Before(r.From) && t.Before(r.To)
+}
+
+// bucketStart truncates t to the start of its bucket in the range's location.
+// Weeks start on Monday.
+func (r Range) bucketStart(t time.Time) time.Time {
+	t = t.In(r.Location)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, r.Location)
+	switch r.Bucket {
+	case BucketWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case BucketMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, r.Location)
+	default:
+		return day
+	}
+}
+
+// next returns the start of the bucket following start
+func (r Range) next(start time.Time) time.Time {
+	switch r.Bucket {
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	case BucketMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Buckets returns the start of every bucket overlapping the range
+func (r Range) Buckets() []time.Time {
+	var buckets []time.Time
+	for start := r.bucketStart(r.From); start.Before(r.To); start = r.next(start) {
+		buckets = append(buckets, start)
+	}
+	return buckets
+}
+
+// BucketsExceed reports whether the range has more than limit buckets. It
+// stops counting past limit, so a huge range costs no more than a small one.
+func (r Range) BucketsExceed(limit int) bool {
+	n := 0
+	for start := r.bucketStart(r.From); start.Before(r.To); start = r.next(start) {
+		if n++; n > limit {
+			return true
+		}
+	}
+	return false
+}
+
+// bucketIndexer returns a function mapping a time to its position in
+// buckets, or -1 when it falls outside them
+func (r Range) bucketIndexer(buckets []time.Time) func(time.Time) int {
+	index := make(map[int64]int, len(buckets))
+	for i, b := range buckets {
+		index[b.Unix()] = i
+	}
+	return func(t time.Time) int {
+		if i, ok := index[r.bucketStart(t).Unix()]; ok {
+			return i
+		}
+		return -1
+	}
+}
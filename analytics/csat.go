@@ -0,0 +1,129 @@
+package analytics
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"issue-tracking/entities"
+)
+
+// satisfiedRating is the lowest rating counted as satisfied
+const satisfiedRating = 4
+
+// CSATScore summarizes a set of satisfaction surveys. CSAT is the share of
+// answers rating 4 or 5, in percent.
+type CSATScore struct {
+	Sent          int     `json:"sent"`
+	Answered      int     `json:"answered"`
+	ResponseRate  float64 `json:"response_rate"`
+	AverageRating float64 `json:"average_rating"`
+	CSAT          float64 `json:"csat"`
+	// Ratings counts the answers rating 1 to 5
+	Ratings [5]int `json:"ratings"`
+}
+
+// OfficerCSAT is the score of the surveys credited to one officer
+type OfficerCSAT struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	CSATScore
+}
+
+// PeriodCSAT is the score of the surveys sent in one bucket
+type PeriodCSAT struct {
+	PeriodStart time.Time `json:"period_start"`
+	CSATScore
+}
+
+// CSATReport scores the surveys sent in a range overall, per officer and
+// per bucket
+type CSATReport struct {
+	Overall  CSATScore     `json:"overall"`
+	Officers []OfficerCSAT `json:"officers"`
+	Periods  []PeriodCSAT  `json:"periods"`
+}
+
+// CSAT scores the surveys sent in the range. Surveys count toward the
+// officer assigned and the bucket the issue was closed in, whenever they
+// were answered.
+func CSAT(r Range, surveys []entities.Survey, officers map[uint]entities.Officer) CSATReport {
+	buckets := r.Buckets()
+	indexOf := r.bucketIndexer(buckets)
+
+	var overall csatTally
+	periods := make([]csatTally, len(buckets))
+	byOfficer := map[string]*csatTally{}
+	labels := map[string]string{}
+	for _, survey := range surveys {
+		if !r.Contains(survey.CreatedAt) {
+			continue
+		}
+		overall.add(survey)
+		if i := indexOf(survey.CreatedAt); i >= 0 {
+			periods[i].add(survey)
+		}
+
+		key, label := "unassigned", "Unassigned"
+		if survey.OfficerID != nil {
+			key = strconv.FormatUint(uint64(*survey.OfficerID), 10)
+			label = officers[*survey.OfficerID].FullName
+		}
+		if byOfficer[key] == nil {
+			byOfficer[key] = &csatTally{}
+			labels[key] = label
+		}
+		byOfficer[key].add(survey)
+	}
+
+	report := CSATReport{
+		Overall:  overall.score(),
+		Officers: make([]OfficerCSAT, 0, len(byOfficer)),
+		Periods:  make([]PeriodCSAT, len(buckets)),
+	}
+	keys := make([]string, 0, len(byOfficer))
+	for key := range byOfficer {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		report.Officers = append(report.Officers, OfficerCSAT{Key: key, Label: labels[key], CSATScore: byOfficer[key].score()})
+	}
+	for i, start := range buckets {
+		report.Periods[i] = PeriodCSAT{PeriodStart: start, CSATScore: periods[i].score()}
+	}
+	return report
+}
+
+// csatTally accumulates the surveys of one score
+type csatTally struct {
+	sent    int
+	ratings [5]int
+}
+
+func (t *csatTally) add(survey entities.Survey) {
+	t.sent++
+	if survey.Rating != nil && *survey.Rating >= 1 && *survey.Rating <= 5 {
+		t.ratings[*survey.Rating-1]++
+	}
+}
+
+func (t *csatTally) score() CSATScore {
+	score := CSATScore{Sent: t.sent, Ratings: t.ratings}
+	total, satisfied := 0, 0
+	for i, n := range t.ratings {
+		score.Answered += n
+		total += (i + 1) * n
+		if i+1 >= satisfiedRating {
+			satisfied += n
+		}
+	}
+	if score.Sent > 0 {
+		score.ResponseRate = round(float64(score.Answered) / float64(score.Sent) * 100)
+	}
+	if score.Answered > 0 {
+		score.AverageRating = round(float64(total) / float64(score.Answered))
+		score.CSAT = round(float64(satisfied) / float64(score.Answered) * 100)
+	}
+	return score
+}
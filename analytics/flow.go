@@ -0,0 +1,324 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+)
+
+// FlowSeries is the number of issues in one status at the end of each bucket
+type FlowSeries struct {
+	StatusID    uint   `json:"status_id"`
+	StatusCode  string `json:"status_code"`
+	DisplayName string `json:"display_name"`
+	Color       string `json:"color"`
+	Counts      []int  `json:"counts"`
+}
+
+// CumulativeFlow is the data for a cumulative flow diagram: one series per
+// status, aligned with Periods
+type CumulativeFlow struct {
+	Periods []time.Time  `json:"periods"`
+	Series  []FlowSeries `json:"series"`
+}
+
+// HistogramBin counts durations in [MinHours, MaxHours). MaxHours is nil for
+// the open-ended last bin.
+type HistogramBin struct {
+	Label    string   `json:"label"`
+	MinHours float64  `json:"min_hours"`
+	MaxHours *float64 `json:"max_hours"`
+	Count    int      `json:"count"`
+}
+
+// Distribution describes cycle or lead times of completed issues
+type Distribution struct {
+	DurationStats
+	Reopened  int            `json:"reopened"`
+	Histogram []HistogramBin `json:"histogram"`
+	Items     []ItemDuration `json:"items"`
+}
+
+// ItemDuration is the measured duration of one issue
+type ItemDuration struct {
+	IssueID     uint      `json:"issue_id"`
+	CompletedAt time.Time `json:"completed_at"`
+	Hours       float64   `json:"hours"`
+	Reopened    bool      `json:"reopened"`
+}
+
+// AgingItem is one issue still in progress
+type AgingItem struct {
+	IssueID       uint    `json:"issue_id"`
+	Priority      string  `json:"priority"`
+	AgeHours      float64 `json:"age_hours"`
+	InStatusHours float64 `json:"in_status_hours"`
+}
+
+// AgingStatus groups work in progress by current status
+type AgingStatus struct {
+	StatusID    uint          `json:"status_id"`
+	StatusCode  string        `json:"status_code"`
+	DisplayName string        `json:"display_name"`
+	InStatus    DurationStats `json:"in_status"`
+	Items       []AgingItem   `json:"items"`
+}
+
+// histogramEdges are the bin boundaries in hours: 1 day, 2 days, 4 days,
+// 1 week, 2 weeks and 30 days
+var histogramEdges = []struct {
+	hours float64
+	label string
+}{
+	{0, "< 1d"}, {24, "1-2d"}, {48, "2-4d"}, {96, "4-7d"}, {168, "7-14d"}, {336, "14-30d"}, {720, "30d+"},
+}
+
+// CumulativeFlow counts issues per status at the end of every bucket.
+// Statuses are ordered by display order so the series stack naturally.
+func (ds *Dataset) CumulativeFlow(r Range, now time.Time) CumulativeFlow {
+	buckets := r.Buckets()
+	statusIDs := ds.orderedStatusIDs()
+
+	// Snapshot just before each bucket boundary; the times never decrease
+	at := make([]time.Time, len(buckets))
+	for b, start := range buckets {
+		end := r.next(start)
+		if end.After(r.To) {
+			end = r.To
+		}
+		if end.After(now) {
+			end = now
+		}
+		at[b] = end.Add(-time.Nanosecond)
+	}
+	// first returns the first snapshot at or after t
+	first := func(t time.Time) int {
+		return sort.Search(len(at), func(b int) bool { return !at[b].Before(t) })
+	}
+
+	// Every period in a status adds one to the snapshots it covers: the
+	// deltas mark where it starts and stops counting
+	deltas := make(map[uint][]int, len(statusIDs))
+	for _, id := range statusIDs {
+		deltas[id] = make([]int, len(buckets)+1)
+		deltas[id][0] = ds.Settled[id]
+	}
+	for i := range ds.Timelines {
+		for _, seg := range ds.Timelines[i].Segments() {
+			d, known := deltas[seg.StatusID]
+			if !known {
+				continue
+			}
+			end := len(at)
+			if seg.End != nil {
+				end = first(*seg.End)
+			}
+			if start := first(seg.Start); start < end {
+				d[start]++
+				d[end]--
+			}
+		}
+	}
+
+	flow := CumulativeFlow{Periods: buckets, Series: make([]FlowSeries, len(statusIDs))}
+	for i, id := range statusIDs {
+		status := ds.Statuses[id]
+		counts := make([]int, len(buckets))
+		running := 0
+		for b := range counts {
+			running += deltas[id][b]
+			counts[b] = running
+		}
+		flow.Series[i] = FlowSeries{
+			StatusID:    id,
+			StatusCode:  status.StatusCode,
+			DisplayName: status.DisplayName,
+			Color:       status.Color,
+			Counts:      counts,
+		}
+	}
+	return flow
+}
+
+// LeadTime measures from creation to the final close for issues whose final
+// close happened in the range. Time spent closed before a reopen is not
+// counted, so a reopened issue is measured by the time it was actually open.
+func (ds *Dataset) LeadTime(r Range) Distribution {
+	return ds.distribution(r, func(tl *Timeline) (time.Time, bool) {
+		return tl.CreatedAt, true
+	})
+}
+
+// CycleTime measures from the first time work started to the final close.
+// Work starts on the first transition into one of startStatuses, or when
+// startStatuses is empty, on the first move out of the initial status into a
+// non-terminal status. Issues closed without ever starting are skipped.
+func (ds *Dataset) CycleTime(r Range, startStatuses map[uint]bool) Distribution {
+	return ds.distribution(r, func(tl *Timeline) (time.Time, bool) {
+		initial := tl.InitialStatus()
+		for _, tr := range tl.Transitions {
+			if len(startStatuses) > 0 {
+				if startStatuses[tr.To] {
+					return tr.At, true
+				}
+				continue
+			}
+			if tr.To != initial && !ds.IsTerminal(tr.To) {
+				return tr.At, true
+			}
+		}
+		return time.Time{}, false
+	})
+}
+
+// distribution collects durations from start to the final close, excluding
+// any time spent in terminal statuses in between
+func (ds *Dataset) distribution(r Range, start func(*Timeline) (time.Time, bool)) Distribution {
+	dist := Distribution{Items: []ItemDuration{}}
+	var values []time.Duration
+
+	for i := range ds.Timelines {
+		tl := &ds.Timelines[i]
+		closedAt, ok := ds.finalClose(tl)
+		if !ok || !r.Contains(closedAt) {
+			continue
+		}
+		startedAt, ok := start(tl)
+		if !ok || startedAt.After(closedAt) {
+			continue
+		}
+
+		elapsed := closedAt.Sub(startedAt)
+		reopened := false
+		for _, seg := range tl.Segments() {
+			// A closed period that ended before the final close was reopened
+			if !ds.IsTerminal(seg.StatusID) || seg.End == nil || seg.End.After(closedAt) {
+				continue
+			}
+			reopened = true
+
+			// Only the part after work started counts against the duration
+			segStart := seg.Start
+			if segStart.Before(startedAt) {
+				segStart = startedAt
+			}
+			if seg.End.After(segStart) {
+				elapsed -= seg.End.Sub(segStart)
+			}
+		}
+
+		values = append(values, elapsed)
+		if reopened {
+			dist.Reopened++
+		}
+		dist.Items = append(dist.Items, ItemDuration{
+			IssueID:     tl.IssueID,
+			CompletedAt: closedAt,
+			Hours:       round(elapsed.Hours()),
+			Reopened:    reopened,
+		})
+	}
+
+	dist.DurationStats = Summarize(values)
+	dist.Histogram = histogram(values)
+	return dist
+}
+
+// Aging lists issues in a non-terminal status at now with their total age
+// and the time spent in their current status
+func (ds *Dataset) Aging(now time.Time) []AgingStatus {
+	groups := map[uint]*AgingStatus{}
+	durations := map[uint][]time.Duration{}
+
+	for i := range ds.Timelines {
+		tl := &ds.Timelines[i]
+		if tl.CreatedAt.After(now) {
+			continue
+		}
+
+		// Find the period the issue is in at now
+		var current Segment
+		for _, seg := range tl.Segments() {
+			if !seg.Start.After(now) {
+				current = seg
+			}
+		}
+		if ds.IsTerminal(current.StatusID) {
+			continue
+		}
+
+		group, ok := groups[current.StatusID]
+		if !ok {
+			status := ds.Statuses[current.StatusID]
+			group = &AgingStatus{
+				StatusID:    current.StatusID,
+				StatusCode:  status.StatusCode,
+				DisplayName: status.DisplayName,
+				Items:       []AgingItem{},
+			}
+			groups[current.StatusID] = group
+		}
+
+		inStatus := now.Sub(current.Start)
+		durations[current.StatusID] = append(durations[current.StatusID], inStatus)
+		group.Items = append(group.Items, AgingItem{
+			IssueID:       tl.IssueID,
+			Priority:      tl.Priority,
+			AgeHours:      round(now.Sub(tl.CreatedAt).Hours()),
+			InStatusHours: round(inStatus.Hours()),
+		})
+	}
+
+	result := make([]AgingStatus, 0, len(groups))
+	for _, id := range ds.orderedStatusIDs() {
+		group, ok := groups[id]
+		if !ok {
+			continue
+		}
+		// Oldest work first
+		sort.Slice(group.Items, func(i, j int) bool {
+			return group.Items[i].InStatusHours > group.Items[j].InStatusHours
+		})
+		group.InStatus = Summarize(durations[id])
+		result = append(result, *group)
+	}
+	return result
+}
+
+// orderedStatusIDs returns every known status sorted by display order
+func (ds *Dataset) orderedStatusIDs() []uint {
+	ids := make([]uint, 0, len(ds.Statuses))
+	for id := range ds.Statuses {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		oi, oj := ds.Statuses[ids[i]].DisplayOrder, ds.Statuses[ids[j]].DisplayOrder
+		if oi != oj {
+			return oi < oj
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// histogram sorts durations into the fixed bins
+func histogram(values []time.Duration) []HistogramBin {
+	bins := make([]HistogramBin, len(histogramEdges))
+	for i, edge := range histogramEdges {
+		bins[i] = HistogramBin{Label: edge.label, MinHours: edge.hours}
+		if i+1 < len(histogramEdges) {
+			max := histogramEdges[i+1].hours
+			bins[i].MaxHours = &max
+		}
+	}
+
+	for _, v := range values {
+		hours := v.Hours()
+		for i := len(bins) - 1; i >= 0; i-- {
+			if hours >= bins[i].MinHours {
+				bins[i].Count++
+				break
+			}
+		}
+	}
+	return bins
+}
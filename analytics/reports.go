@@ -0,0 +1,293 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ThroughputPoint counts issues created and closed in one bucket
+type ThroughputPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	Created     int       `json:"created"`
+	Closed      int       `json:"closed"`
+}
+
+// BacklogPoint is the number of open issues at the end of a bucket
+type BacklogPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	At          time.Time `json:"at"`
+	Open        int       `json:"open"`
+}
+
+// DurationStats summarizes a set of durations in hours
+type DurationStats struct {
+	Count     int     `json:"count"`
+	MeanHours float64 `json:"mean_hours"`
+	P50Hours  float64 `json:"p50_hours"`
+	P90Hours  float64 `json:"p90_hours"`
+	P95Hours  float64 `json:"p95_hours"`
+	MaxHours  float64 `json:"max_hours"`
+}
+
+// StatusDuration is the time spent in one status
+type StatusDuration struct {
+	StatusID    uint   `json:"status_id"`
+	StatusCode  string `json:"status_code"`
+	DisplayName string `json:"display_name"`
+	DurationStats
+}
+
+// BreakdownRow counts issues created in the range sharing one key
+type BreakdownRow struct {
+	Key    string `json:"key"`
+	Label  string `json:"label"`
+	Total  int    `json:"total"`
+	Open   int    `json:"open"`
+	Closed int    `json:"closed"`
+}
+
+// Supported breakdown dimensions
+const (
+	ByPriority = "priority"
+	ByAssignee = "assignee"
+	ByStatus   = "status"
+)
+
+// isClose reports whether a transition moves an issue into a terminal status
+func (ds *Dataset) isClose(tr Transition) bool {
+	if !ds.IsTerminal(tr.To) {
+		return false
+	}
+	return tr.From == nil || !ds.IsTerminal(*tr.From)
+}
+
+// Throughput counts issues created and closed per bucket. An issue that is
+// closed, reopened and closed again counts as closed twice.
+func (ds *Dataset) Throughput(r Range) []ThroughputPoint {
+	buckets := r.Buckets()
+	indexOf := r.bucketIndexer(buckets)
+
+	points := make([]ThroughputPoint, len(buckets))
+	for i, b := range buckets {
+		points[i].PeriodStart = b
+	}
+
+	for _, tl := range ds.Timelines {
+		if r.Contains(tl.CreatedAt) {
+			if i := indexOf(tl.CreatedAt); i >= 0 {
+				points[i].Created++
+			}
+		}
+		for _, tr := range tl.Transitions {
+			if r.Contains(tr.At) && ds.isClose(tr) {
+				if i := indexOf(tr.At); i >= 0 {
+					points[i].Closed++
+				}
+			}
+		}
+	}
+	return points
+}
+
+// Backlog counts issues in a non-terminal status at the end of each bucket,
+// or at now for a bucket that has not finished yet
+func (ds *Dataset) Backlog(r Range, now time.Time) []BacklogPoint {
+	// Every open period adds one to the backlog when it starts and removes
+	// one when it ends; sweeping the sorted deltas gives the count at any time
+	type delta struct {
+		at    time.Time
+		value int
+	}
+	var deltas []delta
+	for _, tl := range ds.Timelines {
+		for _, seg := range tl.Segments() {
+			if ds.IsTerminal(seg.StatusID) {
+				continue
+			}
+			deltas = append(deltas, delta{seg.Start, 1})
+			if seg.End != nil {
+				deltas = append(deltas, delta{*seg.End, -1})
+			}
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].at.Before(deltas[j].at) })
+
+	buckets := r.Buckets()
+	points := make([]BacklogPoint, len(buckets))
+	open, next := 0, 0
+	for i, b := range buckets {
+		at := r.next(b)
+		if at.After(r.To) {
+			at = r.To
+		}
+		if at.After(now) {
+			at = now
+		}
+		// Snapshot just before the bucket boundary
+		at = at.Add(-time.Nanosecond)
+
+		for next < len(deltas) && !deltas[next].at.After(at) {
+			open += deltas[next].value
+			next++
+		}
+		points[i] = BacklogPoint{PeriodStart: b, At: at, Open: open}
+	}
+	return points
+}
+
+// TimeInStatus summarizes how long issues stayed in each non-terminal status.
+// Only periods that ended inside the range are included.
+func (ds *Dataset) TimeInStatus(r Range) []StatusDuration {
+	durations := map[uint][]time.Duration{}
+	for _, tl := range ds.Timelines {
+		for _, seg := range tl.Segments() {
+			if seg.End == nil || !r.Contains(*seg.End) || ds.IsTerminal(seg.StatusID) {
+				continue
+			}
+			durations[seg.StatusID] = append(durations[seg.StatusID], seg.End.Sub(seg.Start))
+		}
+	}
+
+	rows := make([]StatusDuration, 0, len(durations))
+	for statusID, values := range durations {
+		status := ds.Statuses[statusID]
+		rows = append(rows, StatusDuration{
+			StatusID:      statusID,
+			StatusCode:    status.StatusCode,
+			DisplayName:   status.DisplayName,
+			DurationStats: Summarize(values),
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		oi, oj := ds.Statuses[rows[i].StatusID].DisplayOrder, ds.Statuses[rows[j].StatusID].DisplayOrder
+		if oi != oj {
+			return oi < oj
+		}
+		return rows[i].StatusID < rows[j].StatusID
+	})
+	return rows
+}
+
+// TimeToClose summarizes the time from creation to the final close for
+// issues that are closed now and whose final close happened in the range
+func (ds *Dataset) TimeToClose(r Range) DurationStats {
+	var values []time.Duration
+	for _, tl := range ds.Timelines {
+		if closedAt, ok := ds.finalClose(&tl); ok && r.Contains(closedAt) {
+			values = append(values, closedAt.Sub(tl.CreatedAt))
+		}
+	}
+	return Summarize(values)
+}
+
+// finalClose returns when the issue last entered a terminal status, if it is
+// still in one
+func (ds *Dataset) finalClose(tl *Timeline) (time.Time, bool) {
+	if len(tl.Transitions) == 0 {
+		return time.Time{}, false
+	}
+	last := len(tl.Transitions) - 1
+	if !ds.IsTerminal(tl.Transitions[last].To) {
+		return time.Time{}, false
+	}
+	for i := last; i >= 0; i-- {
+		if ds.isClose(tl.Transitions[i]) {
+			return tl.Transitions[i].At, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Breakdown groups issues created in the range by priority, assignee or
+// current status
+func (ds *Dataset) Breakdown(r Range, by string) []BreakdownRow {
+	rows := map[string]*BreakdownRow{}
+	var order []string
+
+	for _, tl := range ds.Timelines {
+		if !r.Contains(tl.CreatedAt) {
+			continue
+		}
+
+		key, label := ds.breakdownKey(&tl, by)
+		row, ok := rows[key]
+		if !ok {
+			row = &BreakdownRow{Key: key, Label: label}
+			rows[key] = row
+			order = append(order, key)
+		}
+
+		row.Total++
+		if ds.IsTerminal(tl.StatusID) {
+			row.Closed++
+		} else {
+			row.Open++
+		}
+	}
+
+	sort.Strings(order)
+	result := make([]BreakdownRow, 0, len(order))
+	for _, key := range order {
+		result = append(result, *rows[key])
+	}
+	return result
+}
+
+// breakdownKey returns the grouping key and a display label for an issue
+func (ds *Dataset) breakdownKey(tl *Timeline, by string) (string, string) {
+	switch by {
+	case ByAssignee:
+		if tl.AssigneeID == nil || *tl.AssigneeID == 0 {
+			return "unassigned", "Unassigned"
+		}
+		key := strconv.FormatUint(uint64(*tl.AssigneeID), 10)
+		return key, ds.Officers[*tl.AssigneeID].FullName
+	case ByStatus:
+		status := ds.Statuses[tl.StatusID]
+		return status.StatusCode, status.DisplayName
+	default:
+		return tl.Priority, tl.Priority
+	}
+}
+
+// Summarize computes mean, percentiles and maximum of durations in hours
+func Summarize(values []time.Duration) DurationStats {
+	stats := DurationStats{Count: len(values)}
+	if len(values) == 0 {
+		return stats
+	}
+
+	hours := make([]float64, len(values))
+	total := 0.0
+	for i, v := range values {
+		hours[i] = v.Hours()
+		total += hours[i]
+	}
+	sort.Float64s(hours)
+
+	stats.MeanHours = round(total / float64(len(hours)))
+	stats.P50Hours = round(percentile(hours, 50))
+	stats.P90Hours = round(percentile(hours, 90))
+	stats.P95Hours = round(percentile(hours, 95))
+	stats.MaxHours = round(hours[len(hours)-1])
+	return stats
+}
+
+// percentile interpolates linearly between the closest ranks of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	weight := rank - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[upper]*weight
+}
+
+// round keeps two decimals so JSON output stays readable
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,285 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"issue-tracking/entities"
+)
+
+const (
+	statusOpen       uint = 1
+	statusInProgress uint = 2
+	statusClosed     uint = 3
+)
+
+func day(d int, hour int) time.Time {
+	return time.Date(2024, 1, d, hour, 0, 0, 0, time.UTC)
+}
+
+func ptr(v uint) *uint {
+	return &v
+}
+
+// testDataset has three issues:
+//  1. created Jan 1, in progress Jan 2, closed Jan 3
+//  2. created Jan 2, closed Jan 4, reopened Jan 5, closed Jan 6
+//  3. created Jan 3, still open
+func testDataset() *Dataset {
+	return &Dataset{
+		Statuses: map[uint]entities.IssueStatus{
+			statusOpen:       {StatusID: statusOpen, StatusCode: "open", DisplayOrder: 1},
+			statusInProgress: {StatusID: statusInProgress, StatusCode: "in-progress", DisplayOrder: 2},
+			statusClosed:     {StatusID: statusClosed, StatusCode: "closed", DisplayOrder: 3, IsTerminal: true},
+		},
+		Officers: map[uint]entities.Officer{},
+		Timelines: []Timeline{
+			{
+				IssueID: 1, Priority: "high", StatusID: statusClosed, CreatedAt: day(1, 0),
+				Transitions: []Transition{
+					{At: day(2, 0), From: ptr(statusOpen), To: statusInProgress},
+					{At: day(3, 0), From: ptr(statusInProgress), To: statusClosed},
+				},
+			},
+			{
+				IssueID: 2, Priority: "low", StatusID: statusClosed, CreatedAt: day(2, 0),
+				Transitions: []Transition{
+					{At: day(4, 0), From: ptr(statusOpen), To: statusClosed},
+					{At: day(5, 0), From: ptr(statusClosed), To: statusOpen},
+					{At: day(6, 0), From: ptr(statusOpen), To: statusClosed},
+				},
+			},
+			{IssueID: 3, Priority: "high", StatusID: statusOpen, CreatedAt: day(3, 12)},
+		},
+	}
+}
+
+func testRange() Range {
+	return Range{From: day(1, 0), To: day(8, 0), Bucket: BucketDay, Location: time.UTC}
+}
+
+func TestThroughputCountsReopenedIssuesEachTimeTheyClose(t *testing.T) {
+	points := testDataset().Throughput(testRange())
+	if len(points) != 7 {
+		t.Fatalf("expected 7 daily buckets, got %d", len(points))
+	}
+
+	created := []int{1, 1, 1, 0, 0, 0, 0}
+	closed := []int{0, 0, 1, 1, 0, 1, 0}
+	for i, p := range points {
+		if p.Created != created[i] || p.Closed != closed[i] {
+			t.Errorf("day %d: expected created=%d closed=%d, got created=%d closed=%d",
+				i+1, created[i], closed[i], p.Created, p.Closed)
+		}
+	}
+}
+
+func TestBacklogReconstructedFromHistory(t *testing.T) {
+	points := testDataset().Backlog(testRange(), day(8, 0))
+
+	// End of Jan 1..7
+	expected := []int{1, 2, 2, 1, 2, 1, 1}
+	for i, p := range points {
+		if p.Open != expected[i] {
+			t.Errorf("day %d: expected %d open issues, got %d", i+1, expected[i], p.Open)
+		}
+	}
+}
+
+func TestTimeInStatusSkipsTerminalAndUnfinishedPeriods(t *testing.T) {
+	rows := testDataset().TimeInStatus(testRange())
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 statuses, got %d", len(rows))
+	}
+
+	// Open: issue 1 for 24h, issue 2 for 48h and again for 24h after reopening
+	if rows[0].StatusID != statusOpen || rows[0].Count != 3 || rows[0].MeanHours != 32 {
+		t.Errorf("unexpected open stats: %+v", rows[0])
+	}
+	if rows[1].StatusID != statusInProgress || rows[1].Count != 1 || rows[1].MeanHours != 24 {
+		t.Errorf("unexpected in-progress stats: %+v", rows[1])
+	}
+}
+
+func TestTimeToCloseUsesFinalClose(t *testing.T) {
+	stats := testDataset().TimeToClose(testRange())
+
+	// Issue 1: 48h, issue 2: 96h (Jan 2 to Jan 6)
+	if stats.Count != 2 || stats.MeanHours != 72 || stats.MaxHours != 96 {
+		t.Errorf("unexpected time-to-close stats: %+v", stats)
+	}
+}
+
+func TestBreakdownByPriority(t *testing.T) {
+	rows := testDataset().Breakdown(testRange(), ByPriority)
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 priorities, got %d", len(rows))
+	}
+	if rows[0].Key != "high" || rows[0].Total != 2 || rows[0].Open != 1 || rows[0].Closed != 1 {
+		t.Errorf("unexpected high row: %+v", rows[0])
+	}
+	if rows[1].Key != "low" || rows[1].Total != 1 || rows[1].Closed != 1 {
+		t.Errorf("unexpected low row: %+v", rows[1])
+	}
+}
+
+func TestSummarizePercentiles(t *testing.T) {
+	var values []time.Duration
+	for i := 1; i <= 100; i++ {
+		values = append(values, time.Duration(i)*time.Hour)
+	}
+
+	stats := Summarize(values)
+	if stats.Count != 100 || stats.MeanHours != 50.5 || stats.P50Hours != 50.5 || stats.P90Hours != 90.1 || stats.MaxHours != 100 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestWeeklyBucketsStartOnMonday(t *testing.T) {
+	// Jan 3 2024 is a Wednesday
+	r := Range{From: day(3, 0), To: day(20, 0), Bucket: BucketWeek, Location: time.UTC}
+	buckets := r.Buckets()
+	if len(buckets) != 3 || !buckets[0].Equal(day(1, 0)) || !buckets[2].Equal(day(15, 0)) {
+		t.Errorf("unexpected weekly buckets: %v", buckets)
+	}
+}
+
+func TestBucketsExceed(t *testing.T) {
+	r := Range{From: day(3, 0), To: day(20, 0), Bucket: BucketWeek, Location: time.UTC}
+	if r.BucketsExceed(3) || !r.BucketsExceed(2) {
+		t.Errorf("3 weekly buckets: exceed 3 = %t, exceed 2 = %t", r.BucketsExceed(3), r.BucketsExceed(2))
+	}
+
+	huge := Range{
+		From:     time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+		Bucket:   BucketDay,
+		Location: time.UTC,
+	}
+	if !huge.BucketsExceed(1000) {
+		t.Error("daily buckets over 10000 years do not exceed 1000")
+	}
+}
+
+func TestCumulativeFlowCountsPerStatusPerDay(t *testing.T) {
+	flow := testDataset().CumulativeFlow(testRange(), day(8, 0))
+	if len(flow.Periods) != 7 || len(flow.Series) != 3 {
+		t.Fatalf("expected 7 periods and 3 series, got %d and %d", len(flow.Periods), len(flow.Series))
+	}
+
+	expected := map[uint][]int{
+		statusOpen:       {1, 1, 2, 1, 2, 1, 1},
+		statusInProgress: {0, 1, 0, 0, 0, 0, 0},
+		statusClosed:     {0, 0, 1, 2, 1, 2, 2},
+	}
+	for _, series := range flow.Series {
+		for i, count := range series.Counts {
+			if count != expected[series.StatusID][i] {
+				t.Errorf("status %s day %d: expected %d, got %d", series.StatusCode, i+1, expected[series.StatusID][i], count)
+			}
+		}
+	}
+}
+
+func TestLeadTimeExcludesTimeSpentClosedBeforeReopen(t *testing.T) {
+	dist := testDataset().LeadTime(testRange())
+	if dist.Count != 2 || dist.Reopened != 1 {
+		t.Fatalf("expected 2 items with 1 reopened, got %+v", dist)
+	}
+
+	// Issue 2 was open Jan 2-4 and Jan 5-6: 72h, not the 96h elapsed
+	for _, item := range dist.Items {
+		if item.IssueID == 2 && (item.Hours != 72 || !item.Reopened) {
+			t.Errorf("unexpected lead time for reopened issue: %+v", item)
+		}
+		if item.IssueID == 1 && item.Hours != 48 {
+			t.Errorf("unexpected lead time for issue 1: %+v", item)
+		}
+	}
+
+	// 48h and 72h both fall in the 2-4 day bin
+	if dist.Histogram[2].Count != 2 || dist.Histogram[3].Count != 0 || dist.Histogram[2].Label != "2-4d" {
+		t.Errorf("unexpected histogram: %+v", dist.Histogram)
+	}
+}
+
+func TestCycleTimeStartsWhenWorkStarts(t *testing.T) {
+	ds := testDataset()
+
+	// Only issue 1 ever moved to a non-terminal status other than open
+	dist := ds.CycleTime(testRange(), nil)
+	if dist.Count != 1 || dist.Items[0].IssueID != 1 || dist.Items[0].Hours != 24 {
+		t.Errorf("unexpected cycle time: %+v", dist)
+	}
+
+	// Counting from the reopen makes issue 2 start on Jan 5
+	dist = ds.CycleTime(testRange(), map[uint]bool{statusOpen: true, statusInProgress: true})
+	for _, item := range dist.Items {
+		if item.IssueID == 2 && item.Hours != 24 {
+			t.Errorf("unexpected cycle time for issue 2: %+v", item)
+		}
+	}
+}
+
+func TestAgingListsOpenWork(t *testing.T) {
+	aging := testDataset().Aging(day(5, 12))
+	if len(aging) != 1 || aging[0].StatusID != statusOpen || len(aging[0].Items) != 2 {
+		t.Fatalf("unexpected aging: %+v", aging)
+	}
+
+	// Issue 3 has been open since Jan 3 12:00, issue 2 since its reopen on Jan 5
+	if aging[0].Items[0].IssueID != 3 || aging[0].Items[0].InStatusHours != 48 {
+		t.Errorf("expected oldest item to be issue 3 at 48h, got %+v", aging[0].Items[0])
+	}
+	if aging[0].Items[1].IssueID != 2 || aging[0].Items[1].InStatusHours != 12 || aging[0].Items[1].AgeHours != 84 {
+		t.Errorf("unexpected reopened item: %+v", aging[0].Items[1])
+	}
+}
+
+func TestCSATScoresByOfficerAndPeriod(t *testing.T) {
+	rating := func(v int) *int { return &v }
+	surveys := []entities.Survey{
+		{SurveyID: 1, OfficerID: ptr(1), Rating: rating(5), CreatedAt: day(1, 9)},
+		{SurveyID: 2, OfficerID: ptr(1), Rating: rating(2), CreatedAt: day(1, 10)},
+		{SurveyID: 3, OfficerID: ptr(2), CreatedAt: day(3, 9)},
+		{SurveyID: 4, Rating: rating(4), CreatedAt: day(3, 10)},
+		// Sent before the range
+		{SurveyID: 5, OfficerID: ptr(2), Rating: rating(1), CreatedAt: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	officers := map[uint]entities.Officer{1: {OfficerID: 1, FullName: "Jane Smith"}, 2: {OfficerID: 2, FullName: "Bob Brown"}}
+
+	report := CSAT(testRange(), surveys, officers)
+	overall := report.Overall
+	if overall.Sent != 4 || overall.Answered != 3 || overall.ResponseRate != 75 || overall.AverageRating != 3.67 || overall.CSAT != 66.67 {
+		t.Errorf("unexpected overall score: %+v", overall)
+	}
+	if overall.Ratings != [5]int{0, 1, 0, 1, 1} {
+		t.Errorf("unexpected ratings: %v", overall.Ratings)
+	}
+
+	if len(report.Officers) != 3 {
+		t.Fatalf("expected 3 officer rows, got %+v", report.Officers)
+	}
+	if jane := report.Officers[0]; jane.Key != "1" || jane.Label != "Jane Smith" || jane.Answered != 2 || jane.CSAT != 50 {
+		t.Errorf("unexpected row for Jane: %+v", jane)
+	}
+	if bob := report.Officers[1]; bob.Key != "2" || bob.Sent != 1 || bob.Answered != 0 || bob.CSAT != 0 {
+		t.Errorf("unexpected row for Bob: %+v", bob)
+	}
+	if report.Officers[2].Key != "unassigned" || report.Officers[2].CSAT != 100 {
+		t.Errorf("unexpected unassigned row: %+v", report.Officers[2])
+	}
+
+	if len(report.Periods) != 7 {
+		t.Fatalf("expected 7 days, got %d", len(report.Periods))
+	}
+	if p := report.Periods[0]; p.Sent != 2 || p.AverageRating != 3.5 {
+		t.Errorf("unexpected first day: %+v", p)
+	}
+	if p := report.Periods[2]; p.Sent != 2 || p.Answered != 1 || p.ResponseRate != 50 {
+		t.Errorf("unexpected third day: %+v", p)
+	}
+	if p := report.Periods[1]; p.Sent != 0 || p.CSAT != 0 {
+		t.Errorf("unexpected second day: %+v", p)
+	}
+}
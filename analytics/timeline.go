@@ -0,0 +1,176 @@
+// Package analytics reconstructs issue lifecycles from IssueStatusHistory and
+// computes throughput, backlog and duration statistics from them.
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+)
+
+// Transition is one recorded status change
+type Transition struct {
+	At   time.Time
+	From *uint
+	To   uint
+}
+
+// Timeline is the status history of one issue in chronological order
+type Timeline struct {
+	IssueID     uint
+	Priority    string
+	AssigneeID  *uint
+	StatusID    uint
+	CreatedAt   time.Time
+	Transitions []Transition
+}
+
+// InitialStatus returns the status the issue was created with
+func (tl *Timeline) InitialStatus() uint {
+	if len(tl.Transitions) > 0 && tl.Transitions[0].From != nil {
+		return *tl.Transitions[0].From
+	}
+	if len(tl.Transitions) > 0 {
+		return tl.Transitions[0].To
+	}
+	return tl.StatusID
+}
+
+// StatusAt returns the status the issue had at t. ok is false before the
+// issue was created.
+func (tl *Timeline) StatusAt(t time.Time) (status uint, ok bool) {
+	if t.Before(tl.CreatedAt) {
+		return 0, false
+	}
+	status = tl.InitialStatus()
+	for _, tr := range tl.Transitions {
+		if tr.At.After(t) {
+			break
+		}
+		status = tr.To
+	}
+	return status, true
+}
+
+// Segment is a period an issue spent in one status. End is nil while the
+// issue is still in that status.
+type Segment struct {
+	StatusID uint
+	Start    time.Time
+	End      *time.Time
+}
+
+// Segments splits the timeline into consecutive periods per status
+func (tl *Timeline) Segments() []Segment {
+	segments := make([]Segment, 0, len(tl.Transitions)+1)
+	current := Segment{StatusID: tl.InitialStatus(), Start: tl.CreatedAt}
+	for _, tr := range tl.Transitions {
+		// Transitions that do not change the status (e.g. bulk assignment)
+		// do not start a new segment
+		if tr.To == current.StatusID {
+			continue
+		}
+		at := tr.At
+		current.End = &at
+		segments = append(segments, current)
+		current = Segment{StatusID: tr.To, Start: tr.At}
+	}
+	return append(segments, current)
+}
+
+// Dataset holds everything the reports need from the database
+type Dataset struct {
+	Timelines []Timeline
+	// Settled counts, per status, the issues left out of Timelines because
+	// they have kept that terminal status for the whole period
+	Settled  map[uint]int
+	Statuses map[uint]entities.IssueStatus
+	Officers map[uint]entities.Officer
+}
+
+// IsTerminal reports whether statusID is flagged as terminal
+func (ds *Dataset) IsTerminal(statusID uint) bool {
+	return ds.Statuses[statusID].IsTerminal
+}
+
+// Load reads the issues created before until whose status may change
+// between since and until, together with their full status history, which
+// is needed to know the initial status of each issue. Issues closed before
+// since and untouched since then are only counted in Settled.
+func Load(ctx context.Context, store repositories.Store, since, until time.Time) (*Dataset, error) {
+	ds := &Dataset{
+		Settled:  map[uint]int{},
+		Statuses: map[uint]entities.IssueStatus{},
+		Officers: map[uint]entities.Officer{},
+	}
+
+	statuses, err := store.Statuses().List(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range statuses {
+		ds.Statuses[s.StatusID] = s
+	}
+
+	issues, err := store.Issues().ListActive(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := store.Issues().CountByStatus(ctx, until)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[uint]int, len(issues))
+	ds.Timelines = make([]Timeline, len(issues))
+	var officerIDs []uint
+	for i, issue := range issues {
+		index[issue.IssueID] = i
+		ds.Timelines[i] = Timeline{
+			IssueID:    issue.IssueID,
+			Priority:   issue.Priority,
+			AssigneeID: issue.AssigneeID,
+			StatusID:   issue.StatusID,
+			CreatedAt:  issue.CreatedAt,
+		}
+		counts[issue.StatusID]--
+		if issue.AssigneeID != nil {
+			officerIDs = append(officerIDs, *issue.AssigneeID)
+		}
+	}
+	for statusID, count := range counts {
+		if count > 0 {
+			ds.Settled[statusID] = count
+		}
+	}
+
+	if len(officerIDs) > 0 {
+		officers, err := store.Officers().ListByIDs(ctx, officerIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range officers {
+			ds.Officers[o.OfficerID] = o
+		}
+	}
+
+	history, err := store.Issues().ActiveHistory(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range history {
+		i, ok := index[h.IssueID]
+		if !ok {
+			continue
+		}
+		ds.Timelines[i].Transitions = append(ds.Timelines[i].Transitions, Transition{
+			At:   h.ChangedAt,
+			From: h.OldStatusID,
+			To:   h.NewStatusID,
+		})
+	}
+
+	return ds, nil
+}
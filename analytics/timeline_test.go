@@ -0,0 +1,116 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"issue-tracking/database/dbtest"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+)
+
+func TestLoadSkipsIssuesSettledBeforeThePeriod(t *testing.T) {
+	stores := map[string]func(t *testing.T) repositories.Store{
+		"memory": func(*testing.T) repositories.Store { return repositories.NewMemoryStore() },
+	}
+	for _, driver := range dbtest.Drivers() {
+		stores[driver] = func(t *testing.T) repositories.Store { return repositories.NewGormStore(dbtest.Open(t, driver)) }
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			testLoadSkipsSettledIssues(t, open(t))
+		})
+	}
+}
+
+func testLoadSkipsSettledIssues(t *testing.T, store repositories.Store) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	week := now.AddDate(0, 0, -7)
+
+	var open, closed entities.IssueStatus
+	for _, status := range []*entities.IssueStatus{&open, &closed} {
+		*status = entities.IssueStatus{StatusCode: "open", DisplayName: "Open", Color: "#000000", IsActive: true}
+		if status == &closed {
+			status.StatusCode, status.DisplayName, status.IsTerminal = "closed", "Closed", true
+		}
+		if err := store.Statuses().Create(ctx, status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reporter := entities.User{FullName: "Ann Reporter"}
+	if err := store.Users().Create(ctx, &reporter); err != nil {
+		t.Fatal(err)
+	}
+	officers := []entities.Officer{{FullName: "Jane Smith"}, {FullName: "Bob Brown"}}
+	for i := range officers {
+		if err := store.Officers().Create(ctx, &officers[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	issue := func(title string, status uint, assignee *uint, createdAt time.Time) uint {
+		t.Helper()
+		row := entities.Issue{Title: title, Priority: "medium", ReporterID: reporter.UserID, StatusID: status, AssigneeID: assignee, CreatedAt: createdAt}
+		if err := store.Issues().Create(ctx, &row); err != nil {
+			t.Fatal(err)
+		}
+		return row.IssueID
+	}
+	closedInPeriod := issue("Closed in the period", open.StatusID, nil, week)
+	settled := issue("Closed long ago", closed.StatusID, &officers[0].OfficerID, week)
+	stillOpen := issue("Still open", open.StatusID, &officers[1].OfficerID, week)
+	issue("Created after the period", open.StatusID, nil, now.Add(time.Hour))
+	if err := store.Issues().ApplyChange(ctx, closedInPeriod, repositories.IssueChange{NewStatusID: &closed.StatusID, ChangedBy: officers[0].OfficerID}); err != nil {
+		t.Fatal(err)
+	}
+
+	ds, err := Load(ctx, store, now.Add(-time.Hour), now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.Timelines) != 2 || ds.Timelines[0].IssueID != closedInPeriod || ds.Timelines[1].IssueID != stillOpen {
+		t.Fatalf("timelines = %+v, want issues %d and %d", ds.Timelines, closedInPeriod, stillOpen)
+	}
+	if len(ds.Timelines[0].Transitions) != 1 || ds.Timelines[0].InitialStatus() != open.StatusID {
+		t.Errorf("transitions = %+v", ds.Timelines[0].Transitions)
+	}
+	if len(ds.Settled) != 1 || ds.Settled[closed.StatusID] != 1 {
+		t.Errorf("settled = %v, want issue %d counted as closed", ds.Settled, settled)
+	}
+	if len(ds.Officers) != 1 || ds.Officers[officers[1].OfficerID].FullName != "Bob Brown" {
+		t.Errorf("officers = %v, want only the assignee in scope", ds.Officers)
+	}
+	if len(ds.Statuses) != 2 {
+		t.Errorf("statuses = %v", ds.Statuses)
+	}
+}
+
+func TestCumulativeFlowCountsSettledIssues(t *testing.T) {
+	ds := testDataset()
+	ds.Settled = map[uint]int{statusClosed: 5}
+	flow := ds.CumulativeFlow(testRange(), day(8, 0))
+	for _, series := range flow.Series {
+		if series.StatusID == statusClosed && (series.Counts[0] != 5 || series.Counts[6] != 7) {
+			t.Errorf("closed counts = %v, want 5 settled issues in every bucket", series.Counts)
+		}
+	}
+}
+
+func TestCumulativeFlowStopsAtNow(t *testing.T) {
+	// Buckets after now repeat the snapshot at now, before issue 3 exists
+	flow := testDataset().CumulativeFlow(testRange(), day(3, 6))
+	expected := map[uint][]int{
+		statusOpen:       {1, 1, 1, 1, 1, 1, 1},
+		statusInProgress: {0, 1, 0, 0, 0, 0, 0},
+		statusClosed:     {0, 0, 1, 1, 1, 1, 1},
+	}
+	for _, series := range flow.Series {
+		for i, count := range series.Counts {
+			if count != expected[series.StatusID][i] {
+				t.Errorf("status %s day %d: expected %d, got %d", series.StatusCode, i+1, expected[series.StatusID][i], count)
+			}
+		}
+	}
+}
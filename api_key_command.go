@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"issue-tracking/auth"
+	"issue-tracking/repositories"
+	"issue-tracking/services"
+)
+
+// runCreateAPIKeyCommand implements `issue-tracking create-api-key [flags] NAME`.
+// It is how the first key with the admin scope is made; later keys can be
+// created through the API with it.
+func runCreateAPIKeyCommand(args []string) error {
+	fs := flag.NewFlagSet("create-api-key", flag.ExitOnError)
+	scopes := fs.String("scopes", auth.ScopeIssuesWrite, "comma-separated scopes: "+strings.Join(auth.Scopes, ", "))
+	expires := fs.Duration("expires", 0, "how long the key is valid, such as 720h; it never expires when 0")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: issue-tracking create-api-key [flags] NAME")
+		fmt.Fprintln(fs.Output(), "Prints the key, which cannot be shown again.")
+		fs.PrintDefaults()
+	}
+	cfg := loadConfig(fs, args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one name")
+	}
+
+	req := services.NewAPIKey{Name: fs.Arg(0), Scopes: strings.Split(*scopes, ",")}
+	if *expires > 0 {
+		at := time.Now().Add(*expires)
+		req.ExpiresAt = &at
+	}
+
+	db, shutdown, err := setup(cfg, os.Stderr, cfg.Database.AutoMigrate)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	key, err := services.NewAPIKeyService(repositories.NewGormStore(db)).Create(context.Background(), req)
+	if err != nil {
+		var serviceErr *services.Error
+		if errors.As(err, &serviceErr) && len(serviceErr.ValidationErrors()) > 0 {
+			return fmt.Errorf("%s", serviceErr.ValidationErrors()[0].Message)
+		}
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "created API key %d\n", key.APIKeyID)
+	fmt.Println(key.Key)
+	return nil
+}
+
+// runRevokeAPIKeyCommand implements `issue-tracking revoke-api-key ID`
+func runRevokeAPIKeyCommand(args []string) error {
+	fs := flag.NewFlagSet("revoke-api-key", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: issue-tracking revoke-api-key [flags] ID")
+		fs.PrintDefaults()
+	}
+	cfg := loadConfig(fs, args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one API key ID")
+	}
+	id, err := strconv.ParseUint(fs.Arg(0), 10, 32)
+	if err != nil {
+		return fmt.Errorf("API key ID must be a positive integer")
+	}
+
+	db, shutdown, err := setup(cfg, os.Stderr, cfg.Database.AutoMigrate)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	key, err := services.NewAPIKeyService(repositories.NewGormStore(db)).Revoke(context.Background(), uint(id))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("revoked API key %d (%s)\n", key.APIKeyID, key.Name)
+	return nil
+}
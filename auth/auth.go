@@ -0,0 +1,190 @@
+// Package auth identifies the callers of the API and what they may do. API
+// keys are the credentials of integrations: each is granted a set of scopes
+// and travels on the request context once authenticated. People sign in at
+// the company's OpenID Connect provider instead; their role decides their
+// scopes.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"issue-tracking/entities"
+)
+
+// Scopes an API key can be granted
+const (
+	ScopeIssuesRead    = "issues:read"
+	ScopeIssuesWrite   = "issues:write"
+	ScopeCommentsWrite = "comments:write"
+	ScopeReportsRead   = "reports:read"
+	// ScopeAdmin manages API keys
+	ScopeAdmin = "admin"
+)
+
+// Scopes lists every scope in the order they are documented
+var Scopes = []string{ScopeIssuesRead, ScopeIssuesWrite, ScopeCommentsWrite, ScopeReportsRead, ScopeAdmin}
+
+// RoleScopes lists the scopes of each role of a signed-in person
+var RoleScopes = map[string][]string{
+	entities.RoleUser:    {ScopeIssuesRead, ScopeIssuesWrite, ScopeCommentsWrite},
+	entities.RoleOfficer: {ScopeIssuesRead, ScopeIssuesWrite, ScopeCommentsWrite, ScopeReportsRead},
+	entities.RoleAdmin:   Scopes,
+}
+
+// DefaultAnonymousScopes are granted to callers without an API key or ID
+// token unless configured otherwise: every scope but admin, as the API is
+// open to anonymous callers
+var DefaultAnonymousScopes = []string{ScopeIssuesRead, ScopeIssuesWrite, ScopeCommentsWrite, ScopeReportsRead}
+
+// APIKeyPrefix starts every API key so leaked keys are easy to recognize
+const APIKeyPrefix = "itk_"
+
+// APIKeyHeader is the header API keys may be sent in instead of
+// "Authorization: Bearer"
+const APIKeyHeader = "X-API-Key"
+
+// GenerateAPIKey returns a new key of the form itk_<id>_<secret> together
+// with its identifying prefix itk_<id>, which is stored in clear, and the
+// hash of the whole key, which is stored instead of the key
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	prefix = APIKeyPrefix + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, HashAPIKey(key), nil
+}
+
+// APIKeyPrefixOf returns the identifying prefix of key, or false when key
+// is not shaped like an API key
+func APIKeyPrefixOf(key string) (string, bool) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(key[len(APIKeyPrefix):], "_")
+	if !ok || len(prefix) != 8 || secret == "" {
+		return "", false
+	}
+	return APIKeyPrefix + prefix, true
+}
+
+// HashAPIKey returns the SHA-256 of key. Keys are random, so a fast hash is
+// enough to make the stored values useless to whoever reads them.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// MatchAPIKey compares key with a stored hash in constant time
+func MatchAPIKey(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(hash)) == 1
+}
+
+// KnownScope reports whether scope can be granted
+func KnownScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a copy of ctx carrying the authenticated key
+func WithAPIKey(ctx context.Context, key *entities.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFrom returns the key the request was authenticated with, or nil
+func APIKeyFrom(ctx context.Context) *entities.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*entities.APIKey)
+	return key
+}
+
+type identityContextKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the signed-in identity
+func WithIdentity(ctx context.Context, identity *entities.Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// IdentityFrom returns the identity the request was signed in as, or nil
+func IdentityFrom(ctx context.Context) *entities.Identity {
+	identity, _ := ctx.Value(identityContextKey{}).(*entities.Identity)
+	return identity
+}
+
+type anonymousScopesContextKey struct{}
+
+// WithAnonymousScopes returns a copy of ctx in which callers without an API
+// key or ID token are granted scopes instead of DefaultAnonymousScopes
+func WithAnonymousScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, anonymousScopesContextKey{}, scopes)
+}
+
+// anonymousScopes returns the scopes of callers without credentials in ctx
+func anonymousScopes(ctx context.Context) []string {
+	if scopes, ok := ctx.Value(anonymousScopesContextKey{}).([]string); ok {
+		return scopes
+	}
+	return DefaultAnonymousScopes
+}
+
+// Anonymous reports whether the caller of ctx sent neither an API key nor
+// an ID token
+func Anonymous(ctx context.Context) bool {
+	return APIKeyFrom(ctx) == nil && IdentityFrom(ctx) == nil
+}
+
+// Allowed reports whether the caller of ctx may use scope. Every caller may
+// use the scopes granted to anonymous callers, so credentials never take
+// anything away; beyond those, keys grant what they were created with and
+// signed-in people what their role grants.
+func Allowed(ctx context.Context, scope string) bool {
+	if slices.Contains(anonymousScopes(ctx), scope) {
+		return true
+	}
+	if key := APIKeyFrom(ctx); key != nil {
+		return key.HasScope(scope)
+	}
+	if identity := IdentityFrom(ctx); identity != nil {
+		return slices.Contains(RoleScopes[identity.Role], scope)
+	}
+	return false
+}
+
+// Denial explains to the caller of ctx why it may not use scope
+func Denial(ctx context.Context, scope string) string {
+	if Anonymous(ctx) {
+		return fmt.Sprintf("an API key or ID token granted %s is required", scope)
+	}
+	if identity := IdentityFrom(ctx); identity != nil && APIKeyFrom(ctx) == nil {
+		return fmt.Sprintf("the %s role is not granted %s", identity.Role, scope)
+	}
+	return fmt.Sprintf("the API key is not granted %s", scope)
+}
+
+// OfficerID returns the officer a signed-in officer or admin handles issues
+// as, or false for other callers
+func OfficerID(ctx context.Context) (uint, bool) {
+	identity := IdentityFrom(ctx)
+	if identity == nil || !identity.HandlesIssues() || identity.OfficerID == nil {
+		return 0, false
+	}
+	return *identity.OfficerID, true
+}
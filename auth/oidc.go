@@ -0,0 +1,620 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	_ "crypto/sha512" // SHA-384 and SHA-512 for RS384, RS512, ES384 and ES512
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"issue-tracking/entities"
+)
+
+// ErrInvalidToken is wrapped by the errors of ID tokens that are malformed,
+// badly signed, expired or meant for another client
+var ErrInvalidToken = errors.New("invalid ID token")
+
+const (
+	// clockSkew is how far the clocks of the API and the provider may
+	// disagree when checking token lifetimes
+	clockSkew = time.Minute
+
+	// keyRefreshInterval is how often the provider's keys may be fetched
+	// again for a token signed with an unknown key
+	keyRefreshInterval = time.Minute
+
+	// maxProviderResponse bounds the documents read from the provider
+	maxProviderResponse = 1 << 20
+)
+
+// OIDCConfig is the registration of the API as a client of an OpenID
+// Connect provider and how its accounts map to roles
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// Scopes are requested besides openid
+	Scopes []string
+	// GroupsClaim names the ID token claim listing the groups of an account
+	GroupsClaim   string
+	AdminGroups   []string
+	OfficerGroups []string
+}
+
+// Claims are the ID token claims the API uses
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Name      string
+	Email     string
+	Nonce     string
+	Groups    []string
+	ExpiresAt time.Time
+}
+
+// OIDCProvider signs people in at an OpenID Connect provider with the
+// authorization code flow and PKCE, and verifies the ID tokens it issues
+// against the keys it publishes. The discovery document and keys are fetched
+// on first use, so the API starts while the provider is unreachable, and the
+// keys are fetched again when a token is signed with an unknown one, so key
+// rotation needs no restart.
+type OIDCProvider struct {
+	config OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]crypto.PublicKey
+	keysAt    time.Time
+}
+
+// discovery is the part of the provider's discovery document the API uses
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewOIDCProvider returns a provider for config making requests with
+// client, or http.DefaultClient when client is nil
+func NewOIDCProvider(config OIDCConfig, client *http.Client) *OIDCProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	config.IssuerURL = strings.TrimSuffix(config.IssuerURL, "/")
+	return &OIDCProvider{config: config, client: client}
+}
+
+// AuthCodeURL returns the URL of the provider's sign-in page. The provider
+// redirects back with state, puts nonce in the ID token and only hands out
+// tokens for the code to whoever presents verifier.
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(d.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(append([]string{"openid"}, p.config.Scopes...), " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", PKCEChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Exchange trades an authorization code and its PKCE verifier for an ID
+// token, which it returns unverified
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxProviderResponse)).Decode(&body); err != nil {
+		return "", fmt.Errorf("token response (%s): %w", resp.Status, err)
+	}
+	if body.Error != "" {
+		return "", fmt.Errorf("token request rejected: %s %s", body.Error, body.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request: %s", resp.Status)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("token response has no id_token")
+	}
+	return body.IDToken, nil
+}
+
+// Verify checks the signature, issuer, audience and lifetime of an ID token
+// and returns its claims. Errors about the token itself wrap
+// ErrInvalidToken; others mean the provider could not be reached.
+func (p *OIDCProvider) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalidToken("not a JWT")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, invalidToken("malformed header")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, invalidToken("malformed payload")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalidToken("malformed signature")
+	}
+
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.key(ctx, d, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Issuer            string   `json:"iss"`
+		Subject           string   `json:"sub"`
+		Audience          audience `json:"aud"`
+		AuthorizedParty   string   `json:"azp"`
+		ExpiresAt         *float64 `json:"exp"`
+		NotBefore         *float64 `json:"nbf"`
+		Nonce             string   `json:"nonce"`
+		Name              string   `json:"name"`
+		PreferredUsername string   `json:"preferred_username"`
+		Email             string   `json:"email"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, invalidToken("malformed claims")
+	}
+
+	now := time.Now()
+	switch {
+	case raw.Issuer != d.Issuer:
+		return nil, invalidToken("issued by %q", raw.Issuer)
+	case raw.Subject == "":
+		return nil, invalidToken("no subject")
+	case !slices.Contains(raw.Audience, p.config.ClientID):
+		return nil, invalidToken("not issued for this client")
+	case len(raw.Audience) > 1 && raw.AuthorizedParty != p.config.ClientID:
+		return nil, invalidToken("not authorized for this client")
+	case raw.ExpiresAt == nil:
+		return nil, invalidToken("no expiry")
+	case now.Add(-clockSkew).After(unixTime(*raw.ExpiresAt)):
+		return nil, invalidToken("expired")
+	case raw.NotBefore != nil && now.Add(clockSkew).Before(unixTime(*raw.NotBefore)):
+		return nil, invalidToken("not valid yet")
+	}
+
+	groups, err := p.groups(payload)
+	if err != nil {
+		return nil, err
+	}
+	name := raw.Name
+	for _, fallback := range []string{raw.PreferredUsername, raw.Email, raw.Subject} {
+		if strings.TrimSpace(name) == "" {
+			name = fallback
+		}
+	}
+	return &Claims{
+		Issuer:    raw.Issuer,
+		Subject:   raw.Subject,
+		Name:      strings.TrimSpace(name),
+		Email:     raw.Email,
+		Nonce:     raw.Nonce,
+		Groups:    groups,
+		ExpiresAt: unixTime(*raw.ExpiresAt),
+	}, nil
+}
+
+// Role maps the groups of an account to its role
+func (p *OIDCProvider) Role(claims *Claims) string {
+	inAny := func(groups []string) bool {
+		return slices.ContainsFunc(claims.Groups, func(g string) bool { return slices.Contains(groups, g) })
+	}
+	switch {
+	case inAny(p.config.AdminGroups):
+		return entities.RoleAdmin
+	case inAny(p.config.OfficerGroups):
+		return entities.RoleOfficer
+	default:
+		return entities.RoleUser
+	}
+}
+
+// groups returns the groups claim of payload, which providers send as a
+// list or, for a single group, a string
+func (p *OIDCProvider) groups(payload []byte) ([]string, error) {
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, invalidToken("malformed claims")
+	}
+	value, ok := claims[p.config.GroupsClaim]
+	if !ok || string(value) == "null" {
+		return nil, nil
+	}
+	var groups []string
+	if err := json.Unmarshal(value, &groups); err == nil {
+		return groups, nil
+	}
+	var group string
+	if err := json.Unmarshal(value, &group); err != nil {
+		return nil, invalidToken("%s must be a list of strings", p.config.GroupsClaim)
+	}
+	return []string{group}, nil
+}
+
+// discover returns the discovery document, fetching it on first use
+func (p *OIDCProvider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var d discovery
+	if err := p.fetch(ctx, p.config.IssuerURL+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("discover OpenID provider: %w", err)
+	}
+	if d.Issuer != p.config.IssuerURL {
+		return nil, fmt.Errorf("discover OpenID provider: issuer %q does not match %q", d.Issuer, p.config.IssuerURL)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("discover OpenID provider: authorization_endpoint, token_endpoint and jwks_uri are required")
+	}
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+// key returns the signing key with ID kid, fetching the keys again when it
+// is not known. An empty kid matches the only key of the provider.
+func (p *OIDCProvider) key(ctx context.Context, d *discovery, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lookup := func() crypto.PublicKey {
+		if kid == "" && len(p.keys) == 1 {
+			for _, key := range p.keys {
+				return key
+			}
+		}
+		return p.keys[kid]
+	}
+	if key := lookup(); key != nil {
+		return key, nil
+	}
+	if p.keys != nil && time.Since(p.keysAt) < keyRefreshInterval {
+		return nil, invalidToken("unknown signing key %q", kid)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.fetch(ctx, d.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("fetch OpenID provider keys: %w", err)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys of unsupported types are skipped, tokens signed with them
+		// fail as signed with an unknown key
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	p.keys, p.keysAt = keys, time.Now()
+
+	if key := lookup(); key != nil {
+		return key, nil
+	}
+	return nil, invalidToken("unknown signing key %q", kid)
+}
+
+// fetch decodes the JSON document at url into v
+func (p *OIDCProvider) fetch(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxProviderResponse)).Decode(v)
+}
+
+// jsonWebKey is an RSA or elliptic curve public key of a JWK set
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	number := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil || len(b) == 0 {
+			return nil, fmt.Errorf("key %q: malformed number", k.Kid)
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := number(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := number(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("key %q: unsupported exponent", k.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("key %q: unsupported curve %q", k.Kid, k.Crv)
+		}
+		x, err := number(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := number(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("key %q: point is not on %s", k.Kid, k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("key %q: unsupported key type %q", k.Kid, k.Kty)
+	}
+}
+
+// verifySignature checks a JWS signature made with alg. Only asymmetric
+// algorithms are accepted, so a token cannot be signed with "none" or with
+// a public key used as an HMAC secret.
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg[min(2, len(alg)):] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return invalidToken("unsupported algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		var err error
+		switch alg[:2] {
+		case "RS":
+			err = rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+		case "PS":
+			err = rsa.VerifyPSS(pub, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		default:
+			return invalidToken("algorithm %q does not match the RSA key", alg)
+		}
+		if err != nil {
+			return invalidToken("bad signature")
+		}
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if alg[:2] != "ES" || len(signature) != 2*size {
+			return invalidToken("algorithm %q does not match the EC key", alg)
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return invalidToken("bad signature")
+		}
+	default:
+		return invalidToken("unsupported key")
+	}
+	return nil
+}
+
+// audience is the aud claim, a string or a list of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(int64(seconds), 0)
+}
+
+func invalidToken(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidToken, fmt.Sprintf(format, args...))
+}
+
+// RandomToken returns 32 random bytes, URL-safe base64 encoded, for use as a
+// state, nonce or PKCE verifier
+func RandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// PKCEChallenge returns the S256 code challenge of a PKCE verifier
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// LoginState is what the API keeps in a cookie between sending a browser to
+// the provider and the provider sending it back
+type LoginState struct {
+	State    string    `json:"state"`
+	Nonce    string    `json:"nonce"`
+	Verifier string    `json:"verifier"`
+	Expires  time.Time `json:"expires"`
+}
+
+// SealLoginState encrypts state with secret, so the cookie neither reveals
+// the PKCE verifier nor can be forged, and replicas sharing the secret can
+// finish each other's sign-ins
+func SealLoginState(secret string, state LoginState) (string, error) {
+	aead, err := loginCipher(secret)
+	if err != nil {
+		return "", err
+	}
+	plain, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plain, nil)), nil
+}
+
+// OpenLoginState decrypts a cookie made by SealLoginState and rejects it
+// once it expired
+func OpenLoginState(secret, sealed string) (*LoginState, error) {
+	aead, err := loginCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, errors.New("malformed sign-in state")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("sign-in state was not issued by this API")
+	}
+	var state LoginState
+	if err := json.Unmarshal(plain, &state); err != nil {
+		return nil, errors.New("malformed sign-in state")
+	}
+	if time.Now().After(state.Expires) {
+		return nil, errors.New("sign-in took too long")
+	}
+	return &state, nil
+}
+
+// loginCipher derives the AES-GCM key of sign-in cookies from secret
+func loginCipher(secret string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte("issue-tracking login state\x00" + secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,260 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"issue-tracking/auth"
+	"issue-tracking/auth/oidctest"
+	"issue-tracking/entities"
+)
+
+const clientID = "issue-tracking"
+
+func newProvider(t *testing.T) (*auth.OIDCProvider, *oidctest.Issuer) {
+	t.Helper()
+	issuer := oidctest.NewIssuer(t, clientID)
+	provider := auth.NewOIDCProvider(auth.OIDCConfig{
+		IssuerURL:     issuer.URL,
+		ClientID:      clientID,
+		RedirectURL:   "http://api.test/auth/callback",
+		Scopes:        []string{"profile", "email"},
+		GroupsClaim:   "groups",
+		AdminGroups:   []string{"it-admins"},
+		OfficerGroups: []string{"helpdesk"},
+	}, nil)
+	return provider, issuer
+}
+
+var jane = oidctest.Account{Subject: "u-1", Name: "Jane Smith", Email: "jane@example.com", Groups: []string{"staff", "helpdesk"}}
+
+func TestVerify(t *testing.T) {
+	provider, issuer := newProvider(t)
+	claims, err := provider.Verify(context.Background(), issuer.IDToken(jane, "n-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "u-1" || claims.Name != "Jane Smith" || claims.Email != "jane@example.com" || claims.Nonce != "n-1" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if role := provider.Role(claims); role != entities.RoleOfficer {
+		t.Errorf("role = %q, want officer", role)
+	}
+}
+
+func TestVerifyRejectsBadTokens(t *testing.T) {
+	provider, issuer := newProvider(t)
+	now := time.Now()
+	valid := func(changes map[string]interface{}) map[string]interface{} {
+		claims := map[string]interface{}{
+			"iss": issuer.URL, "sub": "u-1", "aud": clientID,
+			"iat": now.Unix(), "exp": now.Add(time.Hour).Unix(),
+		}
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+			} else {
+				claims[name] = value
+			}
+		}
+		return claims
+	}
+	good := issuer.IDToken(jane, "")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not a JWT", "abc"},
+		{"tampered payload", strings.Split(good, ".")[0] + "." + strings.Split(issuer.IDToken(oidctest.Account{Subject: "admin"}, ""), ".")[1] + "." + strings.Split(good, ".")[2]},
+		{"unsigned", strings.Split(good, ".")[0] + "." + strings.Split(good, ".")[1] + "."},
+		{"expired", issuer.Sign(valid(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()}))},
+		{"no expiry", issuer.Sign(valid(map[string]interface{}{"exp": nil}))},
+		{"not valid yet", issuer.Sign(valid(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()}))},
+		{"other issuer", issuer.Sign(valid(map[string]interface{}{"iss": "https://evil.example.com"}))},
+		{"other audience", issuer.Sign(valid(map[string]interface{}{"aud": "other-app"}))},
+		{"shared audience without azp", issuer.Sign(valid(map[string]interface{}{"aud": []string{clientID, "other-app"}}))},
+		{"no subject", issuer.Sign(valid(map[string]interface{}{"sub": nil}))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.Verify(context.Background(), tt.token)
+			if !errors.Is(err, auth.ErrInvalidToken) {
+				t.Fatalf("Verify = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+
+	shared := issuer.Sign(valid(map[string]interface{}{"aud": []string{clientID, "other-app"}, "azp": clientID}))
+	if _, err := provider.Verify(context.Background(), shared); err != nil {
+		t.Errorf("token with shared audience and azp: %v", err)
+	}
+}
+
+func TestVerifyFollowsKeyRotation(t *testing.T) {
+	provider, issuer := newProvider(t)
+	old := issuer.IDToken(jane, "")
+	if _, err := provider.Verify(context.Background(), old); err != nil {
+		t.Fatal(err)
+	}
+
+	// A token signed with the new key makes the provider fetch the keys
+	// again once the refresh interval allows; until then it is rejected
+	issuer.RotateKey()
+	_, err := provider.Verify(context.Background(), issuer.IDToken(jane, ""))
+	if !errors.Is(err, auth.ErrInvalidToken) || !strings.Contains(err.Error(), "unknown signing key") {
+		t.Fatalf("Verify right after rotation = %v, want unknown signing key", err)
+	}
+
+	fresh, issuer2 := newProvider(t)
+	issuer2.RotateKey()
+	if _, err := fresh.Verify(context.Background(), issuer2.IDToken(jane, "")); err != nil {
+		t.Errorf("Verify with the rotated key: %v", err)
+	}
+}
+
+func TestVerifyProviderDown(t *testing.T) {
+	_, issuer := newProvider(t)
+	provider := auth.NewOIDCProvider(auth.OIDCConfig{IssuerURL: "http://127.0.0.1:1", ClientID: clientID}, nil)
+	_, err := provider.Verify(context.Background(), issuer.IDToken(jane, ""))
+	if err == nil || errors.Is(err, auth.ErrInvalidToken) {
+		t.Fatalf("Verify = %v, want a provider error", err)
+	}
+}
+
+func TestRole(t *testing.T) {
+	provider, issuer := newProvider(t)
+	tests := []struct {
+		groups []string
+		want   string
+	}{
+		{nil, entities.RoleUser},
+		{[]string{"staff"}, entities.RoleUser},
+		{[]string{"helpdesk"}, entities.RoleOfficer},
+		{[]string{"helpdesk", "it-admins"}, entities.RoleAdmin},
+	}
+	for _, tt := range tests {
+		claims, err := provider.Verify(context.Background(), issuer.IDToken(oidctest.Account{Subject: "u-2", Groups: tt.groups}, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := provider.Role(claims); got != tt.want {
+			t.Errorf("groups %v: role = %q, want %q", tt.groups, got, tt.want)
+		}
+	}
+
+	// Providers send a single group as a string
+	claims, err := provider.Verify(context.Background(), issuer.Sign(map[string]interface{}{
+		"iss": issuer.URL, "sub": "u-3", "aud": clientID, "exp": time.Now().Add(time.Hour).Unix(), "groups": "it-admins",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := provider.Role(claims); got != entities.RoleAdmin {
+		t.Errorf("single group: role = %q, want admin", got)
+	}
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	provider, issuer := newProvider(t)
+	issuer.SignInAs(jane)
+	ctx := context.Background()
+
+	signInURL, err := provider.AuthCodeURL(ctx, "s-1", "n-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := mustParse(t, signInURL).Query()
+	if query.Get("code_challenge") != auth.PKCEChallenge("verifier-1") || query.Get("code_challenge_method") != "S256" {
+		t.Errorf("sign-in URL lacks the PKCE challenge: %s", signInURL)
+	}
+	if query.Get("scope") != "openid profile email" {
+		t.Errorf("scope = %q", query.Get("scope"))
+	}
+
+	code := authorize(t, signInURL)
+	if _, err := provider.Exchange(ctx, code, "wrong-verifier"); err == nil {
+		t.Fatal("exchange with the wrong verifier succeeded")
+	}
+
+	code = authorize(t, signInURL)
+	token, err := provider.Exchange(ctx, code, "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := provider.Verify(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != jane.Subject || claims.Nonce != "n-1" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if _, err := provider.Exchange(ctx, code, "verifier-1"); err == nil {
+		t.Error("a code was exchanged twice")
+	}
+}
+
+func TestLoginState(t *testing.T) {
+	secret := strings.Repeat("s", 32)
+	state := auth.LoginState{State: "s-1", Nonce: "n-1", Verifier: "v-1", Expires: time.Now().Add(time.Minute)}
+	sealed, err := auth.SealLoginState(secret, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, "v-1") {
+		t.Error("sealed state shows the verifier")
+	}
+
+	opened, err := auth.OpenLoginState(secret, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened.State != "s-1" || opened.Nonce != "n-1" || opened.Verifier != "v-1" {
+		t.Errorf("opened state = %+v", opened)
+	}
+
+	if _, err := auth.OpenLoginState(strings.Repeat("x", 32), sealed); err == nil {
+		t.Error("state opened with another secret")
+	}
+	if _, err := auth.OpenLoginState(secret, sealed[:len(sealed)-2]+"AA"); err == nil {
+		t.Error("tampered state opened")
+	}
+	state.Expires = time.Now().Add(-time.Second)
+	expired, err := auth.SealLoginState(secret, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.OpenLoginState(secret, expired); err == nil {
+		t.Error("expired state opened")
+	}
+}
+
+// authorize visits the sign-in URL and returns the code the issuer
+// redirects back with
+func authorize(t *testing.T, signInURL string) string {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(signInURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("GET %s = %s", signInURL, resp.Status)
+	}
+	return mustParse(t, resp.Header.Get("Location")).Query().Get("code")
+}
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
@@ -0,0 +1,254 @@
+// Package oidctest runs a local OpenID Connect provider for tests. It
+// publishes a discovery document and signing keys, signs in a preset account
+// without asking at its authorization endpoint and checks the PKCE verifier
+// at its token endpoint.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TokenLifetime is how long the ID tokens of the issuer are valid
+const TokenLifetime = time.Hour
+
+// Account is the person the issuer signs in
+type Account struct {
+	Subject string
+	Name    string
+	Email   string
+	Groups  []string
+}
+
+// Issuer is a running provider. Its URL is the issuer URL.
+type Issuer struct {
+	URL      string
+	ClientID string
+
+	server *httptest.Server
+
+	mu      sync.Mutex
+	key     *rsa.PrivateKey
+	kid     int
+	account Account
+	codes   map[string]grant
+}
+
+// grant is an authorization code waiting to be exchanged
+type grant struct {
+	account     Account
+	nonce       string
+	challenge   string
+	redirectURI string
+}
+
+// NewIssuer starts a provider for the client clientID that is stopped when
+// the test ends
+func NewIssuer(t testing.TB, clientID string) *Issuer {
+	t.Helper()
+	issuer := &Issuer{ClientID: clientID, codes: map[string]grant{}}
+	issuer.RotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("GET /keys", issuer.keys)
+	mux.HandleFunc("GET /authorize", issuer.authorize)
+	mux.HandleFunc("POST /token", issuer.token)
+	issuer.server = httptest.NewServer(mux)
+	issuer.URL = issuer.server.URL
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// SignInAs sets the account the authorization endpoint signs in
+func (i *Issuer) SignInAs(account Account) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.account = account
+}
+
+// RotateKey replaces the signing key; tokens signed before fail to verify
+func (i *Issuer) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.key = key
+	i.kid++
+}
+
+// IDToken returns an ID token for account, as a sign-in with nonce would
+func (i *Issuer) IDToken(account Account, nonce string) string {
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":   i.URL,
+		"sub":   account.Subject,
+		"aud":   i.ClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(TokenLifetime).Unix(),
+		"name":  account.Name,
+		"email": account.Email,
+	}
+	if account.Groups != nil {
+		claims["groups"] = account.Groups
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	return i.Sign(claims)
+}
+
+// Sign returns a token with claims signed with the current key, for tests of
+// tokens the issuer would not hand out
+func (i *Issuer) Sign(claims map[string]interface{}) string {
+	i.mu.Lock()
+	key, kid := i.key, i.kid
+	i.mu.Unlock()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": fmt.Sprint(kid)})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		panic(err)
+	}
+	signed := encode(header) + "." + encode(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return signed + "." + encode(signature)
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                i.URL,
+		"authorization_endpoint":                i.URL + "/authorize",
+		"token_endpoint":                        i.URL + "/token",
+		"jwks_uri":                              i.URL + "/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (i *Issuer) keys(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	pub, kid := i.key.PublicKey, i.kid
+	i.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": fmt.Sprint(kid),
+			"n":   encode(pub.N.Bytes()),
+			"e":   encode(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize signs in the preset account and redirects back with a code
+func (i *Issuer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	switch {
+	case query.Get("client_id") != i.ClientID:
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	case query.Get("response_type") != "code":
+		http.Error(w, "response_type must be code", http.StatusBadRequest)
+		return
+	case query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "":
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirect.Host == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code := encode(random())
+	i.mu.Lock()
+	i.codes[code] = grant{
+		account:     i.account,
+		nonce:       query.Get("nonce"),
+		challenge:   query.Get("code_challenge"),
+		redirectURI: redirect.String(),
+	}
+	i.mu.Unlock()
+
+	back := redirect.Query()
+	back.Set("code", code)
+	back.Set("state", query.Get("state"))
+	redirect.RawQuery = back.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// token exchanges a code once for an ID token
+func (i *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	clientID := r.PostForm.Get("client_id")
+	if user, _, ok := r.BasicAuth(); ok {
+		clientID, _ = url.QueryUnescape(user)
+	}
+
+	i.mu.Lock()
+	g, ok := i.codes[r.PostForm.Get("code")]
+	delete(i.codes, r.PostForm.Get("code"))
+	i.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+	case clientID != i.ClientID:
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+	case !ok || g.redirectURI != r.PostForm.Get("redirect_uri"):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+	case encode(verifier[:]) != g.challenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+	default:
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": encode(random()),
+			"token_type":   "Bearer",
+			"expires_in":   int(TokenLifetime.Seconds()),
+			"id_token":     i.IDToken(g.account, g.nonce),
+		})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func random() []byte {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,61 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSiteVerify(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("secret") != "site-secret" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "error-codes": []string{"invalid-input-secret"}})
+			return
+		}
+		if r.PostForm.Get("remoteip") != "203.0.113.9" {
+			t.Errorf("remoteip = %q", r.PostForm.Get("remoteip"))
+		}
+		switch r.PostForm.Get("response") {
+		case "solved":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+		case "down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "error-codes": []string{"invalid-input-response"}})
+		}
+	}))
+	defer provider.Close()
+
+	verifier, err := New("hcaptcha", "site-secret", provider.URL, provider.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := verifier.Verify(ctx, "solved", "203.0.113.9"); err != nil {
+		t.Errorf("solved captcha: %v", err)
+	}
+	for _, response := range []string{"", "guessed"} {
+		if err := verifier.Verify(ctx, response, "203.0.113.9"); !errors.Is(err, ErrFailed) {
+			t.Errorf("response %q: %v, want ErrFailed", response, err)
+		}
+	}
+	if err := verifier.Verify(ctx, "down", "203.0.113.9"); err == nil || errors.Is(err, ErrFailed) {
+		t.Errorf("provider down: %v, want a provider error", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if v, err := New("none", "", "", nil); err != nil || v.Verify(context.Background(), "", "") != nil {
+		t.Errorf("none: %v", err)
+	}
+	v, err := New("turnstile", "s", "", nil)
+	if err != nil || v.(*SiteVerify).URL != Providers["turnstile"] {
+		t.Errorf("turnstile = %+v, %v", v, err)
+	}
+	if _, err := New("bogus", "s", "", nil); err == nil {
+		t.Error("unknown provider accepted")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"issue-tracking/client"
+	"issue-tracking/config"
+	"issue-tracking/repositories"
+	"issue-tracking/routes"
+	"issue-tracking/services"
+	"issue-tracking/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServerEnv names the environment variable holding the default -server URL
+const ServerEnv = "ISSUE_TRACKING_SERVER"
+
+// openClient registers -server on fs and parses args, and returns an API
+// client. With a server URL the configuration is not needed; otherwise the
+// database is opened and the routes are served in-process, so both modes
+// share the API's validation and status history. The returned function
+// must be called before exit.
+func openClient(fs *flag.FlagSet, args []string) (*client.Client, func(), error) {
+	server := fs.String("server", os.Getenv(ServerEnv), "URL of a running server to call instead of the database (env "+ServerEnv+")")
+	cfg, err := config.Parse(fs, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *server != "" {
+		return client.New(*server, client.WithHTTPClient(tracing.NewHTTPClient())), func() {}, nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+	db, shutdown, err := setup(cfg, os.Stderr, cfg.Database.AutoMigrate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Operators get every endpoint whatever the server exposes, without
+	// signing in
+	local := *cfg
+	local.Features = config.FeatureConfig{Bulk: true, Import: true, Export: true, Reports: true}
+	local.RateLimit.Enabled = false
+	local.OIDC.Enabled = false
+	local.Auth.AnonymousScopes = nil
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	store := repositories.NewGormStore(db)
+	routes.RegisterRoutes(router, store, services.NewIssueService(store), &local)
+
+	api := client.New("http://local", client.WithHTTPClient(&http.Client{Transport: handlerTransport{router}}))
+	return api, shutdown, nil
+}
+
+// handlerTransport serves requests with an in-process handler
+type handlerTransport struct {
+	handler http.Handler
+}
+
+// RoundTrip implements http.RoundTripper
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
@@ -0,0 +1,206 @@
+// Package client is the Go client of the issue tracking API. Its methods
+// send the same requests and decode the same entities as the server, and are
+// tested against the server's routes.
+//
+//	api := client.New("http://localhost:8080")
+//	issue, err := api.CreateIssue(ctx, client.NewIssue{ReporterID: 1, StatusID: 1, Title: "Printer offline", Priority: "high"})
+//
+// Reads and creates are retried when the server is unavailable. Creates
+// carry an idempotency key, so a retried create returns the original issue
+// instead of a duplicate.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of the retry options
+const (
+	DefaultRetries = 3
+	DefaultBackoff = 200 * time.Millisecond
+	maxBackoff     = 5 * time.Second
+)
+
+// Client calls the API of one server. It is safe for concurrent use.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	retries int
+	backoff time.Duration
+	apiKey  string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends requests with hc instead of http.DefaultClient
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithRetries sets how many times a failed request is retried and the delay
+// before the first retry, which doubles on each attempt. Zero retries
+// disables retrying.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) { c.retries, c.backoff = retries, backoff }
+}
+
+// WithAPIKey authenticates every request with an API key, so issues created
+// by the client are recorded as reported by its integration
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// New returns a client of the server at baseURL, such as
+// http://localhost:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    http.DefaultClient,
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey makes creates sent with ctx use key instead of a new
+// random one, so retries across process restarts are recognized too
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// newIdempotencyKey returns the key of ctx or a random one
+func newIdempotencyKey(ctx context.Context) string {
+	if key, ok := ctx.Value(idempotencyKey{}).(string); ok && key != "" {
+		return key
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// request describes one API call
+type request struct {
+	method string
+	path   string
+	body   interface{}
+	// idempotent requests are retried; creates are made idempotent with a key
+	idempotent bool
+	key        string
+}
+
+// do sends req and decodes the data of a success response into out
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	envelope := struct {
+		Data interface{} `json:"data"`
+	}{Data: out}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("decode response of %s %s: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// send performs req, retrying it when allowed, and turns error responses
+// into *Error. The caller closes the body of the returned response.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, err
+		}
+	}
+
+	delay := c.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(ctx, req, body)
+		if attempt == c.retries || !req.idempotent || !retryable(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		wait := delay
+		if resp != nil {
+			if after, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				wait = time.Duration(after) * time.Second
+			}
+			resp.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(min(wait, maxBackoff)):
+		}
+		delay *= 2
+	}
+}
+
+// attempt sends the request once
+func (c *Client) attempt(ctx context.Context, req request, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+req.path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.key != "" {
+		httpReq.Header.Set("Idempotency-Key", req.key)
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 400 {
+		return resp, nil
+	}
+	return resp, decodeError(req, resp)
+}
+
+// retryable reports whether a failed attempt may succeed when repeated:
+// network errors, overload and a create still in progress under the same
+// idempotency key
+func retryable(resp *http.Response, err error) bool {
+	if resp == nil {
+		var apiErr *Error
+		return err != nil && !errors.As(err, &apiErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusConflict, http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
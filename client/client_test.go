@@ -0,0 +1,322 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"issue-tracking/client"
+	"issue-tracking/config"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/routes"
+	"issue-tracking/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// uncovered lists the routes the client deliberately leaves to operator
+// tooling and to the browsers of portal visitors
+var uncovered = map[string]bool{
+	"POST /api/issues/bulk":              true,
+	"POST /api/issues/import":            true,
+	"GET /api/admin/api-keys":            true,
+	"POST /api/admin/api-keys":           true,
+	"DELETE /api/admin/api-keys/:id":     true,
+	"POST /api/public/issues":            true,
+	"GET /api/public/tracking":           true,
+	"POST /api/public/tracking/comments": true,
+}
+
+// server runs the real routes over an in-memory store holding the statuses
+// open, in-progress and closed, one user and one officer. It records which
+// routes were called.
+type server struct {
+	store  repositories.Store
+	router *gin.Engine
+	url    string
+
+	mu     sync.Mutex
+	called map[string]bool
+}
+
+func newServer(t *testing.T, wrap func(http.Handler) http.Handler) *server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	s := &server{store: repositories.NewMemoryStore(), called: map[string]bool{}}
+
+	for i, code := range []string{"open", "in-progress", "closed"} {
+		status := entities.IssueStatus{StatusCode: code, DisplayName: code, Color: "#2196F3", DisplayOrder: i, IsActive: true, IsTerminal: code == "closed"}
+		if err := s.store.Statuses().Create(ctx, &status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.store.Users().Create(ctx, &entities.User{FullName: "John Doe"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.store.Officers().Create(ctx, &entities.Officer{FullName: "Jane Smith"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.router = gin.New()
+	s.router.Use(func(c *gin.Context) {
+		s.mu.Lock()
+		s.called[c.Request.Method+" "+c.FullPath()] = true
+		s.mu.Unlock()
+	})
+	routes.RegisterRoutes(s.router, s.store, services.NewIssueService(s.store), config.Default())
+
+	var handler http.Handler = s.router
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	s.url = srv.URL
+	return s
+}
+
+func (s *server) client(opts ...client.Option) *client.Client {
+	return client.New(s.url, append([]client.Option{client.WithRetries(3, time.Millisecond)}, opts...)...)
+}
+
+func TestClient(t *testing.T) {
+	s := newServer(t, nil)
+	api := s.client()
+	ctx := context.Background()
+
+	officer := uint(1)
+	var created []uint
+	for _, title := range []string{"Printer offline", "Login fails", "Slow search", "Broken link", "Typo on homepage"} {
+		issue, err := api.CreateIssue(ctx, client.NewIssue{ReporterID: 1, StatusID: 1, AssigneeID: &officer, Title: title, Priority: "high"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if issue.Title != title || issue.Reporter.FullName != "John Doe" || issue.Status.StatusCode != "open" {
+			t.Errorf("created issue = %+v", issue)
+		}
+		created = append(created, issue.IssueID)
+	}
+
+	moved, err := api.UpdateIssueStatus(ctx, created[1], client.StatusUpdate{NewStatusID: 2, Comment: "Looking into it"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.Status.StatusCode != "in-progress" {
+		t.Errorf("status after update = %q", moved.Status.StatusCode)
+	}
+
+	comment, err := api.CreateComment(ctx, created[1], client.NewComment{UserID: 1, Content: "Any update?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comment.Content != "Any update?" || comment.User.FullName != "John Doe" {
+		t.Errorf("comment = %+v", comment)
+	}
+
+	issue, err := api.GetIssue(ctx, created[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issue.StatusHistory) != 1 || len(issue.Comments) != 1 {
+		t.Errorf("issue has %d history entries and %d comments, want 1 and 1", len(issue.StatusHistory), len(issue.Comments))
+	}
+
+	open, err := api.ListIssues(ctx, client.IssueFilter{Status: "open", AssigneeID: &officer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 4 {
+		t.Errorf("listed %d open issues, want 4", len(open))
+	}
+
+	var iterated []uint
+	for issue, err := range api.Issues(ctx, client.IssueFilter{}, 2) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		iterated = append(iterated, issue.IssueID)
+	}
+	if len(iterated) != len(created) {
+		t.Errorf("iterated %v, want %v", iterated, created)
+	}
+	for issue := range api.Issues(ctx, client.IssueFilter{}, 2) {
+		if issue.IssueID != created[0] {
+			t.Errorf("first issue = %d", issue.IssueID)
+		}
+		break
+	}
+
+	var csv bytes.Buffer
+	if err := api.ExportIssues(ctx, client.ExportOptions{Filter: client.IssueFilter{Status: "in-progress"}, Columns: []string{"issue_id", "title"}}, &csv); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(csv.String(), "Login fails") || strings.Contains(csv.String(), "Printer offline") {
+		t.Errorf("export = %q", csv.String())
+	}
+
+	statuses, err := api.Statuses(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 || statuses[0].StatusCode != "open" {
+		t.Errorf("statuses = %+v", statuses)
+	}
+	officers, err := api.Officers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(officers) != 1 || officers[0].FullName != "Jane Smith" {
+		t.Errorf("officers = %+v", officers)
+	}
+
+	// Every API route is called above, so a route added to the server
+	// fails this test until the client supports it
+	for _, route := range s.router.Routes() {
+		key := route.Method + " " + route.Path
+		if strings.HasPrefix(route.Path, "/api/") && !strings.HasPrefix(route.Path, "/api/reports/") && !uncovered[key] && !s.called[key] {
+			t.Errorf("the client does not call %s", key)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	api := newServer(t, nil).client()
+	ctx := context.Background()
+
+	_, err := api.GetIssue(ctx, 99)
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || !client.IsNotFound(err) || apiErr.Message != "Issue not found" {
+		t.Fatalf("GetIssue(99) error = %#v", err)
+	}
+
+	_, err = api.CreateIssue(ctx, client.NewIssue{ReporterID: 1, StatusID: 1, Title: "x", Priority: "someday"})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 || apiErr.Message != "Validation failed" {
+		t.Fatalf("invalid create error = %#v", err)
+	}
+	fields := map[string]string{}
+	for _, f := range apiErr.Fields {
+		fields[f.Field] = f.Message
+	}
+	if fields["title"] != "title must be at least 3 characters" || fields["priority"] == "" {
+		t.Errorf("validation fields = %+v", apiErr.Fields)
+	}
+
+	_, err = api.CreateIssue(ctx, client.NewIssue{ReporterID: 9, StatusID: 1, Title: "Printer offline", Priority: "low"})
+	if client.StatusCode(err) != 400 || err.Error() != "Reporter not found (400): invalid reporter_id" {
+		t.Errorf("unknown reporter error = %v", err)
+	}
+}
+
+// TestAPIKey creates an issue as an integration
+func TestAPIKey(t *testing.T) {
+	s := newServer(t, nil)
+	ctx := context.Background()
+	key, err := services.NewAPIKeyService(s.store).Create(ctx, services.NewAPIKey{Name: "Monitoring", Scopes: []string{"issues:write"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue, err := s.client(client.WithAPIKey(key.Key)).CreateIssue(ctx, client.NewIssue{ReporterID: 1, StatusID: 1, Title: "Disk almost full", Priority: "high"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Source != entities.IssueSourceIntegration || issue.APIKeyID == nil || *issue.APIKeyID != key.APIKeyID {
+		t.Errorf("source = %q, api_key_id = %v", issue.Source, issue.APIKeyID)
+	}
+
+	_, err = s.client(client.WithAPIKey(key.Key+"x")).ListIssues(ctx, client.IssueFilter{})
+	if client.StatusCode(err) != http.StatusUnauthorized {
+		t.Errorf("wrong key error = %v", err)
+	}
+}
+
+// TestRetriedCreate loses the response of the first create; the retry
+// carries the same idempotency key and gets the original issue back
+func TestRetriedCreate(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	s := newServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+			mu.Lock()
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+			first := len(keys) == 1
+			mu.Unlock()
+			if first {
+				next.ServeHTTP(httptest.NewRecorder(), r)
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	ctx := context.Background()
+
+	issue, err := s.client().CreateIssue(ctx, client.NewIssue{ReporterID: 1, StatusID: 1, Title: "Printer offline", Priority: "low"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("idempotency keys = %q, want the same key twice", keys)
+	}
+	issues, err := s.store.Issues().List(ctx, repositories.IssueFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].IssueID != issue.IssueID {
+		t.Errorf("stored %d issues, want only issue %d", len(issues), issue.IssueID)
+	}
+
+	// A caller-supplied key is used as is
+	keyed := client.WithIdempotencyKey(ctx, "printer-1")
+	if _, err := s.client().CreateIssue(keyed, client.NewIssue{ReporterID: 1, StatusID: 1, Title: "Scanner offline", Priority: "low"}); err != nil {
+		t.Fatal(err)
+	}
+	if keys[len(keys)-1] != "printer-1" {
+		t.Errorf("idempotency key = %q, want printer-1", keys[len(keys)-1])
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	s := newServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			attempts[r.Method]++
+			mu.Unlock()
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+	})
+	ctx := context.Background()
+
+	if _, err := s.client().Statuses(ctx); client.StatusCode(err) != 503 {
+		t.Errorf("Statuses error = %v, want 503", err)
+	}
+	if attempts[http.MethodGet] != 4 {
+		t.Errorf("GET attempts = %d, want 4", attempts[http.MethodGet])
+	}
+
+	if _, err := s.client().UpdateIssueStatus(ctx, 1, client.StatusUpdate{NewStatusID: 2}); client.StatusCode(err) != 503 {
+		t.Errorf("UpdateIssueStatus error = %v, want 503", err)
+	}
+	if attempts[http.MethodPatch] != 1 {
+		t.Errorf("PATCH attempts = %d, want 1", attempts[http.MethodPatch])
+	}
+
+	attempts[http.MethodGet] = 0
+	if _, err := s.client(client.WithRetries(0, 0)).Officers(ctx); err == nil || attempts[http.MethodGet] != 1 {
+		t.Errorf("without retries: err = %v after %d attempts", err, attempts[http.MethodGet])
+	}
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"issue-tracking/utils"
+)
+
+// Error is an error response of the API
+type Error struct {
+	StatusCode int
+	Message    string
+	// Details is the raw details field, such as a reason string
+	Details json.RawMessage
+	// Fields lists the problems of a "Validation failed" response
+	Fields    []utils.ValidationError
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	switch {
+	case len(e.Fields) > 0:
+		messages := make([]string, len(e.Fields))
+		for i, f := range e.Fields {
+			messages[i] = f.Message
+		}
+		return fmt.Sprintf("%s (%d): %s", e.Message, e.StatusCode, strings.Join(messages, "; "))
+	case len(e.Details) > 0 && string(e.Details) != "null":
+		var reason string
+		if json.Unmarshal(e.Details, &reason) == nil {
+			return fmt.Sprintf("%s (%d): %s", e.Message, e.StatusCode, reason)
+		}
+		return fmt.Sprintf("%s (%d): %s", e.Message, e.StatusCode, e.Details)
+	}
+	return fmt.Sprintf("%s (%d)", e.Message, e.StatusCode)
+}
+
+// IsNotFound reports whether err is a 404 response
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// StatusCode returns the status of an error response, or 0 when err is not
+// an *Error
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// decodeError reads and closes the body of an error response
+func decodeError(req request, resp *http.Response) error {
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+
+	var body struct {
+		utils.ErrorResponse
+		Details json.RawMessage `json:"details"`
+	}
+	if json.Unmarshal(data, &body) != nil || body.Message == "" {
+		return &Error{StatusCode: resp.StatusCode, Message: fmt.Sprintf("%s %s: %s", req.method, req.path, resp.Status)}
+	}
+
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Message:    body.Message,
+		Details:    body.Details,
+		RequestID:  body.RequestID,
+	}
+	// Validation responses list the failing fields in details
+	_ = json.Unmarshal(body.Details, &apiErr.Fields)
+	return apiErr
+}
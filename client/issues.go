@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"issue-tracking/entities"
+)
+
+// DefaultPageSize is the page size of Issues when none is given
+const DefaultPageSize = 100
+
+// IssueFilter narrows an issue list. Zero values do not filter.
+type IssueFilter struct {
+	Status     string // status code
+	Priority   string
+	AssigneeID *uint
+	ReporterID *uint
+	Label      string
+}
+
+// values encodes the filter as GET /api/issues query parameters
+func (f IssueFilter) values() url.Values {
+	query := url.Values{}
+	if f.Status != "" {
+		query.Set("status", f.Status)
+	}
+	if f.Priority != "" {
+		query.Set("priority", f.Priority)
+	}
+	if f.AssigneeID != nil {
+		query.Set("assignee_id", strconv.FormatUint(uint64(*f.AssigneeID), 10))
+	}
+	if f.ReporterID != nil {
+		query.Set("reporter_id", strconv.FormatUint(uint64(*f.ReporterID), 10))
+	}
+	if f.Label != "" {
+		query.Set("label", f.Label)
+	}
+	return query
+}
+
+// NewIssue is the body of CreateIssue
+type NewIssue struct {
+	ReporterID  uint   `json:"reporter_id"`
+	AssigneeID  *uint  `json:"assignee_id,omitempty"`
+	StatusID    uint   `json:"status_id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Priority    string `json:"priority"`
+}
+
+// StatusUpdate is the body of UpdateIssueStatus
+type StatusUpdate struct {
+	NewStatusID uint   `json:"new_status_id"`
+	AssigneeID  *uint  `json:"assignee_id,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// NewComment is the body of CreateComment
+type NewComment struct {
+	UserID  uint   `json:"user_id"`
+	Content string `json:"content"`
+}
+
+// ExportOptions selects the format and columns of ExportIssues
+type ExportOptions struct {
+	Filter IssueFilter
+	// Format is csv (the default) or xlsx
+	Format string
+	// Columns lists the exported columns, the server's defaults when empty
+	Columns []string
+}
+
+// ListIssues returns every issue matching filter in one request
+func (c *Client) ListIssues(ctx context.Context, filter IssueFilter) ([]entities.Issue, error) {
+	var issues []entities.Issue
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/issues?" + filter.values().Encode(), idempotent: true}, &issues)
+	return issues, err
+}
+
+// ListIssuesPage returns up to limit issues matching filter with an ID
+// greater than afterID, in ID order
+func (c *Client) ListIssuesPage(ctx context.Context, filter IssueFilter, afterID uint, limit int) ([]entities.Issue, error) {
+	query := filter.values()
+	query.Set("limit", strconv.Itoa(limit))
+	if afterID > 0 {
+		query.Set("after_id", strconv.FormatUint(uint64(afterID), 10))
+	}
+	var issues []entities.Issue
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/issues?" + query.Encode(), idempotent: true}, &issues)
+	return issues, err
+}
+
+// Issues iterates over the issues matching filter, fetching pageSize of them
+// per request (DefaultPageSize when zero). Iteration stops after yielding an
+// error.
+//
+//	for issue, err := range api.Issues(ctx, client.IssueFilter{Status: "open"}, 0) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (c *Client) Issues(ctx context.Context, filter IssueFilter, pageSize int) iter.Seq2[entities.Issue, error] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return func(yield func(entities.Issue, error) bool) {
+		var afterID uint
+		for {
+			page, err := c.ListIssuesPage(ctx, filter, afterID, pageSize)
+			if err != nil {
+				yield(entities.Issue{}, err)
+				return
+			}
+			for _, issue := range page {
+				if !yield(issue, nil) {
+					return
+				}
+			}
+			if len(page) < pageSize {
+				return
+			}
+			afterID = page[len(page)-1].IssueID
+		}
+	}
+}
+
+// GetIssue returns one issue with its status history, comments and labels
+func (c *Client) GetIssue(ctx context.Context, id uint) (*entities.Issue, error) {
+	var issue entities.Issue
+	if err := c.do(ctx, request{method: http.MethodGet, path: issuePath(id, ""), idempotent: true}, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// CreateIssue creates an issue. Retries reuse one idempotency key, see
+// WithIdempotencyKey.
+func (c *Client) CreateIssue(ctx context.Context, issue NewIssue) (*entities.Issue, error) {
+	var created entities.Issue
+	req := request{method: http.MethodPost, path: "/api/issues", body: issue, idempotent: true, key: newIdempotencyKey(ctx)}
+	if err := c.do(ctx, req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateIssueStatus moves an issue to another status, recording the change
+// in its history. It is not retried since each call adds a history entry.
+func (c *Client) UpdateIssueStatus(ctx context.Context, id uint, update StatusUpdate) (*entities.Issue, error) {
+	var issue entities.Issue
+	if err := c.do(ctx, request{method: http.MethodPatch, path: issuePath(id, "/status"), body: update}, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// CreateComment comments on an issue. Retries reuse one idempotency key, see
+// WithIdempotencyKey.
+func (c *Client) CreateComment(ctx context.Context, issueID uint, comment NewComment) (*entities.Comment, error) {
+	var created entities.Comment
+	req := request{method: http.MethodPost, path: issuePath(issueID, "/comment"), body: comment, idempotent: true, key: newIdempotencyKey(ctx)}
+	if err := c.do(ctx, req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// ExportIssues writes the matching issues to w as CSV or XLSX
+func (c *Client) ExportIssues(ctx context.Context, opts ExportOptions, w io.Writer) error {
+	query := opts.Filter.values()
+	if opts.Format != "" {
+		query.Set("format", opts.Format)
+	}
+	if len(opts.Columns) > 0 {
+		query.Set("columns", strings.Join(opts.Columns, ","))
+	}
+
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: "/api/issues/export?" + query.Encode(), idempotent: true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// Statuses returns the active statuses in display order
+func (c *Client) Statuses(ctx context.Context) ([]entities.IssueStatus, error) {
+	var statuses []entities.IssueStatus
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/statuses", idempotent: true}, &statuses)
+	return statuses, err
+}
+
+// Officers returns every officer
+func (c *Client) Officers(ctx context.Context) ([]entities.Officer, error) {
+	var officers []entities.Officer
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/officers", idempotent: true}, &officers)
+	return officers, err
+}
+
+func issuePath(id uint, suffix string) string {
+	return fmt.Sprintf("/api/issues/%d%s", id, suffix)
+}
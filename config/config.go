@@ -0,0 +1,479 @@
+// Package config loads the service configuration. Values come from built-in
+// defaults, then an optional YAML or TOML file, then environment variables,
+// then command line flags, each layer overriding the previous one.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"issue-tracking/auth"
+	"issue-tracking/captcha"
+	"issue-tracking/logging"
+	"issue-tracking/metrics"
+)
+
+// Config is the complete service configuration
+type Config struct {
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	CORS        CORSConfig        `yaml:"cors"`
+	Log         LogConfig         `yaml:"log"`
+	Features    FeatureConfig     `yaml:"features"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	SLA         SLAConfig         `yaml:"sla"`
+	GraphQL     GraphQLConfig     `yaml:"graphql"`
+	GRPC        GRPCConfig        `yaml:"grpc"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`
+	Auth        AuthConfig        `yaml:"auth"`
+	OIDC        OIDCConfig        `yaml:"oidc"`
+	Portal      PortalConfig      `yaml:"portal"`
+	CSAT        CSATConfig        `yaml:"csat"`
+}
+
+// ServerConfig controls the HTTP listener
+type ServerConfig struct {
+	Addr            string    `yaml:"addr" env:"LISTEN_ADDR" flag:"addr" usage:"listen address, host:port"`
+	TLS             TLSConfig `yaml:"tls"`
+	ShutdownTimeout Duration  `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" usage:"how long to drain requests and background work on SIGTERM"`
+	TrustedProxies  []string  `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" usage:"comma-separated proxy IPs or CIDRs whose X-Forwarded-For gives the client IP"`
+}
+
+// TLSConfig enables HTTPS when both files are set
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" env:"TLS_CERT_FILE" flag:"tls-cert" usage:"TLS certificate file (PEM)"`
+	KeyFile  string `yaml:"key_file" env:"TLS_KEY_FILE" flag:"tls-key" usage:"TLS private key file (PEM)"`
+}
+
+// DatabaseConfig holds the connection string and pool limits
+type DatabaseConfig struct {
+	URL             string   `yaml:"url" env:"DATABASE_URL" flag:"database-url" usage:"database connection string" redact:"password"`
+	MaxOpenConns    int      `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" usage:"maximum open connections, 0 for unlimited"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" usage:"maximum idle connections"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" usage:"maximum connection age, 0 for no limit"`
+	ConnMaxIdleTime Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" usage:"maximum idle time of a connection, 0 for no limit"`
+	AutoMigrate     bool     `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE" usage:"apply pending migrations at startup"`
+	ConnectTimeout  Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT" usage:"how long to retry the initial connection, 0 to try once"`
+}
+
+// CORSConfig lists the origins allowed to call the API from a browser. An
+// empty list disables cross-origin requests; "*" allows any origin.
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" flag:"cors-origins" usage:"comma-separated allowed origins"`
+}
+
+// LogConfig controls logging
+type LogConfig struct {
+	Level     string   `yaml:"level" env:"LOG_LEVEL" flag:"log-level" usage:"debug, info, warn or error"`
+	SlowQuery Duration `yaml:"slow_query" env:"LOG_SLOW_QUERY" usage:"log SQL slower than this as a warning, 0 to disable"`
+}
+
+// FeatureConfig switches optional endpoints on or off
+type FeatureConfig struct {
+	Metrics bool `yaml:"metrics" env:"FEATURE_METRICS" usage:"serve /metrics"`
+	Bulk    bool `yaml:"bulk" env:"FEATURE_BULK" usage:"enable POST /api/issues/bulk"`
+	Import  bool `yaml:"import" env:"FEATURE_IMPORT" usage:"enable POST /api/issues/import"`
+	Export  bool `yaml:"export" env:"FEATURE_EXPORT" usage:"enable GET /api/issues/export"`
+	Reports bool `yaml:"reports" env:"FEATURE_REPORTS" usage:"enable /api/reports"`
+	Docs    bool `yaml:"docs" env:"FEATURE_DOCS" usage:"serve Swagger UI at /docs"`
+	GraphQL bool `yaml:"graphql" env:"FEATURE_GRAPHQL" usage:"serve /graphql"`
+	GRPC    bool `yaml:"grpc" env:"FEATURE_GRPC" usage:"serve the gRPC API on grpc.addr"`
+}
+
+// IdempotencyConfig controls Idempotency-Key handling
+type IdempotencyConfig struct {
+	Window Duration `yaml:"window" env:"IDEMPOTENCY_WINDOW" usage:"how long idempotent responses are kept"`
+}
+
+// SLAConfig is the time an issue of each priority may stay open
+type SLAConfig struct {
+	Critical Duration `yaml:"critical" env:"SLA_CRITICAL" usage:"SLA target for critical issues"`
+	High     Duration `yaml:"high" env:"SLA_HIGH" usage:"SLA target for high issues"`
+	Medium   Duration `yaml:"medium" env:"SLA_MEDIUM" usage:"SLA target for medium issues"`
+	Low      Duration `yaml:"low" env:"SLA_LOW" usage:"SLA target for low issues"`
+}
+
+// GraphQLConfig limits the size of GraphQL queries
+type GraphQLConfig struct {
+	MaxDepth      int `yaml:"max_depth" env:"GRAPHQL_MAX_DEPTH" usage:"deepest field nesting a query may select"`
+	MaxComplexity int `yaml:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" usage:"highest estimated number of fields a query may resolve"`
+}
+
+// GRPCConfig controls the gRPC listener. It uses the TLS files of the HTTP
+// server when those are set.
+type GRPCConfig struct {
+	Addr string `yaml:"addr" env:"GRPC_ADDR" flag:"grpc-addr" usage:"gRPC listen address, host:port"`
+}
+
+// RateLimitConfig throttles the HTTP API with a token bucket per client and
+// route class. Reads (GET and HEAD) and writes have separate budgets: a
+// client may send burst requests at once, then per_minute requests a minute.
+// gRPC calls are throttled the same way. It is off by default: callers
+// without an API key or sign-in are told apart by IP address, and behind a
+// load balancer or reverse proxy that is the proxy's unless
+// server.trusted_proxies lists it, so every such caller would share one
+// budget.
+type RateLimitConfig struct {
+	Enabled        bool   `yaml:"enabled" env:"RATE_LIMIT_ENABLED" usage:"throttle API requests per client; behind a proxy, set trusted proxies too"`
+	Store          string `yaml:"store" env:"RATE_LIMIT_STORE" usage:"memory for limits per replica, database to share them across replicas"`
+	ReadPerMinute  int    `yaml:"read_per_minute" env:"RATE_LIMIT_READ_PER_MINUTE" usage:"read requests a client may make per minute"`
+	ReadBurst      int    `yaml:"read_burst" env:"RATE_LIMIT_READ_BURST" usage:"read requests a client may make at once"`
+	WritePerMinute int    `yaml:"write_per_minute" env:"RATE_LIMIT_WRITE_PER_MINUTE" usage:"write requests a client may make per minute"`
+	WriteBurst     int    `yaml:"write_burst" env:"RATE_LIMIT_WRITE_BURST" usage:"write requests a client may make at once"`
+}
+
+// AuthConfig decides what callers without an API key or ID token may do.
+// Keys and signed-in people may always do the same and more. Left unset,
+// anonymous callers get every scope but admin, as before scopes existed,
+// or none once OIDC is enabled and people are expected to sign in.
+type AuthConfig struct {
+	AnonymousScopes []string `yaml:"anonymous_scopes" env:"AUTH_ANONYMOUS_SCOPES" usage:"comma-separated scopes of callers without an API key or ID token; empty for none"`
+}
+
+// OIDCConfig signs people in at the company identity provider with OpenID
+// Connect. Accounts get a role from the groups in their ID token: admin if
+// they are in one of admin_groups, officer if in one of officer_groups and
+// user otherwise.
+type OIDCConfig struct {
+	Enabled       bool     `yaml:"enabled" env:"OIDC_ENABLED" usage:"sign users in with OpenID Connect"`
+	IssuerURL     string   `yaml:"issuer_url" env:"OIDC_ISSUER_URL" usage:"issuer URL of the identity provider"`
+	ClientID      string   `yaml:"client_id" env:"OIDC_CLIENT_ID" usage:"client ID registered at the provider"`
+	ClientSecret  string   `yaml:"client_secret" env:"OIDC_CLIENT_SECRET" usage:"client secret, empty for a public client" redact:"secret"`
+	RedirectURL   string   `yaml:"redirect_url" env:"OIDC_REDIRECT_URL" usage:"URL of /auth/callback as registered at the provider"`
+	Scopes        []string `yaml:"scopes" env:"OIDC_SCOPES" usage:"comma-separated scopes requested besides openid"`
+	GroupsClaim   string   `yaml:"groups_claim" env:"OIDC_GROUPS_CLAIM" usage:"ID token claim listing the groups of an account"`
+	AdminGroups   []string `yaml:"admin_groups" env:"OIDC_ADMIN_GROUPS" usage:"comma-separated groups whose members are admins"`
+	OfficerGroups []string `yaml:"officer_groups" env:"OIDC_OFFICER_GROUPS" usage:"comma-separated groups whose members are officers"`
+	CookieSecret  string   `yaml:"cookie_secret" env:"OIDC_COOKIE_SECRET" usage:"secret of at least 32 characters protecting the sign-in cookie, shared by replicas" redact:"secret"`
+}
+
+// minCookieSecret is the shortest accepted oidc.cookie_secret
+const minCookieSecret = 32
+
+// PortalConfig controls the public portal, where people without an account
+// report issues and follow them with the tracking token they get back.
+// Reports and comments that trip the spam checks are rejected.
+type PortalConfig struct {
+	Enabled      bool          `yaml:"enabled" env:"PORTAL_ENABLED" usage:"serve the public issue portal under /api/public"`
+	Status       string        `yaml:"status" env:"PORTAL_STATUS" usage:"code of the status issues reported on the portal start in"`
+	Captcha      CaptchaConfig `yaml:"captcha"`
+	MaxLinks     int           `yaml:"max_links" env:"PORTAL_MAX_LINKS" usage:"most links a report or comment may contain"`
+	BlockedTerms []string      `yaml:"blocked_terms" env:"PORTAL_BLOCKED_TERMS" usage:"comma-separated words or phrases that mark a report or comment as spam"`
+}
+
+// CaptchaConfig makes portal visitors solve a captcha before reporting or
+// commenting
+type CaptchaConfig struct {
+	Provider  string `yaml:"provider" env:"CAPTCHA_PROVIDER" usage:"none, hcaptcha, recaptcha or turnstile"`
+	Secret    string `yaml:"secret" env:"CAPTCHA_SECRET" usage:"secret key of the site at the captcha provider" redact:"secret"`
+	VerifyURL string `yaml:"verify_url" env:"CAPTCHA_VERIFY_URL" usage:"verification endpoint, empty for the provider's"`
+}
+
+// CSATConfig sends the reporter of an issue a satisfaction survey when it
+// moves to a terminal status. The survey link is signed with secret, so
+// reporters can answer without an account but cannot rate other issues.
+type CSATConfig struct {
+	Enabled   bool     `yaml:"enabled" env:"CSAT_ENABLED" usage:"send a satisfaction survey when an issue closes"`
+	SurveyURL string   `yaml:"survey_url" env:"CSAT_SURVEY_URL" usage:"URL of the page reporters answer surveys on; the survey and signature query parameters are added"`
+	Secret    string   `yaml:"secret" env:"CSAT_SECRET" usage:"secret of at least 32 characters signing survey links, shared by replicas" redact:"secret"`
+	LinkTTL   Duration `yaml:"link_ttl" env:"CSAT_LINK_TTL" usage:"how long a survey can be answered"`
+}
+
+// minSurveySecret is the shortest accepted csat.secret
+const minSurveySecret = 32
+
+// Targets returns the SLA targets keyed by priority
+func (s SLAConfig) Targets() map[string]time.Duration {
+	return map[string]time.Duration{
+		"critical": s.Critical.Duration,
+		"high":     s.High.Duration,
+		"medium":   s.Medium.Duration,
+		"low":      s.Low.Duration,
+	}
+}
+
+// Duration is a time.Duration written as "30s" or "24h" in files and
+// environment variables
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", text)
+	}
+	d.Duration = v
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Default returns the configuration used when nothing is overridden. There
+// is no default database URL so credentials are never baked in.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{Addr: ":8080", ShutdownTimeout: Duration{30 * time.Second}},
+		Database: DatabaseConfig{
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: Duration{30 * time.Minute},
+			ConnMaxIdleTime: Duration{5 * time.Minute},
+			AutoMigrate:     true,
+			ConnectTimeout:  Duration{time.Minute},
+		},
+		Log: LogConfig{Level: "info", SlowQuery: Duration{200 * time.Millisecond}},
+		Features: FeatureConfig{
+			Metrics: true,
+			Bulk:    true,
+			Import:  true,
+			Export:  true,
+			Reports: true,
+			Docs:    true,
+			GraphQL: true,
+			GRPC:    true,
+		},
+		Idempotency: IdempotencyConfig{Window: Duration{24 * time.Hour}},
+		SLA: SLAConfig{
+			Critical: Duration{metrics.DefaultSLATargets["critical"]},
+			High:     Duration{metrics.DefaultSLATargets["high"]},
+			Medium:   Duration{metrics.DefaultSLATargets["medium"]},
+			Low:      Duration{metrics.DefaultSLATargets["low"]},
+		},
+		GraphQL: GraphQLConfig{MaxDepth: 10, MaxComplexity: 10000},
+		GRPC:    GRPCConfig{Addr: ":9090"},
+		RateLimit: RateLimitConfig{
+			Store:          "memory",
+			ReadPerMinute:  600,
+			ReadBurst:      100,
+			WritePerMinute: 60,
+			WriteBurst:     20,
+		},
+		OIDC: OIDCConfig{Scopes: []string{"profile", "email"}, GroupsClaim: "groups"},
+		Portal: PortalConfig{
+			Status:   "open",
+			Captcha:  CaptchaConfig{Provider: "none"},
+			MaxLinks: 2,
+		},
+		CSAT: CSATConfig{LinkTTL: Duration{30 * 24 * time.Hour}},
+	}
+}
+
+// AnonymousScopes returns the scopes of callers without an API key or ID
+// token: auth.anonymous_scopes when it is set, else the default for the
+// OIDC setting
+func (c *Config) AnonymousScopes() []string {
+	switch {
+	case c.Auth.AnonymousScopes != nil:
+		return c.Auth.AnonymousScopes
+	case c.OIDC.Enabled:
+		return []string{}
+	default:
+		return auth.DefaultAnonymousScopes
+	}
+}
+
+// TLSEnabled reports whether the server should serve HTTPS
+func (c *Config) TLSEnabled() bool {
+	return c.Server.TLS.CertFile != "" && c.Server.TLS.KeyFile != ""
+}
+
+// Validate checks every setting and reports all problems at once
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	checkAddr := func(field, addr string) {
+		if _, port, err := net.SplitHostPort(addr); err != nil {
+			add(field, "must be host:port, got %q", addr)
+		} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+			add(field, "invalid port %q", port)
+		}
+	}
+	checkAddr("server.addr", c.Server.Addr)
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				add("server.trusted_proxies", "%q must be an IP address or CIDR", proxy)
+			}
+		}
+	}
+
+	if c.Server.ShutdownTimeout.Duration <= 0 {
+		add("server.shutdown_timeout", "must be positive")
+	}
+
+	tls := c.Server.TLS
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		add("server.tls", "cert_file and key_file must be set together")
+	}
+	if tls.CertFile != "" {
+		if _, err := os.Stat(tls.CertFile); err != nil {
+			add("server.tls.cert_file", "%v", err)
+		}
+	}
+	if tls.KeyFile != "" {
+		if _, err := os.Stat(tls.KeyFile); err != nil {
+			add("server.tls.key_file", "%v", err)
+		}
+	}
+
+	db := c.Database
+	if strings.TrimSpace(db.URL) == "" {
+		add("database.url", "is required (set DATABASE_URL or -database-url)")
+	}
+	if db.MaxOpenConns < 0 {
+		add("database.max_open_conns", "must not be negative")
+	}
+	if db.MaxIdleConns < 0 {
+		add("database.max_idle_conns", "must not be negative")
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		add("database.max_idle_conns", "must not exceed max_open_conns (%d)", db.MaxOpenConns)
+	}
+	if db.ConnMaxLifetime.Duration < 0 {
+		add("database.conn_max_lifetime", "must not be negative")
+	}
+	if db.ConnMaxIdleTime.Duration < 0 {
+		add("database.conn_max_idle_time", "must not be negative")
+	}
+	if db.ConnectTimeout.Duration < 0 {
+		add("database.connect_timeout", "must not be negative")
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			add("cors.allowed_origins", "%q must be \"*\" or scheme://host[:port]", origin)
+		}
+	}
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		add("log.level", "must be one of: debug info warn error, got %q", c.Log.Level)
+	}
+	if c.Log.SlowQuery.Duration < 0 {
+		add("log.slow_query", "must not be negative")
+	}
+
+	if c.Idempotency.Window.Duration <= 0 {
+		add("idempotency.window", "must be positive")
+	}
+	targets := c.SLA.Targets()
+	for _, priority := range []string{"critical", "high", "medium", "low"} {
+		if targets[priority] <= 0 {
+			add("sla."+priority, "must be positive")
+		}
+	}
+	if c.GraphQL.MaxDepth < 1 {
+		add("graphql.max_depth", "must be positive")
+	}
+	if c.GraphQL.MaxComplexity < 1 {
+		add("graphql.max_complexity", "must be positive")
+	}
+	if c.Features.GRPC {
+		checkAddr("grpc.addr", c.GRPC.Addr)
+		if c.GRPC.Addr == c.Server.Addr {
+			add("grpc.addr", "must differ from server.addr")
+		}
+	}
+	if c.RateLimit.Enabled {
+		if c.RateLimit.Store != "memory" && c.RateLimit.Store != "database" {
+			add("rate_limit.store", "must be memory or database, got %q", c.RateLimit.Store)
+		}
+		limits := []struct {
+			field string
+			value int
+		}{
+			{"rate_limit.read_per_minute", c.RateLimit.ReadPerMinute},
+			{"rate_limit.read_burst", c.RateLimit.ReadBurst},
+			{"rate_limit.write_per_minute", c.RateLimit.WritePerMinute},
+			{"rate_limit.write_burst", c.RateLimit.WriteBurst},
+		}
+		for _, limit := range limits {
+			if limit.value < 1 {
+				add(limit.field, "must be positive")
+			}
+		}
+	}
+
+	for _, scope := range c.Auth.AnonymousScopes {
+		if !auth.KnownScope(scope) || scope == auth.ScopeAdmin {
+			add("auth.anonymous_scopes", "must be scopes other than %s, got %q", auth.ScopeAdmin, scope)
+		}
+	}
+
+	if c.OIDC.Enabled {
+		oidc := c.OIDC
+		for _, u := range []struct{ field, value string }{
+			{"oidc.issuer_url", oidc.IssuerURL},
+			{"oidc.redirect_url", oidc.RedirectURL},
+		} {
+			parsed, err := url.Parse(u.value)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				add(u.field, "must be an http or https URL, got %q", u.value)
+			}
+		}
+		if strings.TrimSpace(oidc.ClientID) == "" {
+			add("oidc.client_id", "is required")
+		}
+		if strings.TrimSpace(oidc.GroupsClaim) == "" {
+			add("oidc.groups_claim", "is required")
+		}
+		if len(oidc.CookieSecret) < minCookieSecret {
+			add("oidc.cookie_secret", "must be at least %d characters", minCookieSecret)
+		}
+	}
+
+	if c.Portal.Enabled {
+		portal := c.Portal
+		if strings.TrimSpace(portal.Status) == "" {
+			add("portal.status", "is required")
+		}
+		if portal.MaxLinks < 0 {
+			add("portal.max_links", "must not be negative")
+		}
+		switch _, known := captcha.Providers[portal.Captcha.Provider]; {
+		case portal.Captcha.Provider == "none":
+		case !known:
+			add("portal.captcha.provider", "must be one of: %s, got %q", strings.Join(captcha.ProviderNames(), " "), portal.Captcha.Provider)
+		case portal.Captcha.Secret == "":
+			add("portal.captcha.secret", "is required with provider %s", portal.Captcha.Provider)
+		}
+		if portal.Captcha.VerifyURL != "" {
+			parsed, err := url.Parse(portal.Captcha.VerifyURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				add("portal.captcha.verify_url", "must be an http or https URL, got %q", portal.Captcha.VerifyURL)
+			}
+		}
+	}
+
+	if c.CSAT.Enabled {
+		parsed, err := url.Parse(c.CSAT.SurveyURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			add("csat.survey_url", "must be an http or https URL, got %q", c.CSAT.SurveyURL)
+		}
+		if len(c.CSAT.Secret) < minSurveySecret {
+			add("csat.secret", "must be at least %d characters", minSurveySecret)
+		}
+		if c.CSAT.LinkTTL.Duration <= 0 {
+			add("csat.link_ttl", "must be positive")
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"issue-tracking/auth"
+)
+
+func load(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return Load(fs, args)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: ":9000"
+database:
+  url: "postgres://app:hunter2@db/issues"
+  max_open_conns: 40
+log:
+  level: warn
+features:
+  import: false
+cors:
+  allowed_origins: ["https://file.example"]
+`)
+	t.Setenv("DB_MAX_OPEN_CONNS", "50")
+	t.Setenv("IDEMPOTENCY_WINDOW", "2h")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example, https://b.example")
+	t.Setenv("LOG_LEVEL", "error")
+
+	cfg, err := load(t, "-config", path, "-log-level", "debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.Addr != ":9000" {
+		t.Errorf("addr = %q, want value from file", cfg.Server.Addr)
+	}
+	if cfg.Database.MaxOpenConns != 50 {
+		t.Errorf("max_open_conns = %d, want env override", cfg.Database.MaxOpenConns)
+	}
+	if cfg.Database.MaxIdleConns != 5 {
+		t.Errorf("max_idle_conns = %d, want default", cfg.Database.MaxIdleConns)
+	}
+	if cfg.Log.Level != "debug" {
+		t.Errorf("log level = %q, want flag override", cfg.Log.Level)
+	}
+	if cfg.Features.Import || !cfg.Features.Export {
+		t.Errorf("features = %+v, want import off and export on", cfg.Features)
+	}
+	if cfg.Idempotency.Window.Duration != 2*time.Hour {
+		t.Errorf("idempotency window = %s", cfg.Idempotency.Window)
+	}
+	if got := strings.Join(cfg.CORS.AllowedOrigins, " "); got != "https://a.example https://b.example" {
+		t.Errorf("allowed origins = %q", got)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[database]
+url = "host=db user=app password=hunter2 dbname=issues"
+
+[sla]
+critical = "2h"
+`)
+	cfg, err := load(t, "-config", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SLA.Critical.Duration != 2*time.Hour || cfg.SLA.Low.Duration != 168*time.Hour {
+		t.Errorf("sla = %+v", cfg.SLA)
+	}
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	path := writeFile(t, "config.yaml", "server:\n  adr: \":9000\"\n")
+	if _, err := load(t, "-config", path); err == nil || !strings.Contains(err.Error(), "adr") {
+		t.Fatalf("expected an unknown field error, got %v", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Addr = "8080"
+	cfg.Server.TLS.CertFile = "cert.pem"
+	cfg.Database.MaxOpenConns = 2
+	cfg.Database.MaxIdleConns = 3
+	cfg.CORS.AllowedOrigins = []string{"*", "example.com"}
+	cfg.Log.Level = "loud"
+	cfg.GRPC.Addr = "9090"
+	cfg.Server.TrustedProxies = []string{"10.0.0.0/8", "proxy"}
+	cfg.RateLimit.Enabled = true
+	cfg.RateLimit.Store = "redis"
+	cfg.RateLimit.WriteBurst = 0
+	cfg.Auth.AnonymousScopes = []string{"issues:read", "admin"}
+	cfg.OIDC.Enabled = true
+	cfg.OIDC.IssuerURL = "idp.example.com"
+	cfg.OIDC.CookieSecret = "short"
+	cfg.Portal.Enabled = true
+	cfg.Portal.Status = ""
+	cfg.Portal.Captcha.Provider = "recaptcha"
+	cfg.CSAT.Enabled = true
+	cfg.CSAT.LinkTTL.Duration = 0
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, field := range []string{
+		"server.addr", "server.tls:", "server.tls.cert_file", "database.url",
+		"database.max_idle_conns", "cors.allowed_origins", "log.level", "grpc.addr",
+		"server.trusted_proxies", "rate_limit.store", "rate_limit.write_burst",
+		"auth.anonymous_scopes",
+		"oidc.issuer_url", "oidc.redirect_url", "oidc.client_id", "oidc.cookie_secret",
+		"portal.status", "portal.captcha.secret",
+		"csat.survey_url", "csat.secret", "csat.link_ttl",
+	} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("missing error for %s in:\n%v", field, err)
+		}
+	}
+}
+
+func TestAnonymousScopes(t *testing.T) {
+	cfg := Default()
+	if got := cfg.AnonymousScopes(); len(got) != len(auth.DefaultAnonymousScopes) {
+		t.Errorf("default = %v, want %v", got, auth.DefaultAnonymousScopes)
+	}
+	cfg.OIDC.Enabled = true
+	if got := cfg.AnonymousScopes(); got == nil || len(got) != 0 {
+		t.Errorf("with OIDC = %v, want none", got)
+	}
+	cfg.Auth.AnonymousScopes = []string{auth.ScopeIssuesRead}
+	if got := cfg.AnonymousScopes(); len(got) != 1 || got[0] != auth.ScopeIssuesRead {
+		t.Errorf("configured = %v", got)
+	}
+}
+
+func TestPrintRedactsPasswords(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"postgres://app:hunter2@db:5432/issues?sslmode=disable", "postgres://app:xxxxx@db:5432/issues?sslmode=disable"},
+		{"host=db user=app password=hunter2 dbname=issues", "host=db user=app password=xxxxx dbname=issues"},
+		{"host=db password='hunter 2' dbname=issues", "host=db password=xxxxx dbname=issues"},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.Database.URL = tt.url
+
+		var buf bytes.Buffer
+		if err := cfg.Print(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(buf.String(), "hunter") || !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("printed config does not redact %q:\n%s", tt.url, buf.String())
+		}
+		if cfg.Database.URL != tt.url {
+			t.Errorf("Print modified the config")
+		}
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.OIDC.ClientSecret = "client-secret"
+	cfg.OIDC.CookieSecret = "cookie-secret-cookie-secret-cookie"
+
+	var buf bytes.Buffer
+	if err := cfg.Print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "secret-") || strings.Contains(buf.String(), "client-secret") {
+		t.Errorf("printed config shows a secret:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "client_secret: xxxxx") {
+		t.Errorf("printed config does not redact the client secret:\n%s", buf.String())
+	}
+	if cfg.OIDC.ClientSecret != "client-secret" {
+		t.Errorf("Print modified the config")
+	}
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// FileEnv names the environment variable holding the config file path
+const FileEnv = "CONFIG_FILE"
+
+// redacted replaces secrets in printed configuration
+const redacted = "xxxxx"
+
+// field is one leaf setting of Config
+type field struct {
+	path   string
+	env    string
+	flag   string
+	usage  string
+	redact string
+	value  reflect.Value
+}
+
+// Load registers -config and the per-setting flags on fs, parses args and
+// returns the validated configuration. Callers may register their own flags
+// on fs before calling Load.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	cfg, err := Parse(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// Parse is Load without validation, for commands that only need the
+// configuration in some modes
+func Parse(fs *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+	path := fs.String("config", os.Getenv(FileEnv), "YAML or TOML config file (env "+FileEnv+")")
+
+	// Flag values are collected first and applied last so they win over the
+	// file and environment, which are only known after parsing
+	flagValues := map[string]string{}
+	for _, f := range fields(cfg) {
+		if f.flag == "" {
+			continue
+		}
+		name := f.flag
+		fs.Func(name, fmt.Sprintf("%s (env %s)", f.usage, f.env), func(s string) error {
+			flagValues[name] = s
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range fields(cfg) {
+		if value, ok := os.LookupEnv(f.env); ok && f.env != "" {
+			if err := setValue(f.value, value); err != nil {
+				return nil, fmt.Errorf("%s (%s): %w", f.env, f.path, err)
+			}
+		}
+	}
+	for _, f := range fields(cfg) {
+		if value, ok := flagValues[f.flag]; ok && f.flag != "" {
+			if err := setValue(f.value, value); err != nil {
+				return nil, fmt.Errorf("-%s (%s): %w", f.flag, f.path, err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// loadFile overlays a YAML or TOML file. Unknown keys are rejected so typos
+// do not silently fall back to defaults.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	case ".toml":
+		// Decode TOML generically and hand it to the YAML decoder as JSON so
+		// both formats share the yaml struct tags and strict key checking
+		var doc map[string]interface{}
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+
+	if err := yaml.UnmarshalWithOptions(data, c, yaml.Strict()); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// Print writes the effective configuration as YAML with secrets redacted
+func (c *Config) Print(w io.Writer) error {
+	copied := *c
+	copied.CORS.AllowedOrigins = append([]string(nil), c.CORS.AllowedOrigins...)
+	for _, f := range fields(&copied) {
+		if f.redact == "" || f.value.Kind() != reflect.String {
+			continue
+		}
+		if f.redact == "secret" {
+			if f.value.String() != "" {
+				f.value.SetString(redacted)
+			}
+		} else {
+			f.value.SetString(redact(f.value.String()))
+		}
+	}
+
+	out, err := yaml.Marshal(&copied)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// dsnPassword matches password=... in key/value connection strings
+var dsnPassword = regexp.MustCompile(`(?i)(password=)('[^']*'|\S+)`)
+
+// redact hides the password of a URL or key/value connection string
+func redact(value string) string {
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+			return u.String()
+		}
+		return value
+	}
+	return dsnPassword.ReplaceAllString(value, "${1}"+redacted)
+}
+
+// fields lists the leaf settings of cfg in declaration order
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			path := prefix + sf.Tag.Get("yaml")
+			fv := v.Field(i)
+			if sf.Type.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(Duration{}) {
+				walk(fv, path+".")
+				continue
+			}
+			out = append(out, field{
+				path:   path,
+				env:    sf.Tag.Get("env"),
+				flag:   sf.Tag.Get("flag"),
+				usage:  sf.Tag.Get("usage"),
+				redact: sf.Tag.Get("redact"),
+				value:  fv,
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
+
+// setValue parses s into a leaf setting
+func setValue(v reflect.Value, s string) error {
+	if d, ok := v.Addr().Interface().(*Duration); ok {
+		return d.UnmarshalText([]byte(strings.TrimSpace(s)))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
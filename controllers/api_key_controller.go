@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"issue-tracking/services"
+	"issue-tracking/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyRequest is the body of POST /api/admin/api-keys
+type APIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyController struct {
+	keys *services.APIKeyService
+}
+
+// NewAPIKeyController creates a new API key controller
+func NewAPIKeyController(keys *services.APIKeyService) *APIKeyController {
+	return &APIKeyController{keys: keys}
+}
+
+// GetAllAPIKeys retrieves every API key without the keys themselves
+func (kc *APIKeyController) GetAllAPIKeys(c *gin.Context) {
+	keys, err := kc.keys.List(c.Request.Context())
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, 200, keys)
+}
+
+// CreateAPIKey creates an API key; the response is the only time the key
+// itself is shown
+func (kc *APIKeyController) CreateAPIKey(c *gin.Context) {
+	var req APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "Invalid request body", err.Error())
+		return
+	}
+
+	key, err := kc.keys.Create(c.Request.Context(), services.NewAPIKey{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, 201, key)
+}
+
+// RevokeAPIKey revokes an API key by ID
+func (kc *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid API key ID", "id must be a positive integer")
+		return
+	}
+
+	key, err := kc.keys.Revoke(c.Request.Context(), uint(id))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, 200, key)
+}
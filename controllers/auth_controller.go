@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"issue-tracking/auth"
+	"issue-tracking/services"
+	"issue-tracking/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signInCookie holds the sealed auth.LoginState while a person signs in at
+// the provider
+const signInCookie = "oidc_sign_in"
+
+// signInCookiePath limits the cookie to the sign-in routes
+const signInCookiePath = "/auth"
+
+type AuthController struct {
+	identities   *services.IdentityService
+	cookieSecret string
+	secure       bool
+}
+
+// NewAuthController creates a new sign-in controller. The sign-in cookie is
+// sealed with cookieSecret and only sent over HTTPS when secure is set.
+func NewAuthController(identities *services.IdentityService, cookieSecret string, secure bool) *AuthController {
+	return &AuthController{identities: identities, cookieSecret: cookieSecret, secure: secure}
+}
+
+// Login sends the browser to the provider's sign-in page, remembering the
+// state, nonce and PKCE verifier of the sign-in in a cookie
+func (ac *AuthController) Login(c *gin.Context) {
+	url, state, err := ac.identities.StartSignIn(c.Request.Context())
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	sealed, err := auth.SealLoginState(ac.cookieSecret, *state)
+	if err != nil {
+		utils.RespondError(c, 500, "Failed to start sign-in", nil)
+		return
+	}
+
+	// Lax, as the provider sends the browser back with a top-level GET
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(signInCookie, sealed, int(time.Until(state.Expires).Seconds()), signInCookiePath, "", ac.secure, true)
+	c.Redirect(http.StatusFound, url)
+}
+
+// Callback finishes a sign-in when the provider sends the browser back. It
+// responds with the ID token to send as "Authorization: Bearer" and the
+// identity it signs in as.
+func (ac *AuthController) Callback(c *gin.Context) {
+	if reason := c.Query("error"); reason != "" {
+		utils.RespondError(c, 401, "Sign-in failed", reason+" "+c.Query("error_description"))
+		return
+	}
+
+	sealed, err := c.Cookie(signInCookie)
+	if err != nil {
+		utils.RespondError(c, 400, "Sign-in not started", "start at /auth/login")
+		return
+	}
+	// The cookie is good for one attempt
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(signInCookie, "", -1, signInCookiePath, "", ac.secure, true)
+
+	state, err := auth.OpenLoginState(ac.cookieSecret, sealed)
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid sign-in state", err.Error())
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Query("state")), []byte(state.State)) != 1 {
+		utils.RespondError(c, 400, "Invalid sign-in state", "state does not match the sign-in")
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		utils.RespondError(c, 400, "Invalid sign-in state", "code is required")
+		return
+	}
+
+	signIn, err := ac.identities.FinishSignIn(c.Request.Context(), state, code)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, 200, signIn)
+}
+
+// Me returns the identity the request is signed in as
+func (ac *AuthController) Me(c *gin.Context) {
+	identity := auth.IdentityFrom(c.Request.Context())
+	if identity == nil {
+		c.Header("WWW-Authenticate", "Bearer")
+		utils.RespondError(c, 401, "Unauthorized", "an ID token is required")
+		return
+	}
+	utils.RespondSuccess(c, 200, identity)
+}
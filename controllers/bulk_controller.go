@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"issue-tracking/auth"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/services"
+	"issue-tracking/utils"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBulkIssues caps how many issues a single bulk request may touch
+const maxBulkIssues = 500
+
+// Bulk operations
+const (
+	BulkChangeStatus = "change_status"
+	BulkAssign       = "assign"
+	BulkSetPriority  = "set_priority"
+	BulkAddLabel     = "add_label"
+	BulkClose        = "close"
+)
+
+// Bulk execution modes
+const (
+	BulkModeTransactional = "transactional"
+	BulkModeBestEffort    = "best_effort"
+)
+
+type BulkController struct {
+	store  repositories.Store
+	issues *services.IssueService
+}
+
+// NewBulkController creates a new bulk controller
+func NewBulkController(store repositories.Store, issues *services.IssueService) *BulkController {
+	return &BulkController{store: store, issues: issues}
+}
+
+// BulkRequest selects issues by ID or by a filter expression using the same
+// query syntax as GET /api/issues (e.g. "status=open&priority=high")
+type BulkRequest struct {
+	IssueIDs  []uint `json:"issue_ids"`
+	Filter    string `json:"filter"`
+	Operation string `json:"operation" binding:"required"`
+	Mode      string `json:"mode"`
+	Comment   string `json:"comment"`
+
+	StatusID   *uint  `json:"status_id"`
+	AssigneeID *uint  `json:"assignee_id"`
+	Priority   string `json:"priority"`
+	Label      string `json:"label"`
+}
+
+// BulkItemResult reports the outcome for one issue
+type BulkItemResult struct {
+	IssueID uint   `json:"issue_id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkResult summarizes a bulk request
+type BulkResult struct {
+	Operation string           `json:"operation"`
+	Mode      string           `json:"mode"`
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// BulkUpdateIssues applies one operation to many issues
+func (bc *BulkController) BulkUpdateIssues(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "Invalid request body", err.Error())
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = BulkModeTransactional
+	}
+	if req.Mode != BulkModeTransactional && req.Mode != BulkModeBestEffort {
+		utils.RespondError(c, 400, "Invalid mode", "mode must be one of: transactional best_effort")
+		return
+	}
+
+	if (len(req.IssueIDs) == 0) == (req.Filter == "") {
+		utils.RespondError(c, 400, "Invalid selection", "provide either issue_ids or filter")
+		return
+	}
+
+	change, err := buildChange(ctx, bc.store, req)
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) {
+		respondServiceError(c, err)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid operation", err.Error())
+		return
+	}
+
+	issueIDs, err := resolveIssueIDs(ctx, bc.store, req)
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid filter", err.Error())
+		return
+	}
+	if len(issueIDs) > maxBulkIssues {
+		utils.RespondError(c, 400, "Too many issues", fmt.Sprintf("a bulk request may affect at most %d issues", maxBulkIssues))
+		return
+	}
+
+	result := BulkResult{
+		Operation: req.Operation,
+		Mode:      req.Mode,
+		Total:     len(issueIDs),
+		Results:   make([]BulkItemResult, 0, len(issueIDs)),
+	}
+
+	// In transactional mode the first failure rolls back every issue
+	errs, err := bc.issues.BulkChange(ctx, issueIDs, change, req.Mode == BulkModeTransactional)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	for i, issueID := range issueIDs {
+		result.add(issueID, errs[i])
+	}
+	if req.Mode == BulkModeTransactional && result.Failed > 0 {
+		utils.RespondError(c, 422, "Bulk operation rolled back", result)
+		return
+	}
+	utils.RespondSuccess(c, 200, result)
+}
+
+// buildChange validates the operation parameters and turns them into an
+// IssueChange. Missing statuses and officers are *services.Error with 422,
+// and failures to look them up with 500; other errors are invalid requests.
+// The label of add_label is resolved by the bulk change itself, so it is
+// created in the same transaction as the changes.
+func buildChange(ctx context.Context, store repositories.Store, req BulkRequest) (repositories.IssueChange, error) {
+	change := repositories.IssueChange{
+		Comment:   req.Comment,
+		ChangedBy: 1, // Default to officer ID 1, should be from auth context in production
+	}
+	if officerID, ok := auth.OfficerID(ctx); ok {
+		change.ChangedBy = officerID
+	}
+
+	switch req.Operation {
+	case BulkChangeStatus:
+		if req.StatusID == nil {
+			return change, errors.New("status_id is required")
+		}
+		status, err := store.Statuses().Get(ctx, *req.StatusID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return change, &services.Error{Status: 422, Message: "Invalid operation", Details: "status_id does not exist"}
+		}
+		if err != nil {
+			return change, &services.Error{Status: 500, Message: "Failed to load status", Details: err.Error()}
+		}
+		change.NewStatusID = &status.StatusID
+
+	case BulkClose:
+		statuses, err := store.Statuses().List(ctx, true)
+		if err != nil {
+			return change, &services.Error{Status: 500, Message: "Failed to load statuses", Details: err.Error()}
+		}
+		for _, status := range statuses {
+			if status.IsTerminal && (req.StatusID == nil || status.StatusID == *req.StatusID) {
+				change.NewStatusID = &status.StatusID
+				break
+			}
+		}
+		if change.NewStatusID == nil {
+			return change, errors.New("no active terminal status found to close issues with")
+		}
+
+	case BulkAssign:
+		// A null assignee_id unassigns the issues
+		if req.AssigneeID != nil {
+			_, err := store.Officers().Get(ctx, *req.AssigneeID)
+			if errors.Is(err, repositories.ErrNotFound) {
+				return change, &services.Error{Status: 422, Message: "Invalid operation", Details: "assignee_id does not exist"}
+			}
+			if err != nil {
+				return change, &services.Error{Status: 500, Message: "Failed to load assignee", Details: err.Error()}
+			}
+		}
+		change.SetAssignee = true
+		change.AssigneeID = req.AssigneeID
+
+	case BulkSetPriority:
+		switch req.Priority {
+		case "low", "medium", "high", "critical":
+		default:
+			return change, errors.New("priority must be one of: low medium high critical")
+		}
+		change.Priority = req.Priority
+
+	case BulkAddLabel:
+		label := entities.Label{Name: req.Label}
+		if validationErrors := utils.ValidateStruct(label); len(validationErrors) > 0 {
+			return change, errors.New(validationErrors[0].Message)
+		}
+		change.Label = &label
+
+	default:
+		return change, fmt.Errorf("operation must be one of: %s %s %s %s %s",
+			BulkChangeStatus, BulkAssign, BulkSetPriority, BulkAddLabel, BulkClose)
+	}
+
+	if change.Comment == "" {
+		change.Comment = "Bulk " + req.Operation
+	}
+	return change, nil
+}
+
+// resolveIssueIDs returns the explicit issue IDs or the IDs matching the filter
+func resolveIssueIDs(ctx context.Context, store repositories.Store, req BulkRequest) ([]uint, error) {
+	if len(req.IssueIDs) > 0 {
+		seen := make(map[uint]bool, len(req.IssueIDs))
+		issueIDs := make([]uint, 0, len(req.IssueIDs))
+		for _, id := range req.IssueIDs {
+			if !seen[id] {
+				seen[id] = true
+				issueIDs = append(issueIDs, id)
+			}
+		}
+		return issueIDs, nil
+	}
+
+	values, err := url.ParseQuery(req.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("filter must use query string syntax: %w", err)
+	}
+
+	filter, err := parseIssueFilter(values)
+	if err != nil {
+		return nil, err
+	}
+	return store.Issues().ListIDs(ctx, filter, maxBulkIssues+1)
+}
+
+// add records the outcome for one issue
+func (r *BulkResult) add(issueID uint, err error) {
+	item := BulkItemResult{IssueID: issueID, Success: err == nil}
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			item.Error = "issue not found"
+		} else {
+			item.Error = err.Error()
+		}
+		r.Failed++
+	} else {
+		r.Succeeded++
+	}
+	r.Results = append(r.Results, item)
+}
@@ -0,0 +1,312 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"issue-tracking/controllers"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// seedBulkStore returns a store with statuses open (1), in-progress (2) and
+// closed (3, terminal), one reporter, officers 1 and 2 and issues 1-3, open
+// with medium priority
+func seedBulkStore(t *testing.T) repositories.Store {
+	t.Helper()
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	for i, code := range []string{"open", "in-progress", "closed"} {
+		status := entities.IssueStatus{StatusCode: code, DisplayName: code, Color: "#000000", DisplayOrder: i, IsActive: true, IsTerminal: code == "closed"}
+		if err := store.Statuses().Create(ctx, &status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Users().Create(ctx, &entities.User{FullName: "Bulk Reporter"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Jane Smith", "Bob Brown"} {
+		if err := store.Officers().Create(ctx, &entities.Officer{FullName: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		issue := entities.Issue{ReporterID: 1, StatusID: 1, Title: fmt.Sprintf("Issue %d", i), Priority: "medium"}
+		if err := store.Issues().Create(ctx, &issue); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func bulkRequest(t *testing.T, store repositories.Store, body string) (*httptest.ResponseRecorder, controllers.BulkResult) {
+	t.Helper()
+	return serviceBulkRequest(t, store, services.NewIssueService(store), body)
+}
+
+// serviceBulkRequest sends a bulk request handled by the given issue service
+func serviceBulkRequest(t *testing.T, store repositories.Store, issues *services.IssueService, body string) (*httptest.ResponseRecorder, controllers.BulkResult) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/issues/bulk", controllers.NewBulkController(store, issues).BulkUpdateIssues)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/issues/bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	// Rolled back requests report their result in the error details
+	var envelope struct {
+		Data    *controllers.BulkResult `json:"data"`
+		Details *controllers.BulkResult `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &envelope)
+	switch {
+	case envelope.Data != nil:
+		return w, *envelope.Data
+	case envelope.Details != nil:
+		return w, *envelope.Details
+	}
+	return w, controllers.BulkResult{}
+}
+
+func TestBulkUpdateIssues(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		status    int
+		succeeded int
+		failed    int
+		// check inspects the issues afterwards
+		check func(t *testing.T, issues map[uint]entities.Issue)
+	}{
+		{
+			name:   "change status",
+			body:   `{"operation":"change_status","issue_ids":[1,2,2],"status_id":2}`,
+			status: 200, succeeded: 2,
+			check: func(t *testing.T, issues map[uint]entities.Issue) {
+				if issues[1].StatusID != 2 || issues[2].StatusID != 2 || issues[3].StatusID != 1 {
+					t.Errorf("statuses = %d %d %d", issues[1].StatusID, issues[2].StatusID, issues[3].StatusID)
+				}
+			},
+		},
+		{
+			name:   "close uses the terminal status",
+			body:   `{"operation":"close","issue_ids":[3]}`,
+			status: 200, succeeded: 1,
+			check: func(t *testing.T, issues map[uint]entities.Issue) {
+				if issues[3].StatusID != 3 {
+					t.Errorf("closed issue has status %d", issues[3].StatusID)
+				}
+			},
+		},
+		{
+			name:   "assign by filter",
+			body:   `{"operation":"assign","filter":"status=open","assignee_id":2}`,
+			status: 200, succeeded: 3,
+			check: func(t *testing.T, issues map[uint]entities.Issue) {
+				for id, issue := range issues {
+					if issue.AssigneeID == nil || *issue.AssigneeID != 2 {
+						t.Errorf("issue %d assignee = %v", id, issue.AssigneeID)
+					}
+				}
+			},
+		},
+		{
+			name:   "set priority",
+			body:   `{"operation":"set_priority","issue_ids":[1],"priority":"critical"}`,
+			status: 200, succeeded: 1,
+			check: func(t *testing.T, issues map[uint]entities.Issue) {
+				if issues[1].Priority != "critical" || issues[2].Priority != "medium" {
+					t.Errorf("priorities = %s %s", issues[1].Priority, issues[2].Priority)
+				}
+			},
+		},
+		{
+			name:   "best effort skips missing issues",
+			body:   `{"operation":"set_priority","issue_ids":[1,99],"priority":"high","mode":"best_effort"}`,
+			status: 200, succeeded: 1, failed: 1,
+			check: func(t *testing.T, issues map[uint]entities.Issue) {
+				if issues[1].Priority != "high" {
+					t.Errorf("priority = %s, want high", issues[1].Priority)
+				}
+			},
+		},
+		{
+			name:   "transactional rolls back",
+			body:   `{"operation":"set_priority","issue_ids":[1,99,2],"priority":"high"}`,
+			status: 422, failed: 3,
+			check: func(t *testing.T, issues map[uint]entities.Issue) {
+				if issues[1].Priority != "medium" {
+					t.Errorf("priority = %s after rollback, want medium", issues[1].Priority)
+				}
+			},
+		},
+		{name: "unknown status", body: `{"operation":"change_status","issue_ids":[1],"status_id":9}`, status: 422},
+		{name: "unknown assignee", body: `{"operation":"assign","issue_ids":[1],"assignee_id":9}`, status: 422},
+		{name: "bad priority", body: `{"operation":"set_priority","issue_ids":[1],"priority":"urgent"}`, status: 400},
+		{name: "unknown operation", body: `{"operation":"delete","issue_ids":[1]}`, status: 400},
+		{name: "bad mode", body: `{"operation":"close","issue_ids":[1],"mode":"sometimes"}`, status: 400},
+		{name: "both selections", body: `{"operation":"close","issue_ids":[1],"filter":"status=open"}`, status: 400},
+		{name: "bad filter", body: `{"operation":"close","filter":"priority=urgent"}`, status: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := seedBulkStore(t)
+			w, result := bulkRequest(t, store, tt.body)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if result.Succeeded != tt.succeeded || result.Failed != tt.failed {
+				t.Errorf("succeeded %d failed %d, want %d and %d: %s", result.Succeeded, result.Failed, tt.succeeded, tt.failed, w.Body)
+			}
+			if tt.check == nil {
+				return
+			}
+			list, err := store.Issues().Find(context.Background(), repositories.IssueFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			issues := map[uint]entities.Issue{}
+			for _, issue := range list {
+				issues[issue.IssueID] = issue
+			}
+			tt.check(t, issues)
+		})
+	}
+}
+
+func TestBulkUpdateIssuesLimit(t *testing.T) {
+	ids := make([]string, 501)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i + 1)
+	}
+	w, _ := bulkRequest(t, seedBulkStore(t), `{"operation":"close","issue_ids":[`+strings.Join(ids, ",")+`]}`)
+	if w.Code != 400 || !strings.Contains(w.Body.String(), "Too many issues") {
+		t.Errorf("501 issues = %d: %s", w.Code, w.Body)
+	}
+}
+
+// txOnlyLabels fails label lookups that are not made in a transaction
+type txOnlyLabels struct {
+	repositories.Store
+}
+
+func (s txOnlyLabels) Labels() repositories.LabelRepository {
+	return outsideTxLabels{}
+}
+
+type outsideTxLabels struct {
+	repositories.LabelRepository
+}
+
+func (outsideTxLabels) FindOrCreate(ctx context.Context, label *entities.Label) error {
+	return errors.New("label resolved outside the transaction")
+}
+
+func TestBulkAddLabelInTransaction(t *testing.T) {
+	store := seedBulkStore(t)
+	wrapped := txOnlyLabels{store}
+	w, result := serviceBulkRequest(t, wrapped, services.NewIssueService(wrapped), `{"operation":"add_label","issue_ids":[1,2],"label":"network"}`)
+	if w.Code != 200 || result.Succeeded != 2 {
+		t.Fatalf("add label = %d: %s", w.Code, w.Body)
+	}
+	labels, err := store.Labels().ListByIssues(context.Background(), []uint{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels[1]) != 1 || labels[1][0].Name != "network" || len(labels[2]) != 1 || len(labels[3]) != 0 {
+		t.Errorf("labels = %v", labels)
+	}
+}
+
+// brokenStatuses fails every status lookup
+type brokenStatuses struct {
+	repositories.StatusRepository
+}
+
+func (brokenStatuses) Get(ctx context.Context, id uint) (*entities.IssueStatus, error) {
+	return nil, errors.New("connection refused")
+}
+
+type brokenStatusStore struct {
+	repositories.Store
+}
+
+func (s brokenStatusStore) Statuses() repositories.StatusRepository {
+	return brokenStatuses{}
+}
+
+func TestBulkUpdateIssuesStatusLookupFailure(t *testing.T) {
+	store := brokenStatusStore{seedBulkStore(t)}
+	w, _ := bulkRequest(t, store, `{"operation":"change_status","issue_ids":[1],"status_id":2}`)
+	if w.Code != 500 {
+		t.Errorf("status lookup failure = %d, want 500: %s", w.Code, w.Body)
+	}
+}
+
+func TestBulkUpdateIssuesRecordsHistory(t *testing.T) {
+	store := seedBulkStore(t)
+	if w, _ := bulkRequest(t, store, `{"operation":"close","issue_ids":[1,2],"comment":"Duplicates"}`); w.Code != 200 {
+		t.Fatalf("bulk close = %d: %s", w.Code, w.Body)
+	}
+	history, err := store.Issues().HistoryByIssues(context.Background(), []uint{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	closes := 0
+	for _, h := range history {
+		if h.NewStatusID == 3 && h.Comment == "Duplicates" {
+			closes++
+		}
+	}
+	if closes != 2 {
+		t.Errorf("got %d close history entries, want 2: %+v", closes, history)
+	}
+}
+
+func TestBulkUpdateIssuesPublishesEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want services.EventType
+		ids  []uint
+	}{
+		{"status change", `{"operation":"close","issue_ids":[1,2]}`, services.EventIssueStatusChanged, []uint{1, 2}},
+		{"other change", `{"operation":"set_priority","issue_ids":[3],"priority":"low"}`, services.EventIssueUpdated, []uint{3}},
+		{"best effort failure", `{"operation":"set_priority","issue_ids":[99,2],"priority":"low","mode":"best_effort"}`, services.EventIssueUpdated, []uint{2}},
+		{"rolled back", `{"operation":"set_priority","issue_ids":[1,99],"priority":"low"}`, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := seedBulkStore(t)
+			issues := services.NewIssueService(store)
+			events, stop := issues.Events().Subscribe()
+			defer stop()
+
+			serviceBulkRequest(t, store, issues, tt.body)
+			stop()
+			var ids []uint
+			for event := range events {
+				if event.Type != tt.want || event.Issue == nil {
+					t.Errorf("event = %s for issue %v, want %s", event.Type, event.Issue, tt.want)
+				}
+				ids = append(ids, event.IssueID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.ids) {
+				t.Errorf("events for issues %v, want %v", ids, tt.ids)
+			}
+		})
+	}
+}
@@ -1,32 +1,42 @@
 package controllers
 
 import (
+	"errors"
 	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/services"
 	"issue-tracking/utils"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
+// CommentRequest is the body of POST /api/issues/:id/comment
+type CommentRequest struct {
+	UserID  uint   `json:"user_id" binding:"required"`
+	Content string `json:"content" binding:"required"`
+}
+
 type CommentController struct {
-	db *gorm.DB
+	store  repositories.Store
+	issues *services.IssueService
 }
 
 // NewCommentController creates a new comment controller
-func NewCommentController(db *gorm.DB) *CommentController {
-	return &CommentController{db: db}
+func NewCommentController(store repositories.Store, issues *services.IssueService) *CommentController {
+	return &CommentController{store: store, issues: issues}
 }
 
 // GetCommentsByIssue retrieves all comments for an issue
 func (cc *CommentController) GetCommentsByIssue(c *gin.Context) {
-	issueID := c.Param("issue_id")
-	var comments []entities.Comment
-	if err := cc.db.
-		Where("issue_id = ?", issueID).
-		Preload("User").
-		Order("created_at DESC").
-		Find(&comments).Error; err != nil {
+	issueID, err := strconv.ParseUint(c.Param("issue_id"), 10, 32)
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid issue ID", "issue_id must be a positive integer")
+		return
+	}
+
+	comments, err := cc.store.Comments().ListByIssue(c.Request.Context(), uint(issueID))
+	if err != nil {
 		utils.RespondError(c, 500, "Failed to fetch comments", nil)
 		return
 	}
@@ -45,65 +55,31 @@ func (cc *CommentController) CreateComment(c *gin.Context) {
 		return
 	}
 
-	type CommentRequest struct {
-		UserID  uint   `json:"user_id" binding:"required"`
-		Content string `json:"content" binding:"required"`
-	}
-
 	var req CommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.RespondError(c, 400, "Invalid request body", err.Error())
 		return
 	}
 
-	// Validate UserID
-	var user entities.User
-	if err := cc.db.First(&user, req.UserID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.RespondError(c, 400, "User not found", "invalid user_id")
-			return
-		}
-		utils.RespondError(c, 500, "Failed to validate user", nil)
-		return
-	}
-
-	comment := entities.Comment{
-		IssueID: uint(issueID),
-		UserID:  req.UserID,
-		Content: req.Content,
-	}
-
-	// Validate the comment
-	if validationErrors := utils.ValidateStruct(comment); len(validationErrors) > 0 {
-		utils.RespondValidationError(c, validationErrors)
-		return
-	}
-
-	if err := cc.db.Create(&comment).Error; err != nil {
-		utils.RespondError(c, 500, "Failed to create comment", err.Error())
-		return
-	}
-
-	// Preload user info
-	if err := cc.db.Preload("User").First(&comment).Error; err != nil {
-		utils.RespondError(c, 500, "Failed to fetch created comment", nil)
-		return
-	}
-
-	if err := cc.db.Preload("Issue").First(&comment).Error; err != nil {
-		utils.RespondError(c, 500, "Failed to fetch created comment", nil)
+	created, err := cc.issues.Comment(c.Request.Context(), uint(issueID), req.UserID, req.Content)
+	if err != nil {
+		respondServiceError(c, err)
 		return
 	}
-
-	utils.RespondSuccess(c, 201, comment)
+	utils.RespondSuccess(c, 201, created)
 }
 
 // GetComment retrieves a single comment by ID
 func (cc *CommentController) GetComment(c *gin.Context) {
-	id := c.Param("id")
-	var comment entities.Comment
-	if err := cc.db.Preload("User").Preload("Issue").First(&comment, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid comment ID", "comment_id must be a positive integer")
+		return
+	}
+
+	comment, err := cc.store.Comments().Get(c.Request.Context(), uint(commentID))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
 			utils.RespondError(c, 404, "Comment not found", nil)
 			return
 		}
@@ -115,10 +91,16 @@ func (cc *CommentController) GetComment(c *gin.Context) {
 
 // UpdateComment updates an existing comment
 func (cc *CommentController) UpdateComment(c *gin.Context) {
-	id := c.Param("id")
-	var comment entities.Comment
-	if err := cc.db.First(&comment, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	ctx := c.Request.Context()
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid comment ID", "comment_id must be a positive integer")
+		return
+	}
+
+	comment, err := cc.store.Comments().Get(ctx, uint(commentID))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
 			utils.RespondError(c, 404, "Comment not found", nil)
 			return
 		}
@@ -126,17 +108,17 @@ func (cc *CommentController) UpdateComment(c *gin.Context) {
 		return
 	}
 
-	if err := c.ShouldBindJSON(&comment); err != nil {
+	if err := c.ShouldBindJSON(comment); err != nil {
 		utils.RespondError(c, 400, "Invalid request body", err.Error())
 		return
 	}
 
-	if validationErrors := utils.ValidateStruct(comment); len(validationErrors) > 0 {
+	if validationErrors := utils.ValidateStruct(*comment); len(validationErrors) > 0 {
 		utils.RespondValidationError(c, validationErrors)
 		return
 	}
 
-	if err := cc.db.Save(&comment).Error; err != nil {
+	if err := cc.store.Comments().Update(ctx, comment); err != nil {
 		utils.RespondError(c, 500, "Failed to update comment", err.Error())
 		return
 	}
@@ -145,8 +127,13 @@ func (cc *CommentController) UpdateComment(c *gin.Context) {
 
 // DeleteComment deletes a comment
 func (cc *CommentController) DeleteComment(c *gin.Context) {
-	id := c.Param("id")
-	if err := cc.db.Delete(&entities.Comment{}, id).Error; err != nil {
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid comment ID", "comment_id must be a positive integer")
+		return
+	}
+
+	if err := cc.store.Comments().Delete(c.Request.Context(), uint(commentID)); err != nil {
 		utils.RespondError(c, 500, "Failed to delete comment", err.Error())
 		return
 	}
@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/utils"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// exportBatchSize is how many issues are loaded from the database at a time
+const exportBatchSize = 1000
+
+// utf8BOM makes Excel open CSV files as UTF-8 so Thai text is not garbled
+const utf8BOM = "\xEF\xBB\xBF"
+
+// exportTimeLayout is used for date columns in both CSV and XLSX exports
+const exportTimeLayout = "2006-01-02 15:04:05"
+
+// exportCell escapes text that a spreadsheet would run as a formula:
+// values starting with =, +, -, @, a tab or a carriage return get a leading
+// quote, so an issue title cannot inject =HYPERLINK(...) into an export.
+// Other values are returned unchanged.
+func exportCell(value interface{}) interface{} {
+	text, ok := value.(string)
+	if !ok || text == "" {
+		return value
+	}
+	switch text[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + text
+	}
+	return text
+}
+
+// exportColumn describes one selectable column of an issue export
+type exportColumn struct {
+	Header string
+	Value  func(issue *entities.Issue) interface{}
+}
+
+// exportColumns lists every column that can be requested with ?columns=
+var exportColumns = map[string]exportColumn{
+	"issue_id":    {"Issue ID", func(i *entities.Issue) interface{} { return i.IssueID }},
+	"title":       {"Title", func(i *entities.Issue) interface{} { return i.Title }},
+	"description": {"Description", func(i *entities.Issue) interface{} { return i.Description }},
+	"priority":    {"Priority", func(i *entities.Issue) interface{} { return i.Priority }},
+	"status":      {"Status", func(i *entities.Issue) interface{} { return i.Status.DisplayName }},
+	"status_code": {"Status Code", func(i *entities.Issue) interface{} { return i.Status.StatusCode }},
+	"reporter_id": {"Reporter ID", func(i *entities.Issue) interface{} { return i.ReporterID }},
+	"reporter":    {"Reporter", func(i *entities.Issue) interface{} { return i.Reporter.FullName }},
+	"assignee_id": {"Assignee ID", func(i *entities.Issue) interface{} {
+		if i.AssigneeID == nil {
+			return ""
+		}
+		return *i.AssigneeID
+	}},
+	"assignee": {"Assignee", func(i *entities.Issue) interface{} {
+		if i.Assignee == nil {
+			return ""
+		}
+		return i.Assignee.FullName
+	}},
+	"created_at": {"Created At", func(i *entities.Issue) interface{} { return i.CreatedAt.Format(exportTimeLayout) }},
+	"updated_at": {"Updated At", func(i *entities.Issue) interface{} { return i.UpdatedAt.Format(exportTimeLayout) }},
+}
+
+// defaultExportColumns is used when ?columns= is not provided
+var defaultExportColumns = []string{
+	"issue_id", "title", "priority", "status", "reporter", "assignee", "created_at", "updated_at",
+}
+
+type ExportController struct {
+	store repositories.Store
+}
+
+// NewExportController creates a new export controller
+func NewExportController(store repositories.Store) *ExportController {
+	return &ExportController{store: store}
+}
+
+// ExportIssues streams the issue list as CSV or XLSX. It accepts the same
+// filters as GetAllIssues plus format (csv or xlsx), columns (comma separated)
+// and bom (set to false to omit the UTF-8 BOM from CSV output).
+func (ec *ExportController) ExportIssues(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		utils.RespondError(c, 400, "Invalid format", "format must be one of: csv xlsx")
+		return
+	}
+
+	columns, err := parseExportColumns(c.Query("columns"))
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid columns", err.Error())
+		return
+	}
+
+	filter, err := parseIssueFilter(c.Request.URL.Query())
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid filter", err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("issues-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == "csv" {
+		ec.writeCSV(c, filter, columns)
+		return
+	}
+	ec.writeXLSX(c, filter, columns)
+}
+
+// writeCSV writes rows to the response as each batch is loaded
+func (ec *ExportController) writeCSV(c *gin.Context, filter repositories.IssueFilter, columns []exportColumn) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(200)
+
+	if c.DefaultQuery("bom", "true") != "false" {
+		c.Writer.WriteString(utf8BOM)
+	}
+
+	w := csv.NewWriter(c.Writer)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Header
+	}
+	w.Write(header)
+
+	err := ec.store.Issues().Batches(c.Request.Context(), filter, exportBatchSize, func(batch []entities.Issue) error {
+		row := make([]string, len(columns))
+		for i := range batch {
+			for j, col := range columns {
+				row[j] = fmt.Sprint(exportCell(col.Value(&batch[i])))
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		c.Writer.Flush()
+		return w.Error()
+	})
+	w.Flush()
+
+	// Headers are already sent, so the connection is dropped instead of
+	// ending the response; clients see an error rather than a file that
+	// looks complete
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "issue CSV export aborted", slog.Any("error", err))
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// writeXLSX uses excelize's stream writer, which spills rows to a temporary
+// file instead of holding the whole sheet in memory
+func (ec *ExportController) writeXLSX(c *gin.Context, filter repositories.IssueFilter, columns []exportColumn) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	const sheet = "Issues"
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		utils.RespondError(c, 500, "Failed to create spreadsheet", nil)
+		return
+	}
+
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		utils.RespondError(c, 500, "Failed to create spreadsheet", nil)
+		return
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, col := range columns {
+		header[i] = col.Header
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		utils.RespondError(c, 500, "Failed to create spreadsheet", nil)
+		return
+	}
+
+	rowNum := 2
+	err = ec.store.Issues().Batches(c.Request.Context(), filter, exportBatchSize, func(batch []entities.Issue) error {
+		for i := range batch {
+			row := make([]interface{}, len(columns))
+			for j, col := range columns {
+				row[j] = exportCell(col.Value(&batch[i]))
+			}
+			cell, err := excelize.CoordinatesToCellName(1, rowNum)
+			if err != nil {
+				return err
+			}
+			if err := sw.SetRow(cell, row); err != nil {
+				return err
+			}
+			rowNum++
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondError(c, 500, "Failed to export issues", nil)
+		return
+	}
+
+	if err := sw.Flush(); err != nil {
+		utils.RespondError(c, 500, "Failed to export issues", nil)
+		return
+	}
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Status(200)
+	if err := f.Write(c.Writer); err != nil {
+		slog.ErrorContext(c.Request.Context(), "issue XLSX export aborted", slog.Any("error", err))
+	}
+}
+
+// parseExportColumns resolves a comma separated column list
+func parseExportColumns(value string) ([]exportColumn, error) {
+	keys := defaultExportColumns
+	if value != "" {
+		keys = strings.Split(value, ",")
+	}
+
+	columns := make([]exportColumn, 0, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		col, ok := exportColumns[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %s", strconv.Quote(key))
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
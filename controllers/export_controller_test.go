@@ -0,0 +1,187 @@
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"issue-tracking/controllers"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+func exportRouter(store repositories.Store) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(utils.RecoverPanic())
+	router.GET("/api/issues/export", controllers.NewExportController(store).ExportIssues)
+	return router
+}
+
+func TestExportIssuesCSV(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  [][]string
+		bom   bool
+	}{
+		{
+			name:  "selected columns",
+			query: "?columns=issue_id,title,status_code",
+			want:  [][]string{{"Issue ID", "Title", "Status Code"}, {"1", "Issue 1", "open"}, {"2", "Issue 2", "open"}, {"3", "Issue 3", "open"}},
+			bom:   true,
+		},
+		{
+			name:  "filtered without BOM",
+			query: "?columns=issue_id,assignee&priority=medium&assignee_id=1&bom=false",
+			want:  [][]string{{"Issue ID", "Assignee"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			exportRouter(seedBulkStore(t)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/issues/export"+tt.query, nil))
+			if w.Code != 200 || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+				t.Fatalf("export = %d %s: %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+			}
+			body := w.Body.String()
+			if got := strings.HasPrefix(body, "\xEF\xBB\xBF"); got != tt.bom {
+				t.Errorf("BOM = %v, want %v", got, tt.bom)
+			}
+			rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(body, "\xEF\xBB\xBF"))).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("rows = %v, want %v", rows, tt.want)
+			}
+			for i := range rows {
+				if strings.Join(rows[i], ",") != strings.Join(tt.want[i], ",") {
+					t.Errorf("row %d = %v, want %v", i, rows[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExportIssuesXLSX(t *testing.T) {
+	w := httptest.NewRecorder()
+	exportRouter(seedBulkStore(t)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/issues/export?format=xlsx&columns=title,priority", nil))
+	if w.Code != 200 || !strings.Contains(w.Header().Get("Content-Disposition"), ".xlsx") {
+		t.Fatalf("export = %d %v", w.Code, w.Header())
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows("Issues")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[0][0] != "Title" || rows[3][0] != "Issue 3" || rows[3][1] != "medium" {
+		t.Errorf("rows = %v", rows)
+	}
+}
+
+func TestExportIssuesEscapesFormulas(t *testing.T) {
+	store := seedBulkStore(t)
+	ctx := context.Background()
+	for _, title := range []string{`=HYPERLINK("http://evil.example","Click")`, "-1+2", "Printer on floor -1"} {
+		issue := entities.Issue{ReporterID: 1, StatusID: 1, Title: title, Priority: "low"}
+		if err := store.Issues().Create(ctx, &issue); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{`'=HYPERLINK("http://evil.example","Click")`, "'-1+2", "Printer on floor -1"}
+
+	w := httptest.NewRecorder()
+	exportRouter(store).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/issues/export?columns=issue_id,title&priority=low&bom=false", nil))
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("CSV rows = %v", rows)
+	}
+	for i, title := range want {
+		if rows[i+1][0] != fmt.Sprint(i+4) || rows[i+1][1] != title {
+			t.Errorf("CSV row %d = %v, want title %q", i+1, rows[i+1], title)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	exportRouter(store).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/issues/export?format=xlsx&columns=title&priority=low", nil))
+	f, err := excelize.OpenReader(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	xlsxRows, err := f.GetRows("Issues")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(xlsxRows) != 4 {
+		t.Fatalf("XLSX rows = %v", xlsxRows)
+	}
+	for i, title := range want {
+		if xlsxRows[i+1][0] != title {
+			t.Errorf("XLSX row %d = %v, want %q", i+1, xlsxRows[i+1], title)
+		}
+	}
+}
+
+func TestExportIssuesRejectsBadParameters(t *testing.T) {
+	for _, query := range []string{"?format=pdf", "?columns=title,secret", "?priority=urgent"} {
+		w := httptest.NewRecorder()
+		exportRouter(seedBulkStore(t)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/issues/export"+query, nil))
+		if w.Code != 400 {
+			t.Errorf("export%s = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+// brokenStore fails while exporting after the first batch
+type brokenStore struct{ repositories.Store }
+
+func (s brokenStore) Issues() repositories.IssueRepository {
+	return brokenIssues{s.Store.Issues()}
+}
+
+type brokenIssues struct{ repositories.IssueRepository }
+
+func (r brokenIssues) Batches(ctx context.Context, filter repositories.IssueFilter, size int, fn func([]entities.Issue) error) error {
+	err := r.IssueRepository.Batches(ctx, filter, size, fn)
+	if err != nil {
+		return err
+	}
+	return errors.New("connection lost")
+}
+
+// TestExportIssuesCSVAbortsOnFailure checks a CSV export that fails after
+// the headers were sent does not end like a complete file
+func TestExportIssuesCSVAbortsOnFailure(t *testing.T) {
+	server := httptest.NewServer(exportRouter(brokenStore{seedBulkStore(t)}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/issues/export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err == nil {
+		t.Errorf("export read without error: %q", body)
+	}
+}
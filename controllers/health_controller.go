@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"issue-tracking/migrations"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// healthCheckTimeout bounds each readiness check so a hung database makes
+// the probe fail instead of time out
+const healthCheckTimeout = 2 * time.Second
+
+// healthCheck reports whether one dependency is usable
+type healthCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// HealthResponse is the body of the probes
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type HealthController struct {
+	checks   []healthCheck
+	draining atomic.Bool
+}
+
+// NewHealthController creates a health controller that is ready when the
+// database answers and every migration has been applied
+func NewHealthController(db *gorm.DB) *HealthController {
+	sqlDB, err := db.DB()
+	if err != nil {
+		failing := func(context.Context) error { return err }
+		return &HealthController{checks: []healthCheck{{"database", failing}}}
+	}
+	migrator, err := migrations.New(sqlDB, db.Dialector.Name())
+	if err != nil {
+		failing := func(context.Context) error { return err }
+		return &HealthController{checks: []healthCheck{{"migrations", failing}}}
+	}
+
+	return &HealthController{checks: []healthCheck{
+		{"database", sqlDB.PingContext},
+		{"migrations", func(ctx context.Context) error {
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if pending > 0 {
+				return fmt.Errorf("%d pending", pending)
+			}
+			return nil
+		}},
+	}}
+}
+
+// StartDraining makes readiness fail so load balancers stop sending new
+// requests while the server shuts down
+func (hc *HealthController) StartDraining() {
+	hc.draining.Store(true)
+}
+
+// Livez reports that the process is running and able to serve requests
+func (hc *HealthController) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Readyz runs every readiness check and responds 503 when any fails or the
+// server is shutting down
+func (hc *HealthController) Readyz(c *gin.Context) {
+	results := map[string]string{}
+	ready := true
+	if hc.draining.Load() {
+		results["shutdown"] = "draining"
+		ready = false
+	}
+
+	for _, check := range hc.checks {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+		err := check.run(ctx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s", healthCheckTimeout)
+			}
+			results[check.name] = err.Error()
+			ready = false
+			continue
+		}
+		results[check.name] = "ok"
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: "unavailable", Checks: results})
+		return
+	}
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok", Checks: results})
+}
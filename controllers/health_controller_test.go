@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+	hung := func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }
+
+	tests := []struct {
+		name     string
+		checks   []healthCheck
+		draining bool
+		want     int
+		results  map[string]string
+	}{
+		{"ready", []healthCheck{{"database", ok}, {"migrations", ok}}, false, 200,
+			map[string]string{"database": "ok", "migrations": "ok"}},
+		{"database down", []healthCheck{{"database", down}, {"migrations", ok}}, false, 503,
+			map[string]string{"database": "connection refused", "migrations": "ok"}},
+		{"check times out", []healthCheck{{"database", hung}}, false, 503,
+			map[string]string{"database": "timed out after 2s"}},
+		{"draining", []healthCheck{{"database", ok}}, true, 503,
+			map[string]string{"database": "ok", "shutdown": "draining"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := &HealthController{checks: tt.checks}
+			if tt.draining {
+				hc.StartDraining()
+			}
+			router := gin.New()
+			router.GET("/readyz", hc.Readyz)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			var body struct {
+				Checks map[string]string `json:"checks"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.results {
+				if body.Checks[name] != want {
+					t.Errorf("%s = %q, want %q", name, body.Checks[name], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"issue-tracking/importer"
+	"issue-tracking/services"
+	"issue-tracking/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImportController struct {
+	issues *services.IssueService
+}
+
+// NewImportController creates a new import controller
+func NewImportController(issues *services.IssueService) *ImportController {
+	return &ImportController{issues: issues}
+}
+
+// ImportIssues imports issues from a CSV or JSON lines file sent either as the
+// raw request body or as the "file" field of a multipart form
+func (ic *ImportController) ImportIssues(c *gin.Context) {
+	mapping, err := importer.ParseMapping(c.Query("mapping"))
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid mapping", err.Error())
+		return
+	}
+
+	batchSize := 0
+	if value := c.Query("batch_size"); value != "" {
+		batchSize, err = strconv.Atoi(value)
+		if err != nil || batchSize <= 0 {
+			utils.RespondError(c, 400, "Invalid batch_size", "batch_size must be a positive integer")
+			return
+		}
+	}
+
+	opts := importer.Options{
+		Format:             c.DefaultQuery("format", importer.FormatCSV),
+		DryRun:             c.Query("dry_run") == "true",
+		CreateMissingUsers: c.Query("create_missing_users") == "true",
+		BatchSize:          batchSize,
+		Mapping:            mapping,
+	}
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			utils.RespondError(c, 400, "Invalid file", "multipart uploads must use the file field")
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			utils.RespondError(c, 400, "Invalid file", err.Error())
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	result, err := ic.issues.Import(c.Request.Context(), body, opts)
+	if err != nil {
+		var serviceErr *services.Error
+		if errors.As(err, &serviceErr) {
+			respondServiceError(c, err)
+			return
+		}
+		if errors.Is(err, importer.ErrMalformedInput) {
+			utils.RespondError(c, 400, "Invalid import file", err.Error())
+			return
+		}
+		// Batches committed before the failure stay imported
+		utils.RespondError(c, 500, "Import failed", result)
+		return
+	}
+
+	if result.DryRun {
+		utils.RespondSuccess(c, 200, result)
+		return
+	}
+	utils.RespondSuccess(c, 201, result)
+}
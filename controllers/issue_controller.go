@@ -1,45 +1,52 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/services"
 	"issue-tracking/utils"
+	"net/url"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
+// MaxIssuePageSize is the largest limit accepted by GET /api/issues
+const MaxIssuePageSize = 500
+
+// StatusUpdateRequest is the body of PATCH /api/issues/:id/status
+type StatusUpdateRequest struct {
+	NewStatusID uint   `json:"new_status_id" binding:"required"`
+	AssigneeID  *uint  `json:"assignee_id"`
+	Comment     string `json:"comment"`
+}
+
 type IssueController struct {
-	db *gorm.DB
+	store  repositories.Store
+	issues *services.IssueService
 }
 
 // NewIssueController creates a new issue controller
-func NewIssueController(db *gorm.DB) *IssueController {
-	return &IssueController{db: db}
+func NewIssueController(store repositories.Store, issues *services.IssueService) *IssueController {
+	return &IssueController{store: store, issues: issues}
 }
 
-// GetAllIssues retrieves all issues with optional status filter
+// GetAllIssues retrieves all issues with optional filters. With limit the
+// issues are returned a page at a time in ID order; after_id is the last ID
+// of the previous page.
 func (ic *IssueController) GetAllIssues(c *gin.Context) {
-	var issues []entities.Issue
-	query := ic.db
-
-	// Apply status filter if provided
-	if status := c.Query("status"); status != "" {
-		query = query.Where("status_id = (SELECT status_id FROM issue_statuses WHERE status_code = ?)", status)
-	}
-
-	if err := query.
-		Preload("Reporter").
-		Preload("Assignee").
-		Preload("Status").
-		Preload("Comments.User").
-		Find(&issues).Error; err != nil {
-		utils.RespondError(c, 500, "Failed to fetch issues", nil)
+	filter, err := ParseIssueQuery(c.Request.URL.Query())
+	if err != nil {
+		respondServiceError(c, err)
 		return
 	}
 
-	if issues == nil {
-		issues = []entities.Issue{}
+	issues, err := ic.issues.List(c.Request.Context(), filter)
+	if err != nil {
+		respondServiceError(c, err)
+		return
 	}
 	utils.RespondSuccess(c, 200, issues)
 }
@@ -53,19 +60,9 @@ func (ic *IssueController) GetIssue(c *gin.Context) {
 		return
 	}
 
-	var issue entities.Issue
-	if err := ic.db.
-		Preload("Reporter").
-		Preload("Assignee").
-		Preload("Status").
-		Preload("StatusHistory").
-		Preload("Comments").
-		First(&issue, issueID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.RespondError(c, 404, "Issue not found", nil)
-			return
-		}
-		utils.RespondError(c, 500, "Failed to fetch issue", nil)
+	issue, err := ic.issues.Get(c.Request.Context(), uint(issueID))
+	if err != nil {
+		respondServiceError(c, err)
 		return
 	}
 	utils.RespondSuccess(c, 200, issue)
@@ -79,65 +76,17 @@ func (ic *IssueController) CreateIssue(c *gin.Context) {
 		return
 	}
 
-	// Validate the issue
-	if validationErrors := utils.ValidateStruct(issue); len(validationErrors) > 0 {
-		utils.RespondValidationError(c, validationErrors)
-		return
-	}
-
-	// Validate reporter exists
-	var reporter entities.User
-	if err := ic.db.First(&reporter, issue.ReporterID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.RespondError(c, 400, "Reporter not found", "invalid reporter_id")
-			return
-		}
-		utils.RespondError(c, 500, "Failed to validate reporter", nil)
-		return
-	}
-
-	// Validate status exists
-	var status entities.IssueStatus
-	if err := ic.db.First(&status, issue.StatusID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.RespondError(c, 400, "Status not found", "invalid status_id")
-			return
-		}
-		utils.RespondError(c, 500, "Failed to validate status", nil)
-		return
-	}
-
-	// Validate assignee if provided
-	if issue.AssigneeID != nil {
-		var assignee entities.Officer
-		if err := ic.db.First(&assignee, *issue.AssigneeID).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				utils.RespondError(c, 400, "Assignee not found", "invalid assignee_id")
-				return
-			}
-			utils.RespondError(c, 500, "Failed to validate assignee", nil)
-			return
-		}
-	}
-
-	if err := ic.db.Create(&issue).Error; err != nil {
-		utils.RespondError(c, 500, "Failed to create issue", err.Error())
+	created, err := ic.issues.Create(c.Request.Context(), issue)
+	if err != nil {
+		respondServiceError(c, err)
 		return
 	}
-
-	// Reload with relations
-	ic.db.
-		Preload("Reporter").
-		Preload("Assignee").
-		Preload("Status").
-		Preload("Comments").
-		First(&issue, issue.IssueID)
-
-	utils.RespondSuccess(c, 201, issue)
+	utils.RespondSuccess(c, 201, created)
 }
 
 // UpdateIssue updates an existing issue
 func (ic *IssueController) UpdateIssue(c *gin.Context) {
+	ctx := c.Request.Context()
 	id := c.Param("id")
 	issueID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
@@ -145,9 +94,9 @@ func (ic *IssueController) UpdateIssue(c *gin.Context) {
 		return
 	}
 
-	var issue entities.Issue
-	if err := ic.db.First(&issue, issueID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	issue, err := ic.store.Issues().Get(ctx, uint(issueID))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
 			utils.RespondError(c, 404, "Issue not found", nil)
 			return
 		}
@@ -155,31 +104,17 @@ func (ic *IssueController) UpdateIssue(c *gin.Context) {
 		return
 	}
 
-	if err := c.ShouldBindJSON(&issue); err != nil {
+	if err := c.ShouldBindJSON(issue); err != nil {
 		utils.RespondError(c, 400, "Invalid request body", err.Error())
 		return
 	}
 
-	// Validate the updated issue
-	if validationErrors := utils.ValidateStruct(issue); len(validationErrors) > 0 {
-		utils.RespondValidationError(c, validationErrors)
-		return
-	}
-
-	if err := ic.db.Save(&issue).Error; err != nil {
-		utils.RespondError(c, 500, "Failed to update issue", err.Error())
+	updated, err := ic.issues.Update(ctx, *issue)
+	if err != nil {
+		respondServiceError(c, err)
 		return
 	}
-
-	// Reload with relations
-	ic.db.
-		Preload("Reporter").
-		Preload("Assignee").
-		Preload("Status").
-		Preload("Comments").
-		First(&issue, issueID)
-
-	utils.RespondSuccess(c, 200, issue)
+	utils.RespondSuccess(c, 200, updated)
 }
 
 // UpdateIssueStatus updates only the status of an issue
@@ -191,91 +126,118 @@ func (ic *IssueController) UpdateIssueStatus(c *gin.Context) {
 		return
 	}
 
-	type StatusUpdate struct {
-		NewStatusID uint   `json:"new_status_id" binding:"required"`
-		AssigneeID  uint   `json:"assignee_id"`
-		Comment     string `json:"comment"`
-	}
-
-	var req StatusUpdate
+	var req StatusUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.RespondError(c, 400, "Invalid request body", err.Error())
 		return
 	}
 
-	// Get the current issue
-	var issue entities.Issue
-	if err := ic.db.First(&issue, issueID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.RespondError(c, 404, "Issue not found", nil)
-			return
-		}
-		utils.RespondError(c, 500, "Failed to fetch issue", nil)
+	issue, err := ic.issues.ChangeStatus(c.Request.Context(), uint(issueID), services.StatusChange{
+		NewStatusID: req.NewStatusID,
+		AssigneeID:  req.AssigneeID,
+		Comment:     req.Comment,
+		ChangedBy:   1, // Default to officer ID 1, should be from auth context in production
+	})
+	if err != nil {
+		respondServiceError(c, err)
 		return
 	}
+	utils.RespondSuccess(c, 200, issue)
+}
 
-	// Validate new status exists
-	var status entities.IssueStatus
-	if err := ic.db.First(&status, req.NewStatusID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.RespondError(c, 400, "Invalid status", "status_id does not exist")
-			return
-		}
-		utils.RespondError(c, 500, "Failed to validate status", nil)
+// DeleteIssue deletes an issue by ID
+func (ic *IssueController) DeleteIssue(c *gin.Context) {
+	id := c.Param("id")
+	issueID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid issue ID", "issue_id must be a positive integer")
 		return
 	}
 
-	oldStatusID := issue.StatusID
-
-	// Update the issue status
-	if err := ic.db.Model(&issue).Updates(map[string]interface{}{
-		"status_id":   req.NewStatusID,
-		"assignee_id": req.AssigneeID,
-	}).Error; err != nil {
-		utils.RespondError(c, 500, "Failed to update status", err.Error())
+	if err := ic.issues.Delete(c.Request.Context(), uint(issueID)); err != nil {
+		respondServiceError(c, err)
 		return
 	}
+	c.JSON(204, nil)
+}
 
-	// Record the status history
-	history := entities.IssueStatusHistory{
-		IssueID:     uint(issueID),
-		OldStatusID: &oldStatusID,
-		NewStatusID: req.NewStatusID,
-		ChangedBy:   1, // Default to officer ID 1, should be from auth context in production
-		Comment:     req.Comment,
+// ParseIssueQuery reads the filter and page of GET /api/issues. Other APIs
+// listing issues pass their parameters as query values to get the same
+// checks and errors.
+func ParseIssueQuery(values url.Values) (repositories.IssueFilter, error) {
+	filter, err := parseIssueFilter(values)
+	if err != nil {
+		return filter, &services.Error{Status: 400, Message: "Invalid filter", Details: err.Error()}
 	}
+	if err := parseIssuePage(values, &filter); err != nil {
+		return filter, &services.Error{Status: 400, Message: "Invalid page", Details: err.Error()}
+	}
+	return filter, nil
+}
 
-	if err := ic.db.Create(&history).Error; err != nil {
-		utils.RespondError(c, 500, "Failed to record status history", err.Error())
-		return
+// parseIssueFilter reads the issue list query parameters: status (status
+// code), priority, assignee_id, reporter_id and label (name)
+func parseIssueFilter(values url.Values) (repositories.IssueFilter, error) {
+	filter := repositories.IssueFilter{
+		StatusCode: values.Get("status"),
+		Label:      values.Get("label"),
 	}
 
-	// Return updated issue with relations
-	if err := ic.db.
-		Preload("Reporter").
-		Preload("Assignee").
-		Preload("Status").
-		Preload("Comments").
-		First(&issue, issueID).Error; err != nil {
-		utils.RespondError(c, 500, "Failed to fetch updated issue", nil)
-		return
+	if priority := values.Get("priority"); priority != "" {
+		switch priority {
+		case "low", "medium", "high", "critical":
+			filter.Priority = priority
+		default:
+			return filter, fmt.Errorf("priority must be one of: low medium high critical")
+		}
 	}
 
-	utils.RespondSuccess(c, 200, issue)
+	if assignee := values.Get("assignee_id"); assignee != "" {
+		assigneeID, err := strconv.ParseUint(assignee, 10, 32)
+		if err != nil {
+			return filter, fmt.Errorf("assignee_id must be a positive integer")
+		}
+		id := uint(assigneeID)
+		filter.AssigneeID = &id
+	}
+
+	if reporter := values.Get("reporter_id"); reporter != "" {
+		reporterID, err := strconv.ParseUint(reporter, 10, 32)
+		if err != nil {
+			return filter, fmt.Errorf("reporter_id must be a positive integer")
+		}
+		id := uint(reporterID)
+		filter.ReporterID = &id
+	}
+
+	return filter, nil
 }
 
-// DeleteIssue deletes an issue by ID
-func (ic *IssueController) DeleteIssue(c *gin.Context) {
-	id := c.Param("id")
-	issueID, err := strconv.ParseUint(id, 10, 32)
-	if err != nil {
-		utils.RespondError(c, 400, "Invalid issue ID", "issue_id must be a positive integer")
-		return
+// parseIssuePage reads the limit and after_id of an issue list page
+func parseIssuePage(values url.Values, filter *repositories.IssueFilter) error {
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxIssuePageSize {
+			return fmt.Errorf("limit must be between 1 and %d", MaxIssuePageSize)
+		}
+		filter.Limit = limit
+	}
+	if value := values.Get("after_id"); value != "" {
+		afterID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("after_id must be a positive integer")
+		}
+		filter.AfterID = uint(afterID)
 	}
+	return nil
+}
 
-	if err := ic.db.Delete(&entities.Issue{}, issueID).Error; err != nil {
-		utils.RespondError(c, 500, "Failed to delete issue", err.Error())
+// respondServiceError responds with an error returned by the service layer
+func respondServiceError(c *gin.Context, err error) {
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) {
+		utils.RespondError(c, serviceErr.Status, serviceErr.Message, serviceErr.Details)
 		return
 	}
-	c.JSON(204, nil)
+	utils.RespondError(c, 500, "Internal server error", err.Error())
 }
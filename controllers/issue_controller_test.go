@@ -0,0 +1,160 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"issue-tracking/controllers"
+	"issue-tracking/database/dbtest"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// seedIssue creates a reporter, a set of statuses and one issue using the first status
+func seedIssue(t *testing.T, db *gorm.DB, statusCount int) (entities.Issue, []entities.IssueStatus) {
+	t.Helper()
+
+	reporter := entities.User{FullName: "Concurrency Reporter"}
+	if err := db.Create(&reporter).Error; err != nil {
+		t.Fatalf("failed to create reporter: %v", err)
+	}
+
+	statuses := make([]entities.IssueStatus, statusCount)
+	for i := range statuses {
+		statuses[i] = entities.IssueStatus{
+			StatusCode:   fmt.Sprintf("%s-%d", t.Name(), i),
+			DisplayName:  fmt.Sprintf("Status %d", i),
+			Color:        "#000000",
+			DisplayOrder: i,
+			IsActive:     true,
+		}
+		if err := db.Create(&statuses[i]).Error; err != nil {
+			t.Fatalf("failed to create status: %v", err)
+		}
+	}
+
+	issue := entities.Issue{
+		ReporterID: reporter.UserID,
+		StatusID:   statuses[0].StatusID,
+		Title:      "Concurrent transitions",
+		Priority:   "high",
+	}
+	if err := db.Create(&issue).Error; err != nil {
+		t.Fatalf("failed to create issue: %v", err)
+	}
+
+	return issue, statuses
+}
+
+func TestUpdateIssueStatusConcurrentTransitions(t *testing.T) {
+	for _, driver := range dbtest.Drivers() {
+		t.Run(driver, func(t *testing.T) {
+			testConcurrentTransitions(t, dbtest.Open(t, driver))
+		})
+	}
+}
+
+func testConcurrentTransitions(t *testing.T, db *gorm.DB) {
+	gin.SetMode(gin.TestMode)
+
+	issue, statuses := seedIssue(t, db, 4)
+
+	store := repositories.NewGormStore(db)
+	router := gin.New()
+	router.PATCH("/api/issues/:id/status", controllers.NewIssueController(store, services.NewIssueService(store)).UpdateIssueStatus)
+
+	const workers = 50
+	var wg sync.WaitGroup
+	codes := make([]int, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			body, _ := json.Marshal(map[string]interface{}{
+				"new_status_id": statuses[i%len(statuses)].StatusID,
+				"comment":       fmt.Sprintf("transition %d", i),
+			})
+			req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/api/issues/%d/status", issue.IssueID), bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			codes[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("transition %d: expected status 200, got %d", i, code)
+		}
+	}
+
+	var history []entities.IssueStatusHistory
+	if err := db.Where("issue_id = ?", issue.IssueID).Order("history_id").Find(&history).Error; err != nil {
+		t.Fatalf("failed to load history: %v", err)
+	}
+	if len(history) != workers {
+		t.Fatalf("expected %d history entries, got %d", workers, len(history))
+	}
+
+	// Every entry must start from the status the previous entry moved to
+	expected := issue.StatusID
+	for _, h := range history {
+		if h.OldStatusID == nil || *h.OldStatusID != expected {
+			t.Fatalf("history %d: expected old_status_id %d, got %v", h.HistoryID, expected, h.OldStatusID)
+		}
+		expected = h.NewStatusID
+	}
+
+	var current entities.Issue
+	if err := db.First(&current, issue.IssueID).Error; err != nil {
+		t.Fatalf("failed to reload issue: %v", err)
+	}
+	if current.StatusID != expected {
+		t.Fatalf("expected issue status %d to match last history entry, got %d", expected, current.StatusID)
+	}
+}
+
+func TestUpdateIssueStatusNotFoundRollsBack(t *testing.T) {
+	for _, driver := range dbtest.Drivers() {
+		t.Run(driver, func(t *testing.T) {
+			testNotFoundRollsBack(t, dbtest.Open(t, driver))
+		})
+	}
+}
+
+func testNotFoundRollsBack(t *testing.T, db *gorm.DB) {
+	gin.SetMode(gin.TestMode)
+
+	_, statuses := seedIssue(t, db, 1)
+
+	store := repositories.NewGormStore(db)
+	router := gin.New()
+	router.PATCH("/api/issues/:id/status", controllers.NewIssueController(store, services.NewIssueService(store)).UpdateIssueStatus)
+
+	body, _ := json.Marshal(map[string]interface{}{"new_status_id": statuses[0].StatusID})
+	req := httptest.NewRequest(http.MethodPatch, "/api/issues/4294967295/status", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+
+	var count int64
+	db.Model(&entities.IssueStatusHistory{}).Where("issue_id = ?", 4294967295).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected no history for missing issue, got %d", count)
+	}
+}
@@ -2,26 +2,25 @@ package controllers
 
 import (
 	"issue-tracking/entities"
+	"issue-tracking/repositories"
 	"issue-tracking/utils"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type OfficerController struct {
-	db *gorm.DB
+	store repositories.Store
 }
 
 // NewOfficerController creates a new officer controller
-func NewOfficerController(db *gorm.DB) *OfficerController {
-	return &OfficerController{db: db}
+func NewOfficerController(store repositories.Store) *OfficerController {
+	return &OfficerController{store: store}
 }
 
 // GetAllOfficers retrieves all officers
 func (oc *OfficerController) GetAllOfficers(c *gin.Context) {
-	var officers []entities.Officer
-
-	if err := oc.db.Find(&officers).Error; err != nil {
+	officers, err := oc.store.Officers().List(c.Request.Context())
+	if err != nil {
 		utils.RespondError(c, 500, "Failed to fetch officers", nil)
 		return
 	}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"issue-tracking/services"
+	"issue-tracking/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrackingTokenHeader carries the tracking token of a portal issue. It is
+// sent in a header rather than the path so it stays out of access logs.
+const TrackingTokenHeader = "X-Tracking-Token"
+
+// PortalReportRequest is the body of POST /api/public/issues. Website is a
+// field hidden from people that must stay empty.
+type PortalReportRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+	Captcha     string `json:"captcha"`
+	Website     string `json:"website"`
+}
+
+// PortalCommentRequest is the body of POST /api/public/tracking/comments
+type PortalCommentRequest struct {
+	Content string `json:"content" binding:"required"`
+	Captcha string `json:"captcha"`
+	Website string `json:"website"`
+}
+
+type PortalController struct {
+	portal *services.PortalService
+}
+
+// NewPortalController creates a new public portal controller
+func NewPortalController(portal *services.PortalService) *PortalController {
+	return &PortalController{portal: portal}
+}
+
+// Report creates an issue for someone without an account and returns its
+// tracking token
+func (pc *PortalController) Report(c *gin.Context) {
+	var req PortalReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "Invalid request body", err.Error())
+		return
+	}
+
+	tracked, err := pc.portal.Report(c.Request.Context(), services.PortalReport{
+		Submission:  submission(c, req.Captcha, req.Website),
+		Name:        req.Name,
+		Email:       req.Email,
+		Phone:       req.Phone,
+		Title:       req.Title,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, 201, tracked)
+}
+
+// Track returns the issue of the tracking token
+func (pc *PortalController) Track(c *gin.Context) {
+	issue, err := pc.portal.Track(c.Request.Context(), c.GetHeader(TrackingTokenHeader))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, 200, issue)
+}
+
+// Comment adds a comment by the reporter to the issue of the tracking token
+func (pc *PortalController) Comment(c *gin.Context) {
+	var req PortalCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "Invalid request body", err.Error())
+		return
+	}
+
+	comment, err := pc.portal.Comment(c.Request.Context(), c.GetHeader(TrackingTokenHeader), req.Content, submission(c, req.Captcha, req.Website))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, 201, comment)
+}
+
+// submission returns what the spam checks need to know about a request
+func submission(c *gin.Context, captcha, honeypot string) services.Submission {
+	return services.Submission{Captcha: captcha, RemoteIP: c.ClientIP(), Honeypot: honeypot}
+}
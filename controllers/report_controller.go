@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"fmt"
+	"issue-tracking/analytics"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultReportDays is the report period when from is not given
+const defaultReportDays = 30
+
+// maxReportBuckets keeps a single report from producing unbounded series
+const maxReportBuckets = 1000
+
+// ReportResponse is a report over a time range
+type ReportResponse struct {
+	From     time.Time   `json:"from"`
+	To       time.Time   `json:"to"`
+	Bucket   string      `json:"bucket"`
+	Timezone string      `json:"timezone"`
+	Report   interface{} `json:"report"`
+}
+
+// AgingResponse is the aging report at a point in time
+type AgingResponse struct {
+	At     time.Time   `json:"at"`
+	Report interface{} `json:"report"`
+}
+
+type ReportController struct {
+	store repositories.Store
+}
+
+// NewReportController creates a new report controller
+func NewReportController(store repositories.Store) *ReportController {
+	return &ReportController{store: store}
+}
+
+// GetThroughput returns issues created and closed per bucket
+func (rc *ReportController) GetThroughput(c *gin.Context) {
+	ds, r, ok := rc.load(c)
+	if !ok {
+		return
+	}
+	utils.RespondSuccess(c, 200, reportResponse(r, ds.Throughput(r)))
+}
+
+// GetBacklog returns the number of open issues at the end of each bucket
+func (rc *ReportController) GetBacklog(c *gin.Context) {
+	ds, r, ok := rc.load(c)
+	if !ok {
+		return
+	}
+	utils.RespondSuccess(c, 200, reportResponse(r, ds.Backlog(r, time.Now())))
+}
+
+// GetTimeInStatus returns duration statistics per non-terminal status
+func (rc *ReportController) GetTimeInStatus(c *gin.Context) {
+	ds, r, ok := rc.load(c)
+	if !ok {
+		return
+	}
+	utils.RespondSuccess(c, 200, reportResponse(r, ds.TimeInStatus(r)))
+}
+
+// GetTimeToClose returns duration statistics from creation to close
+func (rc *ReportController) GetTimeToClose(c *gin.Context) {
+	ds, r, ok := rc.load(c)
+	if !ok {
+		return
+	}
+	utils.RespondSuccess(c, 200, reportResponse(r, ds.TimeToClose(r)))
+}
+
+// GetBreakdown returns issues created in the period grouped by priority,
+// assignee or status
+func (rc *ReportController) GetBreakdown(c *gin.Context) {
+	by := c.DefaultQuery("by", analytics.ByPriority)
+	switch by {
+	case analytics.ByPriority, analytics.ByAssignee, analytics.ByStatus:
+	default:
+		utils.RespondError(c, 400, "Invalid breakdown", fmt.Sprintf("by must be one of: %s %s %s",
+			analytics.ByPriority, analytics.ByAssignee, analytics.ByStatus))
+		return
+	}
+
+	ds, r, ok := rc.load(c)
+	if !ok {
+		return
+	}
+	utils.RespondSuccess(c, 200, reportResponse(r, ds.Breakdown(r, by)))
+}
+
+// GetCumulativeFlow returns issue counts per status at the end of each bucket
+func (rc *ReportController) GetCumulativeFlow(c *gin.Context) {
+	ds, r, ok := rc.load(c)
+	if !ok {
+		return
+	}
+	utils.RespondSuccess(c, 200, reportResponse(r, ds.CumulativeFlow(r, time.Now())))
+}
+
+// GetLeadTime returns the distribution of time from creation to final close
+func (rc *ReportController) GetLeadTime(c *gin.Context) {
+	ds, r, ok := rc.load(c)
+	if !ok {
+		return
+	}
+	utils.RespondSuccess(c, 200, reportResponse(r, ds.LeadTime(r)))
+}
+
+// GetCycleTime returns the distribution of time from start of work to final
+// close. start_status optionally lists the status codes that mark the start.
+func (rc *ReportController) GetCycleTime(c *gin.Context) {
+	ds, r, ok := rc.load(c)
+	if !ok {
+		return
+	}
+
+	startStatuses := map[uint]bool{}
+	if value := c.Query("start_status"); value != "" {
+		codes := map[string]bool{}
+		for _, code := range strings.Split(value, ",") {
+			codes[strings.TrimSpace(code)] = true
+		}
+		for id, status := range ds.Statuses {
+			if codes[status.StatusCode] {
+				startStatuses[id] = true
+				delete(codes, status.StatusCode)
+			}
+		}
+		for code := range codes {
+			utils.RespondError(c, 400, "Invalid start_status", fmt.Sprintf("unknown status code %q", code))
+			return
+		}
+	}
+
+	utils.RespondSuccess(c, 200, reportResponse(r, ds.CycleTime(r, startStatuses)))
+}
+
+// GetAging returns the issues currently in progress grouped by status
+func (rc *ReportController) GetAging(c *gin.Context) {
+	now := time.Now()
+	ds, err := analytics.Load(c.Request.Context(), rc.store, now, now)
+	if err != nil {
+		utils.RespondError(c, 500, "Failed to load report data", nil)
+		return
+	}
+	utils.RespondSuccess(c, 200, AgingResponse{At: now, Report: ds.Aging(now)})
+}
+
+// GetCSAT returns the satisfaction scores of the surveys sent in the period,
+// overall, per officer and per bucket
+func (rc *ReportController) GetCSAT(c *gin.Context) {
+	r, err := parseReportRange(c)
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid report range", err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	surveys, err := rc.store.Surveys().ListSent(ctx, r.From, r.To)
+	if err != nil {
+		utils.RespondError(c, 500, "Failed to load report data", nil)
+		return
+	}
+	officers, err := rc.store.Officers().List(ctx)
+	if err != nil {
+		utils.RespondError(c, 500, "Failed to load report data", nil)
+		return
+	}
+	byID := make(map[uint]entities.Officer, len(officers))
+	for _, officer := range officers {
+		byID[officer.OfficerID] = officer
+	}
+	utils.RespondSuccess(c, 200, reportResponse(r, analytics.CSAT(r, surveys, byID)))
+}
+
+// load parses the report range and reads the dataset, responding with an
+// error and returning false on failure
+func (rc *ReportController) load(c *gin.Context) (*analytics.Dataset, analytics.Range, bool) {
+	r, err := parseReportRange(c)
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid report range", err.Error())
+		return nil, r, false
+	}
+
+	ds, err := analytics.Load(c.Request.Context(), rc.store, r.From, r.To)
+	if err != nil {
+		utils.RespondError(c, 500, "Failed to load report data", nil)
+		return nil, r, false
+	}
+	return ds, r, true
+}
+
+// parseReportRange reads from, to, bucket and tz. Dates may be given as
+// 2006-01-02 (to is inclusive) or RFC 3339. The default is the last 30 days
+// bucketed by day in UTC.
+func parseReportRange(c *gin.Context) (analytics.Range, error) {
+	r := analytics.Range{Bucket: c.DefaultQuery("bucket", analytics.BucketDay), Location: time.UTC}
+
+	if tz := c.Query("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return r, fmt.Errorf("unknown time zone %q", tz)
+		}
+		r.Location = loc
+	}
+
+	now := time.Now().In(r.Location)
+	r.To = now
+	if value := c.Query("to"); value != "" {
+		to, dateOnly, err := parseReportTime(value, r.Location)
+		if err != nil {
+			return r, fmt.Errorf("to: %w", err)
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		r.To = to
+	}
+
+	today := time.Date(r.To.Year(), r.To.Month(), r.To.Day(), 0, 0, 0, 0, r.Location)
+	r.From = today.AddDate(0, 0, -defaultReportDays)
+	if value := c.Query("from"); value != "" {
+		from, _, err := parseReportTime(value, r.Location)
+		if err != nil {
+			return r, fmt.Errorf("from: %w", err)
+		}
+		r.From = from
+	}
+
+	if err := r.Validate(); err != nil {
+		return r, err
+	}
+	if r.BucketsExceed(maxReportBuckets) {
+		return r, fmt.Errorf("range produces more than %d buckets, use a larger bucket", maxReportBuckets)
+	}
+	return r, nil
+}
+
+// parseReportTime accepts a date or an RFC 3339 timestamp
+func parseReportTime(value string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("%q must be a date (2006-01-02) or RFC 3339 timestamp", value)
+}
+
+// reportResponse wraps report data with the range it was computed for
+func reportResponse(r analytics.Range, data interface{}) ReportResponse {
+	return ReportResponse{
+		From:     r.From,
+		To:       r.To,
+		Bucket:   r.Bucket,
+		Timezone: r.Location.String(),
+		Report:   data,
+	}
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatusController struct {
+	store repositories.Store
+}
+
+// NewStatusController creates a new status controller
+func NewStatusController(store repositories.Store) *StatusController {
+	return &StatusController{store: store}
+}
+
+// GetAllStatuses retrieves the active issue statuses in display order
+func (sc *StatusController) GetAllStatuses(c *gin.Context) {
+	statuses, err := sc.store.Statuses().List(c.Request.Context(), true)
+	if err != nil {
+		utils.RespondError(c, 500, "Failed to fetch statuses", nil)
+		return
+	}
+
+	if statuses == nil {
+		statuses = []entities.IssueStatus{}
+	}
+
+	utils.RespondSuccess(c, 200, statuses)
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"strconv"
+
+	"issue-tracking/services"
+	"issue-tracking/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SurveyAnswerRequest is the body of POST /api/surveys/:id
+type SurveyAnswerRequest struct {
+	Rating   int    `json:"rating" binding:"required"`
+	Feedback string `json:"feedback"`
+}
+
+type SurveyController struct {
+	surveys *services.SurveyService
+}
+
+// NewSurveyController creates a new satisfaction survey controller
+func NewSurveyController(surveys *services.SurveyService) *SurveyController {
+	return &SurveyController{surveys: surveys}
+}
+
+// GetSurvey returns the survey of a signed link
+func (sc *SurveyController) GetSurvey(c *gin.Context) {
+	id, ok := surveyID(c)
+	if !ok {
+		return
+	}
+
+	survey, err := sc.surveys.Get(c.Request.Context(), id, c.Query("signature"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, 200, survey)
+}
+
+// AnswerSurvey stores the reporter's rating and feedback
+func (sc *SurveyController) AnswerSurvey(c *gin.Context) {
+	id, ok := surveyID(c)
+	if !ok {
+		return
+	}
+	var req SurveyAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "Invalid request body", err.Error())
+		return
+	}
+
+	survey, err := sc.surveys.Respond(c.Request.Context(), id, c.Query("signature"), services.SurveyAnswer{
+		Rating:   req.Rating,
+		Feedback: req.Feedback,
+	})
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, 200, survey)
+}
+
+// GetPendingSurveys lists the surveys waiting for an answer with their
+// links and the contact details of their reporters, for sending them out
+func (sc *SurveyController) GetPendingSurveys(c *gin.Context) {
+	surveys, err := sc.surveys.Pending(c.Request.Context())
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, 200, surveys)
+}
+
+// surveyID parses the survey ID in the path, responding with an error and
+// returning false when it is invalid
+func surveyID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondError(c, 400, "Invalid survey ID", "id must be a positive integer")
+		return 0, false
+	}
+	return uint(id), true
+}
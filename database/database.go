@@ -0,0 +1,87 @@
+// Package database opens the GORM connection for the driver selected by the
+// database URL: PostgreSQL for key=value or postgres:// connection strings
+// and SQLite for sqlite: URLs.
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Supported drivers, as returned by gorm.Dialector.Name()
+const (
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// sqlitePragmas are applied to every SQLite connection: enforce the foreign
+// keys declared by the migrations, wait for a locked database instead of
+// failing, and use a write-ahead log so readers do not block the writer
+var sqlitePragmas = []string{"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"}
+
+// Driver returns the driver selected by the scheme of rawURL
+func Driver(rawURL string) string {
+	if strings.HasPrefix(rawURL, SQLite+":") {
+		return SQLite
+	}
+	return Postgres
+}
+
+// Dialector returns the GORM dialector for rawURL. SQLite URLs name a file,
+// sqlite:issues.db or sqlite:///var/lib/issue-tracking/issues.db, or
+// sqlite::memory: for a database that lives as long as the process.
+func Dialector(rawURL string) (gorm.Dialector, error) {
+	if Driver(rawURL) == Postgres {
+		return postgres.Open(rawURL), nil
+	}
+
+	path, query, _ := strings.Cut(strings.TrimPrefix(rawURL, SQLite+":"), "?")
+	if rest, ok := strings.CutPrefix(path, "//"); ok {
+		path = rest
+	}
+	if path == "" {
+		return nil, fmt.Errorf("sqlite URL %q does not name a database file", rawURL)
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite URL %q: %w", rawURL, err)
+	}
+	for _, pragma := range sqlitePragmas {
+		params.Add("_pragma", pragma)
+	}
+	return sqlite.Open(path + "?" + params.Encode()), nil
+}
+
+// Open connects to rawURL. SQLite allows a single writer, so its pool is
+// limited to one connection: transactions then wait for each other instead
+// of failing with "database is locked", and an in-memory database is shared
+// by every request. SQLite stores times as text that is compared as text,
+// so GORM timestamps are written in UTC.
+func Open(rawURL string, config *gorm.Config) (*gorm.DB, error) {
+	dialector, err := Dialector(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if dialector.Name() == SQLite {
+		config.NowFunc = func() time.Time { return time.Now().UTC() }
+	}
+
+	db, err := gorm.Open(dialector, config)
+	if err != nil {
+		return db, err
+	}
+	if dialector.Name() == SQLite {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return db, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+	return db, nil
+}
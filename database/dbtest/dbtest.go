@@ -0,0 +1,97 @@
+// Package dbtest opens migrated databases for tests on every supported
+// driver.
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"issue-tracking/database"
+	"issue-tracking/migrations"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Drivers returns the drivers to test against: SQLite always, and
+// PostgreSQL when TEST_DATABASE_URL is set
+func Drivers() []string {
+	drivers := []string{database.SQLite}
+	if os.Getenv("TEST_DATABASE_URL") != "" {
+		drivers = append(drivers, database.Postgres)
+	}
+	return drivers
+}
+
+// Open returns an empty, migrated database for driver that is dropped when
+// the test ends. SQLite databases are files in t.TempDir(); PostgreSQL ones
+// are scratch schemas in the database named by TEST_DATABASE_URL.
+func Open(t testing.TB, driver string) *gorm.DB {
+	t.Helper()
+
+	var db *gorm.DB
+	switch driver {
+	case database.SQLite:
+		db = open(t, "sqlite:"+filepath.Join(t.TempDir(), "test.db"))
+	case database.Postgres:
+		dsn := os.Getenv("TEST_DATABASE_URL")
+		if dsn == "" {
+			t.Skip("TEST_DATABASE_URL not set, skipping PostgreSQL test")
+		}
+		admin := open(t, dsn)
+		schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+		if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+		db = open(t, withSearchPath(dsn, schema))
+	default:
+		t.Fatalf("unknown database driver %q", driver)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrations.New(sqlDB, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+// open connects to url and closes the connection when the test ends
+func open(t testing.TB, url string) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(url, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// withSearchPath points a PostgreSQL key=value or URL connection string at
+// schema
+func withSearchPath(dsn, schema string) string {
+	sep := " "
+	if strings.Contains(dsn, "://") {
+		sep = "&"
+		if !strings.Contains(dsn, "?") {
+			sep = "?"
+		}
+	}
+	return dsn + sep + "search_path=" + schema
+}
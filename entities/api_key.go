@@ -0,0 +1,64 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKey is the credential of an integration, such as a monitoring system
+// opening issues. Only the hash of the key is stored; Prefix identifies it.
+type APIKey struct {
+	APIKeyID   uint       `gorm:"primaryKey;column:api_key_id;autoIncrement" json:"api_key_id"`
+	Name       string     `gorm:"column:name;type:varchar(100);not null" json:"name" validate:"required,min=1,max=100"`
+	Prefix     string     `gorm:"column:prefix;type:varchar(20);not null;uniqueIndex" json:"prefix"`
+	KeyHash    string     `gorm:"column:key_hash;type:varchar(64);not null" json:"-"`
+	Scopes     ScopeList  `gorm:"column:scopes;type:varchar(255);not null" json:"scopes" validate:"required,min=1"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// HasScope reports whether the key was granted scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the key can be used at now
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// ScopeList is stored as one space-separated column
+type ScopeList []string
+
+// Value implements driver.Valuer
+func (s ScopeList) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+// Scan implements sql.Scanner
+func (s *ScopeList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	case nil:
+		*s = nil
+	default:
+		return fmt.Errorf("cannot scan %T into ScopeList", value)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+// IdempotencyKey stores the outcome of a POST request made with an
+// Idempotency-Key header so retries can be answered with the same response
+type IdempotencyKey struct {
+	Key          string    `gorm:"primaryKey;column:idempotency_key;type:varchar(255)" json:"idempotency_key"`
+	Method       string    `gorm:"column:method;type:varchar(10);not null" json:"method"`
+	Path         string    `gorm:"column:path;type:varchar(255);not null" json:"path"`
+	RequestHash  string    `gorm:"column:request_hash;type:varchar(64);not null" json:"request_hash"`
+	StatusCode   int       `gorm:"column:status_code;not null;default:0" json:"status_code"`
+	ContentType  string    `gorm:"column:content_type;type:varchar(100)" json:"content_type"`
+	ResponseBody []byte    `gorm:"column:response_body" json:"-"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	ExpiresAt    time.Time `gorm:"column:expires_at;not null;index" json:"expires_at"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
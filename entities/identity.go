@@ -0,0 +1,41 @@
+package entities
+
+import "time"
+
+// Roles a signed-in account can have, mapped from the groups it belongs to
+// at the identity provider
+const (
+	// RoleUser reports issues and comments on them
+	RoleUser = "user"
+	// RoleOfficer also handles issues and reads reports
+	RoleOfficer = "officer"
+	// RoleAdmin also manages API keys
+	RoleAdmin = "admin"
+)
+
+// Identity links an account at the OpenID Connect provider, named by its
+// issuer and subject, to the user it reports issues as and, for officers and
+// admins, the officer it handles them as. Identities are created on the
+// first sign-in and follow the name, email and role of later ones.
+type Identity struct {
+	IdentityID  uint      `gorm:"primaryKey;column:identity_id;autoIncrement" json:"identity_id"`
+	Issuer      string    `gorm:"column:issuer;type:varchar(255);not null;uniqueIndex:idx_identities_subject" json:"issuer"`
+	Subject     string    `gorm:"column:subject;type:varchar(255);not null;uniqueIndex:idx_identities_subject" json:"subject"`
+	Email       string    `gorm:"column:email;type:varchar(255)" json:"email,omitempty"`
+	Name        string    `gorm:"column:name;type:varchar(255);not null" json:"name"`
+	Role        string    `gorm:"column:role;type:varchar(20);not null" json:"role"`
+	UserID      uint      `gorm:"column:user_id;not null;index" json:"user_id"`
+	OfficerID   *uint     `gorm:"column:officer_id;index" json:"officer_id,omitempty"`
+	LastLoginAt time.Time `gorm:"column:last_login_at" json:"last_login_at"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+func (Identity) TableName() string {
+	return "identities"
+}
+
+// HandlesIssues reports whether the role of the identity makes it an officer
+func (i *Identity) HandlesIssues() bool {
+	return i.Role == RoleOfficer || i.Role == RoleAdmin
+}
@@ -2,10 +2,13 @@ package entities
 
 import "time"
 
-// User represents a user who can report issues
+// User represents a user who can report issues. People reporting on the
+// public portal are users too, with the contact details they gave.
 type User struct {
 	UserID    uint       `gorm:"primaryKey;column:user_id;autoIncrement" json:"user_id"`
 	FullName  string     `gorm:"column:full_name;not null" json:"full_name" validate:"required,min=2,max=255"`
+	Email     string     `gorm:"column:email;type:varchar(255)" json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Phone     string     `gorm:"column:phone;type:varchar(50)" json:"phone,omitempty" validate:"omitempty,min=5,max=50"`
 	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
 	DeletedAt *time.Time `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
@@ -37,6 +40,7 @@ type IssueStatus struct {
 	Color        string    `gorm:"column:color;type:varchar(7);not null" json:"color" validate:"required,len=7"`
 	DisplayOrder int       `gorm:"column:display_order;not null;default:0;index" json:"display_order"`
 	IsActive     bool      `gorm:"column:is_active;default:true" json:"is_active"`
+	IsTerminal   bool      `gorm:"column:is_terminal;default:false" json:"is_terminal"`
 	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
 }
 
@@ -44,6 +48,18 @@ func (IssueStatus) TableName() string {
 	return "issue_statuses"
 }
 
+// Sources of an issue, recorded in Issue.Source
+const (
+	// IssueSourceUser is an issue reported by a person
+	IssueSourceUser = "user"
+	// IssueSourceIntegration is an issue opened by an integration with the
+	// API key in Issue.APIKeyID
+	IssueSourceIntegration = "integration"
+	// IssueSourcePortal is an issue reported on the public portal by someone
+	// without an account, who follows it with a tracking token
+	IssueSourcePortal = "portal"
+)
+
 // Issue represents a support ticket or issue
 type Issue struct {
 	IssueID     uint      `gorm:"primaryKey;column:issue_id;autoIncrement" json:"issue_id"`
@@ -53,6 +69,8 @@ type Issue struct {
 	Title       string    `gorm:"column:title;type:varchar(255);not null" json:"title" validate:"required,min=3,max=255"`
 	Description string    `gorm:"column:description;type:text" json:"description" validate:"max=5000"`
 	Priority    string    `gorm:"column:priority;type:varchar(20);not null;default:'medium';index" json:"priority" validate:"required,oneof=low medium high critical"`
+	Source      string    `gorm:"column:source;type:varchar(20);not null;default:'user'" json:"source"`
+	APIKeyID    *uint     `gorm:"column:api_key_id;index" json:"api_key_id,omitempty"`
 	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime;index" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
 
@@ -62,12 +80,25 @@ type Issue struct {
 	Status        IssueStatus          `gorm:"foreignKey:StatusID;references:StatusID;constraint:OnDelete:RESTRICT" json:"status,omitempty" validate:"-"`
 	StatusHistory []IssueStatusHistory `gorm:"foreignKey:IssueID;references:IssueID;constraint:OnDelete:CASCADE" json:"status_history,omitempty" validate:"-"`
 	Comments      []Comment            `gorm:"foreignKey:IssueID;references:IssueID;constraint:OnDelete:CASCADE" json:"comments,omitempty" validate:"-"`
+	Labels        []Label              `gorm:"many2many:issue_labels;foreignKey:IssueID;joinForeignKey:issue_id;references:LabelID;joinReferences:label_id" json:"labels,omitempty" validate:"-"`
 }
 
 func (Issue) TableName() string {
 	return "issues"
 }
 
+// Label is a free-form tag that can be attached to issues
+type Label struct {
+	LabelID   uint      `gorm:"primaryKey;column:label_id;autoIncrement" json:"label_id"`
+	Name      string    `gorm:"column:name;type:varchar(50);unique;not null" json:"name" validate:"required,min=1,max=50"`
+	Color     string    `gorm:"column:color;type:varchar(7);not null;default:'#808080'" json:"color" validate:"omitempty,len=7"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (Label) TableName() string {
+	return "labels"
+}
+
 // IssueStatusHistory tracks status changes for issues
 type IssueStatusHistory struct {
 	HistoryID   uint      `gorm:"primaryKey;column:history_id;autoIncrement" json:"history_id"`
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// RateLimitBucket is the token bucket of one client and route class. It is
+// only stored in the database when rate limits are shared by replicas.
+type RateLimitBucket struct {
+	Key       string    `gorm:"primaryKey;column:bucket_key;type:varchar(255)" json:"bucket_key"`
+	Tokens    float64   `gorm:"column:tokens;not null" json:"tokens"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null;index;autoUpdateTime:false" json:"updated_at"`
+}
+
+func (RateLimitBucket) TableName() string {
+	return "rate_limit_buckets"
+}
@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+// Survey asks the reporter of an issue how satisfied they were once it
+// closed. OfficerID is the assignee at closure, who the rating is credited
+// to. The reporter answers once, before ExpiresAt.
+type Survey struct {
+	SurveyID    uint       `gorm:"primaryKey;column:survey_id;autoIncrement" json:"survey_id"`
+	IssueID     uint       `gorm:"column:issue_id;not null;index" json:"issue_id"`
+	OfficerID   *uint      `gorm:"column:officer_id;index" json:"officer_id,omitempty"`
+	Rating      *int       `gorm:"column:rating" json:"rating,omitempty" validate:"omitempty,min=1,max=5"`
+	Feedback    string     `gorm:"column:feedback;type:text" json:"feedback,omitempty" validate:"max=2000"`
+	ExpiresAt   time.Time  `gorm:"column:expires_at;not null" json:"expires_at"`
+	RespondedAt *time.Time `gorm:"column:responded_at" json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime;index" json:"created_at"`
+}
+
+func (Survey) TableName() string {
+	return "surveys"
+}
+
+// Pending reports whether the survey can still be answered at now
+func (s *Survey) Pending(now time.Time) bool {
+	return s.RespondedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+// TrackingTokenPrefix starts every tracking token so they are easy to tell
+// from API keys
+const TrackingTokenPrefix = "trk_"
+
+// TrackingToken lets the person who reported an issue on the public portal
+// follow and comment on it without an account. Only the hash of the token
+// is stored.
+type TrackingToken struct {
+	TrackingTokenID uint      `gorm:"primaryKey;column:tracking_token_id;autoIncrement" json:"tracking_token_id"`
+	IssueID         uint      `gorm:"column:issue_id;not null;uniqueIndex" json:"issue_id"`
+	TokenHash       string    `gorm:"column:token_hash;type:varchar(64);not null;uniqueIndex" json:"-"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (TrackingToken) TableName() string {
+	return "tracking_tokens"
+}
@@ -0,0 +1,147 @@
+package graph_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"issue-tracking/database"
+	"issue-tracking/database/dbtest"
+	"issue-tracking/entities"
+	"issue-tracking/graph"
+	"issue-tracking/repositories"
+	"issue-tracking/services"
+
+	"gorm.io/gorm"
+)
+
+// TestBatching resolves a nested query over many issues and checks that
+// each relation is loaded with one query per level rather than per issue
+func TestBatching(t *testing.T) {
+	ctx := context.Background()
+	db := dbtest.Open(t, database.SQLite)
+	store := repositories.NewGormStore(db)
+
+	for i, code := range []string{"open", "in-progress", "closed"} {
+		status := entities.IssueStatus{StatusCode: code, DisplayName: code, Color: "#2196F3", DisplayOrder: i, IsActive: true}
+		if err := store.Statuses().Create(ctx, &status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i <= 5; i++ {
+		if err := store.Users().Create(ctx, &entities.User{FullName: fmt.Sprintf("User %d", i)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Officers().Create(ctx, &entities.Officer{FullName: fmt.Sprintf("Officer %d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	label := entities.Label{Name: "bug"}
+	if err := store.Labels().FindOrCreate(ctx, &label); err != nil {
+		t.Fatal(err)
+	}
+
+	const issues = 20
+	for i := 0; i < issues; i++ {
+		assignee := uint(i%5 + 1)
+		issue := entities.Issue{ReporterID: uint(i%5 + 1), AssigneeID: &assignee, StatusID: 1, Title: fmt.Sprintf("Issue %d", i), Priority: "low"}
+		if err := store.Issues().Create(ctx, &issue); err != nil {
+			t.Fatal(err)
+		}
+		status := uint(2)
+		if err := store.Issues().ApplyChange(ctx, issue.IssueID, repositories.IssueChange{NewStatusID: &status, Label: &label, ChangedBy: assignee}); err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < 2; j++ {
+			comment := entities.Comment{IssueID: issue.IssueID, UserID: uint(j + 1), Content: "Any update?"}
+			if err := store.Comments().Create(ctx, &comment); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	server, err := graph.NewServer(store, services.NewIssueService(store), graph.Limits{MaxDepth: 10, MaxComplexity: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var queries int
+	count := func(*gorm.DB) { queries++ }
+	if err := db.Callback().Query().After("gorm:query").Register("test:count_queries", count); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("test:count_rows", count); err != nil {
+		t.Fatal(err)
+	}
+
+	status, resp := server.Execute(ctx, graph.Request{Query: `{
+		issues(limit: 50) {
+			title
+			reporter { fullName }
+			assignee { fullName }
+			status { code }
+			labels { name }
+			history { oldStatus { code } newStatus { code } changedBy { fullName } }
+			comments { content user { fullName } issue { title } }
+		}
+	}`}, false)
+	if status != http.StatusOK || len(resp.Errors) > 0 {
+		t.Fatalf("status %d, errors %v", status, resp.Errors)
+	}
+
+	got := resp.Data.(map[string]interface{})["issues"].([]interface{})
+	if len(got) != issues {
+		t.Fatalf("got %d issues, want %d", len(got), issues)
+	}
+	first := got[0].(map[string]interface{})
+	if first["status"].(map[string]interface{})["code"] != "in-progress" || len(first["comments"].([]interface{})) != 2 {
+		t.Errorf("first issue = %v", first)
+	}
+
+	// The issues, then one query each for reporters, assignees, statuses,
+	// labels, history and comments, then at most one each for the statuses,
+	// officers, users and issues of the third level not loaded before
+	if queries > 11 {
+		t.Errorf("resolved %d issues with %d queries, want at most 11", issues, queries)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	server, err := graph.NewServer(store, services.NewIssueService(store), graph.Limits{MaxDepth: 3, MaxComplexity: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		err       string
+	}{
+		{name: "within limits", query: `{ issues(limit: 5) { id status { code } } }`},
+		{name: "too deep", query: `{ issues(limit: 1) { comments { user { fullName } } } }`, err: "query depth 4 exceeds the limit of 3"},
+		{name: "depth through fragments", query: `{ issues(limit: 1) { ...f } } fragment f on Issue { comments { user { id } } }`, err: "query depth 4"},
+		{name: "default page size", query: `{ issues { id } }`, err: "query complexity 101 exceeds the limit of 50"},
+		{name: "limit from variable", query: `query($n: Int) { issues(limit: $n) { id title } }`, variables: map[string]interface{}{"n": float64(30)}, err: "query complexity 61"},
+		{name: "assumed list size", query: `{ issues(limit: 3) { comments { id content } } }`, err: "query complexity 64"},
+		{name: "introspection is free", query: `{ __schema { types { name fields { name type { name ofType { name } } } } } }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := server.Execute(ctx, graph.Request{Query: tt.query, Variables: tt.variables}, false)
+			if tt.err == "" {
+				if status != http.StatusOK || len(resp.Errors) > 0 {
+					t.Fatalf("status %d, errors %v", status, resp.Errors)
+				}
+				return
+			}
+			if status != http.StatusBadRequest || len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.err) {
+				t.Fatalf("status %d, errors %v, want %q", status, resp.Errors, tt.err)
+			}
+		})
+	}
+}
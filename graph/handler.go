@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"issue-tracking/repositories"
+	"issue-tracking/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Request is the body of a GraphQL request
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the body of a GraphQL response. Data is absent when the
+// request was rejected before it ran.
+type Response struct {
+	Data   interface{}                `json:"data,omitempty"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+// Server runs GraphQL requests against a store
+type Server struct {
+	schema graphql.Schema
+	store  repositories.Store
+	limits Limits
+}
+
+// NewServer creates a GraphQL server reading from store and making changes
+// through issues
+func NewServer(store repositories.Store, issues *services.IssueService, limits Limits) (*Server, error) {
+	schema, err := newSchema(store, issues)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{schema: schema, store: store, limits: limits}, nil
+}
+
+// Handle serves POST requests with a JSON body and GET requests with query,
+// operationName and variables query parameters. GET runs queries only.
+func (s *Server) Handle(c *gin.Context) {
+	var req Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, rejected(fmt.Errorf("variables must be a JSON object")))
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, rejected(fmt.Errorf("invalid request body: %v", err)))
+		return
+	}
+
+	status, resp := s.Execute(c.Request.Context(), req, c.Request.Method != http.MethodGet)
+	c.JSON(status, resp)
+}
+
+// Execute runs req and returns the HTTP status of the response: 400 when
+// the request is rejected before it runs, such as for a syntax error, an
+// exceeded limit or a mutation when mutations are not allowed, and 200
+// otherwise, even when fields fail.
+func (s *Server) Execute(ctx context.Context, req Request, allowMutations bool) (int, Response) {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return http.StatusBadRequest, Response{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if result := graphql.ValidateDocument(&s.schema, doc, nil); !result.IsValid {
+		return http.StatusBadRequest, Response{Errors: result.Errors}
+	}
+
+	op, fragments, err := operation(doc, req.OperationName)
+	if err != nil {
+		return http.StatusBadRequest, rejected(err)
+	}
+	if op.Operation == ast.OperationTypeMutation && !allowMutations {
+		return http.StatusBadRequest, rejected(fmt.Errorf("mutations must be sent with POST"))
+	}
+	if err := s.limits.check(&s.schema, op, fragments, req.Variables); err != nil {
+		return http.StatusBadRequest, rejected(err)
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, s.store),
+	})
+	return http.StatusOK, Response{Data: result.Data, Errors: result.Errors}
+}
+
+// operation returns the operation of doc to run and the fragments it may use
+func operation(doc *ast.Document, name string) (*ast.OperationDefinition, map[string]*ast.FragmentDefinition, error) {
+	var op *ast.OperationDefinition
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			switch {
+			case name == "" && op != nil:
+				return nil, nil, fmt.Errorf("operationName is required when the query contains several operations")
+			case name == "" || (def.Name != nil && def.Name.Value == name):
+				op = def
+			}
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		}
+	}
+	if op == nil {
+		return nil, nil, fmt.Errorf("unknown operation %q", name)
+	}
+	return op, fragments, nil
+}
+
+// rejected is the response to a request that did not run
+func rejected(err error) Response {
+	return Response{Errors: gqlerrors.FormatErrors(err)}
+}
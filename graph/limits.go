@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// assumedListSize is the length assumed for lists without a limit argument,
+// such as the comments of an issue
+const assumedListSize = 10
+
+// Limits bounds the queries the endpoint runs
+type Limits struct {
+	// MaxDepth is the deepest field nesting; a top-level field has depth 1
+	MaxDepth int
+	// MaxComplexity is the highest estimated number of fields resolved. The
+	// fields below a list count once per element, taking the list's limit
+	// argument or assumedListSize as its length.
+	MaxComplexity int
+}
+
+// cost is the measure of a selection set
+type cost struct {
+	depth      int
+	complexity int
+}
+
+// check measures the operation and returns an error when it exceeds a limit
+func (l Limits) check(schema *graphql.Schema, op *ast.OperationDefinition, fragments map[string]*ast.FragmentDefinition, variables map[string]interface{}) error {
+	root := schema.QueryType()
+	if op.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	m := measurer{fragments: fragments, variables: variables}
+	c := m.selections(root, op.SelectionSet, 1)
+	if c.depth > l.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", c.depth, l.MaxDepth)
+	}
+	if c.complexity > l.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", c.complexity, l.MaxComplexity)
+	}
+	return nil
+}
+
+type measurer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// selections measures the fields selected on parent at depth
+func (m measurer) selections(parent *graphql.Object, set *ast.SelectionSet, depth int) cost {
+	var total cost
+	if set == nil {
+		return total
+	}
+	add := func(c cost) {
+		total.depth = max(total.depth, c.depth)
+		total.complexity += c.complexity
+	}
+
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			add(m.field(parent, selection, depth))
+		case *ast.InlineFragment:
+			add(m.selections(parent, selection.SelectionSet, depth))
+		case *ast.FragmentSpread:
+			if fragment, ok := m.fragments[selection.Name.Value]; ok {
+				add(m.selections(parent, fragment.SelectionSet, depth))
+			}
+		}
+	}
+	return total
+}
+
+// field measures one field and what it selects. Introspection is free.
+func (m measurer) field(parent *graphql.Object, f *ast.Field, depth int) cost {
+	if strings.HasPrefix(f.Name.Value, "__") {
+		return cost{}
+	}
+	def, ok := parent.Fields()[f.Name.Value]
+	if !ok {
+		return cost{depth: depth, complexity: 1}
+	}
+
+	size := 1
+	typ := def.Type
+	if nonNull, ok := typ.(*graphql.NonNull); ok {
+		typ = nonNull.OfType
+	}
+	if list, ok := typ.(*graphql.List); ok {
+		size = m.listSize(def, f)
+		typ = list.OfType
+		if nonNull, ok := typ.(*graphql.NonNull); ok {
+			typ = nonNull.OfType
+		}
+	}
+
+	object, ok := typ.(*graphql.Object)
+	if !ok || f.SelectionSet == nil {
+		return cost{depth: depth, complexity: 1}
+	}
+	children := m.selections(object, f.SelectionSet, depth+1)
+	return cost{depth: max(depth, children.depth), complexity: 1 + size*children.complexity}
+}
+
+// listSize is the limit argument of a list field, its default, or
+// assumedListSize for lists without one
+func (m measurer) listSize(def *graphql.FieldDefinition, f *ast.Field) int {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil {
+				return max(n, 1)
+			}
+		case *ast.Variable:
+			switch n := m.variables[value.Name.Value].(type) {
+			case int:
+				return max(n, 1)
+			case float64:
+				return max(int(n), 1)
+			}
+		}
+	}
+	for _, arg := range def.Args {
+		if arg.Name() == "limit" {
+			if n, ok := arg.DefaultValue.(int); ok {
+				return n
+			}
+		}
+	}
+	return assumedListSize
+}
@@ -0,0 +1,176 @@
+package graph
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+)
+
+// loader batches the lookups made while one level of a query resolves.
+// load registers a key and returns a thunk; the executor calls the thunks
+// once every field of the level has been resolved, and the first one fetches
+// all registered keys in one call. Results are kept for the request.
+type loader[V any] struct {
+	fetch func(ctx context.Context, ids []uint) (map[uint]V, error)
+
+	mu      sync.Mutex
+	pending []uint
+	results map[uint]loaded[V]
+}
+
+type loaded[V any] struct {
+	value V
+	found bool
+	err   error
+}
+
+func newLoader[V any](fetch func(ctx context.Context, ids []uint) (map[uint]V, error)) *loader[V] {
+	return &loader[V]{fetch: fetch, results: map[uint]loaded[V]{}}
+}
+
+// load registers id and returns a thunk yielding its value
+func (l *loader[V]) load(ctx context.Context, id uint) func() (V, bool, error) {
+	l.mu.Lock()
+	if _, ok := l.results[id]; !ok && !slices.Contains(l.pending, id) {
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (V, bool, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if _, ok := l.results[id]; !ok {
+			if !slices.Contains(l.pending, id) {
+				l.pending = append(l.pending, id)
+			}
+			l.flush(ctx)
+		}
+		r := l.results[id]
+		return r.value, r.found, r.err
+	}
+}
+
+// flush fetches the pending keys. The caller holds mu.
+func (l *loader[V]) flush(ctx context.Context) {
+	ids := l.pending
+	l.pending = nil
+	values, err := l.fetch(ctx, ids)
+	for _, id := range ids {
+		value, found := values[id]
+		l.results[id] = loaded[V]{value: value, found: found, err: err}
+	}
+}
+
+// reset forgets the fetched values so a mutation's result is read afresh
+func (l *loader[V]) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending = nil
+	l.results = map[uint]loaded[V]{}
+}
+
+// one resolves to the value of id, or null when it does not exist
+func one[V any](ctx context.Context, l *loader[V], id uint) func() (interface{}, error) {
+	thunk := l.load(ctx, id)
+	return func() (interface{}, error) {
+		value, found, err := thunk()
+		if err != nil || !found {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+// many resolves to the values listed under id, or an empty list
+func many[V any](ctx context.Context, l *loader[[]V], id uint) func() (interface{}, error) {
+	thunk := l.load(ctx, id)
+	return func() (interface{}, error) {
+		values, _, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if values == nil {
+			values = []V{}
+		}
+		return values, nil
+	}
+}
+
+// loaders holds the loaders of one request
+type loaders struct {
+	issues   *loader[entities.Issue]
+	users    *loader[entities.User]
+	officers *loader[entities.Officer]
+	statuses *loader[entities.IssueStatus]
+	comments *loader[[]entities.Comment]
+	history  *loader[[]entities.IssueStatusHistory]
+	labels   *loader[[]entities.Label]
+}
+
+func newLoaders(store repositories.Store) *loaders {
+	return &loaders{
+		issues:   newLoader(byID(store.Issues().ListByIDs, func(i entities.Issue) uint { return i.IssueID })),
+		users:    newLoader(byID(store.Users().ListByIDs, func(u entities.User) uint { return u.UserID })),
+		officers: newLoader(byID(store.Officers().ListByIDs, func(o entities.Officer) uint { return o.OfficerID })),
+		statuses: newLoader(byID(store.Statuses().ListByIDs, func(s entities.IssueStatus) uint { return s.StatusID })),
+		comments: newLoader(groupedBy(store.Comments().ListByIssues, func(c entities.Comment) uint { return c.IssueID })),
+		history:  newLoader(groupedBy(store.Issues().HistoryByIssues, func(h entities.IssueStatusHistory) uint { return h.IssueID })),
+		labels:   newLoader(store.Labels().ListByIssues),
+	}
+}
+
+// reset clears every loader
+func (l *loaders) reset() {
+	l.issues.reset()
+	l.users.reset()
+	l.officers.reset()
+	l.statuses.reset()
+	l.comments.reset()
+	l.history.reset()
+	l.labels.reset()
+}
+
+// byID adapts a ListByIDs method to a loader fetch
+func byID[V any](list func(context.Context, []uint) ([]V, error), key func(V) uint) func(context.Context, []uint) (map[uint]V, error) {
+	return func(ctx context.Context, ids []uint) (map[uint]V, error) {
+		rows, err := list(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[uint]V, len(rows))
+		for _, row := range rows {
+			values[key(row)] = row
+		}
+		return values, nil
+	}
+}
+
+// groupedBy adapts a has-many lookup to a loader fetch
+func groupedBy[V any](list func(context.Context, []uint) ([]V, error), key func(V) uint) func(context.Context, []uint) (map[uint][]V, error) {
+	return func(ctx context.Context, ids []uint) (map[uint][]V, error) {
+		rows, err := list(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		values := map[uint][]V{}
+		for _, row := range rows {
+			values[key(row)] = append(values[key(row)], row)
+		}
+		return values, nil
+	}
+}
+
+type loadersKey struct{}
+
+// withLoaders returns a context carrying fresh loaders over store
+func withLoaders(ctx context.Context, store repositories.Store) context.Context {
+	return context.WithValue(ctx, loadersKey{}, newLoaders(store))
+}
+
+// loadersFrom returns the loaders of a request
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,419 @@
+// Package graph serves the GraphQL API over the same store and service layer
+// as the REST controllers. Nested fields are loaded in batches, one query
+// per relation and level instead of one per parent, and queries deeper or
+// costlier than the configured limits are rejected before they run.
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"issue-tracking/controllers"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/services"
+
+	"github.com/graphql-go/graphql"
+)
+
+// DefaultPageSize is the number of issues returned when no limit is given
+const DefaultPageSize = 100
+
+// serviceError exposes the HTTP status and details of a service error as
+// GraphQL error extensions
+type serviceError struct{ err *services.Error }
+
+func (e serviceError) Error() string { return e.err.Error() }
+
+func (e serviceError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"status": e.err.Status}
+	if e.err.Details != nil {
+		extensions["details"] = e.err.Details
+	}
+	return extensions
+}
+
+// resolverError wraps service errors so their extensions are reported
+func resolverError(err error) error {
+	var svcErr *services.Error
+	if errors.As(err, &svcErr) {
+		return serviceError{svcErr}
+	}
+	return err
+}
+
+// field declares a field computed from a source of type T
+func field[T any](typ graphql.Output, resolve func(src T) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return resolve(p.Source.(T)), nil
+		},
+	}
+}
+
+// relation declares a field loaded in batches by the loaders of the request
+func relation[T any](typ graphql.Output, resolve func(ctx context.Context, l *loaders, src T) func() (interface{}, error)) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return resolve(p.Context, loadersFrom(p.Context), p.Source.(T)), nil
+		},
+	}
+}
+
+// idArg reads a required or optional ID argument
+func idArg(args map[string]interface{}, name string) (*uint, error) {
+	value, ok := args[name].(int)
+	if !ok {
+		return nil, nil
+	}
+	if value < 1 {
+		return nil, fmt.Errorf("%s must be a positive integer", name)
+	}
+	id := uint(value)
+	return &id, nil
+}
+
+var priorityEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "Priority",
+	Values: graphql.EnumValueConfigMap{
+		"low":      {Value: "low"},
+		"medium":   {Value: "medium"},
+		"high":     {Value: "high"},
+		"critical": {Value: "critical"},
+	},
+})
+
+var userType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "User",
+	Description: "A person who reports issues and comments on them",
+	Fields: graphql.Fields{
+		"id":        field(graphql.NewNonNull(graphql.Int), func(u entities.User) interface{} { return int(u.UserID) }),
+		"fullName":  field(graphql.NewNonNull(graphql.String), func(u entities.User) interface{} { return u.FullName }),
+		"createdAt": field(graphql.NewNonNull(graphql.DateTime), func(u entities.User) interface{} { return u.CreatedAt }),
+	},
+})
+
+var officerType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Officer",
+	Description: "A person who handles issues",
+	Fields: graphql.Fields{
+		"id":        field(graphql.NewNonNull(graphql.Int), func(o entities.Officer) interface{} { return int(o.OfficerID) }),
+		"fullName":  field(graphql.NewNonNull(graphql.String), func(o entities.Officer) interface{} { return o.FullName }),
+		"createdAt": field(graphql.NewNonNull(graphql.DateTime), func(o entities.Officer) interface{} { return o.CreatedAt }),
+	},
+})
+
+var statusType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "IssueStatus",
+	Fields: graphql.Fields{
+		"id":           field(graphql.NewNonNull(graphql.Int), func(s entities.IssueStatus) interface{} { return int(s.StatusID) }),
+		"code":         field(graphql.NewNonNull(graphql.String), func(s entities.IssueStatus) interface{} { return s.StatusCode }),
+		"displayName":  field(graphql.NewNonNull(graphql.String), func(s entities.IssueStatus) interface{} { return s.DisplayName }),
+		"description":  field(graphql.NewNonNull(graphql.String), func(s entities.IssueStatus) interface{} { return s.Description }),
+		"color":        field(graphql.NewNonNull(graphql.String), func(s entities.IssueStatus) interface{} { return s.Color }),
+		"displayOrder": field(graphql.NewNonNull(graphql.Int), func(s entities.IssueStatus) interface{} { return s.DisplayOrder }),
+		"isActive":     field(graphql.NewNonNull(graphql.Boolean), func(s entities.IssueStatus) interface{} { return s.IsActive }),
+		"isTerminal":   field(graphql.NewNonNull(graphql.Boolean), func(s entities.IssueStatus) interface{} { return s.IsTerminal }),
+	},
+})
+
+var labelType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Label",
+	Fields: graphql.Fields{
+		"name":  field(graphql.NewNonNull(graphql.String), func(l entities.Label) interface{} { return l.Name }),
+		"color": field(graphql.NewNonNull(graphql.String), func(l entities.Label) interface{} { return l.Color }),
+	},
+})
+
+var statusChangeType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "StatusChange",
+	Description: "An entry of the status history of an issue",
+	Fields: graphql.Fields{
+		"id": field(graphql.NewNonNull(graphql.Int), func(h entities.IssueStatusHistory) interface{} { return int(h.HistoryID) }),
+		"oldStatus": relation(statusType, func(ctx context.Context, l *loaders, h entities.IssueStatusHistory) func() (interface{}, error) {
+			if h.OldStatusID == nil {
+				return func() (interface{}, error) { return nil, nil }
+			}
+			return one(ctx, l.statuses, *h.OldStatusID)
+		}),
+		"newStatus": relation(graphql.NewNonNull(statusType), func(ctx context.Context, l *loaders, h entities.IssueStatusHistory) func() (interface{}, error) {
+			return one(ctx, l.statuses, h.NewStatusID)
+		}),
+		"changedBy": relation(officerType, func(ctx context.Context, l *loaders, h entities.IssueStatusHistory) func() (interface{}, error) {
+			return one(ctx, l.officers, h.ChangedBy)
+		}),
+		"comment":   field(graphql.NewNonNull(graphql.String), func(h entities.IssueStatusHistory) interface{} { return h.Comment }),
+		"changedAt": field(graphql.NewNonNull(graphql.DateTime), func(h entities.IssueStatusHistory) interface{} { return h.ChangedAt }),
+	},
+})
+
+// issueType and commentType refer to each other, so their fields are
+// declared when the schema is built
+var issueType, commentType *graphql.Object
+
+func init() {
+	issueType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Issue",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          field(graphql.NewNonNull(graphql.Int), func(i entities.Issue) interface{} { return int(i.IssueID) }),
+				"title":       field(graphql.NewNonNull(graphql.String), func(i entities.Issue) interface{} { return i.Title }),
+				"description": field(graphql.NewNonNull(graphql.String), func(i entities.Issue) interface{} { return i.Description }),
+				"priority":    field(graphql.NewNonNull(priorityEnum), func(i entities.Issue) interface{} { return i.Priority }),
+				"createdAt":   field(graphql.NewNonNull(graphql.DateTime), func(i entities.Issue) interface{} { return i.CreatedAt }),
+				"updatedAt":   field(graphql.NewNonNull(graphql.DateTime), func(i entities.Issue) interface{} { return i.UpdatedAt }),
+				"reporter": relation(graphql.NewNonNull(userType), func(ctx context.Context, l *loaders, i entities.Issue) func() (interface{}, error) {
+					return one(ctx, l.users, i.ReporterID)
+				}),
+				"assignee": relation(officerType, func(ctx context.Context, l *loaders, i entities.Issue) func() (interface{}, error) {
+					if i.AssigneeID == nil {
+						return func() (interface{}, error) { return nil, nil }
+					}
+					return one(ctx, l.officers, *i.AssigneeID)
+				}),
+				"status": relation(graphql.NewNonNull(statusType), func(ctx context.Context, l *loaders, i entities.Issue) func() (interface{}, error) {
+					return one(ctx, l.statuses, i.StatusID)
+				}),
+				"comments": relation(listOf(commentType), func(ctx context.Context, l *loaders, i entities.Issue) func() (interface{}, error) {
+					return many(ctx, l.comments, i.IssueID)
+				}),
+				"history": relation(listOf(statusChangeType), func(ctx context.Context, l *loaders, i entities.Issue) func() (interface{}, error) {
+					return many(ctx, l.history, i.IssueID)
+				}),
+				"labels": relation(listOf(labelType), func(ctx context.Context, l *loaders, i entities.Issue) func() (interface{}, error) {
+					return many(ctx, l.labels, i.IssueID)
+				}),
+			}
+		}),
+	})
+
+	commentType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Comment",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        field(graphql.NewNonNull(graphql.Int), func(c entities.Comment) interface{} { return int(c.CommentID) }),
+				"content":   field(graphql.NewNonNull(graphql.String), func(c entities.Comment) interface{} { return c.Content }),
+				"createdAt": field(graphql.NewNonNull(graphql.DateTime), func(c entities.Comment) interface{} { return c.CreatedAt }),
+				"user": relation(graphql.NewNonNull(userType), func(ctx context.Context, l *loaders, c entities.Comment) func() (interface{}, error) {
+					return one(ctx, l.users, c.UserID)
+				}),
+				"issue": relation(graphql.NewNonNull(issueType), func(ctx context.Context, l *loaders, c entities.Comment) func() (interface{}, error) {
+					return one(ctx, l.issues, c.IssueID)
+				}),
+			}
+		}),
+	})
+}
+
+// listOf is a non-null list of non-null t
+func listOf(t graphql.Type) graphql.Output {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+}
+
+// issueFilterArgs match the query parameters of GET /api/issues
+var issueFilterArgs = graphql.FieldConfigArgument{
+	"status":     {Type: graphql.String, Description: "Status code"},
+	"priority":   {Type: priorityEnum},
+	"assigneeId": {Type: graphql.Int},
+	"reporterId": {Type: graphql.Int},
+	"label":      {Type: graphql.String, Description: "Label name"},
+	"limit": {
+		Type:         graphql.Int,
+		DefaultValue: DefaultPageSize,
+		Description:  fmt.Sprintf("Page size, at most %d", controllers.MaxIssuePageSize),
+	},
+	"afterId": {Type: graphql.Int, Description: "Return issues with a greater ID"},
+}
+
+// issueFilter reads the arguments of the issues query
+func issueFilter(args map[string]interface{}) (repositories.IssueFilter, error) {
+	filter := repositories.IssueFilter{}
+	filter.StatusCode, _ = args["status"].(string)
+	filter.Priority, _ = args["priority"].(string)
+	filter.Label, _ = args["label"].(string)
+
+	var err error
+	if filter.AssigneeID, err = idArg(args, "assigneeId"); err != nil {
+		return filter, err
+	}
+	if filter.ReporterID, err = idArg(args, "reporterId"); err != nil {
+		return filter, err
+	}
+	if afterID, ok := args["afterId"].(int); ok {
+		if afterID < 0 {
+			return filter, fmt.Errorf("afterId must not be negative")
+		}
+		filter.AfterID = uint(afterID)
+	}
+	filter.Limit, _ = args["limit"].(int)
+	if filter.Limit < 1 || filter.Limit > controllers.MaxIssuePageSize {
+		return filter, fmt.Errorf("limit must be between 1 and %d", controllers.MaxIssuePageSize)
+	}
+	return filter, nil
+}
+
+// byIDField declares a root field returning one record by ID, or null
+func byIDField[V any](typ graphql.Output, pick func(l *loaders) *loader[V]) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.Int)}},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			id, err := idArg(p.Args, "id")
+			if err != nil {
+				return nil, err
+			}
+			return one(p.Context, pick(loadersFrom(p.Context)), *id), nil
+		},
+	}
+}
+
+var createIssueInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "CreateIssueInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"reporterId":  {Type: graphql.NewNonNull(graphql.Int)},
+		"assigneeId":  {Type: graphql.Int},
+		"statusId":    {Type: graphql.NewNonNull(graphql.Int)},
+		"title":       {Type: graphql.NewNonNull(graphql.String)},
+		"description": {Type: graphql.String},
+		"priority":    {Type: graphql.NewNonNull(priorityEnum)},
+	},
+})
+
+// newSchema builds the schema. Root fields read from store and mutations go
+// through the service layer, so they apply the same checks as REST.
+func newSchema(store repositories.Store, issues *services.IssueService) (graphql.Schema, error) {
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"issues": {
+				Type:        listOf(issueType),
+				Description: "Issues ordered by ID, a page at a time; pass the last ID of a page as afterId to get the next one",
+				Args:        issueFilterArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					filter, err := issueFilter(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return store.Issues().Find(p.Context, filter)
+				},
+			},
+			"issue":   byIDField(issueType, func(l *loaders) *loader[entities.Issue] { return l.issues }),
+			"user":    byIDField(userType, func(l *loaders) *loader[entities.User] { return l.users }),
+			"officer": byIDField(officerType, func(l *loaders) *loader[entities.Officer] { return l.officers }),
+			"statuses": {
+				Type:        listOf(statusType),
+				Description: "Active statuses in display order",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return store.Statuses().List(p.Context, true)
+				},
+			},
+			"officers": {
+				Type: listOf(officerType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return store.Officers().List(p.Context)
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createIssue": {
+				Type: graphql.NewNonNull(issueType),
+				Args: graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(createIssueInput)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					input := p.Args["input"].(map[string]interface{})
+					issue := entities.Issue{}
+					issue.Title, _ = input["title"].(string)
+					issue.Description, _ = input["description"].(string)
+					issue.Priority, _ = input["priority"].(string)
+					var err error
+					if issue.AssigneeID, err = idArg(input, "assigneeId"); err != nil {
+						return nil, err
+					}
+					if reporterID, ok := input["reporterId"].(int); ok && reporterID > 0 {
+						issue.ReporterID = uint(reporterID)
+					}
+					if statusID, ok := input["statusId"].(int); ok && statusID > 0 {
+						issue.StatusID = uint(statusID)
+					}
+
+					created, err := issues.Create(p.Context, issue)
+					if err != nil {
+						return nil, resolverError(err)
+					}
+					loadersFrom(p.Context).reset()
+					return *created, nil
+				},
+			},
+			"transitionIssue": {
+				Type:        graphql.NewNonNull(issueType),
+				Description: "Moves an issue to another status, recording the change in its history",
+				Args: graphql.FieldConfigArgument{
+					"id":         {Type: graphql.NewNonNull(graphql.Int)},
+					"statusId":   {Type: graphql.NewNonNull(graphql.Int)},
+					"assigneeId": {Type: graphql.Int, Description: "Reassigns the issue when set"},
+					"comment":    {Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+					statusID, err := idArg(p.Args, "statusId")
+					if err != nil {
+						return nil, err
+					}
+					change := services.StatusChange{
+						NewStatusID: *statusID,
+						ChangedBy:   1, // Default to officer ID 1, should be from auth context in production
+					}
+					if change.AssigneeID, err = idArg(p.Args, "assigneeId"); err != nil {
+						return nil, err
+					}
+					change.Comment, _ = p.Args["comment"].(string)
+
+					issue, err := issues.ChangeStatus(p.Context, *id, change)
+					if err != nil {
+						return nil, resolverError(err)
+					}
+					loadersFrom(p.Context).reset()
+					return *issue, nil
+				},
+			},
+			"addComment": {
+				Type: graphql.NewNonNull(commentType),
+				Args: graphql.FieldConfigArgument{
+					"issueId": {Type: graphql.NewNonNull(graphql.Int)},
+					"userId":  {Type: graphql.NewNonNull(graphql.Int)},
+					"content": {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					issueID, err := idArg(p.Args, "issueId")
+					if err != nil {
+						return nil, err
+					}
+					userID, err := idArg(p.Args, "userId")
+					if err != nil {
+						return nil, err
+					}
+					content, _ := p.Args["content"].(string)
+
+					comment, err := issues.Comment(p.Context, *issueID, *userID, content)
+					if err != nil {
+						return nil, resolverError(err)
+					}
+					loadersFrom(p.Context).reset()
+					return *comment, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
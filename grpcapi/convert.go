@@ -0,0 +1,157 @@
+package grpcapi
+
+import (
+	"time"
+
+	"issue-tracking/entities"
+	pb "issue-tracking/proto/issuetracking/v1"
+	"issue-tracking/services"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// eventTypes maps the service events to their proto type
+var eventTypes = map[services.EventType]pb.IssueEventType{
+	services.EventIssueCreated:       pb.IssueEventType_ISSUE_EVENT_TYPE_CREATED,
+	services.EventIssueStatusChanged: pb.IssueEventType_ISSUE_EVENT_TYPE_STATUS_CHANGED,
+	services.EventIssueCommented:     pb.IssueEventType_ISSUE_EVENT_TYPE_COMMENTED,
+	services.EventIssueUpdated:       pb.IssueEventType_ISSUE_EVENT_TYPE_UPDATED,
+	services.EventIssueDeleted:       pb.IssueEventType_ISSUE_EVENT_TYPE_DELETED,
+}
+
+// timestamp converts a time, leaving unset times empty
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func userMessage(u *entities.User) *pb.User {
+	if u == nil || u.UserID == 0 {
+		return nil
+	}
+	return &pb.User{
+		UserId:    uint32(u.UserID),
+		FullName:  u.FullName,
+		CreatedAt: timestamp(u.CreatedAt),
+		UpdatedAt: timestamp(u.UpdatedAt),
+	}
+}
+
+func officerMessage(o *entities.Officer) *pb.Officer {
+	if o == nil || o.OfficerID == 0 {
+		return nil
+	}
+	return &pb.Officer{
+		OfficerId: uint32(o.OfficerID),
+		FullName:  o.FullName,
+		CreatedAt: timestamp(o.CreatedAt),
+		UpdatedAt: timestamp(o.UpdatedAt),
+	}
+}
+
+func statusMessage(s *entities.IssueStatus) *pb.IssueStatus {
+	if s == nil || s.StatusID == 0 {
+		return nil
+	}
+	return &pb.IssueStatus{
+		StatusId:     uint32(s.StatusID),
+		StatusCode:   s.StatusCode,
+		DisplayName:  s.DisplayName,
+		Description:  s.Description,
+		Color:        s.Color,
+		DisplayOrder: int32(s.DisplayOrder),
+		IsActive:     s.IsActive,
+		IsTerminal:   s.IsTerminal,
+		CreatedAt:    timestamp(s.CreatedAt),
+	}
+}
+
+func labelMessage(l *entities.Label) *pb.Label {
+	return &pb.Label{
+		LabelId:   uint32(l.LabelID),
+		Name:      l.Name,
+		Color:     l.Color,
+		CreatedAt: timestamp(l.CreatedAt),
+	}
+}
+
+func historyMessage(h *entities.IssueStatusHistory) *pb.StatusChange {
+	change := &pb.StatusChange{
+		HistoryId:   uint32(h.HistoryID),
+		IssueId:     uint32(h.IssueID),
+		NewStatusId: uint32(h.NewStatusID),
+		ChangedBy:   uint32(h.ChangedBy),
+		Comment:     h.Comment,
+		ChangedAt:   timestamp(h.ChangedAt),
+	}
+	if h.OldStatusID != nil {
+		old := uint32(*h.OldStatusID)
+		change.OldStatusId = &old
+	}
+	return change
+}
+
+func issueMessage(i *entities.Issue) *pb.Issue {
+	if i == nil || i.IssueID == 0 {
+		return nil
+	}
+	issue := &pb.Issue{
+		IssueId:     uint32(i.IssueID),
+		ReporterId:  uint32(i.ReporterID),
+		StatusId:    uint32(i.StatusID),
+		Title:       i.Title,
+		Description: i.Description,
+		Priority:    i.Priority,
+		CreatedAt:   timestamp(i.CreatedAt),
+		UpdatedAt:   timestamp(i.UpdatedAt),
+		Reporter:    userMessage(&i.Reporter),
+		Assignee:    officerMessage(i.Assignee),
+		Status:      statusMessage(&i.Status),
+		Source:      i.Source,
+	}
+	if i.AssigneeID != nil {
+		assignee := uint32(*i.AssigneeID)
+		issue.AssigneeId = &assignee
+	}
+	if i.APIKeyID != nil {
+		key := uint32(*i.APIKeyID)
+		issue.ApiKeyId = &key
+	}
+	for j := range i.StatusHistory {
+		issue.StatusHistory = append(issue.StatusHistory, historyMessage(&i.StatusHistory[j]))
+	}
+	for j := range i.Comments {
+		issue.Comments = append(issue.Comments, commentMessage(&i.Comments[j]))
+	}
+	for j := range i.Labels {
+		issue.Labels = append(issue.Labels, labelMessage(&i.Labels[j]))
+	}
+	return issue
+}
+
+func commentMessage(c *entities.Comment) *pb.Comment {
+	if c == nil {
+		return nil
+	}
+	return &pb.Comment{
+		CommentId: uint32(c.CommentID),
+		IssueId:   uint32(c.IssueID),
+		UserId:    uint32(c.UserID),
+		Content:   c.Content,
+		CreatedAt: timestamp(c.CreatedAt),
+		Issue:     issueMessage(&c.Issue),
+		User:      userMessage(&c.User),
+	}
+}
+
+func eventMessage(e services.Event) *pb.IssueEvent {
+	return &pb.IssueEvent{
+		Type:       eventTypes[e.Type],
+		IssueId:    uint32(e.IssueID),
+		OccurredAt: timestamp(e.OccurredAt),
+		Issue:      issueMessage(e.Issue),
+		Comment:    commentMessage(e.Comment),
+	}
+}
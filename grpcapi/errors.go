@@ -0,0 +1,57 @@
+package grpcapi
+
+import (
+	"errors"
+	"net/http"
+
+	"issue-tracking/services"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusCodes maps the HTTP statuses of service errors to the gRPC code that
+// grpc-gateway translates back to the same status
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.Aborted,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+	http.StatusInternalServerError: codes.Internal,
+}
+
+// grpcCode returns the gRPC code for an HTTP status, Internal when it has none
+func grpcCode(httpStatus int) codes.Code {
+	if code, ok := statusCodes[httpStatus]; ok {
+		return code
+	}
+	return codes.Internal
+}
+
+// statusError converts an error of the service layer to a gRPC status. The
+// message is the REST message followed by its details when they are text;
+// failed validation rules are attached as a BadRequest detail.
+func statusError(err error) error {
+	var serviceErr *services.Error
+	if !errors.As(err, &serviceErr) {
+		return status.Error(codes.Internal, "Internal server error")
+	}
+
+	st := status.New(grpcCode(serviceErr.Status), serviceErr.Error())
+	if fields := serviceErr.ValidationErrors(); len(fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(fields))
+		for i, field := range fields {
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message}
+		}
+		if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
@@ -0,0 +1,216 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"issue-tracking/auth"
+	"issue-tracking/logging"
+	"issue-tracking/middlewares"
+	"issue-tracking/services"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// requestIDKey is the metadata key of logging.RequestIDHeader
+var requestIDKey = strings.ToLower(logging.RequestIDHeader)
+
+// logUnary writes one log line per call, like the HTTP access log
+func logUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx = withRequestID(ctx)
+	resp, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+// logStream writes one log line per stream once it ends
+func logStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx := withRequestID(ss.Context())
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	logCall(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// recoverUnary answers a panicking call with Internal
+func recoverUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = panicked(ctx, recovered)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// recoverStream ends a panicking stream with Internal
+func recoverStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = panicked(ss.Context(), recovered)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func panicked(ctx context.Context, recovered interface{}) error {
+	slog.ErrorContext(ctx, "panic recovered",
+		slog.Any("panic", recovered),
+		slog.String("stack", string(debug.Stack())),
+	)
+	return status.Error(codes.Internal, "Internal Server Error")
+}
+
+// withRequestID stores the x-request-id sent by the client, or a new ID, in
+// ctx and returns it to the client as a header
+func withRequestID(ctx context.Context) context.Context {
+	var id string
+	if ids := metadata.ValueFromIncomingContext(ctx, requestIDKey); len(ids) > 0 {
+		id = ids[0]
+	}
+	ctx, id = logging.AssignRequestID(ctx, id)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	return ctx
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK, codes.Canceled:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+	slog.Log(ctx, level, "rpc",
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+	)
+}
+
+// contextStream overrides the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// authUnary authenticates the API key sent in the authorization or
+// x-api-key metadata like the REST API does; calls without one pass with
+// the anonymous scopes, or the default ones when anonymous is nil
+func authUnary(keys *services.APIKeyService, anonymous []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, keys, anonymous)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authStream authenticates the API key of a stream like authUnary
+func authStream(keys *services.APIKeyService, anonymous []string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), keys, anonymous)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate returns ctx carrying the API key of the call, if any
+func authenticate(ctx context.Context, keys *services.APIKeyService, anonymous []string) (context.Context, error) {
+	if anonymous != nil {
+		ctx = auth.WithAnonymousScopes(ctx, anonymous)
+	}
+	var key string
+	if values := metadata.ValueFromIncomingContext(ctx, strings.ToLower(auth.APIKeyHeader)); len(values) > 0 {
+		key = strings.TrimSpace(values[0])
+	} else if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+		scheme, token, ok := strings.Cut(values[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") && strings.HasPrefix(token, auth.APIKeyPrefix) {
+			key = strings.TrimSpace(token)
+		}
+	}
+	if key == "" {
+		return ctx, nil
+	}
+
+	record, err := keys.Authenticate(ctx, key)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return auth.WithAPIKey(ctx, record), nil
+}
+
+// rateLimitUnary answers ResourceExhausted once the client of a call has
+// used up its budget, with a RetryInfo detail saying when to call again
+func rateLimitUnary(limiter *middlewares.RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := limitRate(ctx, limiter, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// rateLimitStream counts opening a stream as one call, like rateLimitUnary
+func rateLimitStream(limiter *middlewares.RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := limitRate(ss.Context(), limiter, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// limitRate counts a call to method against its client: the API key of the
+// call, else the address of the peer. Calls pass when the buckets cannot be
+// reached.
+func limitRate(ctx context.Context, limiter *middlewares.RateLimiter, method string) error {
+	r, mapped := rules[method]
+	read := !mapped || r.method == http.MethodGet
+
+	var client string
+	if key := auth.APIKeyFrom(ctx); key != nil {
+		client = fmt.Sprintf("key:%d", key.APIKeyID)
+	} else if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		client = "ip:" + host
+	}
+
+	decision, err := limiter.Take(ctx, read, client)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to check rate limit", slog.String("client", client), slog.Any("error", err))
+		return nil
+	}
+	if decision.Allowed {
+		return nil
+	}
+	st := status.New(codes.ResourceExhausted, "Too many requests: "+decision.Reason())
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(decision.RetryAfter())}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,152 @@
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"issue-tracking/openapi"
+	pb "issue-tracking/proto/issuetracking/v1"
+	"issue-tracking/services"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// rule is the HTTP mapping of an RPC from its google.api.http option
+type rule struct {
+	method string
+	path   string
+	body   bool
+}
+
+// pathVariable matches a {field} of an HTTP rule path
+var pathVariable = regexp.MustCompile(`\{([^}]+)\}`)
+
+// rules maps the full method names of the IssueService RPCs to their HTTP
+// rule; the event stream has none
+var rules = func() map[string]rule {
+	service := pb.File_issuetracking_v1_issue_tracking_proto.Services().ByName("IssueService")
+	methods := service.Methods()
+	mapped := map[string]rule{}
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		option, ok := proto.GetExtension(method.Options().(*descriptorpb.MethodOptions), annotations.E_Http).(*annotations.HttpRule)
+		if !ok || option == nil {
+			continue
+		}
+		r := rule{body: option.GetBody() == "*"}
+		switch {
+		case option.GetGet() != "":
+			r.method, r.path = http.MethodGet, option.GetGet()
+		case option.GetPost() != "":
+			r.method, r.path = http.MethodPost, option.GetPost()
+		case option.GetPatch() != "":
+			r.method, r.path = http.MethodPatch, option.GetPatch()
+		case option.GetPut() != "":
+			r.method, r.path = http.MethodPut, option.GetPut()
+		case option.GetDelete() != "":
+			r.method, r.path = http.MethodDelete, option.GetDelete()
+		}
+		mapped[fmt.Sprintf("/%s/%s", service.FullName(), method.Name())] = r
+	}
+	return mapped
+}()
+
+// fields returns the populated fields of msg by proto name, as grpc-gateway
+// reads them from a request: numbers are json.Number and unset optional
+// fields are absent
+func fields(msg proto.Message) (map[string]interface{}, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	values := map[string]interface{}{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// query encodes fields as query parameters
+func query(fields map[string]interface{}) url.Values {
+	values := url.Values{}
+	for name, value := range fields {
+		if list, ok := value.([]interface{}); ok {
+			for _, item := range list {
+				values.Add(name, fmt.Sprint(item))
+			}
+			continue
+		}
+		values.Set(name, fmt.Sprint(value))
+	}
+	return values
+}
+
+// request builds the HTTP request r maps msg to: path variables are taken
+// from their fields and the other fields form the JSON body or, for rules
+// without a body, the query
+func (r rule) request(ctx context.Context, msg proto.Message) (*http.Request, error) {
+	values, err := fields(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	path := pathVariable.ReplaceAllStringFunc(r.path, func(variable string) string {
+		name := variable[1 : len(variable)-1]
+		value, ok := values[name]
+		delete(values, name)
+		if !ok {
+			value = 0
+		}
+		return url.PathEscape(fmt.Sprint(value))
+	})
+
+	if !r.body {
+		target := path
+		if len(values) > 0 {
+			target += "?" + query(values).Encode()
+		}
+		return http.NewRequestWithContext(ctx, r.method, target, nil)
+	}
+	body, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// validateUnary checks each call against the OpenAPI document as the REST
+// request its HTTP rule maps it to, so it is rejected with the same errors
+func validateUnary(spec *openapi.Spec) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		r, ok := rules[info.FullMethod]
+		msg, isProto := req.(proto.Message)
+		if !ok || !isProto || spec == nil {
+			return handler(ctx, req)
+		}
+
+		httpReq, err := r.request(ctx, msg)
+		if err != nil {
+			return nil, statusError(err)
+		}
+		if problem := spec.Check(httpReq); problem != nil {
+			return nil, statusError(&services.Error{Status: problem.Status, Message: problem.Message, Details: problem.Details})
+		}
+		return handler(ctx, req)
+	}
+}
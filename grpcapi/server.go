@@ -0,0 +1,270 @@
+// Package grpcapi serves the gRPC API defined in proto/issuetracking/v1. It
+// makes changes through the same issue service as the REST controllers and
+// reports the same errors, with the gRPC code matching their HTTP status.
+package grpcapi
+
+import (
+	"context"
+	"sync"
+
+	"issue-tracking/auth"
+	"issue-tracking/controllers"
+	"issue-tracking/entities"
+	"issue-tracking/middlewares"
+	"issue-tracking/openapi"
+	pb "issue-tracking/proto/issuetracking/v1"
+	"issue-tracking/repositories"
+	"issue-tracking/services"
+
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the IssueService RPCs
+type Server struct {
+	pb.UnimplementedIssueServiceServer
+
+	store  repositories.Store
+	issues *services.IssueService
+	keys   *services.APIKeyService
+	spec   *openapi.Spec
+	health *health.Server
+	// limiter throttles calls when set
+	limiter *middlewares.RateLimiter
+	// anonymous are the scopes of calls without an API key, nil for
+	// auth.DefaultAnonymousScopes
+	anonymous []string
+
+	draining  chan struct{}
+	drainOnce sync.Once
+}
+
+// NewServer creates the RPC handlers reading from store and making changes
+// through issues. Requests are validated against spec, the OpenAPI document
+// of the REST API, as the requests their HTTP rules map them to.
+func NewServer(store repositories.Store, issues *services.IssueService, spec *openapi.Spec) *Server {
+	return &Server{
+		store:    store,
+		issues:   issues,
+		keys:     services.NewAPIKeyService(store),
+		spec:     spec,
+		health:   health.NewServer(),
+		draining: make(chan struct{}),
+	}
+}
+
+// LimitRate counts calls against the budgets of limiter, like requests to
+// the REST API. Reads are the RPCs mapped to GET and the event stream.
+func (s *Server) LimitRate(limiter *middlewares.RateLimiter) {
+	s.limiter = limiter
+}
+
+// AnonymousScopes grants scopes to calls without an API key instead of
+// auth.DefaultAnonymousScopes, like the REST API
+func (s *Server) AnonymousScopes(scopes []string) {
+	s.anonymous = scopes
+}
+
+// NewGRPCServer creates a gRPC server with the IssueService of api, the
+// standard health service and reflection for tools such as grpcurl. Calls
+// are logged, panics are answered with Internal, API keys are authenticated,
+// clients are throttled when api limits the rate and requests are validated
+// before they reach the handlers.
+func NewGRPCServer(api *Server, opts ...grpc.ServerOption) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{logUnary, recoverUnary, authUnary(api.keys, api.anonymous)}
+	stream := []grpc.StreamServerInterceptor{logStream, recoverStream, authStream(api.keys, api.anonymous)}
+	if api.limiter != nil {
+		unary = append(unary, rateLimitUnary(api.limiter))
+		stream = append(stream, rateLimitStream(api.limiter))
+	}
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(append(unary, validateUnary(api.spec))...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	server := grpc.NewServer(opts...)
+	pb.RegisterIssueServiceServer(server, api)
+	healthpb.RegisterHealthServer(server, api.health)
+	reflection.Register(server)
+	return server
+}
+
+// Drain reports the server as not serving and ends the event streams with
+// Unavailable, so a graceful stop does not wait for watchers to hang up
+func (s *Server) Drain() {
+	s.drainOnce.Do(func() {
+		s.health.Shutdown()
+		close(s.draining)
+	})
+}
+
+// ListIssues lists issues like GET /api/issues; the request fields are
+// read as its query parameters
+func (s *Server) ListIssues(ctx context.Context, req *pb.ListIssuesRequest) (*pb.ListIssuesResponse, error) {
+	values, err := fields(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	filter, err := controllers.ParseIssueQuery(query(values))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	issues, err := s.issues.List(ctx, filter)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &pb.ListIssuesResponse{Issues: make([]*pb.Issue, len(issues))}
+	for i := range issues {
+		resp.Issues[i] = issueMessage(&issues[i])
+	}
+	return resp, nil
+}
+
+// GetIssue returns one issue like GET /api/issues/:id
+func (s *Server) GetIssue(ctx context.Context, req *pb.GetIssueRequest) (*pb.Issue, error) {
+	issue, err := s.issues.Get(ctx, uint(req.GetIssueId()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return issueMessage(issue), nil
+}
+
+// CreateIssue opens an issue like POST /api/issues
+func (s *Server) CreateIssue(ctx context.Context, req *pb.CreateIssueRequest) (*pb.Issue, error) {
+	issue := entities.Issue{
+		ReporterID:  uint(req.GetReporterId()),
+		AssigneeID:  optionalID(req.AssigneeId),
+		StatusID:    uint(req.GetStatusId()),
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		Priority:    req.GetPriority(),
+	}
+	created, err := s.issues.Create(ctx, issue)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return issueMessage(created), nil
+}
+
+// UpdateIssueStatus transitions an issue like PATCH /api/issues/:id/status
+func (s *Server) UpdateIssueStatus(ctx context.Context, req *pb.UpdateIssueStatusRequest) (*pb.Issue, error) {
+	body := controllers.StatusUpdateRequest{
+		NewStatusID: uint(req.GetNewStatusId()),
+		AssigneeID:  optionalID(req.AssigneeId),
+		Comment:     req.GetComment(),
+	}
+	if err := binding.Validator.ValidateStruct(body); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid request body: %v", err)
+	}
+
+	issue, err := s.issues.ChangeStatus(ctx, uint(req.GetIssueId()), services.StatusChange{
+		NewStatusID: body.NewStatusID,
+		AssigneeID:  body.AssigneeID,
+		Comment:     body.Comment,
+		ChangedBy:   1, // Default to officer ID 1 as the REST API does
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return issueMessage(issue), nil
+}
+
+// CreateComment comments on an issue like POST /api/issues/:id/comment
+func (s *Server) CreateComment(ctx context.Context, req *pb.CreateCommentRequest) (*pb.Comment, error) {
+	body := controllers.CommentRequest{UserID: uint(req.GetUserId()), Content: req.GetContent()}
+	if err := binding.Validator.ValidateStruct(body); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid request body: %v", err)
+	}
+
+	comment, err := s.issues.Comment(ctx, uint(req.GetIssueId()), body.UserID, body.Content)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return commentMessage(comment), nil
+}
+
+// ListStatuses lists the active statuses like GET /api/statuses
+func (s *Server) ListStatuses(ctx context.Context, _ *pb.ListStatusesRequest) (*pb.ListStatusesResponse, error) {
+	statuses, err := s.store.Statuses().List(ctx, true)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Failed to fetch statuses")
+	}
+
+	resp := &pb.ListStatusesResponse{Statuses: make([]*pb.IssueStatus, len(statuses))}
+	for i := range statuses {
+		resp.Statuses[i] = statusMessage(&statuses[i])
+	}
+	return resp, nil
+}
+
+// ListOfficers lists the officers like GET /api/officers
+func (s *Server) ListOfficers(ctx context.Context, _ *pb.ListOfficersRequest) (*pb.ListOfficersResponse, error) {
+	officers, err := s.store.Officers().List(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Failed to fetch officers")
+	}
+
+	resp := &pb.ListOfficersResponse{Officers: make([]*pb.Officer, len(officers))}
+	for i := range officers {
+		resp.Officers[i] = officerMessage(&officers[i])
+	}
+	return resp, nil
+}
+
+// WatchIssueEvents streams the changes made through the issue service until
+// the client cancels. The response headers are sent once the subscription
+// is in place, so a client that has received them will not miss a change.
+// A client too slow to keep up gets ResourceExhausted and must watch again.
+func (s *Server) WatchIssueEvents(req *pb.WatchIssueEventsRequest, stream grpc.ServerStreamingServer[pb.IssueEvent]) error {
+	if ctx := stream.Context(); !auth.Allowed(ctx, auth.ScopeIssuesRead) {
+		if auth.Anonymous(ctx) {
+			return status.Error(codes.Unauthenticated, "Unauthorized: "+auth.Denial(ctx, auth.ScopeIssuesRead))
+		}
+		return status.Error(codes.PermissionDenied, "Forbidden: "+auth.Denial(ctx, auth.ScopeIssuesRead))
+	}
+
+	watched := map[uint]bool{}
+	for _, id := range req.GetIssueIds() {
+		watched[uint(id)] = true
+	}
+
+	events, stop := s.issues.Events().Subscribe()
+	defer stop()
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-s.draining:
+			return status.Error(codes.Unavailable, "server is shutting down, watch again")
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "event stream fell behind, watch again")
+			}
+			if len(watched) > 0 && !watched[event.IssueID] {
+				continue
+			}
+			if err := stream.Send(eventMessage(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// optionalID converts an optional proto ID
+func optionalID(id *uint32) *uint {
+	if id == nil {
+		return nil
+	}
+	v := uint(*id)
+	return &v
+}
@@ -0,0 +1,480 @@
+package grpcapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"issue-tracking/auth"
+	"issue-tracking/config"
+	"issue-tracking/database/dbtest"
+	"issue-tracking/entities"
+	"issue-tracking/grpcapi"
+	pb "issue-tracking/proto/issuetracking/v1"
+	"issue-tracking/repositories"
+	"issue-tracking/routes"
+	"issue-tracking/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// env is the REST router and a gRPC client sharing one store and service
+type env struct {
+	router *gin.Engine
+	client pb.IssueServiceClient
+	api    *grpcapi.Server
+	store  repositories.Store
+}
+
+// newEnv serves both APIs over store, which holds two statuses, a user, an
+// officer and issue 1
+func newEnv(t *testing.T, store repositories.Store) *env {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	for i, code := range []string{"open", "closed"} {
+		status := entities.IssueStatus{StatusCode: code, DisplayName: code, Color: "#2196F3", DisplayOrder: i, IsActive: true}
+		if err := store.Statuses().Create(ctx, &status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Users().Create(ctx, &entities.User{FullName: "John Doe"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Officers().Create(ctx, &entities.Officer{FullName: "Jane Smith"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Issues().Create(ctx, &entities.Issue{ReporterID: 1, StatusID: 1, Title: "Login page fails", Priority: "high"}); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := services.NewIssueService(store)
+	router := gin.New()
+	spec := routes.RegisterRoutes(router, store, issues, config.Default())
+
+	api := grpcapi.NewServer(store, issues, spec)
+	return &env{router: router, client: dial(t, grpcapi.NewGRPCServer(api)), api: api, store: store}
+}
+
+// dial serves server in memory until the test ends and returns a client
+func dial(t *testing.T, server *grpc.Server) pb.IssueServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewIssueServiceClient(conn)
+}
+
+// rest sends one request to the REST API and returns its status and, for
+// errors, the message followed by the details when they are text
+func (e *env) rest(t *testing.T, method, path, body string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.router.ServeHTTP(w, req)
+
+	var resp struct {
+		Message string      `json:"message"`
+		Details interface{} `json:"details"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	if details, ok := resp.Details.(string); ok && details != "" {
+		return w.Code, resp.Message + ": " + details
+	}
+	return w.Code, resp.Message
+}
+
+// TestHTTPRules checks that every RPC but the event stream is annotated with
+// the REST route it mirrors, in a form grpc-gateway can serve
+func TestHTTPRules(t *testing.T) {
+	router := gin.New()
+	store := repositories.NewMemoryStore()
+	routes.RegisterRoutes(router, store, services.NewIssueService(store), config.Default())
+	registered := map[string]bool{}
+	param := regexp.MustCompile(`:[^/]+`)
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+param.ReplaceAllString(route.Path, "{}")] = true
+	}
+
+	variable := regexp.MustCompile(`\{([^}]+)\}`)
+	methods := pb.File_issuetracking_v1_issue_tracking_proto.Services().ByName("IssueService").Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		rule, _ := proto.GetExtension(method.Options().(*descriptorpb.MethodOptions), annotations.E_Http).(*annotations.HttpRule)
+		if method.IsStreamingServer() {
+			if rule != nil {
+				t.Errorf("%s: streaming RPC has an HTTP rule", method.Name())
+			}
+			continue
+		}
+		if rule == nil {
+			t.Errorf("%s: no HTTP rule", method.Name())
+			continue
+		}
+
+		var verb, path string
+		switch pattern := rule.Pattern.(type) {
+		case *annotations.HttpRule_Get:
+			verb, path = http.MethodGet, pattern.Get
+		case *annotations.HttpRule_Post:
+			verb, path = http.MethodPost, pattern.Post
+		case *annotations.HttpRule_Patch:
+			verb, path = http.MethodPatch, pattern.Patch
+		default:
+			t.Errorf("%s: unexpected pattern %T", method.Name(), rule.Pattern)
+			continue
+		}
+		if !registered[verb+" "+variable.ReplaceAllString(path, "{}")] {
+			t.Errorf("%s: %s %s is not a REST route", method.Name(), verb, path)
+		}
+		for _, match := range variable.FindAllStringSubmatch(path, -1) {
+			if method.Input().Fields().ByName(protoreflect.Name(match[1])) == nil {
+				t.Errorf("%s: path variable %s is not a request field", method.Name(), match[1])
+			}
+		}
+		if rule.Body != "" && rule.Body != "*" {
+			t.Errorf("%s: body %q, want the whole request", method.Name(), rule.Body)
+		}
+		if rule.ResponseBody != "" && method.Output().Fields().ByName(protoreflect.Name(rule.ResponseBody)) == nil {
+			t.Errorf("%s: response_body %s is not a response field", method.Name(), rule.ResponseBody)
+		}
+	}
+}
+
+// TestSameErrorsAsREST makes each request over both APIs and checks that
+// the gRPC code maps back to the REST status and the messages match
+func TestSameErrorsAsREST(t *testing.T) {
+	backends := map[string]func(*testing.T) repositories.Store{
+		"memory": func(*testing.T) repositories.Store { return repositories.NewMemoryStore() },
+	}
+	for _, driver := range dbtest.Drivers() {
+		driver := driver
+		backends[driver] = func(t *testing.T) repositories.Store { return repositories.NewGormStore(dbtest.Open(t, driver)) }
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		call   func(context.Context, pb.IssueServiceClient) error
+	}{
+		{
+			name: "get", method: http.MethodGet, path: "/api/issues/1",
+			call: func(ctx context.Context, c pb.IssueServiceClient) error {
+				_, err := c.GetIssue(ctx, &pb.GetIssueRequest{IssueId: 1})
+				return err
+			},
+		},
+		{
+			name: "get unknown", method: http.MethodGet, path: "/api/issues/99",
+			call: func(ctx context.Context, c pb.IssueServiceClient) error {
+				_, err := c.GetIssue(ctx, &pb.GetIssueRequest{IssueId: 99})
+				return err
+			},
+		},
+		{
+			name: "list bad priority", method: http.MethodGet, path: "/api/issues?priority=urgent",
+			call: func(ctx context.Context, c pb.IssueServiceClient) error {
+				_, err := c.ListIssues(ctx, &pb.ListIssuesRequest{Priority: "urgent"})
+				return err
+			},
+		},
+		{
+			name: "list bad limit", method: http.MethodGet, path: "/api/issues?limit=1000",
+			call: func(ctx context.Context, c pb.IssueServiceClient) error {
+				_, err := c.ListIssues(ctx, &pb.ListIssuesRequest{Limit: 1000})
+				return err
+			},
+		},
+		{
+			name: "create unknown reporter", method: http.MethodPost, path: "/api/issues",
+			body: `{"reporter_id":9,"status_id":1,"title":"Printer jam","priority":"low"}`,
+			call: func(ctx context.Context, c pb.IssueServiceClient) error {
+				_, err := c.CreateIssue(ctx, &pb.CreateIssueRequest{ReporterId: 9, StatusId: 1, Title: "Printer jam", Priority: "low"})
+				return err
+			},
+		},
+		{
+			name: "create invalid", method: http.MethodPost, path: "/api/issues",
+			body: `{"reporter_id":1,"status_id":1,"title":"No","priority":"urgent"}`,
+			call: func(ctx context.Context, c pb.IssueServiceClient) error {
+				_, err := c.CreateIssue(ctx, &pb.CreateIssueRequest{ReporterId: 1, StatusId: 1, Title: "No", Priority: "urgent"})
+				return err
+			},
+		},
+		{
+			name: "transition without status", method: http.MethodPatch, path: "/api/issues/1/status",
+			body: `{"comment":"done"}`,
+			call: func(ctx context.Context, c pb.IssueServiceClient) error {
+				_, err := c.UpdateIssueStatus(ctx, &pb.UpdateIssueStatusRequest{IssueId: 1, Comment: "done"})
+				return err
+			},
+		},
+		{
+			name: "transition to unknown status", method: http.MethodPatch, path: "/api/issues/1/status",
+			body: `{"new_status_id":9}`,
+			call: func(ctx context.Context, c pb.IssueServiceClient) error {
+				_, err := c.UpdateIssueStatus(ctx, &pb.UpdateIssueStatusRequest{IssueId: 1, NewStatusId: 9})
+				return err
+			},
+		},
+		{
+			name: "transition unknown issue", method: http.MethodPatch, path: "/api/issues/99/status",
+			body: `{"new_status_id":2}`,
+			call: func(ctx context.Context, c pb.IssueServiceClient) error {
+				_, err := c.UpdateIssueStatus(ctx, &pb.UpdateIssueStatusRequest{IssueId: 99, NewStatusId: 2})
+				return err
+			},
+		},
+		{
+			name: "comment by unknown user", method: http.MethodPost, path: "/api/issues/1/comment",
+			body: `{"user_id":9,"content":"Any update?"}`,
+			call: func(ctx context.Context, c pb.IssueServiceClient) error {
+				_, err := c.CreateComment(ctx, &pb.CreateCommentRequest{IssueId: 1, UserId: 9, Content: "Any update?"})
+				return err
+			},
+		},
+		{
+			name: "comment without content", method: http.MethodPost, path: "/api/issues/1/comment",
+			body: `{"user_id":1}`,
+			call: func(ctx context.Context, c pb.IssueServiceClient) error {
+				_, err := c.CreateComment(ctx, &pb.CreateCommentRequest{IssueId: 1, UserId: 1})
+				return err
+			},
+		},
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			e := newEnv(t, open(t))
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					code, message := e.rest(t, tt.method, tt.path, tt.body)
+					err := tt.call(context.Background(), e.client)
+					st := status.Convert(err)
+					if got := runtime.HTTPStatusFromCode(st.Code()); got != code {
+						t.Errorf("gRPC %s maps to %d, REST answered %d", st.Code(), got, code)
+					}
+					if err != nil && st.Message() != message {
+						t.Errorf("gRPC message %q, REST message %q", st.Message(), message)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestValidationDetails(t *testing.T) {
+	e := newEnv(t, repositories.NewMemoryStore())
+	_, err := e.client.CreateIssue(context.Background(), &pb.CreateIssueRequest{ReporterId: 1, StatusId: 1, Title: "No", Priority: "low"})
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || st.Message() != "Validation failed" {
+		t.Fatalf("status = %v", st)
+	}
+	var fields []string
+	for _, detail := range st.Details() {
+		if bad, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range bad.FieldViolations {
+				fields = append(fields, violation.Field)
+			}
+		}
+	}
+	if len(fields) != 1 || fields[0] != "title" {
+		t.Errorf("field violations = %v, want [title]", fields)
+	}
+}
+
+// TestAPIKeys sends API keys as metadata to a server open to no one
+// without a key: issues created with one record the integration, and scopes
+// and revocation apply as over REST
+func TestAPIKeys(t *testing.T) {
+	e := newEnv(t, repositories.NewMemoryStore())
+	e.api.AnonymousScopes([]string{})
+	e.client = dial(t, grpcapi.NewGRPCServer(e.api))
+	keys := services.NewAPIKeyService(e.store)
+	ctx := context.Background()
+	created, err := keys.Create(ctx, services.NewAPIKey{Name: "Monitoring", Scopes: []string{auth.ScopeIssuesWrite}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withKey := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+created.Key)
+
+	issue, err := e.client.CreateIssue(withKey, &pb.CreateIssueRequest{ReporterId: 1, StatusId: 1, Title: "Disk almost full", Priority: "high"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.GetSource() != entities.IssueSourceIntegration || issue.GetApiKeyId() != uint32(created.APIKeyID) {
+		t.Errorf("source = %q, api_key_id = %d", issue.GetSource(), issue.GetApiKeyId())
+	}
+
+	if _, err := e.client.GetIssue(withKey, &pb.GetIssueRequest{IssueId: 1}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("GetIssue without scope = %v, want PermissionDenied", err)
+	}
+	if _, err := e.client.GetIssue(ctx, &pb.GetIssueRequest{IssueId: 1}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetIssue without a key = %v, want Unauthenticated", err)
+	}
+	stream, err := e.client.WatchIssueEvents(withKey, &pb.WatchIssueEventsRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("WatchIssueEvents without scope = %v, want PermissionDenied", err)
+	}
+
+	if _, err := keys.Revoke(ctx, created.APIKeyID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.client.ListStatuses(withKey, &pb.ListStatusesRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("call with revoked key = %v, want Unauthenticated", err)
+	}
+}
+
+// TestRateLimit checks calls count against the REST budgets: writes run
+// out separately from reads, and each API key has a budget of its own
+func TestRateLimit(t *testing.T) {
+	e := newEnv(t, repositories.NewMemoryStore())
+	cfg := config.Default()
+	cfg.RateLimit.Enabled = true
+	cfg.RateLimit.WritePerMinute = 1
+	cfg.RateLimit.WriteBurst = 1
+	e.api.LimitRate(routes.NewRateLimiter(e.store, cfg))
+	client := dial(t, grpcapi.NewGRPCServer(e.api))
+	ctx := context.Background()
+
+	create := &pb.CreateIssueRequest{ReporterId: 1, StatusId: 1, Title: "Printer jam", Priority: "low"}
+	if _, err := client.CreateIssue(ctx, create); err != nil {
+		t.Fatal(err)
+	}
+	_, err := client.CreateIssue(ctx, create)
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted || !strings.HasPrefix(st.Message(), "Too many requests") {
+		t.Fatalf("second write = %v, want ResourceExhausted", st)
+	}
+	var retry *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			retry = info
+		}
+	}
+	if retry == nil || retry.GetRetryDelay().AsDuration() <= 0 {
+		t.Errorf("retry info = %v", retry)
+	}
+
+	if _, err := client.ListStatuses(ctx, &pb.ListStatusesRequest{}); err != nil {
+		t.Errorf("read after writes ran out = %v", err)
+	}
+	created, err := services.NewAPIKeyService(e.store).Create(ctx, services.NewAPIKey{Name: "Monitoring", Scopes: []string{auth.ScopeIssuesWrite}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withKey := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+created.Key)
+	if _, err := client.CreateIssue(withKey, create); err != nil {
+		t.Errorf("write with an API key = %v", err)
+	}
+}
+
+// TestWatchIssueEvents watches while issues change over both APIs and
+// through the bulk and import routes
+func TestWatchIssueEvents(t *testing.T) {
+	e := newEnv(t, repositories.NewMemoryStore())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	all, err := e.client.WatchIssueEvents(ctx, &pb.WatchIssueEventsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := e.client.WatchIssueEvents(ctx, &pb.WatchIssueEventsRequest{IssueIds: []uint32{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The headers arrive once the server has subscribed
+	for _, stream := range []grpc.ServerStreamingClient[pb.IssueEvent]{all, first} {
+		if _, err := stream.Header(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if code, message := e.rest(t, http.MethodPost, "/api/issues", `{"reporter_id":1,"status_id":1,"title":"Printer jam","priority":"low"}`); code != http.StatusCreated {
+		t.Fatalf("create: %d %s", code, message)
+	}
+	if _, err := e.client.UpdateIssueStatus(ctx, &pb.UpdateIssueStatusRequest{IssueId: 1, NewStatusId: 2, Comment: "Fixed"}); err != nil {
+		t.Fatal(err)
+	}
+	if code, message := e.rest(t, http.MethodPost, "/api/issues/1/comment", `{"user_id":1,"content":"Thanks"}`); code != http.StatusCreated {
+		t.Fatalf("comment: %d %s", code, message)
+	}
+	if code, message := e.rest(t, http.MethodPost, "/api/issues/bulk", `{"operation":"set_priority","issue_ids":[1],"priority":"low"}`); code != http.StatusOK {
+		t.Fatalf("bulk: %d %s", code, message)
+	}
+	if code, message := e.rest(t, http.MethodPost, "/api/issues/import?format=jsonl", `{"title":"Imported","status_id":1,"reporter_id":1}`); code != http.StatusCreated {
+		t.Fatalf("import: %d %s", code, message)
+	}
+
+	want := []struct {
+		typ     pb.IssueEventType
+		issueID uint32
+	}{
+		{pb.IssueEventType_ISSUE_EVENT_TYPE_CREATED, 2},
+		{pb.IssueEventType_ISSUE_EVENT_TYPE_STATUS_CHANGED, 1},
+		{pb.IssueEventType_ISSUE_EVENT_TYPE_COMMENTED, 1},
+		{pb.IssueEventType_ISSUE_EVENT_TYPE_UPDATED, 1},
+		{pb.IssueEventType_ISSUE_EVENT_TYPE_CREATED, 3},
+	}
+	for _, w := range want {
+		event, err := all.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Type != w.typ || event.IssueId != w.issueID {
+			t.Errorf("event = %v %d, want %v %d", event.Type, event.IssueId, w.typ, w.issueID)
+		}
+	}
+	for _, w := range want[1:4] {
+		event, err := first.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Type != w.typ || event.IssueId != w.issueID {
+			t.Errorf("watching issue 1: event = %v %d, want %v %d", event.Type, event.IssueId, w.typ, w.issueID)
+		}
+	}
+
+	e.api.Drain()
+	if _, err := all.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("after Drain: %v, want Unavailable", err)
+	}
+}
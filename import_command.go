@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"issue-tracking/importer"
+	"issue-tracking/repositories"
+)
+
+// runImportCommand implements `issue-tracking import [flags] FILE`
+func runImportCommand(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "input format: csv or jsonl (default: from file extension)")
+	dryRun := fs.Bool("dry-run", false, "validate every row and report errors without writing")
+	createUsers := fs.Bool("create-missing-users", false, "create reporters that cannot be found by name")
+	batchSize := fs.Int("batch-size", importer.DefaultBatchSize, "rows committed per transaction")
+	mapping := fs.String("map", "", "column mapping, e.g. \"Summary=title,Owner=assignee\"")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: issue-tracking import [flags] FILE")
+		fs.PrintDefaults()
+	}
+	cfg := loadConfig(fs, args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one input file")
+	}
+	path := fs.Arg(0)
+
+	if *format == "" {
+		*format = importer.FormatCSV
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".jsonl" || ext == ".ndjson" {
+			*format = importer.FormatJSONL
+		}
+	}
+
+	columnMapping, err := importer.ParseMapping(*mapping)
+	if err != nil {
+		return err
+	}
+
+	db, shutdown, err := setup(cfg, os.Stderr, cfg.Database.AutoMigrate)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	im, err := importer.New(repositories.NewGormStore(db), importer.Options{
+		Format:             *format,
+		DryRun:             *dryRun,
+		CreateMissingUsers: *createUsers,
+		BatchSize:          *batchSize,
+		Mapping:            columnMapping,
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	result, runErr := im.Run(context.Background(), f)
+	if result != nil {
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+	}
+	if runErr != nil {
+		return runErr
+	}
+	if result.Failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", result.Failed, result.Total)
+	}
+	return nil
+}
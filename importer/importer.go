@@ -0,0 +1,535 @@
+// Package importer loads issues in bulk from CSV or JSON lines files.
+package importer
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/utils"
+)
+
+// Supported input formats
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// ErrMalformedInput is returned when the file itself cannot be parsed
+var ErrMalformedInput = errors.New("malformed input")
+
+// DefaultBatchSize is the number of issues committed per transaction
+const DefaultBatchSize = 500
+
+// maxReportedRows caps the per-row errors included in a Result
+const maxReportedRows = 1000
+
+// Fields that source columns can be mapped to
+var issueFields = map[string]bool{
+	"title": true, "description": true, "priority": true,
+	"status": true, "status_id": true,
+	"reporter": true, "reporter_id": true,
+	"assignee": true, "assignee_id": true,
+	"created_at": true,
+}
+
+// pendingUserID stands in for a reporter that has not been created yet
+const pendingUserID = ^uint(0)
+
+// resolvedFields maps source fields to the Issue field they resolve, so a
+// failed lookup is not reported a second time as a missing value
+var resolvedFields = map[string]string{
+	"status": "StatusID", "status_id": "StatusID",
+	"reporter": "ReporterID", "reporter_id": "ReporterID",
+	"assignee": "AssigneeID", "assignee_id": "AssigneeID",
+}
+
+// timeLayouts are tried in order when parsing created_at
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// Options controls how an import runs
+type Options struct {
+	Format string
+	// DryRun validates every row and reports errors without writing anything
+	DryRun bool
+	// CreateMissingUsers creates reporters that cannot be found by name
+	CreateMissingUsers bool
+	BatchSize          int
+	// Mapping renames source columns to issue fields, e.g. "Summary" -> "title"
+	Mapping map[string]string
+	// Committed, when set, is called with the issues of each batch once it
+	// is committed
+	Committed func(issues []entities.Issue)
+}
+
+// RowError lists the problems found in one input row
+type RowError struct {
+	Line   int                     `json:"line"`
+	Errors []utils.ValidationError `json:"errors"`
+}
+
+// Result summarizes an import
+type Result struct {
+	DryRun       bool       `json:"dry_run"`
+	Total        int        `json:"total"`
+	Imported     int        `json:"imported"`
+	Failed       int        `json:"failed"`
+	CreatedUsers []string   `json:"created_users,omitempty"`
+	Errors       []RowError `json:"errors"`
+}
+
+// ParseMapping parses "source=field,source=field" into a column mapping
+func ParseMapping(value string) (map[string]string, error) {
+	mapping := map[string]string{}
+	if strings.TrimSpace(value) == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("mapping %q must be source=field", pair)
+		}
+		source, field := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if !issueFields[field] {
+			return nil, fmt.Errorf("mapping %q targets unknown field %q", pair, field)
+		}
+		mapping[source] = field
+	}
+	return mapping, nil
+}
+
+// Importer resolves and writes imported rows
+type Importer struct {
+	store repositories.Store
+	opts  Options
+
+	statuses map[string]uint
+	users    map[string]uint
+	officers map[string]uint
+}
+
+// New creates an importer for the given options
+func New(store repositories.Store, opts Options) (*Importer, error) {
+	if opts.Format == "" {
+		opts.Format = FormatCSV
+	}
+	if opts.Format != FormatCSV && opts.Format != FormatJSONL {
+		return nil, fmt.Errorf("format must be one of: %s %s", FormatCSV, FormatJSONL)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	return &Importer{
+		store:    store,
+		opts:     opts,
+		statuses: map[string]uint{},
+		users:    map[string]uint{},
+		officers: map[string]uint{},
+	}, nil
+}
+
+// pendingRow is a validated issue waiting for its batch to be committed.
+// reporter names a user created together with the batch.
+type pendingRow struct {
+	line     int
+	issue    entities.Issue
+	reporter string
+}
+
+// Run reads every row from r, validates it and commits valid rows in batches.
+// Rows that fail are reported in the result and skipped.
+func (im *Importer) Run(ctx context.Context, r io.Reader) (*Result, error) {
+	result := &Result{DryRun: im.opts.DryRun, Errors: []RowError{}}
+
+	next, err := im.reader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch []pendingRow
+	for {
+		line, record, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("%w at line %d: %v", ErrMalformedInput, line, err)
+		}
+		result.Total++
+
+		issue, reporter, rowErrors := im.buildIssue(ctx, record, result)
+		if len(rowErrors) > 0 {
+			result.addError(line, rowErrors)
+			continue
+		}
+
+		if im.opts.DryRun {
+			result.Imported++
+			continue
+		}
+
+		batch = append(batch, pendingRow{line: line, issue: issue, reporter: reporter})
+		if len(batch) >= im.opts.BatchSize {
+			if err := im.commit(ctx, batch, result); err != nil {
+				return result, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := im.commit(ctx, batch, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// commit writes one batch, together with the reporters it creates, in a
+// single transaction
+func (im *Importer) commit(ctx context.Context, batch []pendingRow, result *Result) error {
+	issues := make([]entities.Issue, len(batch))
+	for i, row := range batch {
+		issues[i] = row.issue
+	}
+
+	var created []entities.User
+	err := im.store.Transaction(ctx, func(tx repositories.Store) error {
+		ids := map[string]uint{}
+		for i, row := range batch {
+			if row.reporter == "" {
+				continue
+			}
+			key := strings.ToLower(row.reporter)
+			if _, ok := ids[key]; !ok {
+				user := entities.User{FullName: row.reporter}
+				if err := tx.Users().Create(ctx, &user); err != nil {
+					return fmt.Errorf("failed to create user %q: %w", row.reporter, err)
+				}
+				ids[key] = user.UserID
+				created = append(created, user)
+			}
+			issues[i].ReporterID = ids[key]
+		}
+		return tx.Issues().CreateBatch(ctx, issues)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import rows %d-%d: %w", batch[0].line, batch[len(batch)-1].line, err)
+	}
+
+	for _, user := range created {
+		im.users[strings.ToLower(user.FullName)] = user.UserID
+		result.CreatedUsers = append(result.CreatedUsers, user.FullName)
+	}
+	result.Imported += len(batch)
+	if im.opts.Committed != nil {
+		im.opts.Committed(issues)
+	}
+	return nil
+}
+
+// reader returns an iterator over the input rows as field maps
+func (im *Importer) reader(r io.Reader) (func() (int, map[string]string, error), error) {
+	if im.opts.Format == FormatJSONL {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		line := 0
+		return func() (int, map[string]string, error) {
+			for scanner.Scan() {
+				line++
+				text := strings.TrimSpace(scanner.Text())
+				if text == "" {
+					continue
+				}
+				// Numbers keep their literal text, so large IDs are not
+				// formatted as floats such as 1e+06
+				decoder := json.NewDecoder(strings.NewReader(text))
+				decoder.UseNumber()
+				var raw map[string]interface{}
+				if err := decoder.Decode(&raw); err != nil {
+					return line, nil, fmt.Errorf("invalid JSON: %w", err)
+				}
+				if _, err := decoder.Token(); err != io.EOF {
+					return line, nil, errors.New("invalid JSON: unexpected data after the object")
+				}
+				record := make(map[string]string, len(raw))
+				for key, value := range raw {
+					if value != nil {
+						record[im.field(key)] = strings.TrimSpace(fmt.Sprint(value))
+					}
+				}
+				return line, record, nil
+			}
+			if err := scanner.Err(); err != nil {
+				return line, nil, err
+			}
+			return line, nil, io.EOF
+		}, nil
+	}
+
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV header: %v", ErrMalformedInput, err)
+	}
+	if len(header) > 0 {
+		// Spreadsheet exports often start with a UTF-8 BOM
+		header[0] = strings.TrimPrefix(header[0], "\xEF\xBB\xBF")
+	}
+	for i := range header {
+		header[i] = im.field(strings.TrimSpace(header[i]))
+	}
+
+	return func() (int, map[string]string, error) {
+		values, err := cr.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return parseErr.StartLine, nil, err
+			}
+			return 0, nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		record := make(map[string]string, len(header))
+		for i, value := range values {
+			if i < len(header) {
+				record[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		return line, record, nil
+	}, nil
+}
+
+// field applies the column mapping to a source column name. Unmapped
+// columns are normalized so export headers such as "Created At" round-trip.
+func (im *Importer) field(column string) string {
+	if mapped, ok := im.opts.Mapping[column]; ok {
+		return mapped
+	}
+	return strings.ReplaceAll(strings.ToLower(column), " ", "_")
+}
+
+// buildIssue resolves names to IDs and validates the row. reporter names a
+// missing user that has to be created before the issue is written.
+func (im *Importer) buildIssue(ctx context.Context, record map[string]string, result *Result) (issue entities.Issue, reporter string, errs []utils.ValidationError) {
+	failed := map[string]bool{}
+	fail := func(field, message string) {
+		errs = append(errs, utils.ValidationError{Field: field, Message: message})
+		failed[resolvedFields[field]] = true
+	}
+
+	issue = entities.Issue{
+		Title:       record["title"],
+		Description: record["description"],
+		Priority:    strings.ToLower(record["priority"]),
+	}
+	if issue.Priority == "" {
+		issue.Priority = "medium"
+	}
+
+	// Status by ID, code or display name
+	if id := record["status_id"]; id != "" {
+		statusID, err := lookupByID(id, func(id uint) error {
+			_, err := im.store.Statuses().Get(ctx, id)
+			return err
+		})
+		if err != nil {
+			fail("status_id", err.Error())
+		}
+		issue.StatusID = statusID
+	} else if name := record["status"]; name != "" {
+		statusID, err := im.lookupStatus(ctx, name)
+		if err != nil {
+			fail("status", err.Error())
+		}
+		issue.StatusID = statusID
+	}
+
+	// Reporter by ID or full name
+	if id := record["reporter_id"]; id != "" {
+		reporterID, err := lookupByID(id, func(id uint) error {
+			_, err := im.store.Users().Get(ctx, id)
+			return err
+		})
+		if err != nil {
+			fail("reporter_id", err.Error())
+		}
+		issue.ReporterID = reporterID
+	} else if name := record["reporter"]; name != "" {
+		reporterID, err := im.lookupUser(ctx, name)
+		if err != nil {
+			fail("reporter", err.Error())
+		}
+		if reporterID == pendingUserID {
+			reporter = name
+		}
+		issue.ReporterID = reporterID
+	}
+
+	// Assignee by ID or full name
+	if id := record["assignee_id"]; id != "" {
+		assigneeID, err := lookupByID(id, func(id uint) error {
+			_, err := im.store.Officers().Get(ctx, id)
+			return err
+		})
+		if err != nil {
+			fail("assignee_id", err.Error())
+		} else {
+			issue.AssigneeID = &assigneeID
+		}
+	} else if name := record["assignee"]; name != "" {
+		assigneeID, err := im.lookupOfficer(ctx, name)
+		if err != nil {
+			fail("assignee", err.Error())
+		} else {
+			issue.AssigneeID = &assigneeID
+		}
+	}
+
+	if value := record["created_at"]; value != "" {
+		createdAt, err := parseTime(value)
+		if err != nil {
+			fail("created_at", err.Error())
+		}
+		// Stored in UTC like the timestamps GORM writes
+		issue.CreatedAt = createdAt.UTC()
+		issue.UpdatedAt = issue.CreatedAt
+	}
+
+	for _, e := range utils.ValidateStruct(issue) {
+		if !failed[e.Field] {
+			errs = append(errs, e)
+		}
+	}
+
+	// Missing reporters are only created for rows that are otherwise valid
+	if len(errs) > 0 {
+		return issue, "", errs
+	}
+	if reporter != "" {
+		im.reserveUser(reporter, result)
+	}
+	return issue, reporter, nil
+}
+
+// lookupByID parses an ID and checks with get that the row exists
+func lookupByID(value string, get func(id uint) error) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		return 0, errors.New("must be a positive integer")
+	}
+	if err := get(uint(id)); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return 0, fmt.Errorf("%d does not exist", id)
+		}
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// lookupStatus finds a status by code or display name, case-insensitively
+func (im *Importer) lookupStatus(ctx context.Context, name string) (uint, error) {
+	key := strings.ToLower(name)
+	if id, ok := im.statuses[key]; ok {
+		return id, nil
+	}
+
+	status, err := im.store.Statuses().FindByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return 0, fmt.Errorf("status %q not found", name)
+		}
+		return 0, err
+	}
+
+	im.statuses[key] = status.StatusID
+	return status.StatusID, nil
+}
+
+// lookupUser finds a reporter by full name. When the user is missing and
+// may be created, it returns pendingUserID.
+func (im *Importer) lookupUser(ctx context.Context, name string) (uint, error) {
+	key := strings.ToLower(name)
+	if id, ok := im.users[key]; ok {
+		return id, nil
+	}
+
+	existing, err := im.store.Users().FindByName(ctx, name)
+	if err == nil {
+		im.users[key] = existing.UserID
+		return existing.UserID, nil
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return 0, err
+	}
+	if !im.opts.CreateMissingUsers {
+		return 0, fmt.Errorf("user %q not found", name)
+	}
+
+	user := entities.User{FullName: name}
+	if validationErrors := utils.ValidateStruct(user); len(validationErrors) > 0 {
+		return 0, errors.New(validationErrors[0].Message)
+	}
+	return pendingUserID, nil
+}
+
+// reserveUser remembers a missing reporter so later rows share it. The user
+// is created when its batch is committed; dry runs only report it.
+func (im *Importer) reserveUser(name string, result *Result) {
+	key := strings.ToLower(name)
+	if _, ok := im.users[key]; ok {
+		return
+	}
+	im.users[key] = pendingUserID
+	if im.opts.DryRun {
+		result.CreatedUsers = append(result.CreatedUsers, name)
+	}
+}
+
+// lookupOfficer finds an officer by full name
+func (im *Importer) lookupOfficer(ctx context.Context, name string) (uint, error) {
+	key := strings.ToLower(name)
+	if id, ok := im.officers[key]; ok {
+		return id, nil
+	}
+
+	officer, err := im.store.Officers().FindByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return 0, fmt.Errorf("officer %q not found", name)
+		}
+		return 0, err
+	}
+
+	im.officers[key] = officer.OfficerID
+	return officer.OfficerID, nil
+}
+
+// parseTime accepts RFC 3339, "2006-01-02 15:04:05" and "2006-01-02"
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a valid date", value)
+}
+
+// addError records a failed row, keeping the report bounded
+func (r *Result) addError(line int, errs []utils.ValidationError) {
+	r.Failed++
+	if len(r.Errors) < maxReportedRows {
+		r.Errors = append(r.Errors, RowError{Line: line, Errors: errs})
+	}
+}
@@ -0,0 +1,275 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+)
+
+// newStore returns a store with statuses open (1) and closed (2), reporter
+// Ann Reporter (1) and officer Jane Smith (1)
+func newStore(t *testing.T) *repositories.MemoryStore {
+	t.Helper()
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	for _, code := range []string{"open", "closed"} {
+		status := entities.IssueStatus{StatusCode: code, DisplayName: strings.ToUpper(code[:1]) + code[1:], Color: "#000000", IsActive: true, IsTerminal: code == "closed"}
+		if err := store.Statuses().Create(ctx, &status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Users().Create(ctx, &entities.User{FullName: "Ann Reporter"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Officers().Create(ctx, &entities.Officer{FullName: "Jane Smith"}); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func issues(t *testing.T, store repositories.Store) []entities.Issue {
+	t.Helper()
+	list, err := store.Issues().Find(context.Background(), repositories.IssueFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		// seed adds rows to the store before the import
+		seed     func(t *testing.T, store repositories.Store)
+		opts     Options
+		input    string
+		imported int
+		failed   int
+		// errors lists "line:field" for each reported problem
+		errors []string
+		users  []string
+		check  func(t *testing.T, issues []entities.Issue)
+	}{
+		{
+			name:     "CSV by name",
+			input:    "\xEF\xBB\xBFTitle,Priority,Status,Reporter,Assignee,Created At\nBroken light,HIGH,Open,ann reporter,Jane Smith,2024-03-01\n",
+			imported: 1,
+			check: func(t *testing.T, issues []entities.Issue) {
+				issue := issues[0]
+				if issue.Title != "Broken light" || issue.Priority != "high" || issue.StatusID != 1 || issue.ReporterID != 1 ||
+					issue.AssigneeID == nil || *issue.AssigneeID != 1 || issue.CreatedAt.Format("2006-01-02") != "2024-03-01" {
+					t.Errorf("issue = %+v", issue)
+				}
+			},
+		},
+		{
+			name:     "CSV row errors",
+			input:    "title,status_id,reporter_id,created_at\nFine,1,1,\nX,9,1,\nBad date,2,abc,yesterday\n",
+			imported: 1, failed: 2,
+			errors: []string{"3:status_id", "3:Title", "4:reporter_id", "4:created_at"},
+		},
+		{
+			name:     "CSV mapping",
+			opts:     Options{Mapping: map[string]string{"Summary": "title", "Owner": "assignee_id"}},
+			input:    "Summary,Owner,status,reporter\nMapped,1,closed,Ann Reporter\n",
+			imported: 1,
+			check: func(t *testing.T, issues []entities.Issue) {
+				if issues[0].Title != "Mapped" || issues[0].StatusID != 2 || *issues[0].AssigneeID != 1 {
+					t.Errorf("issue = %+v", issues[0])
+				}
+			},
+		},
+		{
+			name:     "JSON lines",
+			opts:     Options{Format: FormatJSONL},
+			input:    `{"title":"From JSON","status_id":1,"reporter_id":1,"description":null}` + "\n\n" + `{"title":"Big ID","status_id":1,"reporter_id":1000000}` + "\n",
+			imported: 2,
+			// Large numbers must not be read as 1e+06
+			seed: func(t *testing.T, store repositories.Store) {
+				if err := store.Users().Create(context.Background(), &entities.User{UserID: 1000000, FullName: "Max Reporter"}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			check: func(t *testing.T, issues []entities.Issue) {
+				if issues[1].ReporterID != 1000000 || issues[0].Description != "" {
+					t.Errorf("issues = %+v", issues)
+				}
+			},
+		},
+		{
+			name:   "JSON lines with a fractional ID",
+			opts:   Options{Format: FormatJSONL},
+			input:  `{"title":"Half","status_id":1.5,"reporter_id":1}`,
+			failed: 1,
+			errors: []string{"1:status_id"},
+		},
+		{
+			name:   "unknown reporter",
+			input:  "title,status,reporter\nOrphan,open,Nobody Here\n",
+			failed: 1,
+			errors: []string{"2:reporter"},
+		},
+		{
+			name:     "creates missing reporters once",
+			opts:     Options{CreateMissingUsers: true, BatchSize: 2},
+			input:    "title,status,reporter\nFirst,open,New Person\nSecond,open,new person\nThird,open,New Person\n",
+			imported: 3,
+			users:    []string{"New Person"},
+			check: func(t *testing.T, issues []entities.Issue) {
+				for _, issue := range issues {
+					if issue.ReporterID != 2 {
+						t.Errorf("issue %q reporter = %d, want 2", issue.Title, issue.ReporterID)
+					}
+				}
+			},
+		},
+		{
+			name:   "invalid rows create no users",
+			opts:   Options{CreateMissingUsers: true},
+			input:  "title,status,reporter\nX,open,New Person\n",
+			failed: 1,
+			errors: []string{"2:Title"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStore(t)
+			if tt.seed != nil {
+				tt.seed(t, store)
+			}
+			im, err := New(store, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := im.Run(context.Background(), strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Imported != tt.imported || result.Failed != tt.failed {
+				t.Errorf("imported %d failed %d, want %d and %d: %+v", result.Imported, result.Failed, tt.imported, tt.failed, result.Errors)
+			}
+			var got []string
+			for _, row := range result.Errors {
+				for _, e := range row.Errors {
+					got = append(got, fmt.Sprintf("%d:%s", row.Line, e.Field))
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(tt.errors, " ") {
+				t.Errorf("errors = %v, want %v", got, tt.errors)
+			}
+			if strings.Join(result.CreatedUsers, ",") != strings.Join(tt.users, ",") {
+				t.Errorf("created users = %v, want %v", result.CreatedUsers, tt.users)
+			}
+			list := issues(t, store)
+			if len(list) != tt.imported {
+				t.Fatalf("stored %d issues, want %d", len(list), tt.imported)
+			}
+			if tt.check != nil {
+				tt.check(t, list)
+			}
+		})
+	}
+}
+
+func TestRunMalformedInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options
+		input string
+	}{
+		{"empty CSV", Options{}, ""},
+		{"CSV quote", Options{}, "title,status_id\n\"open,1\n"},
+		{"JSON", Options{Format: FormatJSONL}, "{\"title\":\"A\"}\n{\"title\":\n"},
+		{"JSON trailing data", Options{Format: FormatJSONL}, `{"title":"A"} {"title":"B"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			im, err := New(newStore(t), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := im.Run(context.Background(), strings.NewReader(tt.input)); !errors.Is(err, ErrMalformedInput) {
+				t.Errorf("err = %v, want ErrMalformedInput", err)
+			}
+		})
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	store := newStore(t)
+	im, err := New(store, Options{DryRun: true, CreateMissingUsers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := im.Run(context.Background(), strings.NewReader("title,status,reporter\nFirst,open,New Person\nSecond,open,New Person\nX,open,Ann Reporter\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.DryRun || result.Total != 3 || result.Imported != 2 || result.Failed != 1 || strings.Join(result.CreatedUsers, ",") != "New Person" {
+		t.Errorf("result = %+v", result)
+	}
+	if list := issues(t, store); len(list) != 0 {
+		t.Errorf("dry run stored %d issues", len(list))
+	}
+	if _, err := store.Users().FindByName(context.Background(), "New Person"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("dry run created a user: %v", err)
+	}
+}
+
+// failingStore fails the second CreateBatch call
+type failingStore struct {
+	repositories.Store
+	batches *int
+}
+
+func (s failingStore) Issues() repositories.IssueRepository {
+	return failingIssues{s.Store.Issues(), s.batches}
+}
+
+func (s failingStore) Transaction(ctx context.Context, fn func(tx repositories.Store) error) error {
+	return s.Store.Transaction(ctx, func(tx repositories.Store) error {
+		return fn(failingStore{tx, s.batches})
+	})
+}
+
+type failingIssues struct {
+	repositories.IssueRepository
+	batches *int
+}
+
+func (r failingIssues) CreateBatch(ctx context.Context, issues []entities.Issue) error {
+	*r.batches++
+	if *r.batches == 2 {
+		return errors.New("disk full")
+	}
+	return r.IssueRepository.CreateBatch(ctx, issues)
+}
+
+func TestRunBatchFailure(t *testing.T) {
+	store := newStore(t)
+	im, err := New(failingStore{store, new(int)}, Options{BatchSize: 2, CreateMissingUsers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := "title,status,reporter\nFirst,open,Ann Reporter\nSecond,open,Ann Reporter\nThird,open,New Person\nFourth,open,Ann Reporter\nFifth,open,Ann Reporter\n"
+	result, err := im.Run(context.Background(), strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "rows 4-5") {
+		t.Fatalf("err = %v, want the failed batch", err)
+	}
+	if result.Imported != 2 || len(result.CreatedUsers) != 0 {
+		t.Errorf("result = %+v, want only the first batch", result)
+	}
+	if list := issues(t, store); len(list) != 2 {
+		t.Errorf("stored %d issues, want 2", len(list))
+	}
+	// The reporter created for the failed batch is rolled back with it
+	if _, err := store.Users().FindByName(context.Background(), "New Person"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("user from the failed batch: %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"issue-tracking/client"
+	"issue-tracking/entities"
+)
+
+// filterFlags registers the GET /api/issues filters on fs and returns the
+// filter they describe once parsed
+func filterFlags(fs *flag.FlagSet) *client.IssueFilter {
+	filter := &client.IssueFilter{}
+	fs.StringVar(&filter.Status, "status", "", "only issues in this status code")
+	fs.StringVar(&filter.Priority, "priority", "", "only issues with this priority: low, medium, high or critical")
+	idFlag(fs, "assignee_id", "only issues assigned to this officer", &filter.AssigneeID)
+	idFlag(fs, "reporter_id", "only issues reported by this user", &filter.ReporterID)
+	fs.StringVar(&filter.Label, "label", "", "only issues with this label")
+	return filter
+}
+
+// idFlag registers a flag setting *id to a positive integer
+func idFlag(fs *flag.FlagSet, name, usage string, id **uint) {
+	fs.Func(name, usage, func(s string) error {
+		value, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return fmt.Errorf("must be a positive integer")
+		}
+		v := uint(value)
+		*id = &v
+		return nil
+	})
+}
+
+// runListIssuesCommand implements `issue-tracking list-issues [flags]`
+func runListIssuesCommand(args []string) error {
+	fs := flag.NewFlagSet("list-issues", flag.ExitOnError)
+	filter := filterFlags(fs)
+	asJSON := fs.Bool("json", false, "print the issues as JSON instead of a table")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: issue-tracking list-issues [flags]")
+		fs.PrintDefaults()
+	}
+	api, shutdown, err := openClient(fs, args)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	issues, err := api.ListIssues(context.Background(), *filter)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(os.Stdout, issues)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tPRIORITY\tASSIGNEE\tREPORTER\tTITLE")
+	for _, issue := range issues {
+		assignee := "-"
+		if issue.Assignee != nil {
+			assignee = issue.Assignee.FullName
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", issue.IssueID, issue.Status.StatusCode,
+			issue.Priority, assignee, issue.Reporter.FullName, issue.Title)
+	}
+	return w.Flush()
+}
+
+// runTransitionIssueCommand implements
+// `issue-tracking transition-issue [flags] ID STATUS`
+func runTransitionIssueCommand(args []string) error {
+	fs := flag.NewFlagSet("transition-issue", flag.ExitOnError)
+	comment := fs.String("comment", "", "comment recorded in the status history")
+	var assigneeID *uint
+	idFlag(fs, "assignee_id", "also assign the issue to this officer", &assigneeID)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: issue-tracking transition-issue [flags] ID STATUS")
+		fmt.Fprintln(fs.Output(), "STATUS is a status code or ID.")
+		fs.PrintDefaults()
+	}
+	api, shutdown, err := openClient(fs, args)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected an issue ID and a status")
+	}
+	issueID, err := strconv.ParseUint(fs.Arg(0), 10, 32)
+	if err != nil {
+		return fmt.Errorf("issue ID must be a positive integer")
+	}
+
+	ctx := context.Background()
+	statuses, err := api.Statuses(ctx)
+	if err != nil {
+		return err
+	}
+	status, err := findStatus(statuses, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	update := client.StatusUpdate{NewStatusID: status.StatusID, AssigneeID: assigneeID, Comment: *comment}
+	issue, err := api.UpdateIssueStatus(ctx, uint(issueID), update)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("issue %d is now %s\n", issue.IssueID, issue.Status.StatusCode)
+	return nil
+}
+
+// findStatus matches a status by code or ID
+func findStatus(statuses []entities.IssueStatus, value string) (entities.IssueStatus, error) {
+	for _, s := range statuses {
+		if s.StatusCode == value || strconv.FormatUint(uint64(s.StatusID), 10) == value {
+			return s, nil
+		}
+	}
+	codes := make([]string, len(statuses))
+	for i, s := range statuses {
+		codes[i] = s.StatusCode
+	}
+	return entities.IssueStatus{}, fmt.Errorf("unknown status %q, expected one of %v", value, codes)
+}
+
+// runExportCommand implements `issue-tracking export [flags]`
+func runExportCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	filter := filterFlags(fs)
+	format := fs.String("format", "csv", "csv, xlsx or json")
+	columns := fs.String("columns", "", "comma separated CSV/XLSX columns (default: the API's default columns)")
+	output := fs.String("o", "", "output file (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: issue-tracking export [flags]")
+		fs.PrintDefaults()
+	}
+	api, shutdown, err := openClient(fs, args)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	ctx := context.Background()
+	switch *format {
+	case "json":
+		issues, err := api.ListIssues(ctx, *filter)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, issues)
+	case "csv", "xlsx":
+		opts := client.ExportOptions{Filter: *filter, Format: *format}
+		if *columns != "" {
+			opts.Columns = strings.Split(*columns, ",")
+		}
+		return api.ExportIssues(ctx, opts, w)
+	default:
+		return fmt.Errorf("format must be one of: csv xlsx json")
+	}
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
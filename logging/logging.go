@@ -0,0 +1,69 @@
+// Package logging configures structured JSON logging with log/slog. Every
+// line logged with a request context carries the request ID and, when the
+// request is traced, the trace and span IDs.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Setup installs a JSON logger writing to w as the slog default. level is one
+// of debug, info, warn or error. The standard library log package is routed
+// through the same handler.
+func Setup(w io.Writer, level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	handler := contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: lvl})}
+	slog.SetDefault(slog.New(handler))
+	// SetDefault points the log package at slog; drop its own timestamp
+	log.SetFlags(0)
+	return nil
+}
+
+// ParseLevel parses debug, info, warn or error
+func ParseLevel(level string) (slog.Level, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return lvl, fmt.Errorf("invalid log level %q, must be one of: debug info warn error", level)
+	}
+	return lvl, nil
+}
+
+// contextHandler adds request and trace IDs found in the context
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
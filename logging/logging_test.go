@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// capture installs a JSON logger writing to a buffer and returns the parsed
+// lines
+func capture(t *testing.T, level string) func() []map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Setup(&buf, level); err != nil {
+		t.Fatal(err)
+	}
+	return func() []map[string]interface{} {
+		var lines []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var entry map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("log line is not JSON: %s", line)
+			}
+			lines = append(lines, entry)
+		}
+		return lines
+	}
+}
+
+func TestSetupRejectsUnknownLevel(t *testing.T) {
+	if err := Setup(&bytes.Buffer{}, "verbose"); err == nil {
+		t.Fatal("expected an error for an unknown level")
+	}
+}
+
+func TestMiddlewareRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lines := capture(t, "info")
+
+	router := gin.New()
+	router.Use(Middleware())
+	var seen string
+	router.GET("/api/issues/:id", func(c *gin.Context) {
+		seen = RequestID(c.Request.Context())
+		c.Status(404)
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		generate bool
+	}{
+		{"taken from header", "abc-123", false},
+		{"generated when missing", "", true},
+		{"generated when unsafe", "bad id\nwith newline", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/issues/1", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			got := w.Header().Get(RequestIDHeader)
+			if got != seen {
+				t.Errorf("response header %q does not match context %q", got, seen)
+			}
+			if tt.generate && (len(got) != 32 || got == tt.header) {
+				t.Errorf("expected a generated ID, got %q", got)
+			}
+			if !tt.generate && got != tt.header {
+				t.Errorf("request ID = %q, want %q", got, tt.header)
+			}
+		})
+	}
+
+	entries := lines()
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d access log lines, want %d", len(entries), len(tests))
+	}
+	first := entries[0]
+	if first["request_id"] != "abc-123" || first["route"] != "/api/issues/:id" || first["level"] != "WARN" {
+		t.Errorf("unexpected access log line: %v", first)
+	}
+}
+
+func TestGormLoggerLevels(t *testing.T) {
+	lines := capture(t, "debug")
+	l := NewGormLogger(100 * time.Millisecond)
+	ctx := WithRequestID(context.Background(), "req-1")
+	stmt := func() (string, int64) { return "SELECT 1", 1 }
+
+	l.Trace(ctx, time.Now(), stmt, nil)
+	l.Trace(ctx, time.Now().Add(-time.Second), stmt, nil)
+	l.Trace(ctx, time.Now(), stmt, errors.New("connection reset"))
+	l.Trace(ctx, time.Now(), stmt, gorm.ErrRecordNotFound)
+
+	want := []string{"DEBUG", "WARN", "ERROR", "DEBUG"}
+	entries := lines()
+	if len(entries) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry["level"] != want[i] {
+			t.Errorf("line %d level = %v, want %s", i, entry["level"], want[i])
+		}
+		if entry["request_id"] != "req-1" {
+			t.Errorf("line %d missing request_id: %v", i, entry)
+		}
+	}
+}
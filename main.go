@@ -1,94 +1,419 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
-	"issue-tracking/entities"
+	"issue-tracking/config"
+	"issue-tracking/controllers"
+	"issue-tracking/database"
+	"issue-tracking/grpcapi"
+	"issue-tracking/logging"
+	"issue-tracking/metrics"
+	"issue-tracking/middlewares"
+	"issue-tracking/migrations"
+	"issue-tracking/repositories"
 	"issue-tracking/routes"
+	"issue-tracking/services"
+	"issue-tracking/tracing"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/driver/postgres"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-var db *gorm.DB
+const (
+	// readHeaderTimeout protects the server from clients that never finish
+	// sending headers
+	readHeaderTimeout = 10 * time.Second
 
-func init() {
-	var err error
+	// idempotencyPurgeInterval is how often expired idempotency keys are deleted
+	idempotencyPurgeInterval = time.Hour
 
-	// Read PostgreSQL connection string from environment or use default
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		dsn = "host=localhost user=postgres password=postgres dbname=issue_tracking port=5432 sslmode=disable"
+	// connectBackoff and maxConnectBackoff bound the wait between attempts
+	// to reach the database at startup
+	connectBackoff    = 500 * time.Millisecond
+	maxConnectBackoff = 10 * time.Second
+)
+
+// command is one subcommand of the binary
+type command struct {
+	run     func(args []string) error
+	summary string
+}
+
+// commands lists the subcommands; the server runs when none is given
+var commands = map[string]command{
+	"serve":            {runServer, "run the HTTP and gRPC servers (default)"},
+	"migrate":          {runMigrateCommand, "apply, roll back or create schema migrations"},
+	"seed":             {runSeedCommand, "load statuses, labels, users and officers from a file"},
+	"import":           {runImportCommand, "import issues from CSV or JSONL"},
+	"create-user":      {runCreateUserCommand, "create a user who can report issues"},
+	"create-officer":   {runCreateOfficerCommand, "create an officer who can handle issues"},
+	"list-issues":      {runListIssuesCommand, "list issues with optional filters"},
+	"transition-issue": {runTransitionIssueCommand, "move an issue to another status"},
+	"export":           {runExportCommand, "export issues as CSV, XLSX or JSON"},
+	"create-api-key":   {runCreateAPIKeyCommand, "create an API key for an integration"},
+	"revoke-api-key":   {runRevokeAPIKeyCommand, "revoke an API key"},
+}
+
+func main() {
+	args := os.Args[1:]
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage(os.Stdout)
+		return
 	}
 
-	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		DisableForeignKeyConstraintWhenMigrating: true,
-	})
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	if err := cmd.run(args); err != nil {
+		fatal(name+" failed", err)
+	}
+}
+
+// usage lists the subcommands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: issue-tracking [command] [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-18s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run `issue-tracking COMMAND -h` for the flags of a command.")
+}
+
+// runServer implements `issue-tracking [serve] [flags]`
+func runServer(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	printConfig := fs.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	cfg := loadConfig(fs, args)
+	if *printConfig {
+		return cfg.Print(os.Stdout)
+	}
+
+	db, shutdown, err := setup(cfg, os.Stdout, cfg.Database.AutoMigrate)
 	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+		return err
 	}
+	defer shutdown()
 
-	if err := db.AutoMigrate(&entities.User{}); err != nil {
-		log.Fatalf("failed to migrate User: %v", err)
+	if err := metrics.RegisterDBStats(db); err != nil {
+		return fmt.Errorf("register database metrics: %w", err)
+	}
+	if err := metrics.RegisterBacklog(db, cfg.SLA.Targets()); err != nil {
+		return fmt.Errorf("register backlog metrics: %w", err)
 	}
 
-	if err := db.AutoMigrate(&entities.Officer{}); err != nil {
-		log.Fatalf("failed to migrate Officer: %v", err)
+	health := controllers.NewHealthController(db)
+	router := gin.New()
+
+	// Client IPs, which are logged and rate limited, are only taken from
+	// X-Forwarded-For when the request came through a trusted proxy
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return fmt.Errorf("set trusted proxies: %w", err)
+	}
+	if cfg.RateLimit.Enabled && len(cfg.Server.TrustedProxies) == 0 {
+		slog.Warn("rate limiting anonymous clients by the address they connect from; behind a proxy, set trusted proxies or they share one budget")
 	}
+	// Trace and log every request; logging runs inside the span so each
+	// line carries the trace ID as well as the request ID
+	router.Use(tracing.Middleware())
+	router.Use(logging.Middleware())
+	router.Use(middlewares.CORS(cfg.CORS.AllowedOrigins))
+
+	// Probes: /livez only says the process is up, /readyz also checks the
+	// database and migrations. /health is kept for existing monitors.
+	router.GET("/livez", health.Livez)
+	router.GET("/readyz", health.Readyz)
+	router.GET("/health", health.Readyz)
+
+	// Register all routes
+	store := repositories.NewGormStore(db)
+	issues := services.NewIssueService(store)
+	spec := routes.RegisterRoutes(router, store, issues, cfg)
 
-	if err := db.AutoMigrate(&entities.IssueStatus{}); err != nil {
-		log.Fatalf("failed to migrate IssueStatus: %v", err)
+	// The gRPC API shares the issue service, so its event streams see the
+	// changes made over HTTP too
+	var rpcAPI *grpcapi.Server
+	var rpcServer *grpc.Server
+	var rpcListener net.Listener
+	if cfg.Features.GRPC {
+		opts := []grpc.ServerOption{tracing.GRPCServerOption()}
+		if cfg.TLSEnabled() {
+			creds, err := credentials.NewServerTLSFromFile(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+			if err != nil {
+				return fmt.Errorf("load gRPC TLS files: %w", err)
+			}
+			opts = append(opts, grpc.Creds(creds))
+		}
+		if rpcListener, err = net.Listen("tcp", cfg.GRPC.Addr); err != nil {
+			return fmt.Errorf("listen for gRPC: %w", err)
+		}
+		rpcAPI = grpcapi.NewServer(store, issues, spec)
+		rpcAPI.AnonymousScopes(cfg.AnonymousScopes())
+		if limiter := routes.NewRateLimiter(store, cfg); limiter != nil {
+			rpcAPI.LimitRate(limiter)
+		}
+		rpcServer = grpcapi.NewGRPCServer(rpcAPI, opts...)
 	}
 
-	if err := db.AutoMigrate(&entities.Issue{}); err != nil {
-		log.Fatalf("failed to migrate Issue: %v", err)
+	// Background workers run until shutdown
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		middlewares.PurgeExpiredIdempotencyKeys(workerCtx, store.IdempotencyKeys(), idempotencyPurgeInterval)
+	}()
+
+	srv := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           router,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	serveErr := make(chan error, 2)
+	go func() {
+		slog.Info("server starting", slog.String("addr", cfg.Server.Addr), slog.Bool("tls", cfg.TLSEnabled()))
+		if cfg.TLSEnabled() {
+			serveErr <- srv.ListenAndServeTLS(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+		} else {
+			serveErr <- srv.ListenAndServe()
+		}
+	}()
+	if rpcServer != nil {
+		go func() {
+			slog.Info("gRPC server starting", slog.String("addr", cfg.GRPC.Addr), slog.Bool("tls", cfg.TLSEnabled()))
+			serveErr <- rpcServer.Serve(rpcListener)
+		}()
 	}
 
-	if err := db.AutoMigrate(&entities.IssueStatusHistory{}); err != nil {
-		log.Fatalf("failed to migrate IssueStatusHistory: %v", err)
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	select {
+	case err := <-serveErr:
+		srv.Close()
+		if rpcServer != nil {
+			rpcServer.Stop()
+		}
+		stopWorkers()
+		workers.Wait()
+		return err
+	case <-signals.Done():
 	}
+	// A second signal kills the process without waiting
+	stopSignals()
+
+	slog.Info("shutting down", slog.String("timeout", cfg.Server.ShutdownTimeout.String()))
+	health.StartDraining()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+	defer cancel()
 
-	if err := db.AutoMigrate(&entities.Comment{}); err != nil {
-		log.Fatalf("failed to migrate Comment: %v", err)
+	// Stop accepting connections and wait for in-flight requests and calls,
+	// then for the workers, all within the one timeout
+	var errs []error
+	rpcStopped := make(chan struct{})
+	if rpcServer != nil {
+		rpcAPI.Drain()
+		go func() {
+			rpcServer.GracefulStop()
+			close(rpcStopped)
+		}()
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("drain requests: %w", err))
+	}
+	if rpcServer != nil {
+		select {
+		case <-rpcStopped:
+		case <-ctx.Done():
+			rpcServer.Stop()
+			errs = append(errs, fmt.Errorf("drain gRPC calls: %w", ctx.Err()))
+		}
+	}
+	stopWorkers()
+	drained := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("background workers did not stop within %s", cfg.Server.ShutdownTimeout))
 	}
 
-	//! create mock data
-	// utils.MockData(db)
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	slog.Info("server stopped")
+	return nil
+}
 
-	fmt.Println("Database initialized successfully")
+// loadConfig parses flags and loads the configuration, exiting with every
+// validation problem listed when it is invalid
+func loadConfig(fs *flag.FlagSet, args []string) *config.Config {
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	return cfg
 }
 
-func main() {
-	router := gin.Default()
-
-	// CORS middleware to allow all origins
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+// setup configures logging to logs and tracing and opens the database,
+// applying pending migrations when migrate is set. The returned function
+// closes the database, flushes pending spans and must be called before exit.
+func setup(cfg *config.Config, logs io.Writer, migrate bool) (*gorm.DB, func(), error) {
+	// JSON logs; commands other than serve log to stderr so their output
+	// can be piped
+	if err := logging.Setup(logs, cfg.Log.Level); err != nil {
+		return nil, nil, err
+	}
+
+	// Exporter is selected by OTEL_TRACES_EXPORTER (none, stdout or otlp)
+	shutdownTracing, err := tracing.Setup(context.Background())
+	if err != nil {
+		return nil, nil, fmt.Errorf("set up tracing: %w", err)
+	}
+	shutdown := func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to flush traces", slog.Any("error", err))
 		}
+	}
 
-		c.Next()
-	})
+	db, err := openDatabase(cfg)
+	if err != nil {
+		shutdown()
+		return nil, nil, err
+	}
+	flushTraces := shutdown
+	shutdown = func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		flushTraces()
+	}
 
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	if migrate {
+		if err := migrateUp(db); err != nil {
+			shutdown()
+			return nil, nil, err
+		}
+	}
+	return db, shutdown, nil
+}
 
-	// Register all routes
-	routes.RegisterRoutes(router, db)
+// migrateUp applies every pending migration. Replicas starting together
+// wait on the migration lock, so each migration runs once.
+func migrateUp(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	migrator, err := migrations.New(sqlDB, db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(context.Background(), 0)
+	for _, m := range applied {
+		slog.Info("migration applied", slog.Int64("version", m.Version), slog.String("name", m.Name))
+	}
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	return nil
+}
 
-	fmt.Println("Server starting on http://localhost:8080")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+// openDatabase connects and applies the pool settings. The first
+// connection is retried with exponential backoff for up to
+// database.connect_timeout so the server can start before PostgreSQL does.
+// SQLite keeps its single connection and ignores the pool settings.
+func openDatabase(cfg *config.Config) (*gorm.DB, error) {
+	// A malformed URL will not get better by retrying
+	if _, err := database.Dialector(cfg.Database.URL); err != nil {
+		return nil, err
 	}
+	deadline := time.Now().Add(cfg.Database.ConnectTimeout.Duration)
+	delay := connectBackoff
+	var db *gorm.DB
+	for attempt := 1; ; attempt++ {
+		var err error
+		// Failed attempts are logged below, not by GORM
+		db, err = database.Open(cfg.Database.URL, &gorm.Config{Logger: logger.Discard})
+		if err == nil {
+			db.Logger = logging.NewGormLogger(cfg.Log.SlowQuery.Duration)
+			break
+		}
+		if db != nil {
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return nil, fmt.Errorf("connect to database (%d attempts): %w", attempt, err)
+		}
+		slog.Warn("database not reachable, retrying",
+			slog.Int("attempt", attempt), slog.String("retry_in", delay.String()), slog.Any("error", err))
+		time.Sleep(delay)
+		delay = min(delay*2, maxConnectBackoff)
+	}
+
+	if db.Dialector.Name() != database.SQLite {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+		sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+		sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime.Duration)
+		sqlDB.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime.Duration)
+	}
+
+	// Record a span for every GORM statement
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("register tracing plugin: %w", err)
+	}
+
+	// Time every GORM statement for /metrics
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("register metrics plugin: %w", err)
+	}
+
+	slog.Info("database initialized")
+	return db, nil
+}
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
 }
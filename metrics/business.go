@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// DefaultSLATargets is how long an issue of each priority may stay open
+var DefaultSLATargets = map[string]time.Duration{
+	"critical": 4 * time.Hour,
+	"high":     24 * time.Hour,
+	"medium":   72 * time.Hour,
+	"low":      168 * time.Hour,
+}
+
+// scrapeTimeout bounds the database queries run for one scrape
+const scrapeTimeout = 5 * time.Second
+
+var (
+	openIssuesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "open_issues"),
+		"Issues in a non-terminal status, by status code and priority.",
+		[]string{"status", "priority"}, nil,
+	)
+	slaBreachesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "sla_breaches"),
+		"Open issues older than the SLA target for their priority.",
+		[]string{"priority"}, nil,
+	)
+)
+
+// backlogCollector queries the issue backlog on every scrape so the gauges
+// are never stale
+type backlogCollector struct {
+	db      *gorm.DB
+	targets map[string]time.Duration
+}
+
+// RegisterBacklog exposes open issue and SLA breach gauges computed from db
+func RegisterBacklog(db *gorm.DB, targets map[string]time.Duration) error {
+	return Registry.Register(&backlogCollector{db: db, targets: targets})
+}
+
+// Describe implements prometheus.Collector
+func (bc *backlogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openIssuesDesc
+	ch <- slaBreachesDesc
+}
+
+// Collect implements prometheus.Collector
+func (bc *backlogCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+	db := bc.db.WithContext(ctx)
+
+	var open []struct {
+		StatusCode string
+		Priority   string
+		Count      int64
+	}
+	if err := db.Table("issues").
+		Select("issue_statuses.status_code, issues.priority, COUNT(*) AS count").
+		Joins("JOIN issue_statuses ON issue_statuses.status_id = issues.status_id").
+		Where("issue_statuses.is_terminal = ?", false).
+		Group("issue_statuses.status_code, issues.priority").
+		Scan(&open).Error; err != nil {
+		slog.Error("metrics: failed to count open issues", slog.Any("error", err))
+		return
+	}
+	for _, row := range open {
+		ch <- prometheus.MustNewConstMetric(openIssuesDesc, prometheus.GaugeValue, float64(row.Count), row.StatusCode, row.Priority)
+	}
+
+	now := time.Now().UTC()
+	for priority, target := range bc.targets {
+		var count int64
+		if err := db.Table("issues").
+			Joins("JOIN issue_statuses ON issue_statuses.status_id = issues.status_id").
+			Where("issue_statuses.is_terminal = ? AND issues.priority = ? AND issues.created_at < ?", false, priority, now.Add(-target)).
+			Count(&count).Error; err != nil {
+			slog.Error("metrics: failed to count SLA breaches", slog.Any("error", err))
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(slaBreachesDesc, prometheus.GaugeValue, float64(count), priority)
+	}
+}
@@ -0,0 +1,144 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"issue-tracking/auth"
+	"issue-tracking/services"
+	"issue-tracking/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyAuth authenticates requests carrying an API key, either as
+// "Authorization: Bearer itk_..." or in X-API-Key. The key travels on the
+// request context and the request is rate limited per key instead of per
+// IP address. Requests without a key pass through; requests with an
+// unknown, expired or revoked key are rejected with 401.
+func APIKeyAuth(keys *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := apiKey(c.Request)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		record, err := keys.Authenticate(c.Request.Context(), key)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithAPIKey(c.Request.Context(), record))
+		SetRateLimitClient(c, fmt.Sprintf("key:%d", record.APIKeyID))
+		c.Next()
+	}
+}
+
+// TokenAuth authenticates people signed in through OpenID Connect, who send
+// their ID token as "Authorization: Bearer <token>". The identity travels
+// on the request context and the request is rate limited per user instead
+// of per IP address. Requests without a token pass through; requests with
+// an invalid or expired token are rejected with 401.
+func TokenAuth(identities *services.IdentityService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := idToken(c.Request)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		identity, err := identities.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			var serviceErr *services.Error
+			if errors.As(err, &serviceErr) && serviceErr.Status == http.StatusUnauthorized {
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			}
+			abortWithError(c, err)
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), identity))
+		SetRateLimitClient(c, fmt.Sprintf("user:%d", identity.UserID))
+		c.Next()
+	}
+}
+
+// AnonymousScopes grants scopes to the requests that carry neither an API
+// key nor an ID token. It runs before the authentication middlewares.
+func AnonymousScopes(scopes []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithAnonymousScopes(c.Request.Context(), scopes))
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose caller was not granted scope: with
+// 401 when the request is anonymous, so the client knows credentials may
+// help, and with 403 otherwise
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if auth.Allowed(ctx, scope) {
+			c.Next()
+			return
+		}
+		if auth.Anonymous(ctx) {
+			c.Header("WWW-Authenticate", "Bearer")
+			utils.RespondError(c, http.StatusUnauthorized, "Unauthorized", auth.Denial(ctx, scope))
+		} else {
+			utils.RespondError(c, http.StatusForbidden, "Forbidden", auth.Denial(ctx, scope))
+		}
+		c.Abort()
+	}
+}
+
+// RequireCaller rejects anonymous requests with 401 and requests whose API
+// key or signed-in person was not granted scope with 403
+func RequireCaller(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if auth.Anonymous(c.Request.Context()) {
+			c.Header("WWW-Authenticate", "Bearer")
+			utils.RespondError(c, http.StatusUnauthorized, "Unauthorized", "an API key or ID token is required")
+			c.Abort()
+			return
+		}
+		RequireScope(scope)(c)
+	}
+}
+
+// abortWithError answers a failed authentication
+func abortWithError(c *gin.Context, err error) {
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) {
+		utils.RespondError(c, serviceErr.Status, serviceErr.Message, serviceErr.Details)
+	} else {
+		utils.RespondError(c, http.StatusInternalServerError, "Internal server error", err.Error())
+	}
+	c.Abort()
+}
+
+// idToken returns the bearer token sent with r when it is not an API key
+func idToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.HasPrefix(token, auth.APIKeyPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// apiKey returns the API key sent with r, if any. Bearer tokens that are not
+// API keys are left for other authentication schemes.
+func apiKey(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get(auth.APIKeyHeader)); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") && strings.HasPrefix(token, auth.APIKeyPrefix) {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyHeader is the request header clients use to make retries safe
+const IdempotencyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength matches the idempotency_keys column size
+const maxIdempotencyKeyLength = 255
+
+// responseRecorder keeps a copy of everything written to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response when a request is retried with the
+// same Idempotency-Key header within window. Keys belong to the client that
+// sent them, so another client reusing one is never answered with the first
+// client's response. Reusing a key with a different request body is rejected
+// with 422. Requests without the header pass through.
+func Idempotency(keys repositories.IdempotencyRepository, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(IdempotencyHeader)
+		if header == "" {
+			c.Next()
+			return
+		}
+		if len(header) > maxIdempotencyKeyLength {
+			utils.RespondError(c, 400, "Invalid Idempotency-Key", "Idempotency-Key must be at most 255 characters")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			utils.RespondError(c, 400, "Invalid request body", err.Error())
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := scopedKey(clientOf(c), header)
+		hash := requestHash(c.Request.Method, c.Request.URL.Path, body)
+		now := time.Now()
+		ctx := c.Request.Context()
+
+		record := entities.IdempotencyKey{
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			RequestHash: hash,
+			ExpiresAt:   now.Add(window),
+		}
+
+		// Claim the key; if another request already holds it, inspect that one instead
+		claimed, err := keys.Claim(ctx, &record)
+		if err != nil {
+			utils.RespondError(c, 500, "Failed to store idempotency key", nil)
+			c.Abort()
+			return
+		}
+
+		if !claimed {
+			existing, err := keys.Get(ctx, key)
+			if err != nil {
+				utils.RespondError(c, 500, "Failed to load idempotency key", nil)
+				c.Abort()
+				return
+			}
+
+			if existing.RequestHash != hash {
+				utils.RespondError(c, http.StatusUnprocessableEntity, "Idempotency-Key reused", "the same Idempotency-Key was used with a different request")
+				c.Abort()
+				return
+			}
+
+			if existing.StatusCode == 0 {
+				utils.RespondError(c, http.StatusConflict, "Request in progress", "a request with this Idempotency-Key is still being processed")
+				c.Abort()
+				return
+			}
+
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(existing.StatusCode, existing.ContentType, existing.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		// The outcome is stored even when the client has gone away, which is
+		// exactly when it retries
+		storeCtx := context.WithoutCancel(ctx)
+
+		// Release the key if the handler panics or the response cannot be
+		// stored so a retry is not stuck behind it
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := keys.Release(storeCtx, key); err != nil {
+				slog.ErrorContext(storeCtx, "failed to release idempotency key", slog.Any("error", err))
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		// Server errors are not cached so the client can retry them
+		status := recorder.Status()
+		if status >= 500 {
+			return
+		}
+		if err := keys.Complete(storeCtx, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			slog.ErrorContext(storeCtx, "failed to store idempotent response", slog.Any("error", err))
+			return
+		}
+		completed = true
+	}
+}
+
+// scopedKey is the key stored for the Idempotency-Key header of client. It
+// is hashed so it fits the column whatever the length of both.
+func scopedKey(client, header string) string {
+	h := sha256.Sum256([]byte(client + "\n" + header))
+	return hex.EncodeToString(h[:])
+}
+
+// requestHash fingerprints the parts of a request that must match on replay
+func requestHash(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte(" "))
+	h.Write([]byte(path))
+	h.Write([]byte("\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// PurgeExpiredIdempotencyKeys deletes expired idempotency records every
+// interval until ctx is cancelled
+func PurgeExpiredIdempotencyKeys(ctx context.Context, keys repositories.IdempotencyRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		count, err := keys.DeleteExpired(ctx, time.Now())
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("failed to purge expired idempotency keys", slog.Any("error", err))
+			}
+			continue
+		}
+		if count > 0 {
+			slog.Debug("purged expired idempotency keys", slog.Int64("count", count))
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package middlewares_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"issue-tracking/middlewares"
+	"issue-tracking/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ctxKeys fails like a database driver once the context is cancelled, and
+// fails Complete when failComplete is set
+type ctxKeys struct {
+	repositories.IdempotencyRepository
+	failComplete bool
+}
+
+func (k *ctxKeys) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if k.failComplete {
+		return errors.New("connection reset")
+	}
+	return k.IdempotencyRepository.Complete(ctx, key, statusCode, contentType, body)
+}
+
+func (k *ctxKeys) Release(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return k.IdempotencyRepository.Release(ctx, key)
+}
+
+// newIdempotentRouter serves POST /items behind the middleware. The handler
+// counts its calls and responds with status, then runs after.
+func newIdempotentRouter(keys repositories.IdempotencyRepository, status *int, after func(c *gin.Context)) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	calls := 0
+	router := gin.New()
+	router.POST("/items", middlewares.Idempotency(keys, time.Hour), func(c *gin.Context) {
+		calls++
+		c.JSON(*status, gin.H{"call": calls})
+		if after != nil {
+			after(c)
+		}
+	})
+	return router, &calls
+}
+
+func post(router http.Handler, ctx context.Context, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body)).WithContext(ctx)
+	if key != "" {
+		req.Header.Set(middlewares.IdempotencyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		first    string // body of the first request
+		retry    string // body of the retry with the same key
+		key      string
+		status   int // of the handler
+		want     int // status of the retry
+		wantBody string
+		calls    int
+	}{
+		{name: "replays the response", first: `{"a":1}`, retry: `{"a":1}`, key: "k1", status: 201, want: 201, wantBody: `"call":1`, calls: 1},
+		{name: "rejects another body", first: `{"a":1}`, retry: `{"a":2}`, key: "k1", status: 201, want: 422, calls: 1},
+		{name: "client errors are replayed", first: `{}`, retry: `{}`, key: "k1", status: 400, want: 400, wantBody: `"call":1`, calls: 1},
+		{name: "server errors are retried", first: `{}`, retry: `{}`, key: "k1", status: 500, want: 500, wantBody: `"call":2`, calls: 2},
+		{name: "no key passes through", first: `{}`, retry: `{}`, status: 201, want: 201, wantBody: `"call":2`, calls: 2},
+		{name: "long key", first: `{}`, retry: `{}`, key: strings.Repeat("k", 256), status: 201, want: 400, calls: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			router, calls := newIdempotentRouter(repositories.NewMemoryStore().IdempotencyKeys(), &status, nil)
+			post(router, ctx, tt.key, tt.first)
+			w := post(router, ctx, tt.key, tt.retry)
+			if w.Code != tt.want || !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("retry = %d %s, want %d %s", w.Code, w.Body, tt.want, tt.wantBody)
+			}
+			if *calls != tt.calls {
+				t.Errorf("handler ran %d times, want %d", *calls, tt.calls)
+			}
+		})
+	}
+}
+
+// TestIdempotencyKeysBelongToTheClient checks another client sending the
+// same key and body gets a response of its own, not the first client's
+func TestIdempotencyKeysBelongToTheClient(t *testing.T) {
+	status := 201
+	router, calls := newIdempotentRouter(repositories.NewMemoryStore().IdempotencyKeys(), &status, nil)
+	send := func(addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"a":1}`))
+		req.Header.Set(middlewares.IdempotencyHeader, "k1")
+		req.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	send("192.0.2.1:1234")
+	if w := send("198.51.100.7:4321"); w.Header().Get("Idempotent-Replayed") != "" || !strings.Contains(w.Body.String(), `"call":2`) {
+		t.Errorf("other client = %d %s, want a response of its own", w.Code, w.Body)
+	}
+	if w := send("192.0.2.1:5678"); w.Header().Get("Idempotent-Replayed") != "true" || !strings.Contains(w.Body.String(), `"call":1`) {
+		t.Errorf("retry by the first client = %d %s, want the replay", w.Code, w.Body)
+	}
+	if *calls != 2 {
+		t.Errorf("handler ran %d times, want 2", *calls)
+	}
+}
+
+func TestIdempotencyRequestInProgress(t *testing.T) {
+	status := 201
+	var router *gin.Engine
+	var retry *httptest.ResponseRecorder
+	// The retry arrives while the first request is still being handled
+	router, _ = newIdempotentRouter(repositories.NewMemoryStore().IdempotencyKeys(), &status, func(*gin.Context) {
+		if retry == nil {
+			retry = post(router, context.Background(), "k1", `{}`)
+		}
+	})
+
+	post(router, context.Background(), "k1", `{}`)
+	if retry == nil || retry.Code != http.StatusConflict {
+		t.Fatalf("retry while in progress = %v, want 409", retry)
+	}
+}
+
+// TestIdempotencyStoresResponseWhenClientLeaves checks the response is kept
+// when the client disconnects before it is stored, so its retry is replayed
+func TestIdempotencyStoresResponseWhenClientLeaves(t *testing.T) {
+	keys := &ctxKeys{IdempotencyRepository: repositories.NewMemoryStore().IdempotencyKeys()}
+	ctx, cancel := context.WithCancel(context.Background())
+	status := 201
+	router, calls := newIdempotentRouter(keys, &status, func(*gin.Context) { cancel() })
+
+	post(router, ctx, "k1", `{}`)
+	w := post(router, context.Background(), "k1", `{}`)
+	if w.Code != 201 || w.Header().Get("Idempotent-Replayed") != "true" || *calls != 1 {
+		t.Errorf("retry = %d replayed %q after %d calls, want the stored 201", w.Code, w.Header().Get("Idempotent-Replayed"), *calls)
+	}
+}
+
+// TestIdempotencyReleasesKeyWhenStoringFails checks a response that cannot
+// be stored does not leave the key in progress
+func TestIdempotencyReleasesKeyWhenStoringFails(t *testing.T) {
+	keys := &ctxKeys{IdempotencyRepository: repositories.NewMemoryStore().IdempotencyKeys(), failComplete: true}
+	status := 201
+	router, calls := newIdempotentRouter(keys, &status, nil)
+
+	post(router, context.Background(), "k1", `{}`)
+	keys.failComplete = false
+	w := post(router, context.Background(), "k1", `{}`)
+	if w.Code != 201 || *calls != 2 {
+		t.Errorf("retry = %d after %d calls, want the request to run again: %s", w.Code, *calls, w.Body)
+	}
+	if w := post(router, context.Background(), "k1", `{}`); !strings.Contains(w.Body.String(), `"call":2`) {
+		t.Errorf("second retry = %s, want the stored response", w.Body)
+	}
+}
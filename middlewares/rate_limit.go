@@ -0,0 +1,199 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitClientKey is the gin context key holding the client a request is
+// counted against when it is not its IP address
+const rateLimitClientKey = "rate_limit_client"
+
+// rateLimitPurgeInterval is how often buckets that have filled up again are
+// deleted
+const rateLimitPurgeInterval = time.Minute
+
+// Budget is the token bucket of one route class: Burst requests at once,
+// refilled at PerMinute requests a minute
+type Budget struct {
+	PerMinute int
+	Burst     int
+}
+
+// rate is the refill rate in tokens per second
+func (b Budget) rate() float64 {
+	return float64(b.PerMinute) / 60
+}
+
+// RateLimiter keeps a token bucket per client and route class in buckets,
+// which replicas share when it is backed by the database
+type RateLimiter struct {
+	buckets repositories.RateLimitRepository
+	read    Budget
+	write   Budget
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+// NewRateLimiter returns a limiter with separate budgets for reads (GET,
+// HEAD and OPTIONS) and writes
+func NewRateLimiter(buckets repositories.RateLimitRepository, read, write Budget) *RateLimiter {
+	return &RateLimiter{buckets: buckets, read: read, write: write, lastPurge: time.Now()}
+}
+
+// SetRateLimitClient counts the request against client, such as "key:12"
+// for an API key or "user:7" for a signed-in user, instead of its IP
+// address. Idempotency keys are scoped to the same client. Authentication
+// middlewares call it before RateLimit runs.
+func SetRateLimitClient(c *gin.Context, client string) {
+	c.Set(rateLimitClientKey, client)
+}
+
+// clientOf returns the client set with SetRateLimitClient, else the IP
+// address of the request
+func clientOf(c *gin.Context) string {
+	if client := c.GetString(rateLimitClientKey); client != "" {
+		return client
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimit answers 429 once the client of a request has used up the budget
+// of its route class, with Retry-After saying when the next request will be
+// accepted. Every response carries the RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers. Requests pass when the
+// buckets cannot be reached, so an outage of the limiter does not take the
+// API down with it.
+func RateLimit(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		read := false
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			read = true
+		}
+		client := clientOf(c)
+		ctx := c.Request.Context()
+		decision, err := limiter.Take(ctx, read, client)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to check rate limit", slog.String("client", client), slog.Any("error", err))
+			c.Next()
+			return
+		}
+		budget, tokens := decision.Budget, decision.Tokens
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(budget.Burst))
+		header.Set("RateLimit-Remaining", strconv.Itoa(int(tokens)))
+		header.Set("RateLimit-Reset", seconds((float64(budget.Burst)-tokens)/budget.rate()))
+		header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=60;burst=%d", budget.PerMinute, budget.Burst))
+
+		if !decision.Allowed {
+			header.Set("Retry-After", seconds(decision.RetryAfter().Seconds()))
+			utils.RespondError(c, http.StatusTooManyRequests, "Too many requests", decision.Reason())
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Decision is the outcome of counting a request against its bucket
+type Decision struct {
+	// Class is "read" or "write"
+	Class   string
+	Budget  Budget
+	Allowed bool
+	// Tokens is what is left in the bucket after the request
+	Tokens float64
+}
+
+// RetryAfter is how long a rejected client has to wait for the next token
+func (d Decision) RetryAfter() time.Duration {
+	return time.Duration(math.Max(1-d.Tokens, 0) / d.Budget.rate() * float64(time.Second))
+}
+
+// Reason explains the limit a rejected request ran into
+func (d Decision) Reason() string {
+	return fmt.Sprintf("%s requests are limited to %d at once and %d a minute", d.Class, d.Budget.Burst, d.Budget.PerMinute)
+}
+
+// Take counts a request of client against the read or write budget. An
+// error means the buckets could not be reached; callers let the request
+// pass then.
+func (l *RateLimiter) Take(ctx context.Context, read bool, client string) (Decision, error) {
+	decision := Decision{Class: "write", Budget: l.write}
+	if read {
+		decision.Class, decision.Budget = "read", l.read
+	}
+	now := time.Now()
+	l.purge(ctx, now)
+
+	err := l.buckets.Update(ctx, decision.Class+":"+client, func(bucket *entities.RateLimitBucket) {
+		decision.Allowed = take(bucket, decision.Budget, now)
+		decision.Tokens = bucket.Tokens
+	})
+	return decision, err
+}
+
+// take refills bucket for the time since it was last used and takes a token
+// when a whole one is left. A new bucket starts full.
+func take(bucket *entities.RateLimitBucket, budget Budget, now time.Time) bool {
+	if bucket.UpdatedAt.IsZero() {
+		bucket.Tokens = float64(budget.Burst)
+		bucket.UpdatedAt = now
+	}
+	// Replica clocks may disagree slightly; never move a bucket back in time
+	if elapsed := now.Sub(bucket.UpdatedAt); elapsed > 0 {
+		bucket.Tokens = math.Min(float64(budget.Burst), bucket.Tokens+elapsed.Seconds()*budget.rate())
+		bucket.UpdatedAt = now
+	}
+	if bucket.Tokens < 1 {
+		return false
+	}
+	bucket.Tokens--
+	return true
+}
+
+// purge deletes the buckets that have been idle long enough to be full
+// again, which is how a missing bucket starts, at most once per
+// rateLimitPurgeInterval
+func (l *RateLimiter) purge(ctx context.Context, now time.Time) {
+	l.mu.Lock()
+	if now.Sub(l.lastPurge) < rateLimitPurgeInterval {
+		l.mu.Unlock()
+		return
+	}
+	l.lastPurge = now
+	l.mu.Unlock()
+
+	idle := 0.0
+	for _, budget := range []Budget{l.read, l.write} {
+		idle = math.Max(idle, float64(budget.Burst)/budget.rate())
+	}
+	count, err := l.buckets.DeleteIdle(ctx, now.Add(-time.Duration(idle*float64(time.Second))))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to purge idle rate limit buckets", slog.Any("error", err))
+		return
+	}
+	if count > 0 {
+		slog.DebugContext(ctx, "purged idle rate limit buckets", slog.Int64("count", count))
+	}
+}
+
+// seconds formats d seconds as a whole number, rounded up
+func seconds(d float64) string {
+	return strconv.Itoa(int(math.Ceil(math.Max(d, 0))))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"issue-tracking/migrations"
+)
+
+// runMigrateCommand implements `issue-tracking migrate up|down|status|create`
+func runMigrateCommand(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	steps := fs.Int("steps", 0, "number of migrations to apply or roll back (up: default all, down: default 1)")
+	dir := fs.String("dir", "", "directory new migrations are created in (default: one per database driver)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: issue-tracking migrate [flags] up|down|status|create NAME")
+		fs.PrintDefaults()
+	}
+	cfg := loadConfig(fs, args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("expected a migrate command")
+	}
+	command := fs.Arg(0)
+
+	// create only writes files and needs no database
+	if command == "create" {
+		if fs.NArg() != 2 {
+			return fmt.Errorf("usage: migrate create NAME")
+		}
+		// Every driver gets the pair so their versions stay in step
+		dirs := migrations.Dirs
+		if *dir != "" {
+			dirs = []string{*dir}
+		}
+		for _, d := range dirs {
+			paths, err := migrations.Create(d, fs.Arg(1))
+			for _, path := range paths {
+				fmt.Println("created", path)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	db, shutdown, err := setup(cfg, os.Stderr, false)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	migrator, err := migrations.New(sqlDB, db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx, *steps)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+
+	case "down":
+		if *steps == 0 {
+			*steps = 1
+		}
+		rolledBack, err := migrator.Down(ctx, *steps)
+		for _, m := range rolledBack {
+			fmt.Printf("rolled back %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+
+	case "status":
+		list, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range list {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return w.Flush()
+
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+}
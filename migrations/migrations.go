@@ -0,0 +1,353 @@
+// Package migrations applies the versioned SQL schema migrations embedded in
+// the binary. Each migration is a pair of files named
+// NNNN_description.up.sql and NNNN_description.down.sql, written once per
+// driver in the postgres and sqlite directories with the same versions and
+// names. Applied versions are recorded in the schema_migrations table.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Dirs are where new migrations are created, one per driver, relative to
+// the repository root
+var Dirs = []string{"migrations/postgres", "migrations/sqlite"}
+
+// lockKey identifies the advisory lock held while migrating so replicas
+// starting together apply each migration once
+const lockKey int64 = 0x69737375655f6d67 // "issue_mg"
+
+// dialect holds the statements that differ between drivers
+type dialect struct {
+	// lock and unlock are empty when the driver has no advisory locks
+	lock, unlock string
+	tableExists  string
+	createTable  string
+	record       string
+	unrecord     string
+}
+
+var dialects = map[string]dialect{
+	"postgres": {
+		lock:        `SELECT pg_advisory_lock($1)`,
+		unlock:      `SELECT pg_advisory_unlock($1)`,
+		tableExists: `SELECT to_regclass('schema_migrations') IS NOT NULL`,
+		createTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL
+		)`,
+		record:   `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+		unrecord: `DELETE FROM schema_migrations WHERE version = $1`,
+	},
+	// SQLite databases serve a single node, so there is nobody to race
+	"sqlite": {
+		tableExists: `SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`,
+		createTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)`,
+		record:   `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		unrecord: `DELETE FROM schema_migrations WHERE version = ?`,
+	},
+}
+
+// fileName matches 0001_create_users.up.sql
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// nonWord is replaced by underscores in new migration names
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Migration is one schema change with its rollback
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status reports whether a migration has been applied
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// Load reads the embedded migrations of a driver in version order
+func Load(driver string) ([]Migration, error) {
+	if _, ok := dialects[driver]; !ok {
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+	sub, err := fs.Sub(files, driver)
+	if err != nil {
+		return nil, err
+	}
+	return parse(sub)
+}
+
+// parse reads migration pairs from fsys and checks that versions are unique
+// and every up file has a down file
+func parse(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		m := fileName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration file %s does not match NNNN_name.(up|down).sql", entry.Name())
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(content)
+		} else {
+			mig.Down = string(content)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", mig.Version, mig.Name)
+		}
+		list = append(list, *mig)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// Migrator applies migrations to a PostgreSQL or SQLite database
+type Migrator struct {
+	db         *sql.DB
+	dialect    dialect
+	migrations []Migration
+}
+
+// New returns a migrator for the embedded migrations of driver, which is
+// "postgres" or "sqlite" as reported by gorm.Dialector.Name()
+func New(db *sql.DB, driver string) (*Migrator, error) {
+	list, err := Load(driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialects[driver], migrations: list}, nil
+}
+
+// Up applies up to steps pending migrations in order, or all of them when
+// steps is 0, and returns the ones applied
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+			err := inTx(ctx, conn, mig.Up, m.dialect.record, mig.Version, mig.Name, time.Now().UTC())
+			if err != nil {
+				return fmt.Errorf("apply %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations, newest first, and
+// returns the ones rolled back
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var rolledBack []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+			err := inTx(ctx, conn, mig.Down, m.dialect.unrecord, mig.Version)
+			if err != nil {
+				return fmt.Errorf("roll back %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			rolledBack = append(rolledBack, mig)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status lists every known migration and when it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Read-only: a database that was never migrated has everything pending
+	var exists bool
+	if err := conn.QueryRowContext(ctx, m.dialect.tableExists).Scan(&exists); err != nil {
+		return nil, err
+	}
+	done := map[int64]time.Time{}
+	if exists {
+		if done, err = appliedVersions(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	list := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		list[i] = Status{Version: mig.Version, Name: mig.Name}
+		if at, ok := done[mig.Version]; ok {
+			list[i].AppliedAt = &at
+		}
+	}
+	return list, nil
+}
+
+// Pending returns the number of migrations not applied yet
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	list, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, s := range list {
+		if s.AppliedAt == nil {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// withLock runs fn on one connection holding the migration advisory lock,
+// when the driver has one. Session-level advisory locks belong to a
+// connection, so everything must run on the same one.
+func (m *Migrator) withLock(ctx context.Context, fn func(*sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect.lock != "" {
+		if _, err := conn.ExecContext(ctx, m.dialect.lock, lockKey); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), m.dialect.unlock, lockKey)
+	}
+
+	// Create schema_migrations on first use
+	if _, err := conn.ExecContext(ctx, m.dialect.createTable); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+// appliedVersions returns when each applied version was applied
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		done[version] = at
+	}
+	return done, rows.Err()
+}
+
+// inTx runs a migration script and its bookkeeping statement atomically
+func inTx(ctx context.Context, conn *sql.Conn, script, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Create writes an empty up/down pair to dir, numbered after the highest
+// existing version, and returns the paths written
+func Create(dir, name string) ([]string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = nonWord.ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	existing, err := parse(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+	next := int64(1)
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%04d_%s.%s.sql", next, name, direction))
+		content := fmt.Sprintf("-- %04d %s (%s)\n", next, name, direction)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,195 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"issue-tracking/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	list, err := Load("postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) < 2 {
+		t.Fatalf("got %d migrations, want at least 2", len(list))
+	}
+	for i, m := range list {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d has version %d, versions must be contiguous", i, m.Version)
+		}
+	}
+	if !strings.Contains(list[1].Up, "FOREIGN KEY") {
+		t.Errorf("expected 0002 to add foreign keys")
+	}
+
+	if _, err := Load("mysql"); err == nil {
+		t.Error("expected an error for a driver without migrations")
+	}
+}
+
+// TestDriversInStep checks that every driver has the same migrations, so a
+// version means the same schema on each
+func TestDriversInStep(t *testing.T) {
+	want, err := Load("postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("sqlite has %d migrations, postgres has %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Version != want[i].Version || got[i].Name != want[i].Name {
+			t.Errorf("sqlite migration %04d_%s does not match postgres %04d_%s",
+				got[i].Version, got[i].Name, want[i].Version, want[i].Name)
+		}
+	}
+}
+
+func TestParseRejectsBrokenSets(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		want  string
+	}{
+		{"missing down", fstest.MapFS{"0001_a.up.sql": {Data: []byte("SELECT 1")}}, "needs both"},
+		{"bad name", fstest.MapFS{"first.sql": {Data: []byte("SELECT 1")}}, "does not match"},
+		{"two names", fstest.MapFS{
+			"0001_a.up.sql":   {Data: []byte("SELECT 1")},
+			"0001_b.down.sql": {Data: []byte("SELECT 1")},
+		}, "two names"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.files)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateNumbersAfterHighestVersion(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0001_a.up.sql", "0001_a.down.sql", "0007_b.up.sql", "0007_b.down.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := Create(dir, "Add Issue Due-Date")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(dir, "0008_add_issue_due_date.up.sql"),
+		filepath.Join(dir, "0008_add_issue_due_date.down.sql"),
+	}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("created %v, want %v", paths, want)
+	}
+	if _, err := parse(os.DirFS(dir)); err != nil {
+		t.Errorf("created files do not parse: %v", err)
+	}
+}
+
+// TestUpDownRoundTrip runs every migration up, down and up again on a
+// SQLite file and, when TEST_DATABASE_URL is set, in a scratch schema of
+// that PostgreSQL database
+func TestUpDownRoundTrip(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := database.Open("sqlite:"+filepath.Join(t.TempDir(), "test.db"), silent())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		})
+		testUpDownRoundTrip(t, db)
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		dsn := os.Getenv("TEST_DATABASE_URL")
+		if dsn == "" {
+			t.Skip("TEST_DATABASE_URL not set, skipping PostgreSQL test")
+		}
+		admin, err := database.Open(dsn, silent())
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema := fmt.Sprintf("migrations_test_%d", time.Now().UnixNano())
+		if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+		sep := " "
+		if strings.Contains(dsn, "://") {
+			sep = "&"
+			if !strings.Contains(dsn, "?") {
+				sep = "?"
+			}
+		}
+		db, err := database.Open(dsn+sep+"search_path="+schema, silent())
+		if err != nil {
+			t.Fatal(err)
+		}
+		testUpDownRoundTrip(t, db)
+	})
+}
+
+func silent() *gorm.Config {
+	return &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+}
+
+func testUpDownRoundTrip(t *testing.T, db *gorm.DB) {
+	sqlDB, _ := db.DB()
+	migrator, err := New(sqlDB, db.Dialector.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if pending, err := migrator.Pending(ctx); err != nil || pending != len(migrator.migrations) {
+		t.Fatalf("pending = %d, %v before migrating", pending, err)
+	}
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ := migrator.Pending(ctx); pending != 0 {
+		t.Fatalf("pending = %d after up", pending)
+	}
+
+	// Foreign keys are enforced
+	err = db.Exec("INSERT INTO issues (reporter_id, status_id, title, priority) VALUES (999, 999, 'Orphan', 'low')").Error
+	if err == nil {
+		t.Error("expected a foreign key violation for an issue without reporter")
+	}
+
+	rolledBack, err := migrator.Down(ctx, len(migrator.migrations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rolledBack) != len(migrator.migrations) {
+		t.Fatalf("rolled back %d migrations", len(rolledBack))
+	}
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		t.Fatalf("second up failed: %v", err)
+	}
+}
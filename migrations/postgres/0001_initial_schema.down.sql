@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS comments;
+DROP TABLE IF EXISTS issue_status_history;
+DROP TABLE IF EXISTS issue_labels;
+DROP TABLE IF EXISTS issues;
+DROP TABLE IF EXISTS labels;
+DROP TABLE IF EXISTS issue_statuses;
+DROP TABLE IF EXISTS officer;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,102 @@
+-- Baseline schema. Every statement is guarded so databases created by the
+-- former AutoMigrate start from the same state as new ones.
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id    BIGSERIAL PRIMARY KEY,
+    full_name  TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS officer (
+    officer_id BIGSERIAL PRIMARY KEY,
+    full_name  TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS issue_statuses (
+    status_id     BIGSERIAL PRIMARY KEY,
+    status_code   VARCHAR(50) NOT NULL,
+    display_name  VARCHAR(100) NOT NULL,
+    description   TEXT,
+    color         VARCHAR(7) NOT NULL,
+    display_order BIGINT NOT NULL DEFAULT 0,
+    is_active     BOOLEAN DEFAULT TRUE,
+    is_terminal   BOOLEAN DEFAULT FALSE,
+    created_at    TIMESTAMPTZ,
+    CONSTRAINT uni_issue_statuses_status_code UNIQUE (status_code)
+);
+CREATE INDEX IF NOT EXISTS idx_issue_statuses_status_code ON issue_statuses (status_code);
+CREATE INDEX IF NOT EXISTS idx_issue_statuses_display_order ON issue_statuses (display_order);
+
+CREATE TABLE IF NOT EXISTS labels (
+    label_id   BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(50) NOT NULL,
+    color      VARCHAR(7) NOT NULL DEFAULT '#808080',
+    created_at TIMESTAMPTZ,
+    CONSTRAINT uni_labels_name UNIQUE (name)
+);
+
+CREATE TABLE IF NOT EXISTS issues (
+    issue_id    BIGSERIAL PRIMARY KEY,
+    reporter_id BIGINT NOT NULL,
+    assignee_id BIGINT,
+    status_id   BIGINT NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    description TEXT,
+    priority    VARCHAR(20) NOT NULL DEFAULT 'medium',
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_issues_reporter_id ON issues (reporter_id);
+CREATE INDEX IF NOT EXISTS idx_issues_assignee_id ON issues (assignee_id);
+CREATE INDEX IF NOT EXISTS idx_issues_status_id ON issues (status_id);
+CREATE INDEX IF NOT EXISTS idx_issues_priority ON issues (priority);
+CREATE INDEX IF NOT EXISTS idx_issues_created_at ON issues (created_at);
+
+CREATE TABLE IF NOT EXISTS issue_labels (
+    issue_id BIGINT NOT NULL,
+    label_id BIGINT NOT NULL,
+    PRIMARY KEY (issue_id, label_id)
+);
+
+CREATE TABLE IF NOT EXISTS issue_status_history (
+    history_id    BIGSERIAL PRIMARY KEY,
+    issue_id      BIGINT NOT NULL,
+    old_status_id BIGINT,
+    new_status_id BIGINT NOT NULL,
+    changed_by    BIGINT NOT NULL,
+    comment       TEXT,
+    changed_at    TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_issue_status_history_issue_id ON issue_status_history (issue_id);
+CREATE INDEX IF NOT EXISTS idx_issue_status_history_old_status_id ON issue_status_history (old_status_id);
+CREATE INDEX IF NOT EXISTS idx_issue_status_history_new_status_id ON issue_status_history (new_status_id);
+CREATE INDEX IF NOT EXISTS idx_issue_status_history_changed_by ON issue_status_history (changed_by);
+CREATE INDEX IF NOT EXISTS idx_issue_status_history_changed_at ON issue_status_history (changed_at);
+
+CREATE TABLE IF NOT EXISTS comments (
+    comment_id BIGSERIAL PRIMARY KEY,
+    issue_id   BIGINT NOT NULL,
+    user_id    BIGINT NOT NULL,
+    content    TEXT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_comments_issue_id ON comments (issue_id);
+CREATE INDEX IF NOT EXISTS idx_comments_created_at ON comments (created_at);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(255) PRIMARY KEY,
+    method          VARCHAR(10) NOT NULL,
+    path            VARCHAR(255) NOT NULL,
+    request_hash    VARCHAR(64) NOT NULL,
+    status_code     BIGINT NOT NULL DEFAULT 0,
+    content_type    VARCHAR(100),
+    response_body   BYTEA,
+    created_at      TIMESTAMPTZ,
+    expires_at      TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,15 @@
+ALTER TABLE issue_labels
+    DROP CONSTRAINT IF EXISTS fk_issue_labels_label,
+    DROP CONSTRAINT IF EXISTS fk_issue_labels_issue;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS fk_comments_user,
+    DROP CONSTRAINT IF EXISTS fk_issues_comments;
+
+ALTER TABLE issue_status_history
+    DROP CONSTRAINT IF EXISTS fk_issues_status_history;
+
+ALTER TABLE issues
+    DROP CONSTRAINT IF EXISTS fk_issues_status,
+    DROP CONSTRAINT IF EXISTS fk_issues_assignee,
+    DROP CONSTRAINT IF EXISTS fk_issues_reporter;
@@ -0,0 +1,26 @@
+-- Foreign keys for the relations declared on the entities. This fails if
+-- existing rows reference missing parents; clean those up and run it again.
+
+ALTER TABLE issues
+    ADD CONSTRAINT fk_issues_reporter FOREIGN KEY (reporter_id)
+        REFERENCES users (user_id) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_issues_assignee FOREIGN KEY (assignee_id)
+        REFERENCES officer (officer_id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_issues_status FOREIGN KEY (status_id)
+        REFERENCES issue_statuses (status_id) ON DELETE RESTRICT;
+
+ALTER TABLE issue_status_history
+    ADD CONSTRAINT fk_issues_status_history FOREIGN KEY (issue_id)
+        REFERENCES issues (issue_id) ON DELETE CASCADE;
+
+ALTER TABLE comments
+    ADD CONSTRAINT fk_issues_comments FOREIGN KEY (issue_id)
+        REFERENCES issues (issue_id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_comments_user FOREIGN KEY (user_id)
+        REFERENCES users (user_id) ON DELETE RESTRICT;
+
+ALTER TABLE issue_labels
+    ADD CONSTRAINT fk_issue_labels_issue FOREIGN KEY (issue_id)
+        REFERENCES issues (issue_id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_issue_labels_label FOREIGN KEY (label_id)
+        REFERENCES labels (label_id) ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+ALTER TABLE issues
+    DROP CONSTRAINT IF EXISTS fk_issues_api_key,
+    DROP COLUMN IF EXISTS api_key_id,
+    DROP COLUMN IF EXISTS source;
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,20 @@
+-- API keys of integrations, and the key that opened each issue
+CREATE TABLE IF NOT EXISTS api_keys (
+    api_key_id   BIGSERIAL PRIMARY KEY,
+    name         VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(20) NOT NULL,
+    key_hash     VARCHAR(64) NOT NULL,
+    scopes       VARCHAR(255) NOT NULL,
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys (prefix);
+
+ALTER TABLE issues
+    ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS api_key_id BIGINT,
+    ADD CONSTRAINT fk_issues_api_key FOREIGN KEY (api_key_id)
+        REFERENCES api_keys (api_key_id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_issues_api_key_id ON issues (api_key_id);
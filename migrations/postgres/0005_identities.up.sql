@@ -0,0 +1,22 @@
+-- Accounts at the OpenID Connect provider and the user and officer each
+-- signs in as
+CREATE TABLE IF NOT EXISTS identities (
+    identity_id   BIGSERIAL PRIMARY KEY,
+    issuer        VARCHAR(255) NOT NULL,
+    subject       VARCHAR(255) NOT NULL,
+    email         VARCHAR(255),
+    name          VARCHAR(255) NOT NULL,
+    role          VARCHAR(20) NOT NULL,
+    user_id       BIGINT NOT NULL,
+    officer_id    BIGINT,
+    last_login_at TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ,
+    updated_at    TIMESTAMPTZ,
+    CONSTRAINT fk_identities_user FOREIGN KEY (user_id)
+        REFERENCES users (user_id) ON DELETE CASCADE,
+    CONSTRAINT fk_identities_officer FOREIGN KEY (officer_id)
+        REFERENCES officer (officer_id) ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identities_subject ON identities (issuer, subject);
+CREATE INDEX IF NOT EXISTS idx_identities_user_id ON identities (user_id);
+CREATE INDEX IF NOT EXISTS idx_identities_officer_id ON identities (officer_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS tracking_tokens;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS phone,
+    DROP COLUMN IF EXISTS email;
@@ -0,0 +1,16 @@
+-- Contact details of reporters and the tokens people who reported on the
+-- public portal follow their issue with
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS phone VARCHAR(50);
+
+CREATE TABLE IF NOT EXISTS tracking_tokens (
+    tracking_token_id BIGSERIAL PRIMARY KEY,
+    issue_id          BIGINT NOT NULL,
+    token_hash        VARCHAR(64) NOT NULL,
+    created_at        TIMESTAMPTZ,
+    CONSTRAINT fk_tracking_tokens_issue FOREIGN KEY (issue_id)
+        REFERENCES issues (issue_id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tracking_tokens_issue_id ON tracking_tokens (issue_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tracking_tokens_token_hash ON tracking_tokens (token_hash);
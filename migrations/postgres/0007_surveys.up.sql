@@ -0,0 +1,18 @@
+-- Satisfaction surveys sent to reporters when their issue closes
+CREATE TABLE IF NOT EXISTS surveys (
+    survey_id    BIGSERIAL PRIMARY KEY,
+    issue_id     BIGINT NOT NULL,
+    officer_id   BIGINT,
+    rating       INTEGER,
+    feedback     TEXT,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    responded_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ,
+    CONSTRAINT fk_surveys_issue FOREIGN KEY (issue_id)
+        REFERENCES issues (issue_id) ON DELETE CASCADE,
+    CONSTRAINT fk_surveys_officer FOREIGN KEY (officer_id)
+        REFERENCES officer (officer_id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_surveys_issue_id ON surveys (issue_id);
+CREATE INDEX IF NOT EXISTS idx_surveys_officer_id ON surveys (officer_id);
+CREATE INDEX IF NOT EXISTS idx_surveys_created_at ON surveys (created_at);
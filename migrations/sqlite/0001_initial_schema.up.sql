@@ -0,0 +1,110 @@
+-- Baseline schema for SQLite. SQLite cannot add constraints to existing
+-- tables, so the foreign keys that PostgreSQL adds in 0002 are declared here.
+
+CREATE TABLE users (
+    user_id    INTEGER PRIMARY KEY AUTOINCREMENT,
+    full_name  TEXT NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME
+);
+
+CREATE TABLE officer (
+    officer_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    full_name  TEXT NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME
+);
+
+CREATE TABLE issue_statuses (
+    status_id     INTEGER PRIMARY KEY AUTOINCREMENT,
+    status_code   VARCHAR(50) NOT NULL,
+    display_name  VARCHAR(100) NOT NULL,
+    description   TEXT,
+    color         VARCHAR(7) NOT NULL,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    is_active     BOOLEAN DEFAULT TRUE,
+    is_terminal   BOOLEAN DEFAULT FALSE,
+    created_at    DATETIME,
+    CONSTRAINT uni_issue_statuses_status_code UNIQUE (status_code)
+);
+CREATE INDEX idx_issue_statuses_status_code ON issue_statuses (status_code);
+CREATE INDEX idx_issue_statuses_display_order ON issue_statuses (display_order);
+
+CREATE TABLE labels (
+    label_id   INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(50) NOT NULL,
+    color      VARCHAR(7) NOT NULL DEFAULT '#808080',
+    created_at DATETIME,
+    CONSTRAINT uni_labels_name UNIQUE (name)
+);
+
+CREATE TABLE issues (
+    issue_id    INTEGER PRIMARY KEY AUTOINCREMENT,
+    reporter_id INTEGER NOT NULL
+        CONSTRAINT fk_issues_reporter REFERENCES users (user_id) ON DELETE RESTRICT,
+    assignee_id INTEGER
+        CONSTRAINT fk_issues_assignee REFERENCES officer (officer_id) ON DELETE SET NULL,
+    status_id   INTEGER NOT NULL
+        CONSTRAINT fk_issues_status REFERENCES issue_statuses (status_id) ON DELETE RESTRICT,
+    title       VARCHAR(255) NOT NULL,
+    description TEXT,
+    priority    VARCHAR(20) NOT NULL DEFAULT 'medium',
+    created_at  DATETIME,
+    updated_at  DATETIME
+);
+CREATE INDEX idx_issues_reporter_id ON issues (reporter_id);
+CREATE INDEX idx_issues_assignee_id ON issues (assignee_id);
+CREATE INDEX idx_issues_status_id ON issues (status_id);
+CREATE INDEX idx_issues_priority ON issues (priority);
+CREATE INDEX idx_issues_created_at ON issues (created_at);
+
+CREATE TABLE issue_labels (
+    issue_id INTEGER NOT NULL
+        CONSTRAINT fk_issue_labels_issue REFERENCES issues (issue_id) ON DELETE CASCADE,
+    label_id INTEGER NOT NULL
+        CONSTRAINT fk_issue_labels_label REFERENCES labels (label_id) ON DELETE CASCADE,
+    PRIMARY KEY (issue_id, label_id)
+);
+
+CREATE TABLE issue_status_history (
+    history_id    INTEGER PRIMARY KEY AUTOINCREMENT,
+    issue_id      INTEGER NOT NULL
+        CONSTRAINT fk_issues_status_history REFERENCES issues (issue_id) ON DELETE CASCADE,
+    old_status_id INTEGER,
+    new_status_id INTEGER NOT NULL,
+    changed_by    INTEGER NOT NULL,
+    comment       TEXT,
+    changed_at    DATETIME
+);
+CREATE INDEX idx_issue_status_history_issue_id ON issue_status_history (issue_id);
+CREATE INDEX idx_issue_status_history_old_status_id ON issue_status_history (old_status_id);
+CREATE INDEX idx_issue_status_history_new_status_id ON issue_status_history (new_status_id);
+CREATE INDEX idx_issue_status_history_changed_by ON issue_status_history (changed_by);
+CREATE INDEX idx_issue_status_history_changed_at ON issue_status_history (changed_at);
+
+CREATE TABLE comments (
+    comment_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    issue_id   INTEGER NOT NULL
+        CONSTRAINT fk_issues_comments REFERENCES issues (issue_id) ON DELETE CASCADE,
+    user_id    INTEGER NOT NULL
+        CONSTRAINT fk_comments_user REFERENCES users (user_id) ON DELETE RESTRICT,
+    content    TEXT NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX idx_comments_issue_id ON comments (issue_id);
+CREATE INDEX idx_comments_created_at ON comments (created_at);
+
+CREATE TABLE idempotency_keys (
+    idempotency_key VARCHAR(255) PRIMARY KEY,
+    method          VARCHAR(10) NOT NULL,
+    path            VARCHAR(255) NOT NULL,
+    request_hash    VARCHAR(64) NOT NULL,
+    status_code     INTEGER NOT NULL DEFAULT 0,
+    content_type    VARCHAR(100),
+    response_body   BLOB,
+    created_at      DATETIME,
+    expires_at      DATETIME NOT NULL
+);
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,2 @@
+-- Nothing to undo, see 0002_foreign_keys.up.sql
+SELECT 1;
@@ -0,0 +1,3 @@
+-- The foreign keys are declared with the tables in 0001 on SQLite. This
+-- migration only keeps the version numbers in step with PostgreSQL.
+SELECT 1;
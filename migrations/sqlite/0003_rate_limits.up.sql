@@ -0,0 +1,7 @@
+-- Token buckets of the rate limiter when limits are shared by replicas
+CREATE TABLE rate_limit_buckets (
+    bucket_key VARCHAR(255) PRIMARY KEY,
+    tokens     REAL NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE INDEX idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_issues_api_key_id;
+ALTER TABLE issues DROP COLUMN api_key_id;
+ALTER TABLE issues DROP COLUMN source;
+
+DROP TABLE IF EXISTS api_keys;
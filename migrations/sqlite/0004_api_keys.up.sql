@@ -0,0 +1,18 @@
+-- API keys of integrations, and the key that opened each issue
+CREATE TABLE api_keys (
+    api_key_id   INTEGER PRIMARY KEY AUTOINCREMENT,
+    name         VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(20) NOT NULL,
+    key_hash     VARCHAR(64) NOT NULL,
+    scopes       VARCHAR(255) NOT NULL,
+    expires_at   DATETIME,
+    last_used_at DATETIME,
+    revoked_at   DATETIME,
+    created_at   DATETIME
+);
+CREATE UNIQUE INDEX idx_api_keys_prefix ON api_keys (prefix);
+
+ALTER TABLE issues ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'user';
+ALTER TABLE issues ADD COLUMN api_key_id INTEGER
+    CONSTRAINT fk_issues_api_key REFERENCES api_keys (api_key_id) ON DELETE SET NULL;
+CREATE INDEX idx_issues_api_key_id ON issues (api_key_id);
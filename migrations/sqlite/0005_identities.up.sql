@@ -0,0 +1,20 @@
+-- Accounts at the OpenID Connect provider and the user and officer each
+-- signs in as
+CREATE TABLE identities (
+    identity_id   INTEGER PRIMARY KEY AUTOINCREMENT,
+    issuer        VARCHAR(255) NOT NULL,
+    subject       VARCHAR(255) NOT NULL,
+    email         VARCHAR(255),
+    name          VARCHAR(255) NOT NULL,
+    role          VARCHAR(20) NOT NULL,
+    user_id       INTEGER NOT NULL
+        CONSTRAINT fk_identities_user REFERENCES users (user_id) ON DELETE CASCADE,
+    officer_id    INTEGER
+        CONSTRAINT fk_identities_officer REFERENCES officer (officer_id) ON DELETE SET NULL,
+    last_login_at DATETIME,
+    created_at    DATETIME,
+    updated_at    DATETIME
+);
+CREATE UNIQUE INDEX idx_identities_subject ON identities (issuer, subject);
+CREATE INDEX idx_identities_user_id ON identities (user_id);
+CREATE INDEX idx_identities_officer_id ON identities (officer_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS tracking_tokens;
+
+ALTER TABLE users DROP COLUMN phone;
+ALTER TABLE users DROP COLUMN email;
@@ -0,0 +1,14 @@
+-- Contact details of reporters and the tokens people who reported on the
+-- public portal follow their issue with
+ALTER TABLE users ADD COLUMN email VARCHAR(255);
+ALTER TABLE users ADD COLUMN phone VARCHAR(50);
+
+CREATE TABLE tracking_tokens (
+    tracking_token_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    issue_id          INTEGER NOT NULL
+        CONSTRAINT fk_tracking_tokens_issue REFERENCES issues (issue_id) ON DELETE CASCADE,
+    token_hash        VARCHAR(64) NOT NULL,
+    created_at        DATETIME
+);
+CREATE UNIQUE INDEX idx_tracking_tokens_issue_id ON tracking_tokens (issue_id);
+CREATE UNIQUE INDEX idx_tracking_tokens_token_hash ON tracking_tokens (token_hash);
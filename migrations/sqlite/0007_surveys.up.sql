@@ -0,0 +1,16 @@
+-- Satisfaction surveys sent to reporters when their issue closes
+CREATE TABLE surveys (
+    survey_id    INTEGER PRIMARY KEY AUTOINCREMENT,
+    issue_id     INTEGER NOT NULL
+        CONSTRAINT fk_surveys_issue REFERENCES issues (issue_id) ON DELETE CASCADE,
+    officer_id   INTEGER
+        CONSTRAINT fk_surveys_officer REFERENCES officer (officer_id) ON DELETE SET NULL,
+    rating       INTEGER,
+    feedback     TEXT,
+    expires_at   DATETIME NOT NULL,
+    responded_at DATETIME,
+    created_at   DATETIME
+);
+CREATE INDEX idx_surveys_issue_id ON surveys (issue_id);
+CREATE INDEX idx_surveys_officer_id ON surveys (officer_id);
+CREATE INDEX idx_surveys_created_at ON surveys (created_at);
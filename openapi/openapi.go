@@ -0,0 +1,233 @@
+// Package openapi builds the OpenAPI 3 document of the API from the routes
+// registered on the router, a description of each operation and the Go types
+// the handlers accept and return. It also validates requests against the
+// document and serves it together with Swagger UI.
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"issue-tracking/utils"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// Operation describes one route. Path parameters are taken from the route
+// and are numeric IDs.
+type Operation struct {
+	Summary     string
+	Description string
+	Tag         string
+	Params      []Param
+
+	// Body is a value of the JSON request body type, nil when the route
+	// takes no body
+	Body interface{}
+	// Upload names the multipart field of an uploaded file; the file may
+	// also be sent as the raw request body. Uploads are not validated.
+	Upload string
+
+	// Status is the success status, 200 when zero. Redirects have no body.
+	Status int
+	// Response is a value of the type returned in the data field of the
+	// success response
+	Response interface{}
+	// Produces lists the content types of a response that is not the JSON
+	// envelope, such as a file download
+	Produces []string
+	// Errors lists the error statuses the route returns besides 500
+	Errors []int
+	// Unwrapped responses are Response itself rather than the success or
+	// error envelope, as with the health probes
+	Unwrapped bool
+
+	// Hidden leaves the route out of the document
+	Hidden bool
+}
+
+// Param is a query or header parameter
+type Param struct {
+	Name        string
+	In          string // "query" when empty, or "header"
+	Description string
+	Schema      *openapi3.Schema
+	Required    bool
+}
+
+// Spec is the document of one router. Its middleware and handler can be
+// registered before the routes they describe; they use the document once
+// Build has run.
+type Spec struct {
+	doc    *openapi3.T
+	routes map[string]*routers.Route
+}
+
+// New returns an empty document
+func New(title, version string) *Spec {
+	return &Spec{doc: &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: title, Version: version},
+		Paths:   openapi3.NewPaths(),
+	}}
+}
+
+// Build describes every route with the operation registered under
+// "METHOD /path", using gin's path syntax. Operations without a route, such
+// as those of disabled features, are skipped. It fails when a route has no
+// operation or the resulting document is invalid.
+func (s *Spec) Build(routes gin.RoutesInfo, operations map[string]Operation) error {
+	sorted := append(gin.RoutesInfo(nil), routes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].Method < sorted[j].Method
+	})
+
+	schemas := newSchemas()
+	errorRef := schemas.of(utils.ErrorResponse{})
+	lookup := map[string]*routers.Route{}
+	var undocumented []string
+
+	for _, route := range sorted {
+		key := route.Method + " " + route.Path
+		op, ok := operations[key]
+		if !ok {
+			undocumented = append(undocumented, key)
+			continue
+		}
+		if op.Hidden {
+			continue
+		}
+
+		path, pathParams := convertPath(route.Path)
+		operation := op.describe(schemas, errorRef)
+		operation.Parameters = append(pathParams, operation.Parameters...)
+
+		item := s.doc.Paths.Value(path)
+		if item == nil {
+			item = &openapi3.PathItem{}
+			s.doc.Paths.Set(path, item)
+		}
+		item.SetOperation(route.Method, operation)
+		lookup[key] = &routers.Route{Spec: s.doc, Path: path, PathItem: item, Method: route.Method, Operation: operation}
+	}
+	if len(undocumented) > 0 {
+		return fmt.Errorf("routes without an OpenAPI operation: %s", strings.Join(undocumented, ", "))
+	}
+
+	s.doc.Components = &openapi3.Components{Schemas: schemas.components}
+	if err := s.doc.Validate(context.Background()); err != nil {
+		return fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	s.routes = lookup
+	return nil
+}
+
+// Document returns the document built from the routes
+func (s *Spec) Document() *openapi3.T {
+	return s.doc
+}
+
+// Handler serves the document as JSON
+func (s *Spec) Handler(c *gin.Context) {
+	c.JSON(http.StatusOK, s.doc)
+}
+
+// describe turns op into an OpenAPI operation
+func (op Operation) describe(schemas *schemas, errorRef *openapi3.SchemaRef) *openapi3.Operation {
+	operation := &openapi3.Operation{
+		Summary:     op.Summary,
+		Description: op.Description,
+		Responses:   openapi3.NewResponses(),
+	}
+	if op.Tag != "" {
+		operation.Tags = []string{op.Tag}
+	}
+
+	for _, p := range op.Params {
+		in := p.In
+		if in == "" {
+			in = openapi3.ParameterInQuery
+		}
+		operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{Value: &openapi3.Parameter{
+			Name:        p.Name,
+			In:          in,
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      inline(p.Schema),
+		}})
+	}
+
+	switch {
+	case op.Body != nil:
+		operation.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+			WithRequired(true).
+			WithJSONSchemaRef(schemas.of(op.Body))}
+	case op.Upload != "":
+		file := openapi3.NewObjectSchema().
+			WithProperty(op.Upload, openapi3.NewStringSchema().WithFormat("binary")).
+			WithRequired([]string{op.Upload})
+		operation.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+			WithRequired(true).
+			WithContent(openapi3.Content{
+				"multipart/form-data": openapi3.NewMediaType().WithSchema(file),
+				"*/*":                 openapi3.NewMediaType(),
+			})}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := openapi3.NewResponse().WithDescription(http.StatusText(status))
+	switch {
+	case op.Unwrapped:
+		errorRef = schemas.of(op.Response)
+		success.Content = openapi3.NewContentWithJSONSchemaRef(errorRef)
+	case status >= 300 && status < 400:
+		// Redirects only say where to go
+		location := &openapi3.Header{Parameter: openapi3.Parameter{Schema: openapi3.NewStringSchema().NewRef()}}
+		success.Headers = openapi3.Headers{"Location": &openapi3.HeaderRef{Value: location}}
+	case len(op.Produces) > 0:
+		binary := openapi3.NewStringSchema().WithFormat("binary")
+		success.Content = openapi3.Content{}
+		for _, contentType := range op.Produces {
+			success.Content[contentType] = openapi3.NewMediaType().WithSchema(binary)
+		}
+	default:
+		success.Content = openapi3.NewContentWithJSONSchemaRef(schemas.of(utils.SuccessResponse{Status: status, Data: op.Response}))
+	}
+	operation.AddResponse(status, success)
+
+	codes := append([]int{}, op.Errors...)
+	for _, code := range append(codes, http.StatusInternalServerError) {
+		operation.AddResponse(code, openapi3.NewResponse().
+			WithDescription(http.StatusText(code)).
+			WithContent(openapi3.NewContentWithJSONSchemaRef(errorRef)))
+	}
+	return operation
+}
+
+// convertPath turns /api/issues/:id into /api/issues/{id} and describes its
+// parameters
+func convertPath(path string) (string, openapi3.Parameters) {
+	var params openapi3.Parameters
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok {
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		id := openapi3.NewInt64Schema().WithMin(1).WithMax(float64(^uint32(0)))
+		params = append(params, &openapi3.ParameterRef{Value: openapi3.NewPathParameter(name).WithSchema(id)})
+	}
+	return strings.Join(segments, "/"), params
+}
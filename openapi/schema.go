@@ -0,0 +1,260 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemas turns Go types into JSON schemas the way encoding/json encodes
+// them. Named structs become components referenced by name. A struct whose
+// interface fields hold values, such as the response envelope, is inlined
+// so those values can be described by their own types.
+type schemas struct {
+	components openapi3.Schemas
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: openapi3.Schemas{}, names: map[reflect.Type]string{}}
+}
+
+// of returns the schema of v
+func (s *schemas) of(v interface{}) *openapi3.SchemaRef {
+	return s.schema(reflect.TypeOf(v), reflect.ValueOf(v))
+}
+
+// schema returns the schema of t. v is a value of type t when one is known
+// and the zero Value otherwise.
+func (s *schemas) schema(t reflect.Type, v reflect.Value) *openapi3.SchemaRef {
+	if t == nil {
+		return inline(&openapi3.Schema{})
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if v.IsValid() {
+			v = v.Elem()
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if v.IsValid() && !v.IsNil() {
+			return s.schema(v.Elem().Type(), v.Elem())
+		}
+		return inline(&openapi3.Schema{})
+	case reflect.Bool:
+		return inline(openapi3.NewBoolSchema())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return inline(openapi3.NewIntegerSchema())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return inline(openapi3.NewIntegerSchema().WithMin(0))
+	case reflect.Float32, reflect.Float64:
+		return inline(openapi3.NewFloat64Schema())
+	case reflect.String:
+		return inline(openapi3.NewStringSchema())
+	case reflect.Slice, reflect.Array:
+		if t == rawType {
+			return inline(&openapi3.Schema{})
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return inline(openapi3.NewBytesSchema())
+		}
+		array := openapi3.NewArraySchema()
+		array.Items = s.schema(t.Elem(), reflect.Value{})
+		return inline(array)
+	case reflect.Map:
+		object := openapi3.NewObjectSchema()
+		object.AdditionalProperties = openapi3.AdditionalProperties{Schema: s.schema(t.Elem(), reflect.Value{})}
+		return inline(object)
+	case reflect.Struct:
+		if t == timeType {
+			return inline(openapi3.NewDateTimeSchema())
+		}
+		if holdsValue(t, v) {
+			return inline(s.object(t, v))
+		}
+		return s.component(t)
+	}
+	return inline(&openapi3.Schema{})
+}
+
+// component returns a reference to the component schema of struct t,
+// adding it on first use. The name is taken before the fields are described
+// so types that refer to each other end in a reference instead of a loop.
+func (s *schemas) component(t reflect.Type) *openapi3.SchemaRef {
+	name, ok := s.names[t]
+	if !ok {
+		name = s.name(t)
+		s.names[t] = name
+		s.components[name] = inline(openapi3.NewObjectSchema())
+		s.fields(s.components[name].Value, t, reflect.Value{})
+	}
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, s.components[name].Value)
+}
+
+// name is the Go type name, prefixed with its package when another package
+// already uses it
+func (s *schemas) name(t reflect.Type) string {
+	name := t.Name()
+	if name == "" {
+		name = "Object"
+	}
+	for _, taken := range s.names {
+		if taken == name {
+			pkg := []rune(path.Base(t.PkgPath()))
+			pkg[0] = unicode.ToUpper(pkg[0])
+			return string(pkg) + name
+		}
+	}
+	return name
+}
+
+// object describes the exported fields of struct t. Embedded structs
+// without a JSON name contribute their fields, as in encoding/json.
+func (s *schemas) object(t reflect.Type, v reflect.Value) *openapi3.Schema {
+	object := openapi3.NewObjectSchema()
+	s.fields(object, t, v)
+	return object
+}
+
+func (s *schemas) fields(object *openapi3.Schema, t reflect.Type, v reflect.Value) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		var value reflect.Value
+		if v.IsValid() {
+			value = v.Field(i)
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			s.fields(object, field.Type, value)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := s.schema(field.Type, value)
+		if schema.Ref == "" {
+			// encoding/json writes nil pointers, slices and maps as null
+			switch field.Type.Kind() {
+			case reflect.Ptr, reflect.Slice, reflect.Map:
+				schema.Value.Nullable = !strings.Contains(options, "omitempty")
+			}
+			constrain(schema.Value, field.Tag)
+		}
+		if _, ok := rules(field.Tag)["required"]; ok {
+			object.Required = append(object.Required, name)
+		}
+		object.Properties[name] = schema
+	}
+}
+
+// rules returns the validator rules in the binding and validate tags with
+// their parameters. Rules after dive apply to elements and are left out.
+func rules(tag reflect.StructTag) map[string]string {
+	list := map[string]string{}
+	for _, value := range []string{tag.Get("binding"), tag.Get("validate")} {
+		for _, rule := range strings.Split(value, ",") {
+			key, param, _ := strings.Cut(rule, "=")
+			if key == "dive" {
+				break
+			}
+			if key != "" {
+				list[key] = param
+			}
+		}
+	}
+	return list
+}
+
+// constrain applies the validator rules of a field to its schema
+func constrain(schema *openapi3.Schema, tag reflect.StructTag) {
+	list := rules(tag)
+	size := func(key string) (uint64, bool) {
+		param, ok := list[key]
+		if !ok {
+			return 0, false
+		}
+		n, err := strconv.ParseUint(param, 10, 64)
+		return n, err == nil
+	}
+
+	switch {
+	case schema.Type.Is(openapi3.TypeString):
+		if n, ok := size("min"); ok {
+			schema.MinLength = n
+		}
+		if n, ok := size("max"); ok {
+			schema.MaxLength = &n
+		}
+		if n, ok := size("len"); ok {
+			schema.MinLength, schema.MaxLength = n, &n
+		}
+		if values, ok := list["oneof"]; ok {
+			for _, value := range strings.Fields(values) {
+				schema.Enum = append(schema.Enum, value)
+			}
+		}
+		// The validator treats an empty string as missing
+		if _, ok := list["required"]; ok && schema.MinLength == 0 {
+			schema.MinLength = 1
+		}
+	case schema.Type.Is(openapi3.TypeInteger), schema.Type.Is(openapi3.TypeNumber):
+		for _, key := range []string{"min", "gte"} {
+			if n, ok := size(key); ok {
+				schema.WithMin(float64(n))
+			}
+		}
+		for _, key := range []string{"max", "lte"} {
+			if n, ok := size(key); ok {
+				schema.WithMax(float64(n))
+			}
+		}
+		// The validator treats zero as missing
+		if _, ok := list["required"]; ok && (schema.Min == nil || *schema.Min < 1) {
+			schema.WithMin(1)
+		}
+	case schema.Type.Is(openapi3.TypeArray):
+		if n, ok := size("min"); ok {
+			schema.MinItems = n
+		}
+		if n, ok := size("max"); ok {
+			schema.MaxItems = &n
+		}
+	}
+}
+
+// holdsValue reports whether an interface field of struct value v is set
+func holdsValue(t reflect.Type, v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type.Kind() == reflect.Interface && !v.Field(i).IsNil() {
+			return true
+		}
+	}
+	return false
+}
+
+func inline(schema *openapi3.Schema) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("", schema)
+}
@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type schemaTestItem struct {
+	Name string `json:"name"`
+}
+
+type schemaTestBody struct {
+	ID        uint              `json:"id"`
+	Title     string            `json:"title" validate:"required,min=3,max=255"`
+	Priority  string            `json:"priority" binding:"required,oneof=low high"`
+	Code      string            `json:"code" validate:"omitempty,len=7"`
+	OwnerID   *uint             `json:"owner_id,omitempty"`
+	Count     int               `json:"count" binding:"required,max=10"`
+	Tags      []string          `json:"tags" binding:"max=3,dive,min=1"`
+	Items     []schemaTestItem  `json:"items,omitempty"`
+	Extra     map[string]string `json:"extra"`
+	At        time.Time         `json:"at"`
+	Hidden    string            `json:"-"`
+	unexposed string
+	schemaTestEmbedded
+}
+
+type schemaTestEmbedded struct {
+	Note string `json:"note"`
+}
+
+func TestSchemaFromTags(t *testing.T) {
+	s := newSchemas()
+	ref := s.of(schemaTestBody{})
+	if ref.Ref != "#/components/schemas/schemaTestBody" {
+		t.Fatalf("ref = %q", ref.Ref)
+	}
+	body := s.components["schemaTestBody"].Value
+
+	if want := []string{"title", "priority", "count"}; !reflect.DeepEqual(body.Required, want) {
+		t.Errorf("required = %v, want %v", body.Required, want)
+	}
+	for _, name := range []string{"Hidden", "-", "unexposed", "schemaTestEmbedded"} {
+		if _, ok := body.Properties[name]; ok {
+			t.Errorf("property %q should be left out", name)
+		}
+	}
+
+	prop := func(name string) *openapi3.Schema {
+		t.Helper()
+		p, ok := body.Properties[name]
+		if !ok {
+			t.Fatalf("missing property %q", name)
+		}
+		return p.Value
+	}
+	if p := prop("id"); !p.Type.Is(openapi3.TypeInteger) || p.Min == nil || *p.Min != 0 {
+		t.Errorf("id = %+v, want integer with minimum 0", p)
+	}
+	if p := prop("title"); p.MinLength != 3 || p.MaxLength == nil || *p.MaxLength != 255 {
+		t.Errorf("title length = %d..%v, want 3..255", p.MinLength, p.MaxLength)
+	}
+	if p := prop("priority"); !reflect.DeepEqual(p.Enum, []interface{}{"low", "high"}) || p.MinLength != 1 {
+		t.Errorf("priority = %+v, want enum low high", p)
+	}
+	if p := prop("code"); p.MinLength != 7 || *p.MaxLength != 7 {
+		t.Errorf("code length = %d..%d, want 7", p.MinLength, *p.MaxLength)
+	}
+	if p := prop("owner_id"); p.Nullable {
+		t.Error("omitempty pointer should not be nullable")
+	}
+	if p := prop("count"); *p.Min != 1 || *p.Max != 10 {
+		t.Errorf("count = %v..%v, want 1..10", *p.Min, *p.Max)
+	}
+	if p := prop("tags"); !p.Nullable || *p.MaxItems != 3 || p.Items.Value.MinLength != 0 {
+		t.Errorf("tags = %+v, want nullable with at most 3 unconstrained items", p)
+	}
+	if p := prop("items"); p.Items.Ref != "#/components/schemas/schemaTestItem" {
+		t.Errorf("items ref = %q", p.Items.Ref)
+	}
+	if p := prop("extra"); !p.Nullable || !p.AdditionalProperties.Schema.Value.Type.Is(openapi3.TypeString) {
+		t.Errorf("extra = %+v, want nullable map of strings", p)
+	}
+	if p := prop("at"); p.Format != "date-time" {
+		t.Errorf("at format = %q, want date-time", p.Format)
+	}
+	if p := prop("note"); !p.Type.Is(openapi3.TypeString) {
+		t.Errorf("embedded note = %+v", p)
+	}
+}
+
+type schemaTestEnvelope struct {
+	Data interface{} `json:"data"`
+}
+
+func TestSchemaInlinesHeldValues(t *testing.T) {
+	s := newSchemas()
+	ref := s.of(schemaTestEnvelope{Data: []schemaTestItem{}})
+	if ref.Ref != "" {
+		t.Fatalf("envelope holding a value should be inlined, got %q", ref.Ref)
+	}
+	data := ref.Value.Properties["data"].Value
+	if !data.Type.Is(openapi3.TypeArray) || data.Items.Ref != "#/components/schemas/schemaTestItem" {
+		t.Errorf("data = %+v, want array of schemaTestItem", data)
+	}
+	if _, ok := s.components["schemaTestEnvelope"]; ok {
+		t.Error("inlined envelope should not be a component")
+	}
+}
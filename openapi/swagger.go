@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// swaggerInitializer replaces the Swagger UI file that names the document
+// to load
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: %q,
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    presets: [
+      SwaggerUIBundle.presets.apis,
+      SwaggerUIStandalonePreset
+    ],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// SwaggerUI serves the bundled Swagger UI showing the document at specURL.
+// It must be registered on a path ending in /*filepath.
+func SwaggerUI(specURL string) gin.HandlerFunc {
+	files := http.FileServer(http.FS(swaggerFiles.FS))
+	initializer := fmt.Sprintf(swaggerInitializer, specURL)
+
+	return func(c *gin.Context) {
+		file := c.Param("filepath")
+		switch file {
+		case "", "/":
+			file = "/index.html"
+		case "/swagger-initializer.js":
+			c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(initializer))
+			return
+		}
+
+		// http.FileServer redirects /index.html to the directory, so serve
+		// it under the name of the directory instead
+		req := c.Request.Clone(c.Request.Context())
+		req.URL.Path = file
+		if file == "/index.html" {
+			req.URL.Path = "/"
+		}
+		files.ServeHTTP(c.Writer, req)
+	}
+}
@@ -0,0 +1,224 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"issue-tracking/utils"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// validateOptions reports every problem of a request
+var validateOptions = openapi3filter.Options{
+	MultiError:          true,
+	SkipSettingDefaults: true,
+	AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+}
+
+// uploadOptions leaves out the body, which the handler streams instead
+var uploadOptions = func() openapi3filter.Options {
+	options := validateOptions
+	options.ExcludeRequestBody = true
+	return options
+}()
+
+// ValidateRequests rejects requests whose parameters or JSON body do not
+// match the document with a 400 listing every problem, in the same format
+// as handler validation errors. Routes outside the document pass through.
+func (s *Spec) ValidateRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, ok := s.routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		params := map[string]string{}
+		for _, p := range c.Params {
+			params[p.Key] = p.Value
+		}
+		if problem := validate(route, c.Request, params); problem != nil {
+			utils.RespondError(c, problem.Status, problem.Message, problem.Details)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Check validates a request that did not come through the router, such as
+// a gRPC call translated to its HTTP rule, against the operation matching
+// its method and path. It returns the error ValidateRequests would respond
+// with, or nil when the request is valid or matches no operation.
+func (s *Spec) Check(req *http.Request) *utils.ErrorResponse {
+	var match *routers.Route
+	var matchParams map[string]string
+	for _, route := range s.routes {
+		if route.Method != req.Method {
+			continue
+		}
+		// A literal segment wins over a parameter, as in the router
+		params, ok := matchPath(route.Path, req.URL.Path)
+		if ok && (match == nil || len(params) < len(matchParams)) {
+			match, matchParams = route, params
+		}
+	}
+	if match == nil {
+		return nil
+	}
+	return validate(match, req, matchParams)
+}
+
+// validate checks req against the operation of route
+func validate(route *routers.Route, req *http.Request, params map[string]string) *utils.ErrorResponse {
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: params,
+		Route:      route,
+		Options:    &validateOptions,
+	}
+	if body := route.Operation.RequestBody; body != nil && body.Value.Content.Get("multipart/form-data") != nil {
+		input.Options = &uploadOptions
+	}
+
+	err := openapi3filter.ValidateRequest(req.Context(), input)
+	if err == nil {
+		return nil
+	}
+
+	var problems []utils.ValidationError
+	for _, e := range flatten(err) {
+		reqErr, ok := e.(*openapi3filter.RequestError)
+		if !ok {
+			problems = append(problems, utils.ValidationError{Message: e.Error()})
+			continue
+		}
+		// A body that cannot be decoded has no fields to report
+		if reqErr.Parameter == nil && !isSchemaError(reqErr.Err) {
+			return &utils.ErrorResponse{Status: http.StatusBadRequest, Message: "Invalid request body", Details: reqErr.Error()}
+		}
+		problems = append(problems, fieldErrors(reqErr)...)
+	}
+	return &utils.ErrorResponse{Status: http.StatusBadRequest, Message: "Validation failed", Details: problems}
+}
+
+// matchPath matches path against an OpenAPI path template and returns the
+// values of its parameters
+func matchPath(template, path string) (map[string]string, bool) {
+	want := strings.Split(strings.Trim(template, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, segment := range want {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[segment[1:len(segment)-1]] = got[i]
+		} else if segment != got[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// fieldErrors describes a parameter or body error per field
+func fieldErrors(reqErr *openapi3filter.RequestError) []utils.ValidationError {
+	var problems []utils.ValidationError
+	for _, e := range flatten(reqErr.Err) {
+		field := "body"
+		if reqErr.Parameter != nil {
+			field = reqErr.Parameter.Name
+		}
+		schemaErr, ok := e.(*openapi3.SchemaError)
+		if !ok {
+			// Parameters that do not parse as their type
+			message := fmt.Sprintf("%s is invalid", field)
+			if reqErr.Parameter != nil && reqErr.Parameter.Schema != nil {
+				message = typeMessage(field, reqErr.Parameter.Schema.Value)
+			}
+			problems = append(problems, utils.ValidationError{Field: field, Message: message})
+			continue
+		}
+		if path := schemaErr.JSONPointer(); len(path) > 0 {
+			field = strings.Join(path, ".")
+			if reqErr.Parameter != nil {
+				field = reqErr.Parameter.Name + "." + field
+			}
+		}
+		problems = append(problems, utils.ValidationError{Field: field, Message: schemaMessage(field, schemaErr)})
+	}
+	return problems
+}
+
+// schemaMessage words a schema violation like the handler validation errors
+func schemaMessage(field string, err *openapi3.SchemaError) string {
+	schema := err.Schema
+	switch err.SchemaField {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "type", "format":
+		return typeMessage(field, schema)
+	case "enum":
+		values := make([]string, len(schema.Enum))
+		for i, value := range schema.Enum {
+			values[i] = fmt.Sprint(value)
+		}
+		return fmt.Sprintf("%s must be one of: %s", field, strings.Join(values, " "))
+	case "minLength":
+		return fmt.Sprintf("%s must be at least %d characters", field, schema.MinLength)
+	case "maxLength":
+		return fmt.Sprintf("%s must be at most %d characters", field, *schema.MaxLength)
+	case "minimum":
+		return fmt.Sprintf("%s must be at least %g", field, *schema.Min)
+	case "maximum":
+		return fmt.Sprintf("%s must be at most %g", field, *schema.Max)
+	case "minItems":
+		return fmt.Sprintf("%s must have at least %d items", field, schema.MinItems)
+	case "maxItems":
+		return fmt.Sprintf("%s must have at most %d items", field, *schema.MaxItems)
+	}
+	return fmt.Sprintf("%s is invalid", field)
+}
+
+// typeMessage says which type field must have
+func typeMessage(field string, schema *openapi3.Schema) string {
+	switch {
+	case schema == nil || schema.Type == nil:
+		return fmt.Sprintf("%s is invalid", field)
+	case schema.Type.Is(openapi3.TypeInteger):
+		return fmt.Sprintf("%s must be an integer", field)
+	case schema.Type.Is(openapi3.TypeObject), schema.Type.Is(openapi3.TypeArray):
+		return fmt.Sprintf("%s must be an %s", field, strings.Join(schema.Type.Slice(), " or "))
+	case schema.Format == "date-time":
+		return fmt.Sprintf("%s must be an RFC 3339 timestamp", field)
+	}
+	return fmt.Sprintf("%s must be a %s", field, strings.Join(schema.Type.Slice(), " or "))
+}
+
+// flatten expands nested multi-errors
+func flatten(err error) []error {
+	multi, ok := err.(openapi3.MultiError)
+	if !ok {
+		return []error{err}
+	}
+	var list []error
+	for _, e := range multi {
+		list = append(list, flatten(e)...)
+	}
+	return list
+}
+
+// isSchemaError reports whether err only holds schema violations
+func isSchemaError(err error) bool {
+	for _, e := range flatten(err) {
+		if _, ok := e.(*openapi3.SchemaError); !ok {
+			return false
+		}
+	}
+	return err != nil
+}
@@ -0,0 +1,8 @@
+// Package proto holds the protobuf definitions of the gRPC API. The Go code
+// next to each .proto file is generated with buf:
+//
+//	buf dep update   # once, to fetch googleapis
+//	go generate ./proto
+package proto
+
+//go:generate buf generate
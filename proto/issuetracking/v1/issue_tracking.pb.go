@@ -0,0 +1,1722 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: issuetracking/v1/issue_tracking.proto
+
+package issuetrackingv1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// IssueEventType is what happened to an issue
+type IssueEventType int32
+
+const (
+	IssueEventType_ISSUE_EVENT_TYPE_UNSPECIFIED    IssueEventType = 0
+	IssueEventType_ISSUE_EVENT_TYPE_CREATED        IssueEventType = 1
+	IssueEventType_ISSUE_EVENT_TYPE_STATUS_CHANGED IssueEventType = 2
+	IssueEventType_ISSUE_EVENT_TYPE_COMMENTED      IssueEventType = 3
+	// UPDATED is any other change, such as a new priority or assignee
+	IssueEventType_ISSUE_EVENT_TYPE_UPDATED IssueEventType = 4
+	IssueEventType_ISSUE_EVENT_TYPE_DELETED IssueEventType = 5
+)
+
+// Enum value maps for IssueEventType.
+var (
+	IssueEventType_name = map[int32]string{
+		0: "ISSUE_EVENT_TYPE_UNSPECIFIED",
+		1: "ISSUE_EVENT_TYPE_CREATED",
+		2: "ISSUE_EVENT_TYPE_STATUS_CHANGED",
+		3: "ISSUE_EVENT_TYPE_COMMENTED",
+		4: "ISSUE_EVENT_TYPE_UPDATED",
+		5: "ISSUE_EVENT_TYPE_DELETED",
+	}
+	IssueEventType_value = map[string]int32{
+		"ISSUE_EVENT_TYPE_UNSPECIFIED":    0,
+		"ISSUE_EVENT_TYPE_CREATED":        1,
+		"ISSUE_EVENT_TYPE_STATUS_CHANGED": 2,
+		"ISSUE_EVENT_TYPE_COMMENTED":      3,
+		"ISSUE_EVENT_TYPE_UPDATED":        4,
+		"ISSUE_EVENT_TYPE_DELETED":        5,
+	}
+)
+
+func (x IssueEventType) Enum() *IssueEventType {
+	p := new(IssueEventType)
+	*p = x
+	return p
+}
+
+func (x IssueEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IssueEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_issuetracking_v1_issue_tracking_proto_enumTypes[0].Descriptor()
+}
+
+func (IssueEventType) Type() protoreflect.EnumType {
+	return &file_issuetracking_v1_issue_tracking_proto_enumTypes[0]
+}
+
+func (x IssueEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IssueEventType.Descriptor instead.
+func (IssueEventType) EnumDescriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{0}
+}
+
+// User is a person who reports issues
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	FullName      string                 `protobuf:"bytes,2,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *User) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *User) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *User) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *User) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// Officer is a person who handles issues
+type Officer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OfficerId     uint32                 `protobuf:"varint,1,opt,name=officer_id,json=officerId,proto3" json:"officer_id,omitempty"`
+	FullName      string                 `protobuf:"bytes,2,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Officer) Reset() {
+	*x = Officer{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Officer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Officer) ProtoMessage() {}
+
+func (x *Officer) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Officer.ProtoReflect.Descriptor instead.
+func (*Officer) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Officer) GetOfficerId() uint32 {
+	if x != nil {
+		return x.OfficerId
+	}
+	return 0
+}
+
+func (x *Officer) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *Officer) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Officer) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// IssueStatus is a step of the issue workflow
+type IssueStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StatusId      uint32                 `protobuf:"varint,1,opt,name=status_id,json=statusId,proto3" json:"status_id,omitempty"`
+	StatusCode    string                 `protobuf:"bytes,2,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,3,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Color         string                 `protobuf:"bytes,5,opt,name=color,proto3" json:"color,omitempty"`
+	DisplayOrder  int32                  `protobuf:"varint,6,opt,name=display_order,json=displayOrder,proto3" json:"display_order,omitempty"`
+	IsActive      bool                   `protobuf:"varint,7,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	IsTerminal    bool                   `protobuf:"varint,8,opt,name=is_terminal,json=isTerminal,proto3" json:"is_terminal,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueStatus) Reset() {
+	*x = IssueStatus{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueStatus) ProtoMessage() {}
+
+func (x *IssueStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueStatus.ProtoReflect.Descriptor instead.
+func (*IssueStatus) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IssueStatus) GetStatusId() uint32 {
+	if x != nil {
+		return x.StatusId
+	}
+	return 0
+}
+
+func (x *IssueStatus) GetStatusCode() string {
+	if x != nil {
+		return x.StatusCode
+	}
+	return ""
+}
+
+func (x *IssueStatus) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *IssueStatus) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *IssueStatus) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *IssueStatus) GetDisplayOrder() int32 {
+	if x != nil {
+		return x.DisplayOrder
+	}
+	return 0
+}
+
+func (x *IssueStatus) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *IssueStatus) GetIsTerminal() bool {
+	if x != nil {
+		return x.IsTerminal
+	}
+	return false
+}
+
+func (x *IssueStatus) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// Label is a tag attached to issues
+type Label struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LabelId       uint32                 `protobuf:"varint,1,opt,name=label_id,json=labelId,proto3" json:"label_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Color         string                 `protobuf:"bytes,3,opt,name=color,proto3" json:"color,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Label) Reset() {
+	*x = Label{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Label) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Label) ProtoMessage() {}
+
+func (x *Label) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Label.ProtoReflect.Descriptor instead.
+func (*Label) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Label) GetLabelId() uint32 {
+	if x != nil {
+		return x.LabelId
+	}
+	return 0
+}
+
+func (x *Label) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Label) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *Label) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// StatusChange is an entry of the status history of an issue
+type StatusChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HistoryId     uint32                 `protobuf:"varint,1,opt,name=history_id,json=historyId,proto3" json:"history_id,omitempty"`
+	IssueId       uint32                 `protobuf:"varint,2,opt,name=issue_id,json=issueId,proto3" json:"issue_id,omitempty"`
+	OldStatusId   *uint32                `protobuf:"varint,3,opt,name=old_status_id,json=oldStatusId,proto3,oneof" json:"old_status_id,omitempty"`
+	NewStatusId   uint32                 `protobuf:"varint,4,opt,name=new_status_id,json=newStatusId,proto3" json:"new_status_id,omitempty"`
+	ChangedBy     uint32                 `protobuf:"varint,5,opt,name=changed_by,json=changedBy,proto3" json:"changed_by,omitempty"`
+	Comment       string                 `protobuf:"bytes,6,opt,name=comment,proto3" json:"comment,omitempty"`
+	ChangedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusChange) Reset() {
+	*x = StatusChange{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusChange) ProtoMessage() {}
+
+func (x *StatusChange) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusChange.ProtoReflect.Descriptor instead.
+func (*StatusChange) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StatusChange) GetHistoryId() uint32 {
+	if x != nil {
+		return x.HistoryId
+	}
+	return 0
+}
+
+func (x *StatusChange) GetIssueId() uint32 {
+	if x != nil {
+		return x.IssueId
+	}
+	return 0
+}
+
+func (x *StatusChange) GetOldStatusId() uint32 {
+	if x != nil && x.OldStatusId != nil {
+		return *x.OldStatusId
+	}
+	return 0
+}
+
+func (x *StatusChange) GetNewStatusId() uint32 {
+	if x != nil {
+		return x.NewStatusId
+	}
+	return 0
+}
+
+func (x *StatusChange) GetChangedBy() uint32 {
+	if x != nil {
+		return x.ChangedBy
+	}
+	return 0
+}
+
+func (x *StatusChange) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *StatusChange) GetChangedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return nil
+}
+
+// Issue is a support ticket. The relations are set when the issue is
+// returned on its own and left empty when it is nested in a comment.
+type Issue struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	IssueId     uint32                 `protobuf:"varint,1,opt,name=issue_id,json=issueId,proto3" json:"issue_id,omitempty"`
+	ReporterId  uint32                 `protobuf:"varint,2,opt,name=reporter_id,json=reporterId,proto3" json:"reporter_id,omitempty"`
+	AssigneeId  *uint32                `protobuf:"varint,3,opt,name=assignee_id,json=assigneeId,proto3,oneof" json:"assignee_id,omitempty"`
+	StatusId    uint32                 `protobuf:"varint,4,opt,name=status_id,json=statusId,proto3" json:"status_id,omitempty"`
+	Title       string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	Description string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	// priority is one of low, medium, high or critical
+	Priority      string                 `protobuf:"bytes,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Reporter      *User                  `protobuf:"bytes,10,opt,name=reporter,proto3" json:"reporter,omitempty"`
+	Assignee      *Officer               `protobuf:"bytes,11,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Status        *IssueStatus           `protobuf:"bytes,12,opt,name=status,proto3" json:"status,omitempty"`
+	StatusHistory []*StatusChange        `protobuf:"bytes,13,rep,name=status_history,json=statusHistory,proto3" json:"status_history,omitempty"`
+	Comments      []*Comment             `protobuf:"bytes,14,rep,name=comments,proto3" json:"comments,omitempty"`
+	Labels        []*Label               `protobuf:"bytes,15,rep,name=labels,proto3" json:"labels,omitempty"`
+	// source is user, or integration for issues opened with an API key
+	Source        string  `protobuf:"bytes,16,opt,name=source,proto3" json:"source,omitempty"`
+	ApiKeyId      *uint32 `protobuf:"varint,17,opt,name=api_key_id,json=apiKeyId,proto3,oneof" json:"api_key_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Issue) Reset() {
+	*x = Issue{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Issue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Issue) ProtoMessage() {}
+
+func (x *Issue) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Issue.ProtoReflect.Descriptor instead.
+func (*Issue) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Issue) GetIssueId() uint32 {
+	if x != nil {
+		return x.IssueId
+	}
+	return 0
+}
+
+func (x *Issue) GetReporterId() uint32 {
+	if x != nil {
+		return x.ReporterId
+	}
+	return 0
+}
+
+func (x *Issue) GetAssigneeId() uint32 {
+	if x != nil && x.AssigneeId != nil {
+		return *x.AssigneeId
+	}
+	return 0
+}
+
+func (x *Issue) GetStatusId() uint32 {
+	if x != nil {
+		return x.StatusId
+	}
+	return 0
+}
+
+func (x *Issue) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Issue) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Issue) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *Issue) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Issue) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Issue) GetReporter() *User {
+	if x != nil {
+		return x.Reporter
+	}
+	return nil
+}
+
+func (x *Issue) GetAssignee() *Officer {
+	if x != nil {
+		return x.Assignee
+	}
+	return nil
+}
+
+func (x *Issue) GetStatus() *IssueStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *Issue) GetStatusHistory() []*StatusChange {
+	if x != nil {
+		return x.StatusHistory
+	}
+	return nil
+}
+
+func (x *Issue) GetComments() []*Comment {
+	if x != nil {
+		return x.Comments
+	}
+	return nil
+}
+
+func (x *Issue) GetLabels() []*Label {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Issue) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Issue) GetApiKeyId() uint32 {
+	if x != nil && x.ApiKeyId != nil {
+		return *x.ApiKeyId
+	}
+	return 0
+}
+
+// Comment is a message left on an issue
+type Comment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CommentId     uint32                 `protobuf:"varint,1,opt,name=comment_id,json=commentId,proto3" json:"comment_id,omitempty"`
+	IssueId       uint32                 `protobuf:"varint,2,opt,name=issue_id,json=issueId,proto3" json:"issue_id,omitempty"`
+	UserId        uint32                 `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Issue         *Issue                 `protobuf:"bytes,6,opt,name=issue,proto3" json:"issue,omitempty"`
+	User          *User                  `protobuf:"bytes,7,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Comment) Reset() {
+	*x = Comment{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Comment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Comment) ProtoMessage() {}
+
+func (x *Comment) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Comment.ProtoReflect.Descriptor instead.
+func (*Comment) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Comment) GetCommentId() uint32 {
+	if x != nil {
+		return x.CommentId
+	}
+	return 0
+}
+
+func (x *Comment) GetIssueId() uint32 {
+	if x != nil {
+		return x.IssueId
+	}
+	return 0
+}
+
+func (x *Comment) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Comment) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Comment) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Comment) GetIssue() *Issue {
+	if x != nil {
+		return x.Issue
+	}
+	return nil
+}
+
+func (x *Comment) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// ListIssuesRequest takes the query parameters of GET /api/issues
+type ListIssuesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// status is a status code
+	Status     string  `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Priority   string  `protobuf:"bytes,2,opt,name=priority,proto3" json:"priority,omitempty"`
+	AssigneeId *uint32 `protobuf:"varint,3,opt,name=assignee_id,json=assigneeId,proto3,oneof" json:"assignee_id,omitempty"`
+	ReporterId *uint32 `protobuf:"varint,4,opt,name=reporter_id,json=reporterId,proto3,oneof" json:"reporter_id,omitempty"`
+	// label is a label name
+	Label string `protobuf:"bytes,5,opt,name=label,proto3" json:"label,omitempty"`
+	// limit is the page size; all matching issues are returned when it is 0
+	Limit int32 `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	// after_id is the last issue ID of the previous page
+	AfterId       uint32 `protobuf:"varint,7,opt,name=after_id,json=afterId,proto3" json:"after_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIssuesRequest) Reset() {
+	*x = ListIssuesRequest{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIssuesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIssuesRequest) ProtoMessage() {}
+
+func (x *ListIssuesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIssuesRequest.ProtoReflect.Descriptor instead.
+func (*ListIssuesRequest) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListIssuesRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListIssuesRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *ListIssuesRequest) GetAssigneeId() uint32 {
+	if x != nil && x.AssigneeId != nil {
+		return *x.AssigneeId
+	}
+	return 0
+}
+
+func (x *ListIssuesRequest) GetReporterId() uint32 {
+	if x != nil && x.ReporterId != nil {
+		return *x.ReporterId
+	}
+	return 0
+}
+
+func (x *ListIssuesRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *ListIssuesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListIssuesRequest) GetAfterId() uint32 {
+	if x != nil {
+		return x.AfterId
+	}
+	return 0
+}
+
+type ListIssuesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Issues        []*Issue               `protobuf:"bytes,1,rep,name=issues,proto3" json:"issues,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIssuesResponse) Reset() {
+	*x = ListIssuesResponse{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIssuesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIssuesResponse) ProtoMessage() {}
+
+func (x *ListIssuesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIssuesResponse.ProtoReflect.Descriptor instead.
+func (*ListIssuesResponse) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListIssuesResponse) GetIssues() []*Issue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+type GetIssueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IssueId       uint32                 `protobuf:"varint,1,opt,name=issue_id,json=issueId,proto3" json:"issue_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIssueRequest) Reset() {
+	*x = GetIssueRequest{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIssueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIssueRequest) ProtoMessage() {}
+
+func (x *GetIssueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIssueRequest.ProtoReflect.Descriptor instead.
+func (*GetIssueRequest) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetIssueRequest) GetIssueId() uint32 {
+	if x != nil {
+		return x.IssueId
+	}
+	return 0
+}
+
+type CreateIssueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReporterId    uint32                 `protobuf:"varint,1,opt,name=reporter_id,json=reporterId,proto3" json:"reporter_id,omitempty"`
+	AssigneeId    *uint32                `protobuf:"varint,2,opt,name=assignee_id,json=assigneeId,proto3,oneof" json:"assignee_id,omitempty"`
+	StatusId      uint32                 `protobuf:"varint,3,opt,name=status_id,json=statusId,proto3" json:"status_id,omitempty"`
+	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	Priority      string                 `protobuf:"bytes,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateIssueRequest) Reset() {
+	*x = CreateIssueRequest{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateIssueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateIssueRequest) ProtoMessage() {}
+
+func (x *CreateIssueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateIssueRequest.ProtoReflect.Descriptor instead.
+func (*CreateIssueRequest) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CreateIssueRequest) GetReporterId() uint32 {
+	if x != nil {
+		return x.ReporterId
+	}
+	return 0
+}
+
+func (x *CreateIssueRequest) GetAssigneeId() uint32 {
+	if x != nil && x.AssigneeId != nil {
+		return *x.AssigneeId
+	}
+	return 0
+}
+
+func (x *CreateIssueRequest) GetStatusId() uint32 {
+	if x != nil {
+		return x.StatusId
+	}
+	return 0
+}
+
+func (x *CreateIssueRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateIssueRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateIssueRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+type UpdateIssueStatusRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	IssueId     uint32                 `protobuf:"varint,1,opt,name=issue_id,json=issueId,proto3" json:"issue_id,omitempty"`
+	NewStatusId uint32                 `protobuf:"varint,2,opt,name=new_status_id,json=newStatusId,proto3" json:"new_status_id,omitempty"`
+	// assignee_id reassigns the issue when set
+	AssigneeId    *uint32 `protobuf:"varint,3,opt,name=assignee_id,json=assigneeId,proto3,oneof" json:"assignee_id,omitempty"`
+	Comment       string  `protobuf:"bytes,4,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateIssueStatusRequest) Reset() {
+	*x = UpdateIssueStatusRequest{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateIssueStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateIssueStatusRequest) ProtoMessage() {}
+
+func (x *UpdateIssueStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateIssueStatusRequest.ProtoReflect.Descriptor instead.
+func (*UpdateIssueStatusRequest) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateIssueStatusRequest) GetIssueId() uint32 {
+	if x != nil {
+		return x.IssueId
+	}
+	return 0
+}
+
+func (x *UpdateIssueStatusRequest) GetNewStatusId() uint32 {
+	if x != nil {
+		return x.NewStatusId
+	}
+	return 0
+}
+
+func (x *UpdateIssueStatusRequest) GetAssigneeId() uint32 {
+	if x != nil && x.AssigneeId != nil {
+		return *x.AssigneeId
+	}
+	return 0
+}
+
+func (x *UpdateIssueStatusRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+type CreateCommentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IssueId       uint32                 `protobuf:"varint,1,opt,name=issue_id,json=issueId,proto3" json:"issue_id,omitempty"`
+	UserId        uint32                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCommentRequest) Reset() {
+	*x = CreateCommentRequest{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCommentRequest) ProtoMessage() {}
+
+func (x *CreateCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCommentRequest.ProtoReflect.Descriptor instead.
+func (*CreateCommentRequest) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateCommentRequest) GetIssueId() uint32 {
+	if x != nil {
+		return x.IssueId
+	}
+	return 0
+}
+
+func (x *CreateCommentRequest) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *CreateCommentRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type ListStatusesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStatusesRequest) Reset() {
+	*x = ListStatusesRequest{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStatusesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStatusesRequest) ProtoMessage() {}
+
+func (x *ListStatusesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStatusesRequest.ProtoReflect.Descriptor instead.
+func (*ListStatusesRequest) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{13}
+}
+
+type ListStatusesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Statuses      []*IssueStatus         `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStatusesResponse) Reset() {
+	*x = ListStatusesResponse{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStatusesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStatusesResponse) ProtoMessage() {}
+
+func (x *ListStatusesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStatusesResponse.ProtoReflect.Descriptor instead.
+func (*ListStatusesResponse) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListStatusesResponse) GetStatuses() []*IssueStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+type ListOfficersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOfficersRequest) Reset() {
+	*x = ListOfficersRequest{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOfficersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOfficersRequest) ProtoMessage() {}
+
+func (x *ListOfficersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOfficersRequest.ProtoReflect.Descriptor instead.
+func (*ListOfficersRequest) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{15}
+}
+
+type ListOfficersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Officers      []*Officer             `protobuf:"bytes,1,rep,name=officers,proto3" json:"officers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOfficersResponse) Reset() {
+	*x = ListOfficersResponse{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOfficersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOfficersResponse) ProtoMessage() {}
+
+func (x *ListOfficersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOfficersResponse.ProtoReflect.Descriptor instead.
+func (*ListOfficersResponse) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListOfficersResponse) GetOfficers() []*Officer {
+	if x != nil {
+		return x.Officers
+	}
+	return nil
+}
+
+// WatchIssueEventsRequest selects the events to stream
+type WatchIssueEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// issue_ids limits the stream to these issues; every issue is watched
+	// when it is empty
+	IssueIds      []uint32 `protobuf:"varint,1,rep,packed,name=issue_ids,json=issueIds,proto3" json:"issue_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchIssueEventsRequest) Reset() {
+	*x = WatchIssueEventsRequest{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchIssueEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchIssueEventsRequest) ProtoMessage() {}
+
+func (x *WatchIssueEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchIssueEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchIssueEventsRequest) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WatchIssueEventsRequest) GetIssueIds() []uint32 {
+	if x != nil {
+		return x.IssueIds
+	}
+	return nil
+}
+
+// IssueEvent is a change to an issue
+type IssueEvent struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Type       IssueEventType         `protobuf:"varint,1,opt,name=type,proto3,enum=issuetracking.v1.IssueEventType" json:"type,omitempty"`
+	IssueId    uint32                 `protobuf:"varint,2,opt,name=issue_id,json=issueId,proto3" json:"issue_id,omitempty"`
+	OccurredAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	// issue is the issue after the change, or before it for
+	// ISSUE_EVENT_TYPE_DELETED. It has no relations for
+	// ISSUE_EVENT_TYPE_COMMENTED.
+	Issue *Issue `protobuf:"bytes,4,opt,name=issue,proto3" json:"issue,omitempty"`
+	// comment is set for ISSUE_EVENT_TYPE_COMMENTED
+	Comment       *Comment `protobuf:"bytes,5,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueEvent) Reset() {
+	*x = IssueEvent{}
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueEvent) ProtoMessage() {}
+
+func (x *IssueEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_issuetracking_v1_issue_tracking_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueEvent.ProtoReflect.Descriptor instead.
+func (*IssueEvent) Descriptor() ([]byte, []int) {
+	return file_issuetracking_v1_issue_tracking_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *IssueEvent) GetType() IssueEventType {
+	if x != nil {
+		return x.Type
+	}
+	return IssueEventType_ISSUE_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *IssueEvent) GetIssueId() uint32 {
+	if x != nil {
+		return x.IssueId
+	}
+	return 0
+}
+
+func (x *IssueEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *IssueEvent) GetIssue() *Issue {
+	if x != nil {
+		return x.Issue
+	}
+	return nil
+}
+
+func (x *IssueEvent) GetComment() *Comment {
+	if x != nil {
+		return x.Comment
+	}
+	return nil
+}
+
+var File_issuetracking_v1_issue_tracking_proto protoreflect.FileDescriptor
+
+const file_issuetracking_v1_issue_tracking_proto_rawDesc = "" +
+	"\n" +
+	"%issuetracking/v1/issue_tracking.proto\x12\x10issuetracking.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb2\x01\n" +
+	"\x04User\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\x12\x1b\n" +
+	"\tfull_name\x18\x02 \x01(\tR\bfullName\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xbb\x01\n" +
+	"\aOfficer\x12\x1d\n" +
+	"\n" +
+	"officer_id\x18\x01 \x01(\rR\tofficerId\x12\x1b\n" +
+	"\tfull_name\x18\x02 \x01(\tR\bfullName\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xc4\x02\n" +
+	"\vIssueStatus\x12\x1b\n" +
+	"\tstatus_id\x18\x01 \x01(\rR\bstatusId\x12\x1f\n" +
+	"\vstatus_code\x18\x02 \x01(\tR\n" +
+	"statusCode\x12!\n" +
+	"\fdisplay_name\x18\x03 \x01(\tR\vdisplayName\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05color\x18\x05 \x01(\tR\x05color\x12#\n" +
+	"\rdisplay_order\x18\x06 \x01(\x05R\fdisplayOrder\x12\x1b\n" +
+	"\tis_active\x18\a \x01(\bR\bisActive\x12\x1f\n" +
+	"\vis_terminal\x18\b \x01(\bR\n" +
+	"isTerminal\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x87\x01\n" +
+	"\x05Label\x12\x19\n" +
+	"\blabel_id\x18\x01 \x01(\rR\alabelId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05color\x18\x03 \x01(\tR\x05color\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x9b\x02\n" +
+	"\fStatusChange\x12\x1d\n" +
+	"\n" +
+	"history_id\x18\x01 \x01(\rR\thistoryId\x12\x19\n" +
+	"\bissue_id\x18\x02 \x01(\rR\aissueId\x12'\n" +
+	"\rold_status_id\x18\x03 \x01(\rH\x00R\voldStatusId\x88\x01\x01\x12\"\n" +
+	"\rnew_status_id\x18\x04 \x01(\rR\vnewStatusId\x12\x1d\n" +
+	"\n" +
+	"changed_by\x18\x05 \x01(\rR\tchangedBy\x12\x18\n" +
+	"\acomment\x18\x06 \x01(\tR\acomment\x129\n" +
+	"\n" +
+	"changed_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tchangedAtB\x10\n" +
+	"\x0e_old_status_id\"\xfb\x05\n" +
+	"\x05Issue\x12\x19\n" +
+	"\bissue_id\x18\x01 \x01(\rR\aissueId\x12\x1f\n" +
+	"\vreporter_id\x18\x02 \x01(\rR\n" +
+	"reporterId\x12$\n" +
+	"\vassignee_id\x18\x03 \x01(\rH\x00R\n" +
+	"assigneeId\x88\x01\x01\x12\x1b\n" +
+	"\tstatus_id\x18\x04 \x01(\rR\bstatusId\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bpriority\x18\a \x01(\tR\bpriority\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x122\n" +
+	"\breporter\x18\n" +
+	" \x01(\v2\x16.issuetracking.v1.UserR\breporter\x125\n" +
+	"\bassignee\x18\v \x01(\v2\x19.issuetracking.v1.OfficerR\bassignee\x125\n" +
+	"\x06status\x18\f \x01(\v2\x1d.issuetracking.v1.IssueStatusR\x06status\x12E\n" +
+	"\x0estatus_history\x18\r \x03(\v2\x1e.issuetracking.v1.StatusChangeR\rstatusHistory\x125\n" +
+	"\bcomments\x18\x0e \x03(\v2\x19.issuetracking.v1.CommentR\bcomments\x12/\n" +
+	"\x06labels\x18\x0f \x03(\v2\x17.issuetracking.v1.LabelR\x06labels\x12\x16\n" +
+	"\x06source\x18\x10 \x01(\tR\x06source\x12!\n" +
+	"\n" +
+	"api_key_id\x18\x11 \x01(\rH\x01R\bapiKeyId\x88\x01\x01B\x0e\n" +
+	"\f_assignee_idB\r\n" +
+	"\v_api_key_id\"\x8c\x02\n" +
+	"\aComment\x12\x1d\n" +
+	"\n" +
+	"comment_id\x18\x01 \x01(\rR\tcommentId\x12\x19\n" +
+	"\bissue_id\x18\x02 \x01(\rR\aissueId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\rR\x06userId\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12-\n" +
+	"\x05issue\x18\x06 \x01(\v2\x17.issuetracking.v1.IssueR\x05issue\x12*\n" +
+	"\x04user\x18\a \x01(\v2\x16.issuetracking.v1.UserR\x04user\"\xfa\x01\n" +
+	"\x11ListIssuesRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1a\n" +
+	"\bpriority\x18\x02 \x01(\tR\bpriority\x12$\n" +
+	"\vassignee_id\x18\x03 \x01(\rH\x00R\n" +
+	"assigneeId\x88\x01\x01\x12$\n" +
+	"\vreporter_id\x18\x04 \x01(\rH\x01R\n" +
+	"reporterId\x88\x01\x01\x12\x14\n" +
+	"\x05label\x18\x05 \x01(\tR\x05label\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\x12\x19\n" +
+	"\bafter_id\x18\a \x01(\rR\aafterIdB\x0e\n" +
+	"\f_assignee_idB\x0e\n" +
+	"\f_reporter_id\"E\n" +
+	"\x12ListIssuesResponse\x12/\n" +
+	"\x06issues\x18\x01 \x03(\v2\x17.issuetracking.v1.IssueR\x06issues\",\n" +
+	"\x0fGetIssueRequest\x12\x19\n" +
+	"\bissue_id\x18\x01 \x01(\rR\aissueId\"\xdc\x01\n" +
+	"\x12CreateIssueRequest\x12\x1f\n" +
+	"\vreporter_id\x18\x01 \x01(\rR\n" +
+	"reporterId\x12$\n" +
+	"\vassignee_id\x18\x02 \x01(\rH\x00R\n" +
+	"assigneeId\x88\x01\x01\x12\x1b\n" +
+	"\tstatus_id\x18\x03 \x01(\rR\bstatusId\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\tR\bpriorityB\x0e\n" +
+	"\f_assignee_id\"\xa9\x01\n" +
+	"\x18UpdateIssueStatusRequest\x12\x19\n" +
+	"\bissue_id\x18\x01 \x01(\rR\aissueId\x12\"\n" +
+	"\rnew_status_id\x18\x02 \x01(\rR\vnewStatusId\x12$\n" +
+	"\vassignee_id\x18\x03 \x01(\rH\x00R\n" +
+	"assigneeId\x88\x01\x01\x12\x18\n" +
+	"\acomment\x18\x04 \x01(\tR\acommentB\x0e\n" +
+	"\f_assignee_id\"d\n" +
+	"\x14CreateCommentRequest\x12\x19\n" +
+	"\bissue_id\x18\x01 \x01(\rR\aissueId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\rR\x06userId\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\"\x15\n" +
+	"\x13ListStatusesRequest\"Q\n" +
+	"\x14ListStatusesResponse\x129\n" +
+	"\bstatuses\x18\x01 \x03(\v2\x1d.issuetracking.v1.IssueStatusR\bstatuses\"\x15\n" +
+	"\x13ListOfficersRequest\"M\n" +
+	"\x14ListOfficersResponse\x125\n" +
+	"\bofficers\x18\x01 \x03(\v2\x19.issuetracking.v1.OfficerR\bofficers\"6\n" +
+	"\x17WatchIssueEventsRequest\x12\x1b\n" +
+	"\tissue_ids\x18\x01 \x03(\rR\bissueIds\"\xfe\x01\n" +
+	"\n" +
+	"IssueEvent\x124\n" +
+	"\x04type\x18\x01 \x01(\x0e2 .issuetracking.v1.IssueEventTypeR\x04type\x12\x19\n" +
+	"\bissue_id\x18\x02 \x01(\rR\aissueId\x12;\n" +
+	"\voccurred_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12-\n" +
+	"\x05issue\x18\x04 \x01(\v2\x17.issuetracking.v1.IssueR\x05issue\x123\n" +
+	"\acomment\x18\x05 \x01(\v2\x19.issuetracking.v1.CommentR\acomment*\xd1\x01\n" +
+	"\x0eIssueEventType\x12 \n" +
+	"\x1cISSUE_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18ISSUE_EVENT_TYPE_CREATED\x10\x01\x12#\n" +
+	"\x1fISSUE_EVENT_TYPE_STATUS_CHANGED\x10\x02\x12\x1e\n" +
+	"\x1aISSUE_EVENT_TYPE_COMMENTED\x10\x03\x12\x1c\n" +
+	"\x18ISSUE_EVENT_TYPE_UPDATED\x10\x04\x12\x1c\n" +
+	"\x18ISSUE_EVENT_TYPE_DELETED\x10\x052\xb5\a\n" +
+	"\fIssueService\x12t\n" +
+	"\n" +
+	"ListIssues\x12#.issuetracking.v1.ListIssuesRequest\x1a$.issuetracking.v1.ListIssuesResponse\"\x1b\x82\xd3\xe4\x93\x02\x15b\x06issues\x12\v/api/issues\x12f\n" +
+	"\bGetIssue\x12!.issuetracking.v1.GetIssueRequest\x1a\x17.issuetracking.v1.Issue\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/api/issues/{issue_id}\x12d\n" +
+	"\vCreateIssue\x12$.issuetracking.v1.CreateIssueRequest\x1a\x17.issuetracking.v1.Issue\"\x16\x82\xd3\xe4\x93\x02\x10:\x01*\"\v/api/issues\x12\x82\x01\n" +
+	"\x11UpdateIssueStatus\x12*.issuetracking.v1.UpdateIssueStatusRequest\x1a\x17.issuetracking.v1.Issue\"(\x82\xd3\xe4\x93\x02\":\x01*2\x1d/api/issues/{issue_id}/status\x12}\n" +
+	"\rCreateComment\x12&.issuetracking.v1.CreateCommentRequest\x1a\x19.issuetracking.v1.Comment\")\x82\xd3\xe4\x93\x02#:\x01*\"\x1e/api/issues/{issue_id}/comment\x12~\n" +
+	"\fListStatuses\x12%.issuetracking.v1.ListStatusesRequest\x1a&.issuetracking.v1.ListStatusesResponse\"\x1f\x82\xd3\xe4\x93\x02\x19b\bstatuses\x12\r/api/statuses\x12~\n" +
+	"\fListOfficers\x12%.issuetracking.v1.ListOfficersRequest\x1a&.issuetracking.v1.ListOfficersResponse\"\x1f\x82\xd3\xe4\x93\x02\x19b\bofficers\x12\r/api/officers\x12]\n" +
+	"\x10WatchIssueEvents\x12).issuetracking.v1.WatchIssueEventsRequest\x1a\x1c.issuetracking.v1.IssueEvent0\x01B7Z5issue-tracking/proto/issuetracking/v1;issuetrackingv1b\x06proto3"
+
+var (
+	file_issuetracking_v1_issue_tracking_proto_rawDescOnce sync.Once
+	file_issuetracking_v1_issue_tracking_proto_rawDescData []byte
+)
+
+func file_issuetracking_v1_issue_tracking_proto_rawDescGZIP() []byte {
+	file_issuetracking_v1_issue_tracking_proto_rawDescOnce.Do(func() {
+		file_issuetracking_v1_issue_tracking_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_issuetracking_v1_issue_tracking_proto_rawDesc), len(file_issuetracking_v1_issue_tracking_proto_rawDesc)))
+	})
+	return file_issuetracking_v1_issue_tracking_proto_rawDescData
+}
+
+var file_issuetracking_v1_issue_tracking_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_issuetracking_v1_issue_tracking_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_issuetracking_v1_issue_tracking_proto_goTypes = []any{
+	(IssueEventType)(0),              // 0: issuetracking.v1.IssueEventType
+	(*User)(nil),                     // 1: issuetracking.v1.User
+	(*Officer)(nil),                  // 2: issuetracking.v1.Officer
+	(*IssueStatus)(nil),              // 3: issuetracking.v1.IssueStatus
+	(*Label)(nil),                    // 4: issuetracking.v1.Label
+	(*StatusChange)(nil),             // 5: issuetracking.v1.StatusChange
+	(*Issue)(nil),                    // 6: issuetracking.v1.Issue
+	(*Comment)(nil),                  // 7: issuetracking.v1.Comment
+	(*ListIssuesRequest)(nil),        // 8: issuetracking.v1.ListIssuesRequest
+	(*ListIssuesResponse)(nil),       // 9: issuetracking.v1.ListIssuesResponse
+	(*GetIssueRequest)(nil),          // 10: issuetracking.v1.GetIssueRequest
+	(*CreateIssueRequest)(nil),       // 11: issuetracking.v1.CreateIssueRequest
+	(*UpdateIssueStatusRequest)(nil), // 12: issuetracking.v1.UpdateIssueStatusRequest
+	(*CreateCommentRequest)(nil),     // 13: issuetracking.v1.CreateCommentRequest
+	(*ListStatusesRequest)(nil),      // 14: issuetracking.v1.ListStatusesRequest
+	(*ListStatusesResponse)(nil),     // 15: issuetracking.v1.ListStatusesResponse
+	(*ListOfficersRequest)(nil),      // 16: issuetracking.v1.ListOfficersRequest
+	(*ListOfficersResponse)(nil),     // 17: issuetracking.v1.ListOfficersResponse
+	(*WatchIssueEventsRequest)(nil),  // 18: issuetracking.v1.WatchIssueEventsRequest
+	(*IssueEvent)(nil),               // 19: issuetracking.v1.IssueEvent
+	(*timestamppb.Timestamp)(nil),    // 20: google.protobuf.Timestamp
+}
+var file_issuetracking_v1_issue_tracking_proto_depIdxs = []int32{
+	20, // 0: issuetracking.v1.User.created_at:type_name -> google.protobuf.Timestamp
+	20, // 1: issuetracking.v1.User.updated_at:type_name -> google.protobuf.Timestamp
+	20, // 2: issuetracking.v1.Officer.created_at:type_name -> google.protobuf.Timestamp
+	20, // 3: issuetracking.v1.Officer.updated_at:type_name -> google.protobuf.Timestamp
+	20, // 4: issuetracking.v1.IssueStatus.created_at:type_name -> google.protobuf.Timestamp
+	20, // 5: issuetracking.v1.Label.created_at:type_name -> google.protobuf.Timestamp
+	20, // 6: issuetracking.v1.StatusChange.changed_at:type_name -> google.protobuf.Timestamp
+	20, // 7: issuetracking.v1.Issue.created_at:type_name -> google.protobuf.Timestamp
+	20, // 8: issuetracking.v1.Issue.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 9: issuetracking.v1.Issue.reporter:type_name -> issuetracking.v1.User
+	2,  // 10: issuetracking.v1.Issue.assignee:type_name -> issuetracking.v1.Officer
+	3,  // 11: issuetracking.v1.Issue.status:type_name -> issuetracking.v1.IssueStatus
+	5,  // 12: issuetracking.v1.Issue.status_history:type_name -> issuetracking.v1.StatusChange
+	7,  // 13: issuetracking.v1.Issue.comments:type_name -> issuetracking.v1.Comment
+	4,  // 14: issuetracking.v1.Issue.labels:type_name -> issuetracking.v1.Label
+	20, // 15: issuetracking.v1.Comment.created_at:type_name -> google.protobuf.Timestamp
+	6,  // 16: issuetracking.v1.Comment.issue:type_name -> issuetracking.v1.Issue
+	1,  // 17: issuetracking.v1.Comment.user:type_name -> issuetracking.v1.User
+	6,  // 18: issuetracking.v1.ListIssuesResponse.issues:type_name -> issuetracking.v1.Issue
+	3,  // 19: issuetracking.v1.ListStatusesResponse.statuses:type_name -> issuetracking.v1.IssueStatus
+	2,  // 20: issuetracking.v1.ListOfficersResponse.officers:type_name -> issuetracking.v1.Officer
+	0,  // 21: issuetracking.v1.IssueEvent.type:type_name -> issuetracking.v1.IssueEventType
+	20, // 22: issuetracking.v1.IssueEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	6,  // 23: issuetracking.v1.IssueEvent.issue:type_name -> issuetracking.v1.Issue
+	7,  // 24: issuetracking.v1.IssueEvent.comment:type_name -> issuetracking.v1.Comment
+	8,  // 25: issuetracking.v1.IssueService.ListIssues:input_type -> issuetracking.v1.ListIssuesRequest
+	10, // 26: issuetracking.v1.IssueService.GetIssue:input_type -> issuetracking.v1.GetIssueRequest
+	11, // 27: issuetracking.v1.IssueService.CreateIssue:input_type -> issuetracking.v1.CreateIssueRequest
+	12, // 28: issuetracking.v1.IssueService.UpdateIssueStatus:input_type -> issuetracking.v1.UpdateIssueStatusRequest
+	13, // 29: issuetracking.v1.IssueService.CreateComment:input_type -> issuetracking.v1.CreateCommentRequest
+	14, // 30: issuetracking.v1.IssueService.ListStatuses:input_type -> issuetracking.v1.ListStatusesRequest
+	16, // 31: issuetracking.v1.IssueService.ListOfficers:input_type -> issuetracking.v1.ListOfficersRequest
+	18, // 32: issuetracking.v1.IssueService.WatchIssueEvents:input_type -> issuetracking.v1.WatchIssueEventsRequest
+	9,  // 33: issuetracking.v1.IssueService.ListIssues:output_type -> issuetracking.v1.ListIssuesResponse
+	6,  // 34: issuetracking.v1.IssueService.GetIssue:output_type -> issuetracking.v1.Issue
+	6,  // 35: issuetracking.v1.IssueService.CreateIssue:output_type -> issuetracking.v1.Issue
+	6,  // 36: issuetracking.v1.IssueService.UpdateIssueStatus:output_type -> issuetracking.v1.Issue
+	7,  // 37: issuetracking.v1.IssueService.CreateComment:output_type -> issuetracking.v1.Comment
+	15, // 38: issuetracking.v1.IssueService.ListStatuses:output_type -> issuetracking.v1.ListStatusesResponse
+	17, // 39: issuetracking.v1.IssueService.ListOfficers:output_type -> issuetracking.v1.ListOfficersResponse
+	19, // 40: issuetracking.v1.IssueService.WatchIssueEvents:output_type -> issuetracking.v1.IssueEvent
+	33, // [33:41] is the sub-list for method output_type
+	25, // [25:33] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
+}
+
+func init() { file_issuetracking_v1_issue_tracking_proto_init() }
+func file_issuetracking_v1_issue_tracking_proto_init() {
+	if File_issuetracking_v1_issue_tracking_proto != nil {
+		return
+	}
+	file_issuetracking_v1_issue_tracking_proto_msgTypes[4].OneofWrappers = []any{}
+	file_issuetracking_v1_issue_tracking_proto_msgTypes[5].OneofWrappers = []any{}
+	file_issuetracking_v1_issue_tracking_proto_msgTypes[7].OneofWrappers = []any{}
+	file_issuetracking_v1_issue_tracking_proto_msgTypes[10].OneofWrappers = []any{}
+	file_issuetracking_v1_issue_tracking_proto_msgTypes[11].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_issuetracking_v1_issue_tracking_proto_rawDesc), len(file_issuetracking_v1_issue_tracking_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_issuetracking_v1_issue_tracking_proto_goTypes,
+		DependencyIndexes: file_issuetracking_v1_issue_tracking_proto_depIdxs,
+		EnumInfos:         file_issuetracking_v1_issue_tracking_proto_enumTypes,
+		MessageInfos:      file_issuetracking_v1_issue_tracking_proto_msgTypes,
+	}.Build()
+	File_issuetracking_v1_issue_tracking_proto = out.File
+	file_issuetracking_v1_issue_tracking_proto_goTypes = nil
+	file_issuetracking_v1_issue_tracking_proto_depIdxs = nil
+}
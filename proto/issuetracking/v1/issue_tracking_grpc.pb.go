@@ -0,0 +1,421 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: issuetracking/v1/issue_tracking.proto
+
+package issuetrackingv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IssueService_ListIssues_FullMethodName        = "/issuetracking.v1.IssueService/ListIssues"
+	IssueService_GetIssue_FullMethodName          = "/issuetracking.v1.IssueService/GetIssue"
+	IssueService_CreateIssue_FullMethodName       = "/issuetracking.v1.IssueService/CreateIssue"
+	IssueService_UpdateIssueStatus_FullMethodName = "/issuetracking.v1.IssueService/UpdateIssueStatus"
+	IssueService_CreateComment_FullMethodName     = "/issuetracking.v1.IssueService/CreateComment"
+	IssueService_ListStatuses_FullMethodName      = "/issuetracking.v1.IssueService/ListStatuses"
+	IssueService_ListOfficers_FullMethodName      = "/issuetracking.v1.IssueService/ListOfficers"
+	IssueService_WatchIssueEvents_FullMethodName  = "/issuetracking.v1.IssueService/WatchIssueEvents"
+)
+
+// IssueServiceClient is the client API for IssueService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// IssueService is the gRPC counterpart of the REST API. Each RPC with an HTTP
+// rule behaves like the REST route it names: the same checks, the same
+// errors and, with proto field names, the same JSON fields.
+type IssueServiceClient interface {
+	// ListIssues lists issues with optional filters, a page at a time when
+	// limit is set
+	ListIssues(ctx context.Context, in *ListIssuesRequest, opts ...grpc.CallOption) (*ListIssuesResponse, error)
+	// GetIssue returns one issue with its relations
+	GetIssue(ctx context.Context, in *GetIssueRequest, opts ...grpc.CallOption) (*Issue, error)
+	// CreateIssue opens a new issue
+	CreateIssue(ctx context.Context, in *CreateIssueRequest, opts ...grpc.CallOption) (*Issue, error)
+	// UpdateIssueStatus moves an issue to another status
+	UpdateIssueStatus(ctx context.Context, in *UpdateIssueStatusRequest, opts ...grpc.CallOption) (*Issue, error)
+	// CreateComment adds a comment to an issue
+	CreateComment(ctx context.Context, in *CreateCommentRequest, opts ...grpc.CallOption) (*Comment, error)
+	// ListStatuses lists the active statuses in display order
+	ListStatuses(ctx context.Context, in *ListStatusesRequest, opts ...grpc.CallOption) (*ListStatusesResponse, error)
+	// ListOfficers lists the officers
+	ListOfficers(ctx context.Context, in *ListOfficersRequest, opts ...grpc.CallOption) (*ListOfficersResponse, error)
+	// WatchIssueEvents streams the issues created, transitioned and commented
+	// on from the time of the call until the client cancels it. It has no REST
+	// counterpart.
+	WatchIssueEvents(ctx context.Context, in *WatchIssueEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IssueEvent], error)
+}
+
+type issueServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIssueServiceClient(cc grpc.ClientConnInterface) IssueServiceClient {
+	return &issueServiceClient{cc}
+}
+
+func (c *issueServiceClient) ListIssues(ctx context.Context, in *ListIssuesRequest, opts ...grpc.CallOption) (*ListIssuesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListIssuesResponse)
+	err := c.cc.Invoke(ctx, IssueService_ListIssues_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issueServiceClient) GetIssue(ctx context.Context, in *GetIssueRequest, opts ...grpc.CallOption) (*Issue, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Issue)
+	err := c.cc.Invoke(ctx, IssueService_GetIssue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issueServiceClient) CreateIssue(ctx context.Context, in *CreateIssueRequest, opts ...grpc.CallOption) (*Issue, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Issue)
+	err := c.cc.Invoke(ctx, IssueService_CreateIssue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issueServiceClient) UpdateIssueStatus(ctx context.Context, in *UpdateIssueStatusRequest, opts ...grpc.CallOption) (*Issue, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Issue)
+	err := c.cc.Invoke(ctx, IssueService_UpdateIssueStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issueServiceClient) CreateComment(ctx context.Context, in *CreateCommentRequest, opts ...grpc.CallOption) (*Comment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Comment)
+	err := c.cc.Invoke(ctx, IssueService_CreateComment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issueServiceClient) ListStatuses(ctx context.Context, in *ListStatusesRequest, opts ...grpc.CallOption) (*ListStatusesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStatusesResponse)
+	err := c.cc.Invoke(ctx, IssueService_ListStatuses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issueServiceClient) ListOfficers(ctx context.Context, in *ListOfficersRequest, opts ...grpc.CallOption) (*ListOfficersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOfficersResponse)
+	err := c.cc.Invoke(ctx, IssueService_ListOfficers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issueServiceClient) WatchIssueEvents(ctx context.Context, in *WatchIssueEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IssueEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IssueService_ServiceDesc.Streams[0], IssueService_WatchIssueEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchIssueEventsRequest, IssueEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IssueService_WatchIssueEventsClient = grpc.ServerStreamingClient[IssueEvent]
+
+// IssueServiceServer is the server API for IssueService service.
+// All implementations must embed UnimplementedIssueServiceServer
+// for forward compatibility.
+//
+// IssueService is the gRPC counterpart of the REST API. Each RPC with an HTTP
+// rule behaves like the REST route it names: the same checks, the same
+// errors and, with proto field names, the same JSON fields.
+type IssueServiceServer interface {
+	// ListIssues lists issues with optional filters, a page at a time when
+	// limit is set
+	ListIssues(context.Context, *ListIssuesRequest) (*ListIssuesResponse, error)
+	// GetIssue returns one issue with its relations
+	GetIssue(context.Context, *GetIssueRequest) (*Issue, error)
+	// CreateIssue opens a new issue
+	CreateIssue(context.Context, *CreateIssueRequest) (*Issue, error)
+	// UpdateIssueStatus moves an issue to another status
+	UpdateIssueStatus(context.Context, *UpdateIssueStatusRequest) (*Issue, error)
+	// CreateComment adds a comment to an issue
+	CreateComment(context.Context, *CreateCommentRequest) (*Comment, error)
+	// ListStatuses lists the active statuses in display order
+	ListStatuses(context.Context, *ListStatusesRequest) (*ListStatusesResponse, error)
+	// ListOfficers lists the officers
+	ListOfficers(context.Context, *ListOfficersRequest) (*ListOfficersResponse, error)
+	// WatchIssueEvents streams the issues created, transitioned and commented
+	// on from the time of the call until the client cancels it. It has no REST
+	// counterpart.
+	WatchIssueEvents(*WatchIssueEventsRequest, grpc.ServerStreamingServer[IssueEvent]) error
+	mustEmbedUnimplementedIssueServiceServer()
+}
+
+// UnimplementedIssueServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIssueServiceServer struct{}
+
+func (UnimplementedIssueServiceServer) ListIssues(context.Context, *ListIssuesRequest) (*ListIssuesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIssues not implemented")
+}
+func (UnimplementedIssueServiceServer) GetIssue(context.Context, *GetIssueRequest) (*Issue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIssue not implemented")
+}
+func (UnimplementedIssueServiceServer) CreateIssue(context.Context, *CreateIssueRequest) (*Issue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateIssue not implemented")
+}
+func (UnimplementedIssueServiceServer) UpdateIssueStatus(context.Context, *UpdateIssueStatusRequest) (*Issue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateIssueStatus not implemented")
+}
+func (UnimplementedIssueServiceServer) CreateComment(context.Context, *CreateCommentRequest) (*Comment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateComment not implemented")
+}
+func (UnimplementedIssueServiceServer) ListStatuses(context.Context, *ListStatusesRequest) (*ListStatusesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStatuses not implemented")
+}
+func (UnimplementedIssueServiceServer) ListOfficers(context.Context, *ListOfficersRequest) (*ListOfficersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOfficers not implemented")
+}
+func (UnimplementedIssueServiceServer) WatchIssueEvents(*WatchIssueEventsRequest, grpc.ServerStreamingServer[IssueEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchIssueEvents not implemented")
+}
+func (UnimplementedIssueServiceServer) mustEmbedUnimplementedIssueServiceServer() {}
+func (UnimplementedIssueServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeIssueServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IssueServiceServer will
+// result in compilation errors.
+type UnsafeIssueServiceServer interface {
+	mustEmbedUnimplementedIssueServiceServer()
+}
+
+func RegisterIssueServiceServer(s grpc.ServiceRegistrar, srv IssueServiceServer) {
+	// If the following call pancis, it indicates UnimplementedIssueServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IssueService_ServiceDesc, srv)
+}
+
+func _IssueService_ListIssues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListIssuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssueServiceServer).ListIssues(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssueService_ListIssues_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssueServiceServer).ListIssues(ctx, req.(*ListIssuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssueService_GetIssue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIssueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssueServiceServer).GetIssue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssueService_GetIssue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssueServiceServer).GetIssue(ctx, req.(*GetIssueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssueService_CreateIssue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateIssueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssueServiceServer).CreateIssue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssueService_CreateIssue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssueServiceServer).CreateIssue(ctx, req.(*CreateIssueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssueService_UpdateIssueStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateIssueStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssueServiceServer).UpdateIssueStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssueService_UpdateIssueStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssueServiceServer).UpdateIssueStatus(ctx, req.(*UpdateIssueStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssueService_CreateComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssueServiceServer).CreateComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssueService_CreateComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssueServiceServer).CreateComment(ctx, req.(*CreateCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssueService_ListStatuses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStatusesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssueServiceServer).ListStatuses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssueService_ListStatuses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssueServiceServer).ListStatuses(ctx, req.(*ListStatusesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssueService_ListOfficers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOfficersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssueServiceServer).ListOfficers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssueService_ListOfficers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssueServiceServer).ListOfficers(ctx, req.(*ListOfficersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssueService_WatchIssueEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchIssueEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IssueServiceServer).WatchIssueEvents(m, &grpc.GenericServerStream[WatchIssueEventsRequest, IssueEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IssueService_WatchIssueEventsServer = grpc.ServerStreamingServer[IssueEvent]
+
+// IssueService_ServiceDesc is the grpc.ServiceDesc for IssueService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IssueService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "issuetracking.v1.IssueService",
+	HandlerType: (*IssueServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListIssues",
+			Handler:    _IssueService_ListIssues_Handler,
+		},
+		{
+			MethodName: "GetIssue",
+			Handler:    _IssueService_GetIssue_Handler,
+		},
+		{
+			MethodName: "CreateIssue",
+			Handler:    _IssueService_CreateIssue_Handler,
+		},
+		{
+			MethodName: "UpdateIssueStatus",
+			Handler:    _IssueService_UpdateIssueStatus_Handler,
+		},
+		{
+			MethodName: "CreateComment",
+			Handler:    _IssueService_CreateComment_Handler,
+		},
+		{
+			MethodName: "ListStatuses",
+			Handler:    _IssueService_ListStatuses_Handler,
+		},
+		{
+			MethodName: "ListOfficers",
+			Handler:    _IssueService_ListOfficers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchIssueEvents",
+			Handler:       _IssueService_WatchIssueEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "issuetracking/v1/issue_tracking.proto",
+}
@@ -0,0 +1,648 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"issue-tracking/entities"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormStore implements Store with GORM
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a store backed by db
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Issues() IssueRepository                 { return gormIssues{s.db} }
+func (s *GormStore) Comments() CommentRepository             { return gormComments{s.db} }
+func (s *GormStore) Statuses() StatusRepository              { return gormStatuses{s.db} }
+func (s *GormStore) Users() UserRepository                   { return gormUsers{s.db} }
+func (s *GormStore) Officers() OfficerRepository             { return gormOfficers{s.db} }
+func (s *GormStore) Labels() LabelRepository                 { return gormLabels{s.db} }
+func (s *GormStore) IdempotencyKeys() IdempotencyRepository  { return gormIdempotencyKeys{s.db} }
+func (s *GormStore) RateLimits() RateLimitRepository         { return gormRateLimits{s.db} }
+func (s *GormStore) APIKeys() APIKeyRepository               { return gormAPIKeys{s.db} }
+func (s *GormStore) Identities() IdentityRepository          { return gormIdentities{s.db} }
+func (s *GormStore) TrackingTokens() TrackingTokenRepository { return gormTrackingTokens{s.db} }
+func (s *GormStore) Surveys() SurveyRepository               { return gormSurveys{s.db} }
+
+// Transaction implements Store. Nested calls use savepoints.
+func (s *GormStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormStore{db: tx})
+	})
+}
+
+// notFound translates GORM's not-found error to ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// first loads the row with primary key id into dest
+func first(ctx context.Context, db *gorm.DB, dest interface{}, id uint) error {
+	return notFound(db.WithContext(ctx).First(dest, id).Error)
+}
+
+type gormIssues struct{ db *gorm.DB }
+
+// filter narrows an issue query
+func (r gormIssues) filter(ctx context.Context, f IssueFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&entities.Issue{})
+	if f.StatusCode != "" {
+		query = query.Where("status_id IN (SELECT status_id FROM issue_statuses WHERE status_code = ?)", f.StatusCode)
+	}
+	if f.Priority != "" {
+		query = query.Where("priority = ?", f.Priority)
+	}
+	if f.AssigneeID != nil {
+		query = query.Where("assignee_id = ?", *f.AssigneeID)
+	}
+	if f.ReporterID != nil {
+		query = query.Where("reporter_id = ?", *f.ReporterID)
+	}
+	if f.Label != "" {
+		query = query.Where("issue_id IN (SELECT issue_labels.issue_id FROM issue_labels JOIN labels ON labels.label_id = issue_labels.label_id WHERE labels.name = ?)", f.Label)
+	}
+	if f.AfterID != 0 {
+		query = query.Where("issue_id > ?", f.AfterID)
+	}
+	return query
+}
+
+func (r gormIssues) List(ctx context.Context, f IssueFilter) ([]entities.Issue, error) {
+	var issues []entities.Issue
+	query := r.filter(ctx, f)
+	if f.Limit > 0 {
+		query = query.Limit(f.Limit)
+	}
+	err := query.
+		Preload("Reporter").
+		Preload("Assignee").
+		Preload("Status").
+		Preload("Comments.User").
+		Preload("Labels").
+		Order("issue_id").
+		Find(&issues).Error
+	return issues, err
+}
+
+func (r gormIssues) ListIDs(ctx context.Context, f IssueFilter, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.filter(ctx, f).Order("issue_id").Limit(limit).Pluck("issue_id", &ids).Error
+	return ids, err
+}
+
+func (r gormIssues) Batches(ctx context.Context, f IssueFilter, size int, fn func([]entities.Issue) error) error {
+	var batch []entities.Issue
+	return r.filter(ctx, f).
+		Preload("Reporter").
+		Preload("Assignee").
+		Preload("Status").
+		FindInBatches(&batch, size, func(*gorm.DB, int) error {
+			return fn(batch)
+		}).Error
+}
+
+func (r gormIssues) Find(ctx context.Context, f IssueFilter) ([]entities.Issue, error) {
+	var issues []entities.Issue
+	query := r.filter(ctx, f)
+	if f.Limit > 0 {
+		query = query.Limit(f.Limit)
+	}
+	err := query.Order("issue_id").Find(&issues).Error
+	return issues, err
+}
+
+func (r gormIssues) Get(ctx context.Context, id uint) (*entities.Issue, error) {
+	var issue entities.Issue
+	err := r.db.WithContext(ctx).
+		Preload("Reporter").
+		Preload("Assignee").
+		Preload("Status").
+		Preload("StatusHistory").
+		Preload("Comments").
+		Preload("Labels").
+		First(&issue, id).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &issue, nil
+}
+
+func (r gormIssues) ListByIDs(ctx context.Context, ids []uint) ([]entities.Issue, error) {
+	var issues []entities.Issue
+	err := r.db.WithContext(ctx).Where("issue_id IN ?", ids).Order("issue_id").Find(&issues).Error
+	return issues, err
+}
+
+func (r gormIssues) Create(ctx context.Context, issue *entities.Issue) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(issue).Error
+}
+
+func (r gormIssues) CreateBatch(ctx context.Context, issues []entities.Issue) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Omit(clause.Associations).Create(&issues).Error
+	})
+}
+
+func (r gormIssues) Update(ctx context.Context, issue *entities.Issue) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(issue).Error
+}
+
+func (r gormIssues) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&entities.Issue{}, id).Error
+}
+
+func (r gormIssues) ApplyChange(ctx context.Context, id uint, change IssueChange) error {
+	// Lock the issue row for the whole change so concurrent updates are
+	// serialized and each history entry records the status it actually replaced
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var issue entities.Issue
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&issue, id).Error; err != nil {
+			return notFound(err)
+		}
+
+		oldStatusID := issue.StatusID
+		newStatusID := oldStatusID
+
+		updates := map[string]interface{}{}
+		if change.SetAssignee {
+			updates["assignee_id"] = change.AssigneeID
+		}
+		if change.Priority != "" {
+			updates["priority"] = change.Priority
+		}
+		if change.NewStatusID != nil {
+			newStatusID = *change.NewStatusID
+			updates["status_id"] = newStatusID
+		}
+
+		if len(updates) > 0 {
+			if err := tx.Model(&issue).Updates(updates).Error; err != nil {
+				return UpdateIssueError{err}
+			}
+		}
+
+		if change.Label != nil {
+			if err := tx.Model(&issue).Association("Labels").Append(change.Label); err != nil {
+				return UpdateIssueError{err}
+			}
+		}
+
+		history := entities.IssueStatusHistory{
+			IssueID:     id,
+			OldStatusID: &oldStatusID,
+			NewStatusID: newStatusID,
+			ChangedBy:   change.ChangedBy,
+			Comment:     change.Comment,
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return RecordHistoryError{err}
+		}
+
+		// The row lock makes only the change that actually closes the issue
+		// see an open status here, so each closure sends one survey
+		if change.SurveyTTL > 0 && newStatusID != oldStatusID {
+			var terminal []uint
+			err := tx.Model(&entities.IssueStatus{}).
+				Where("status_id IN ? AND is_terminal = ?", []uint{oldStatusID, newStatusID}, true).
+				Pluck("status_id", &terminal).Error
+			if err != nil {
+				return UpdateIssueError{err}
+			}
+			if slices.Contains(terminal, newStatusID) && !slices.Contains(terminal, oldStatusID) {
+				assigneeID := issue.AssigneeID
+				if change.SetAssignee {
+					assigneeID = change.AssigneeID
+				}
+				survey := entities.Survey{IssueID: id, OfficerID: assigneeID, ExpiresAt: time.Now().Add(change.SurveyTTL)}
+				if err := tx.Create(&survey).Error; err != nil {
+					return UpdateIssueError{err}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// active selects the issues ListActive returns
+func (r gormIssues) active(ctx context.Context, since, until time.Time) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	open := db.Model(&entities.IssueStatus{}).Select("status_id").Where("is_terminal = ?", false)
+	changed := db.Model(&entities.IssueStatusHistory{}).Select("issue_id").Where("changed_at >= ?", since.UTC())
+	return db.Model(&entities.Issue{}).
+		Where("created_at < ?", until.UTC()).
+		Where("created_at >= ? OR status_id IN (?) OR issue_id IN (?)", since.UTC(), open, changed)
+}
+
+func (r gormIssues) ListActive(ctx context.Context, since, until time.Time) ([]entities.Issue, error) {
+	var issues []entities.Issue
+	err := r.active(ctx, since, until).
+		Select("issue_id", "priority", "assignee_id", "status_id", "created_at").
+		Order("issue_id").
+		Find(&issues).Error
+	return issues, err
+}
+
+func (r gormIssues) ActiveHistory(ctx context.Context, since, until time.Time) ([]entities.IssueStatusHistory, error) {
+	var history []entities.IssueStatusHistory
+	err := r.db.WithContext(ctx).
+		Where("issue_id IN (?)", r.active(ctx, since, until).Select("issue_id")).
+		Order("issue_id, changed_at, history_id").
+		Find(&history).Error
+	return history, err
+}
+
+func (r gormIssues) CountByStatus(ctx context.Context, until time.Time) (map[uint]int, error) {
+	var rows []struct {
+		StatusID uint
+		Count    int
+	}
+	err := r.db.WithContext(ctx).Model(&entities.Issue{}).
+		Select("status_id, COUNT(*) AS count").
+		Where("created_at < ?", until.UTC()).
+		Group("status_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.StatusID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r gormIssues) HistoryByIssues(ctx context.Context, issueIDs []uint) ([]entities.IssueStatusHistory, error) {
+	var history []entities.IssueStatusHistory
+	err := r.db.WithContext(ctx).Where("issue_id IN ?", issueIDs).Order("issue_id, changed_at, history_id").Find(&history).Error
+	return history, err
+}
+
+type gormComments struct{ db *gorm.DB }
+
+func (r gormComments) ListByIssue(ctx context.Context, issueID uint) ([]entities.Comment, error) {
+	var comments []entities.Comment
+	err := r.db.WithContext(ctx).
+		Where("issue_id = ?", issueID).
+		Preload("User").
+		Order("created_at DESC").
+		Find(&comments).Error
+	return comments, err
+}
+
+func (r gormComments) ListByIssues(ctx context.Context, issueIDs []uint) ([]entities.Comment, error) {
+	var comments []entities.Comment
+	err := r.db.WithContext(ctx).Where("issue_id IN ?", issueIDs).Order("comment_id").Find(&comments).Error
+	return comments, err
+}
+
+func (r gormComments) Get(ctx context.Context, id uint) (*entities.Comment, error) {
+	var comment entities.Comment
+	if err := r.db.WithContext(ctx).Preload("User").Preload("Issue").First(&comment, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &comment, nil
+}
+
+func (r gormComments) Create(ctx context.Context, comment *entities.Comment) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(comment).Error
+}
+
+func (r gormComments) Update(ctx context.Context, comment *entities.Comment) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(comment).Error
+}
+
+func (r gormComments) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&entities.Comment{}, id).Error
+}
+
+type gormStatuses struct{ db *gorm.DB }
+
+func (r gormStatuses) List(ctx context.Context, activeOnly bool) ([]entities.IssueStatus, error) {
+	query := r.db.WithContext(ctx).Order("display_order, status_id")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	var statuses []entities.IssueStatus
+	err := query.Find(&statuses).Error
+	return statuses, err
+}
+
+func (r gormStatuses) Get(ctx context.Context, id uint) (*entities.IssueStatus, error) {
+	var status entities.IssueStatus
+	if err := first(ctx, r.db, &status, id); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (r gormStatuses) ListByIDs(ctx context.Context, ids []uint) ([]entities.IssueStatus, error) {
+	var statuses []entities.IssueStatus
+	err := r.db.WithContext(ctx).Where("status_id IN ?", ids).Find(&statuses).Error
+	return statuses, err
+}
+
+func (r gormStatuses) FindByName(ctx context.Context, name string) (*entities.IssueStatus, error) {
+	key := strings.ToLower(name)
+	var status entities.IssueStatus
+	err := r.db.WithContext(ctx).
+		Where("LOWER(status_code) = ? OR LOWER(display_name) = ?", key, key).
+		Order("status_id").
+		First(&status).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &status, nil
+}
+
+func (r gormStatuses) Create(ctx context.Context, status *entities.IssueStatus) error {
+	return r.db.WithContext(ctx).Create(status).Error
+}
+
+type gormUsers struct{ db *gorm.DB }
+
+func (r gormUsers) Get(ctx context.Context, id uint) (*entities.User, error) {
+	var user entities.User
+	if err := first(ctx, r.db, &user, id); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r gormUsers) ListByIDs(ctx context.Context, ids []uint) ([]entities.User, error) {
+	var users []entities.User
+	err := r.db.WithContext(ctx).Where("user_id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+func (r gormUsers) FindByName(ctx context.Context, name string) (*entities.User, error) {
+	var user entities.User
+	err := r.db.WithContext(ctx).Where("LOWER(full_name) = ?", strings.ToLower(name)).Order("user_id").First(&user).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r gormUsers) Create(ctx context.Context, user *entities.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+type gormOfficers struct{ db *gorm.DB }
+
+func (r gormOfficers) List(ctx context.Context) ([]entities.Officer, error) {
+	var officers []entities.Officer
+	err := r.db.WithContext(ctx).Order("officer_id").Find(&officers).Error
+	return officers, err
+}
+
+func (r gormOfficers) Get(ctx context.Context, id uint) (*entities.Officer, error) {
+	var officer entities.Officer
+	if err := first(ctx, r.db, &officer, id); err != nil {
+		return nil, err
+	}
+	return &officer, nil
+}
+
+func (r gormOfficers) ListByIDs(ctx context.Context, ids []uint) ([]entities.Officer, error) {
+	var officers []entities.Officer
+	err := r.db.WithContext(ctx).Where("officer_id IN ?", ids).Find(&officers).Error
+	return officers, err
+}
+
+func (r gormOfficers) FindByName(ctx context.Context, name string) (*entities.Officer, error) {
+	var officer entities.Officer
+	err := r.db.WithContext(ctx).Where("LOWER(full_name) = ?", strings.ToLower(name)).Order("officer_id").First(&officer).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &officer, nil
+}
+
+func (r gormOfficers) Create(ctx context.Context, officer *entities.Officer) error {
+	return r.db.WithContext(ctx).Create(officer).Error
+}
+
+type gormLabels struct{ db *gorm.DB }
+
+func (r gormLabels) FindOrCreate(ctx context.Context, label *entities.Label) error {
+	return r.db.WithContext(ctx).Where(entities.Label{Name: label.Name}).FirstOrCreate(label).Error
+}
+
+func (r gormLabels) ListByIssues(ctx context.Context, issueIDs []uint) (map[uint][]entities.Label, error) {
+	var rows []struct {
+		IssueID        uint
+		entities.Label `gorm:"embedded"`
+	}
+	err := r.db.WithContext(ctx).
+		Table("labels").
+		Select("issue_labels.issue_id, labels.*").
+		Joins("JOIN issue_labels ON issue_labels.label_id = labels.label_id").
+		Where("issue_labels.issue_id IN ?", issueIDs).
+		Order("labels.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	labels := map[uint][]entities.Label{}
+	for _, row := range rows {
+		labels[row.IssueID] = append(labels[row.IssueID], row.Label)
+	}
+	return labels, nil
+}
+
+type gormIdempotencyKeys struct{ db *gorm.DB }
+
+func (r gormIdempotencyKeys) Claim(ctx context.Context, record *entities.IdempotencyKey) (bool, error) {
+	db := r.db.WithContext(ctx)
+
+	// SQLite compares times as text, so every time is written in UTC
+	record.ExpiresAt = record.ExpiresAt.UTC()
+
+	// Drop an expired record so the key can be used again
+	if err := db.Where("idempotency_key = ? AND expires_at <= ?", record.Key, time.Now().UTC()).Delete(&entities.IdempotencyKey{}).Error; err != nil {
+		return false, err
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r gormIdempotencyKeys) Get(ctx context.Context, key string) (*entities.IdempotencyKey, error) {
+	var record entities.IdempotencyKey
+	if err := r.db.WithContext(ctx).First(&record, "idempotency_key = ?", key).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &record, nil
+}
+
+func (r gormIdempotencyKeys) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	return r.db.WithContext(ctx).Model(&entities.IdempotencyKey{Key: key}).Updates(map[string]interface{}{
+		"status_code":   statusCode,
+		"content_type":  contentType,
+		"response_body": body,
+	}).Error
+}
+
+func (r gormIdempotencyKeys) Release(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Delete(&entities.IdempotencyKey{Key: key}).Error
+}
+
+func (r gormIdempotencyKeys) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now.UTC()).Delete(&entities.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
+
+type gormRateLimits struct{ db *gorm.DB }
+
+func (r gormRateLimits) Update(ctx context.Context, key string, fn func(bucket *entities.RateLimitBucket)) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Create the bucket first so there is always a row to lock, even
+		// when replicas see a new client at the same time
+		empty := entities.RateLimitBucket{Key: key}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&empty).Error; err != nil {
+			return err
+		}
+
+		var bucket entities.RateLimitBucket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bucket, "bucket_key = ?", key).Error; err != nil {
+			return err
+		}
+		fn(&bucket)
+		bucket.UpdatedAt = bucket.UpdatedAt.UTC()
+		return tx.Save(&bucket).Error
+	})
+}
+
+func (r gormRateLimits) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("updated_at < ?", before.UTC()).Delete(&entities.RateLimitBucket{})
+	return result.RowsAffected, result.Error
+}
+
+type gormAPIKeys struct{ db *gorm.DB }
+
+func (r gormAPIKeys) List(ctx context.Context) ([]entities.APIKey, error) {
+	var keys []entities.APIKey
+	err := r.db.WithContext(ctx).Order("api_key_id").Find(&keys).Error
+	return keys, err
+}
+
+func (r gormAPIKeys) Get(ctx context.Context, id uint) (*entities.APIKey, error) {
+	var key entities.APIKey
+	if err := first(ctx, r.db, &key, id); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r gormAPIKeys) FindByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error) {
+	var key entities.APIKey
+	if err := r.db.WithContext(ctx).First(&key, "prefix = ?", prefix).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &key, nil
+}
+
+func (r gormAPIKeys) Create(ctx context.Context, key *entities.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r gormAPIKeys) Revoke(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.APIKey{}).
+		Where("api_key_id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at.UTC()).Error
+}
+
+func (r gormAPIKeys) Touch(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.APIKey{}).
+		Where("api_key_id = ?", id).
+		Update("last_used_at", at.UTC()).Error
+}
+
+type gormIdentities struct{ db *gorm.DB }
+
+func (r gormIdentities) FindBySubject(ctx context.Context, issuer, subject string) (*entities.Identity, error) {
+	var identity entities.Identity
+	if err := r.db.WithContext(ctx).First(&identity, "issuer = ? AND subject = ?", issuer, subject).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &identity, nil
+}
+
+func (r gormIdentities) Create(ctx context.Context, identity *entities.Identity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r gormIdentities) Update(ctx context.Context, identity *entities.Identity) error {
+	return r.db.WithContext(ctx).Save(identity).Error
+}
+
+type gormTrackingTokens struct{ db *gorm.DB }
+
+func (r gormTrackingTokens) FindByHash(ctx context.Context, hash string) (*entities.TrackingToken, error) {
+	var token entities.TrackingToken
+	if err := r.db.WithContext(ctx).First(&token, "token_hash = ?", hash).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (r gormTrackingTokens) Create(ctx context.Context, token *entities.TrackingToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+type gormSurveys struct{ db *gorm.DB }
+
+func (r gormSurveys) Get(ctx context.Context, id uint) (*entities.Survey, error) {
+	var survey entities.Survey
+	if err := first(ctx, r.db, &survey, id); err != nil {
+		return nil, err
+	}
+	return &survey, nil
+}
+
+func (r gormSurveys) ListSent(ctx context.Context, from, to time.Time) ([]entities.Survey, error) {
+	var surveys []entities.Survey
+	err := r.db.WithContext(ctx).
+		Where("created_at >= ? AND created_at < ?", from.UTC(), to.UTC()).
+		Order("survey_id").Find(&surveys).Error
+	return surveys, err
+}
+
+func (r gormSurveys) ListPending(ctx context.Context, issueID uint, now time.Time) ([]entities.Survey, error) {
+	query := r.db.WithContext(ctx).Where("responded_at IS NULL AND expires_at > ?", now.UTC())
+	if issueID != 0 {
+		query = query.Where("issue_id = ?", issueID)
+	}
+	var surveys []entities.Survey
+	err := query.Order("survey_id").Find(&surveys).Error
+	return surveys, err
+}
+
+func (r gormSurveys) Create(ctx context.Context, survey *entities.Survey) error {
+	return r.db.WithContext(ctx).Create(survey).Error
+}
+
+func (r gormSurveys) Respond(ctx context.Context, id uint, rating int, feedback string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.Survey{}).
+		Where("survey_id = ? AND responded_at IS NULL", id).
+		Updates(map[string]interface{}{"rating": rating, "feedback": feedback, "responded_at": at.UTC()})
+	return result.RowsAffected > 0, result.Error
+}
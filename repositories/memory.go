@@ -0,0 +1,1258 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"issue-tracking/entities"
+)
+
+// defaultLabelColor matches the column default of labels.color
+const defaultLabelColor = "#808080"
+
+// MemoryStore implements Store in memory. It enforces the foreign keys and
+// cascades of the SQL schema so handlers behave as they do against a
+// database, and is meant for tests.
+type MemoryStore struct {
+	data *memoryData
+	inTx bool
+}
+
+// memoryData is shared by a store and the stores of its transactions. mu
+// guards state, txMu serializes transactions so a rollback cannot discard
+// another transaction's changes.
+type memoryData struct {
+	mu    sync.Mutex
+	txMu  sync.Mutex
+	state memoryState
+
+	// Rate limits are not rolled back with transactions
+	rateLimits *MemoryRateLimits
+}
+
+// memoryState holds the rows without relations, keyed by primary key
+type memoryState struct {
+	lastID      map[string]uint
+	users       map[uint]entities.User
+	officers    map[uint]entities.Officer
+	statuses    map[uint]entities.IssueStatus
+	labels      map[uint]entities.Label
+	issues      map[uint]entities.Issue
+	issueLabels map[uint][]uint
+	history     map[uint]entities.IssueStatusHistory
+	comments    map[uint]entities.Comment
+	idempotency map[string]entities.IdempotencyKey
+	apiKeys     map[uint]entities.APIKey
+	identities  map[uint]entities.Identity
+	tracking    map[uint]entities.TrackingToken
+	surveys     map[uint]entities.Survey
+}
+
+// NewMemoryStore returns an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: &memoryData{rateLimits: NewMemoryRateLimits(), state: memoryState{
+		lastID:      map[string]uint{},
+		users:       map[uint]entities.User{},
+		officers:    map[uint]entities.Officer{},
+		statuses:    map[uint]entities.IssueStatus{},
+		labels:      map[uint]entities.Label{},
+		issues:      map[uint]entities.Issue{},
+		issueLabels: map[uint][]uint{},
+		history:     map[uint]entities.IssueStatusHistory{},
+		comments:    map[uint]entities.Comment{},
+		idempotency: map[string]entities.IdempotencyKey{},
+		apiKeys:     map[uint]entities.APIKey{},
+		identities:  map[uint]entities.Identity{},
+		tracking:    map[uint]entities.TrackingToken{},
+		surveys:     map[uint]entities.Survey{},
+	}}}
+}
+
+func (s *MemoryStore) Issues() IssueRepository                 { return memoryIssues{s} }
+func (s *MemoryStore) Comments() CommentRepository             { return memoryComments{s} }
+func (s *MemoryStore) Statuses() StatusRepository              { return memoryStatuses{s} }
+func (s *MemoryStore) Users() UserRepository                   { return memoryUsers{s} }
+func (s *MemoryStore) Officers() OfficerRepository             { return memoryOfficers{s} }
+func (s *MemoryStore) Labels() LabelRepository                 { return memoryLabels{s} }
+func (s *MemoryStore) IdempotencyKeys() IdempotencyRepository  { return memoryIdempotencyKeys{s} }
+func (s *MemoryStore) RateLimits() RateLimitRepository         { return s.data.rateLimits }
+func (s *MemoryStore) APIKeys() APIKeyRepository               { return memoryAPIKeys{s} }
+func (s *MemoryStore) Identities() IdentityRepository          { return memoryIdentities{s} }
+func (s *MemoryStore) TrackingTokens() TrackingTokenRepository { return memoryTrackingTokens{s} }
+func (s *MemoryStore) Surveys() SurveyRepository               { return memorySurveys{s} }
+
+// Transaction implements Store by restoring a snapshot of the data when fn
+// fails or panics
+func (s *MemoryStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	if !s.inTx {
+		s.data.txMu.Lock()
+		defer s.data.txMu.Unlock()
+	}
+
+	s.data.mu.Lock()
+	saved := s.data.state.clone()
+	s.data.mu.Unlock()
+
+	committed := false
+	defer func() {
+		if !committed {
+			s.data.mu.Lock()
+			s.data.state = saved
+			s.data.mu.Unlock()
+		}
+	}()
+	if err := fn(&MemoryStore{data: s.data, inTx: true}); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// lock locks the data and returns it for a single operation
+func (s *MemoryStore) lock() *memoryState {
+	s.data.mu.Lock()
+	return &s.data.state
+}
+
+func (s *MemoryStore) unlock() {
+	s.data.mu.Unlock()
+}
+
+func (st memoryState) clone() memoryState {
+	labels := make(map[uint][]uint, len(st.issueLabels))
+	for id, ids := range st.issueLabels {
+		labels[id] = slices.Clone(ids)
+	}
+	return memoryState{
+		lastID:      maps.Clone(st.lastID),
+		users:       maps.Clone(st.users),
+		officers:    maps.Clone(st.officers),
+		statuses:    maps.Clone(st.statuses),
+		labels:      maps.Clone(st.labels),
+		issues:      maps.Clone(st.issues),
+		issueLabels: labels,
+		history:     maps.Clone(st.history),
+		comments:    maps.Clone(st.comments),
+		idempotency: maps.Clone(st.idempotency),
+		apiKeys:     maps.Clone(st.apiKeys),
+		identities:  maps.Clone(st.identities),
+		tracking:    maps.Clone(st.tracking),
+		surveys:     maps.Clone(st.surveys),
+	}
+}
+
+// nextID returns the next value of a table's sequence
+func (st *memoryState) nextID(table string) uint {
+	st.lastID[table]++
+	return st.lastID[table]
+}
+
+// useID records an explicitly set primary key, like a sequence that is kept
+// ahead of inserted IDs
+func (st *memoryState) useID(table string, id uint) uint {
+	if id == 0 {
+		return st.nextID(table)
+	}
+	if id > st.lastID[table] {
+		st.lastID[table] = id
+	}
+	return id
+}
+
+// foreignKeyError mimics a database foreign key violation
+func foreignKeyError(table, column string, id uint) error {
+	return fmt.Errorf("insert or update on table %q violates foreign key constraint: %s %d does not exist", table, column, id)
+}
+
+// checkIssue validates the foreign keys of an issue row
+func (st *memoryState) checkIssue(issue *entities.Issue) error {
+	if _, ok := st.users[issue.ReporterID]; !ok {
+		return foreignKeyError("issues", "reporter_id", issue.ReporterID)
+	}
+	if _, ok := st.statuses[issue.StatusID]; !ok {
+		return foreignKeyError("issues", "status_id", issue.StatusID)
+	}
+	if issue.AssigneeID != nil {
+		if _, ok := st.officers[*issue.AssigneeID]; !ok {
+			return foreignKeyError("issues", "assignee_id", *issue.AssigneeID)
+		}
+	}
+	if issue.APIKeyID != nil {
+		if _, ok := st.apiKeys[*issue.APIKeyID]; !ok {
+			return foreignKeyError("issues", "api_key_id", *issue.APIKeyID)
+		}
+	}
+	return nil
+}
+
+// insertIssue stores an issue without its relations
+func (st *memoryState) insertIssue(issue *entities.Issue) error {
+	if err := st.checkIssue(issue); err != nil {
+		return err
+	}
+	if _, exists := st.issues[issue.IssueID]; exists && issue.IssueID != 0 {
+		return fmt.Errorf("duplicate key value violates unique constraint \"issues_pkey\"")
+	}
+	id := st.useID("issues", issue.IssueID)
+	issue.IssueID = id
+	now := time.Now()
+	if issue.Priority == "" {
+		issue.Priority = "medium"
+	}
+	if issue.Source == "" {
+		issue.Source = entities.IssueSourceUser
+	}
+	if issue.CreatedAt.IsZero() {
+		issue.CreatedAt = now
+	}
+	if issue.UpdatedAt.IsZero() {
+		issue.UpdatedAt = now
+	}
+	st.issues[id] = withoutRelations(*issue)
+	return nil
+}
+
+// withoutRelations strips the associations so only columns are stored
+func withoutRelations(issue entities.Issue) entities.Issue {
+	issue.Reporter = entities.User{}
+	issue.Assignee = nil
+	issue.Status = entities.IssueStatus{}
+	issue.StatusHistory = nil
+	issue.Comments = nil
+	issue.Labels = nil
+	return issue
+}
+
+// issueMatches reports whether an issue passes filter
+func (st *memoryState) issueMatches(issue entities.Issue, f IssueFilter) bool {
+	if issue.IssueID <= f.AfterID {
+		return false
+	}
+	if f.StatusCode != "" && st.statuses[issue.StatusID].StatusCode != f.StatusCode {
+		return false
+	}
+	if f.Priority != "" && issue.Priority != f.Priority {
+		return false
+	}
+	if f.AssigneeID != nil && (issue.AssigneeID == nil || *issue.AssigneeID != *f.AssigneeID) {
+		return false
+	}
+	if f.ReporterID != nil && issue.ReporterID != *f.ReporterID {
+		return false
+	}
+	if f.Label != "" {
+		return slices.ContainsFunc(st.issueLabels[issue.IssueID], func(id uint) bool {
+			return st.labels[id].Name == f.Label
+		})
+	}
+	return true
+}
+
+// matchingIssues returns the issues passing filter ordered by ID
+func (st *memoryState) matchingIssues(f IssueFilter) []entities.Issue {
+	var issues []entities.Issue
+	for _, id := range slices.Sorted(maps.Keys(st.issues)) {
+		if issue := st.issues[id]; st.issueMatches(issue, f) {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// issueRelations selects what loadIssue populates, like GORM preloads
+type issueRelations struct {
+	history      bool
+	comments     bool
+	commentUsers bool
+	labels       bool
+}
+
+// loadIssue populates the reporter, assignee and status of an issue and the
+// selected has-many relations
+func (st *memoryState) loadIssue(issue entities.Issue, rel issueRelations) entities.Issue {
+	issue.Reporter = st.users[issue.ReporterID]
+	if issue.AssigneeID != nil {
+		if officer, ok := st.officers[*issue.AssigneeID]; ok {
+			issue.Assignee = &officer
+		}
+	}
+	issue.Status = st.statuses[issue.StatusID]
+
+	if rel.history {
+		issue.StatusHistory = []entities.IssueStatusHistory{}
+		for _, id := range slices.Sorted(maps.Keys(st.history)) {
+			if h := st.history[id]; h.IssueID == issue.IssueID {
+				issue.StatusHistory = append(issue.StatusHistory, h)
+			}
+		}
+	}
+	if rel.comments {
+		issue.Comments = []entities.Comment{}
+		for _, id := range slices.Sorted(maps.Keys(st.comments)) {
+			if c := st.comments[id]; c.IssueID == issue.IssueID {
+				if rel.commentUsers {
+					c.User = st.users[c.UserID]
+				}
+				issue.Comments = append(issue.Comments, c)
+			}
+		}
+	}
+	if rel.labels {
+		issue.Labels = []entities.Label{}
+		for _, id := range st.issueLabels[issue.IssueID] {
+			issue.Labels = append(issue.Labels, st.labels[id])
+		}
+	}
+	return issue
+}
+
+type memoryIssues struct{ s *MemoryStore }
+
+func (r memoryIssues) List(ctx context.Context, f IssueFilter) ([]entities.Issue, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	issues := st.matchingIssues(f)
+	if f.Limit > 0 && len(issues) > f.Limit {
+		issues = issues[:f.Limit]
+	}
+	for i := range issues {
+		issues[i] = st.loadIssue(issues[i], issueRelations{comments: true, commentUsers: true, labels: true})
+	}
+	return issues, nil
+}
+
+func (r memoryIssues) ListIDs(ctx context.Context, f IssueFilter, limit int) ([]uint, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	var ids []uint
+	for _, issue := range st.matchingIssues(f) {
+		if len(ids) == limit {
+			break
+		}
+		ids = append(ids, issue.IssueID)
+	}
+	return ids, nil
+}
+
+func (r memoryIssues) Batches(ctx context.Context, f IssueFilter, size int, fn func([]entities.Issue) error) error {
+	st := r.s.lock()
+	issues := st.matchingIssues(f)
+	for i := range issues {
+		issues[i] = st.loadIssue(issues[i], issueRelations{})
+	}
+	r.s.unlock()
+
+	for batch := range slices.Chunk(issues, size) {
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r memoryIssues) Find(ctx context.Context, f IssueFilter) ([]entities.Issue, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	issues := st.matchingIssues(f)
+	if f.Limit > 0 && len(issues) > f.Limit {
+		issues = issues[:f.Limit]
+	}
+	return issues, nil
+}
+
+func (r memoryIssues) Get(ctx context.Context, id uint) (*entities.Issue, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	issue, ok := st.issues[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	issue = st.loadIssue(issue, issueRelations{history: true, comments: true, labels: true})
+	return &issue, nil
+}
+
+func (r memoryIssues) ListByIDs(ctx context.Context, ids []uint) ([]entities.Issue, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+	return rowsByIDs(st.issues, ids), nil
+}
+
+func (r memoryIssues) Create(ctx context.Context, issue *entities.Issue) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+	return st.insertIssue(issue)
+}
+
+func (r memoryIssues) CreateBatch(ctx context.Context, issues []entities.Issue) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	saved := st.clone()
+	for i := range issues {
+		if err := st.insertIssue(&issues[i]); err != nil {
+			*st = saved
+			return err
+		}
+	}
+	return nil
+}
+
+func (r memoryIssues) Update(ctx context.Context, issue *entities.Issue) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	if _, ok := st.issues[issue.IssueID]; !ok {
+		return st.insertIssue(issue)
+	}
+	if err := st.checkIssue(issue); err != nil {
+		return err
+	}
+	issue.UpdatedAt = time.Now()
+	st.issues[issue.IssueID] = withoutRelations(*issue)
+	return nil
+}
+
+func (r memoryIssues) Delete(ctx context.Context, id uint) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	delete(st.issues, id)
+	delete(st.issueLabels, id)
+	maps.DeleteFunc(st.comments, func(_ uint, c entities.Comment) bool { return c.IssueID == id })
+	maps.DeleteFunc(st.history, func(_ uint, h entities.IssueStatusHistory) bool { return h.IssueID == id })
+	maps.DeleteFunc(st.tracking, func(_ uint, t entities.TrackingToken) bool { return t.IssueID == id })
+	maps.DeleteFunc(st.surveys, func(_ uint, s entities.Survey) bool { return s.IssueID == id })
+	return nil
+}
+
+func (r memoryIssues) ApplyChange(ctx context.Context, id uint, change IssueChange) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	issue, ok := st.issues[id]
+	if !ok {
+		return ErrNotFound
+	}
+	oldStatusID := issue.StatusID
+
+	if change.SetAssignee {
+		issue.AssigneeID = change.AssigneeID
+	}
+	if change.Priority != "" {
+		issue.Priority = change.Priority
+	}
+	if change.NewStatusID != nil {
+		issue.StatusID = *change.NewStatusID
+	}
+	if err := st.checkIssue(&issue); err != nil {
+		return UpdateIssueError{err}
+	}
+
+	var labelID uint
+	if change.Label != nil {
+		if _, ok := st.labels[change.Label.LabelID]; !ok {
+			return UpdateIssueError{foreignKeyError("issue_labels", "label_id", change.Label.LabelID)}
+		}
+		labelID = change.Label.LabelID
+	}
+
+	// Every check has passed, so the writes below cannot fail halfway
+	issue.UpdatedAt = time.Now()
+	st.issues[id] = issue
+	if labelID != 0 && !slices.Contains(st.issueLabels[id], labelID) {
+		st.issueLabels[id] = append(st.issueLabels[id], labelID)
+	}
+	historyID := st.nextID("issue_status_history")
+	st.history[historyID] = entities.IssueStatusHistory{
+		HistoryID:   historyID,
+		IssueID:     id,
+		OldStatusID: &oldStatusID,
+		NewStatusID: issue.StatusID,
+		ChangedBy:   change.ChangedBy,
+		Comment:     change.Comment,
+		ChangedAt:   time.Now(),
+	}
+	if change.SurveyTTL > 0 && !st.statuses[oldStatusID].IsTerminal && st.statuses[issue.StatusID].IsTerminal {
+		surveyID := st.nextID("surveys")
+		st.surveys[surveyID] = entities.Survey{
+			SurveyID:  surveyID,
+			IssueID:   id,
+			OfficerID: issue.AssigneeID,
+			ExpiresAt: time.Now().Add(change.SurveyTTL),
+			CreatedAt: time.Now(),
+		}
+	}
+	return nil
+}
+
+func (r memoryIssues) ListActive(ctx context.Context, since, until time.Time) ([]entities.Issue, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+	return st.activeIssues(since, until), nil
+}
+
+func (r memoryIssues) ActiveHistory(ctx context.Context, since, until time.Time) ([]entities.IssueStatusHistory, error) {
+	st := r.s.lock()
+	active := map[uint]bool{}
+	for _, issue := range st.activeIssues(since, until) {
+		active[issue.IssueID] = true
+	}
+	r.s.unlock()
+
+	history, err := r.history()
+	history = slices.DeleteFunc(history, func(h entities.IssueStatusHistory) bool {
+		return !active[h.IssueID]
+	})
+	return history, err
+}
+
+func (r memoryIssues) CountByStatus(ctx context.Context, until time.Time) (map[uint]int, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	counts := map[uint]int{}
+	for _, issue := range st.issues {
+		if issue.CreatedAt.Before(until) {
+			counts[issue.StatusID]++
+		}
+	}
+	return counts, nil
+}
+
+// activeIssues implements ListActive
+func (st *memoryState) activeIssues(since, until time.Time) []entities.Issue {
+	changed := map[uint]bool{}
+	for _, h := range st.history {
+		if !h.ChangedAt.Before(since) {
+			changed[h.IssueID] = true
+		}
+	}
+
+	var issues []entities.Issue
+	for _, issue := range st.matchingIssues(IssueFilter{}) {
+		if !issue.CreatedAt.Before(until) {
+			continue
+		}
+		if !issue.CreatedAt.Before(since) || !st.statuses[issue.StatusID].IsTerminal || changed[issue.IssueID] {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// history returns every status change ordered by issue and time
+func (r memoryIssues) history() ([]entities.IssueStatusHistory, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	history := slices.Collect(maps.Values(st.history))
+	sort.Slice(history, func(i, j int) bool {
+		a, b := history[i], history[j]
+		if a.IssueID != b.IssueID {
+			return a.IssueID < b.IssueID
+		}
+		if !a.ChangedAt.Equal(b.ChangedAt) {
+			return a.ChangedAt.Before(b.ChangedAt)
+		}
+		return a.HistoryID < b.HistoryID
+	})
+	return history, nil
+}
+
+func (r memoryIssues) HistoryByIssues(ctx context.Context, issueIDs []uint) ([]entities.IssueStatusHistory, error) {
+	history, err := r.history()
+	history = slices.DeleteFunc(history, func(h entities.IssueStatusHistory) bool {
+		return !slices.Contains(issueIDs, h.IssueID)
+	})
+	return history, err
+}
+
+// rowsByIDs returns the rows with the given keys in key order
+func rowsByIDs[T any](rows map[uint]T, ids []uint) []T {
+	var found []T
+	for _, id := range slices.Compact(slices.Sorted(slices.Values(ids))) {
+		if row, ok := rows[id]; ok {
+			found = append(found, row)
+		}
+	}
+	return found
+}
+
+type memoryComments struct{ s *MemoryStore }
+
+func (r memoryComments) ListByIssue(ctx context.Context, issueID uint) ([]entities.Comment, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	comments := []entities.Comment{}
+	for _, c := range st.comments {
+		if c.IssueID == issueID {
+			c.User = st.users[c.UserID]
+			comments = append(comments, c)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.After(comments[j].CreatedAt)
+		}
+		return comments[i].CommentID > comments[j].CommentID
+	})
+	return comments, nil
+}
+
+func (r memoryComments) ListByIssues(ctx context.Context, issueIDs []uint) ([]entities.Comment, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	var comments []entities.Comment
+	for _, id := range slices.Sorted(maps.Keys(st.comments)) {
+		if c := st.comments[id]; slices.Contains(issueIDs, c.IssueID) {
+			comments = append(comments, c)
+		}
+	}
+	return comments, nil
+}
+
+func (r memoryComments) Get(ctx context.Context, id uint) (*entities.Comment, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	comment, ok := st.comments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	comment.User = st.users[comment.UserID]
+	comment.Issue = st.issues[comment.IssueID]
+	return &comment, nil
+}
+
+// checkComment validates the foreign keys of a comment row
+func (st *memoryState) checkComment(comment *entities.Comment) error {
+	if _, ok := st.issues[comment.IssueID]; !ok {
+		return foreignKeyError("comments", "issue_id", comment.IssueID)
+	}
+	if _, ok := st.users[comment.UserID]; !ok {
+		return foreignKeyError("comments", "user_id", comment.UserID)
+	}
+	return nil
+}
+
+func (r memoryComments) Create(ctx context.Context, comment *entities.Comment) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	if err := st.checkComment(comment); err != nil {
+		return err
+	}
+	id := st.useID("comments", comment.CommentID)
+	comment.CommentID = id
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now()
+	}
+	row := *comment
+	row.Issue, row.User = entities.Issue{}, entities.User{}
+	st.comments[id] = row
+	return nil
+}
+
+func (r memoryComments) Update(ctx context.Context, comment *entities.Comment) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	if err := st.checkComment(comment); err != nil {
+		return err
+	}
+	row := *comment
+	row.Issue, row.User = entities.Issue{}, entities.User{}
+	st.comments[comment.CommentID] = row
+	return nil
+}
+
+func (r memoryComments) Delete(ctx context.Context, id uint) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	delete(st.comments, id)
+	return nil
+}
+
+type memoryStatuses struct{ s *MemoryStore }
+
+func (r memoryStatuses) List(ctx context.Context, activeOnly bool) ([]entities.IssueStatus, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	var statuses []entities.IssueStatus
+	for _, status := range st.statuses {
+		if !activeOnly || status.IsActive {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].DisplayOrder != statuses[j].DisplayOrder {
+			return statuses[i].DisplayOrder < statuses[j].DisplayOrder
+		}
+		return statuses[i].StatusID < statuses[j].StatusID
+	})
+	return statuses, nil
+}
+
+func (r memoryStatuses) Get(ctx context.Context, id uint) (*entities.IssueStatus, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	status, ok := st.statuses[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &status, nil
+}
+
+func (r memoryStatuses) ListByIDs(ctx context.Context, ids []uint) ([]entities.IssueStatus, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+	return rowsByIDs(st.statuses, ids), nil
+}
+
+func (r memoryStatuses) FindByName(ctx context.Context, name string) (*entities.IssueStatus, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	for _, id := range slices.Sorted(maps.Keys(st.statuses)) {
+		status := st.statuses[id]
+		if strings.EqualFold(status.StatusCode, name) || strings.EqualFold(status.DisplayName, name) {
+			return &status, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r memoryStatuses) Create(ctx context.Context, status *entities.IssueStatus) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	for _, existing := range st.statuses {
+		if existing.StatusCode == status.StatusCode {
+			return fmt.Errorf("duplicate key value violates unique constraint \"issue_statuses_status_code_key\"")
+		}
+	}
+	id := st.useID("issue_statuses", status.StatusID)
+	status.StatusID = id
+	if status.CreatedAt.IsZero() {
+		status.CreatedAt = time.Now()
+	}
+	st.statuses[id] = *status
+	return nil
+}
+
+type memoryUsers struct{ s *MemoryStore }
+
+func (r memoryUsers) Get(ctx context.Context, id uint) (*entities.User, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	user, ok := st.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r memoryUsers) ListByIDs(ctx context.Context, ids []uint) ([]entities.User, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+	return rowsByIDs(st.users, ids), nil
+}
+
+func (r memoryUsers) FindByName(ctx context.Context, name string) (*entities.User, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	for _, id := range slices.Sorted(maps.Keys(st.users)) {
+		if user := st.users[id]; strings.EqualFold(user.FullName, name) {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r memoryUsers) Create(ctx context.Context, user *entities.User) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	id := st.useID("users", user.UserID)
+	user.UserID = id
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	st.users[id] = *user
+	return nil
+}
+
+type memoryOfficers struct{ s *MemoryStore }
+
+func (r memoryOfficers) List(ctx context.Context) ([]entities.Officer, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	officers := []entities.Officer{}
+	for _, id := range slices.Sorted(maps.Keys(st.officers)) {
+		officers = append(officers, st.officers[id])
+	}
+	return officers, nil
+}
+
+func (r memoryOfficers) Get(ctx context.Context, id uint) (*entities.Officer, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	officer, ok := st.officers[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &officer, nil
+}
+
+func (r memoryOfficers) ListByIDs(ctx context.Context, ids []uint) ([]entities.Officer, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+	return rowsByIDs(st.officers, ids), nil
+}
+
+func (r memoryOfficers) FindByName(ctx context.Context, name string) (*entities.Officer, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	for _, id := range slices.Sorted(maps.Keys(st.officers)) {
+		if officer := st.officers[id]; strings.EqualFold(officer.FullName, name) {
+			return &officer, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r memoryOfficers) Create(ctx context.Context, officer *entities.Officer) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	id := st.useID("officer", officer.OfficerID)
+	officer.OfficerID = id
+	now := time.Now()
+	if officer.CreatedAt.IsZero() {
+		officer.CreatedAt = now
+	}
+	if officer.UpdatedAt.IsZero() {
+		officer.UpdatedAt = now
+	}
+	st.officers[id] = *officer
+	return nil
+}
+
+type memoryLabels struct{ s *MemoryStore }
+
+func (r memoryLabels) FindOrCreate(ctx context.Context, label *entities.Label) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	for _, existing := range st.labels {
+		if existing.Name == label.Name {
+			*label = existing
+			return nil
+		}
+	}
+	label.LabelID = st.nextID("labels")
+	if label.Color == "" {
+		label.Color = defaultLabelColor
+	}
+	if label.CreatedAt.IsZero() {
+		label.CreatedAt = time.Now()
+	}
+	st.labels[label.LabelID] = *label
+	return nil
+}
+
+func (r memoryLabels) ListByIssues(ctx context.Context, issueIDs []uint) (map[uint][]entities.Label, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	labels := map[uint][]entities.Label{}
+	for _, issueID := range issueIDs {
+		if _, ok := labels[issueID]; ok {
+			continue
+		}
+		for _, id := range st.issueLabels[issueID] {
+			labels[issueID] = append(labels[issueID], st.labels[id])
+		}
+		slices.SortFunc(labels[issueID], func(a, b entities.Label) int { return strings.Compare(a.Name, b.Name) })
+	}
+	return labels, nil
+}
+
+type memoryIdempotencyKeys struct{ s *MemoryStore }
+
+func (r memoryIdempotencyKeys) Claim(ctx context.Context, record *entities.IdempotencyKey) (bool, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	if existing, ok := st.idempotency[record.Key]; ok {
+		if existing.ExpiresAt.After(time.Now()) {
+			return false, nil
+		}
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+	st.idempotency[record.Key] = *record
+	return true, nil
+}
+
+func (r memoryIdempotencyKeys) Get(ctx context.Context, key string) (*entities.IdempotencyKey, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	record, ok := st.idempotency[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &record, nil
+}
+
+func (r memoryIdempotencyKeys) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	if record, ok := st.idempotency[key]; ok {
+		record.StatusCode = statusCode
+		record.ContentType = contentType
+		record.ResponseBody = slices.Clone(body)
+		st.idempotency[key] = record
+	}
+	return nil
+}
+
+func (r memoryIdempotencyKeys) Release(ctx context.Context, key string) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	delete(st.idempotency, key)
+	return nil
+}
+
+func (r memoryIdempotencyKeys) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	before := len(st.idempotency)
+	maps.DeleteFunc(st.idempotency, func(_ string, record entities.IdempotencyKey) bool {
+		return !record.ExpiresAt.After(now)
+	})
+	return int64(before - len(st.idempotency)), nil
+}
+
+// MemoryRateLimits implements RateLimitRepository in memory, for rate
+// limits that each replica keeps on its own
+type MemoryRateLimits struct {
+	mu      sync.Mutex
+	buckets map[string]entities.RateLimitBucket
+}
+
+// NewMemoryRateLimits returns an empty in-memory bucket store
+func NewMemoryRateLimits() *MemoryRateLimits {
+	return &MemoryRateLimits{buckets: map[string]entities.RateLimitBucket{}}
+}
+
+func (r *MemoryRateLimits) Update(ctx context.Context, key string, fn func(bucket *entities.RateLimitBucket)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bucket, ok := r.buckets[key]
+	if !ok {
+		bucket = entities.RateLimitBucket{Key: key}
+	}
+	fn(&bucket)
+	r.buckets[key] = bucket
+	return nil
+}
+
+func (r *MemoryRateLimits) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := len(r.buckets)
+	maps.DeleteFunc(r.buckets, func(_ string, bucket entities.RateLimitBucket) bool {
+		return bucket.UpdatedAt.Before(before)
+	})
+	return int64(count - len(r.buckets)), nil
+}
+
+type memoryAPIKeys struct{ s *MemoryStore }
+
+func (r memoryAPIKeys) List(ctx context.Context) ([]entities.APIKey, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	keys := make([]entities.APIKey, 0, len(st.apiKeys))
+	for _, id := range slices.Sorted(maps.Keys(st.apiKeys)) {
+		keys = append(keys, cloneAPIKey(st.apiKeys[id]))
+	}
+	return keys, nil
+}
+
+func (r memoryAPIKeys) Get(ctx context.Context, id uint) (*entities.APIKey, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	key, ok := st.apiKeys[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	key = cloneAPIKey(key)
+	return &key, nil
+}
+
+func (r memoryAPIKeys) FindByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	for _, key := range st.apiKeys {
+		if key.Prefix == prefix {
+			key = cloneAPIKey(key)
+			return &key, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r memoryAPIKeys) Create(ctx context.Context, key *entities.APIKey) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	for _, existing := range st.apiKeys {
+		if existing.Prefix == key.Prefix {
+			return fmt.Errorf("duplicate key value violates unique constraint \"idx_api_keys_prefix\"")
+		}
+	}
+	key.APIKeyID = st.useID("api_keys", key.APIKeyID)
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+	st.apiKeys[key.APIKeyID] = cloneAPIKey(*key)
+	return nil
+}
+
+func (r memoryAPIKeys) Revoke(ctx context.Context, id uint, at time.Time) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	if key, ok := st.apiKeys[id]; ok && key.RevokedAt == nil {
+		key.RevokedAt = &at
+		st.apiKeys[id] = key
+	}
+	return nil
+}
+
+func (r memoryAPIKeys) Touch(ctx context.Context, id uint, at time.Time) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	if key, ok := st.apiKeys[id]; ok {
+		key.LastUsedAt = &at
+		st.apiKeys[id] = key
+	}
+	return nil
+}
+
+// cloneAPIKey copies the scopes so callers cannot change the stored key
+func cloneAPIKey(key entities.APIKey) entities.APIKey {
+	key.Scopes = slices.Clone(key.Scopes)
+	return key
+}
+
+type memoryIdentities struct{ s *MemoryStore }
+
+func (r memoryIdentities) FindBySubject(ctx context.Context, issuer, subject string) (*entities.Identity, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	for _, identity := range st.identities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r memoryIdentities) Create(ctx context.Context, identity *entities.Identity) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	if err := st.checkIdentity(identity); err != nil {
+		return err
+	}
+	for _, existing := range st.identities {
+		if existing.Issuer == identity.Issuer && existing.Subject == identity.Subject {
+			return fmt.Errorf("duplicate key value violates unique constraint \"idx_identities_subject\"")
+		}
+	}
+	identity.IdentityID = st.useID("identities", identity.IdentityID)
+	now := time.Now()
+	if identity.CreatedAt.IsZero() {
+		identity.CreatedAt = now
+	}
+	identity.UpdatedAt = now
+	st.identities[identity.IdentityID] = *identity
+	return nil
+}
+
+func (r memoryIdentities) Update(ctx context.Context, identity *entities.Identity) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	if _, ok := st.identities[identity.IdentityID]; !ok {
+		return ErrNotFound
+	}
+	if err := st.checkIdentity(identity); err != nil {
+		return err
+	}
+	identity.UpdatedAt = time.Now()
+	st.identities[identity.IdentityID] = *identity
+	return nil
+}
+
+// checkIdentity validates the foreign keys of an identity row
+func (st *memoryState) checkIdentity(identity *entities.Identity) error {
+	if _, ok := st.users[identity.UserID]; !ok {
+		return foreignKeyError("identities", "user_id", identity.UserID)
+	}
+	if identity.OfficerID != nil {
+		if _, ok := st.officers[*identity.OfficerID]; !ok {
+			return foreignKeyError("identities", "officer_id", *identity.OfficerID)
+		}
+	}
+	return nil
+}
+
+type memoryTrackingTokens struct{ s *MemoryStore }
+
+func (r memoryTrackingTokens) FindByHash(ctx context.Context, hash string) (*entities.TrackingToken, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	for _, token := range st.tracking {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r memoryTrackingTokens) Create(ctx context.Context, token *entities.TrackingToken) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	if _, ok := st.issues[token.IssueID]; !ok {
+		return foreignKeyError("tracking_tokens", "issue_id", token.IssueID)
+	}
+	for _, existing := range st.tracking {
+		switch {
+		case existing.IssueID == token.IssueID:
+			return fmt.Errorf("duplicate key value violates unique constraint \"idx_tracking_tokens_issue_id\"")
+		case existing.TokenHash == token.TokenHash:
+			return fmt.Errorf("duplicate key value violates unique constraint \"idx_tracking_tokens_token_hash\"")
+		}
+	}
+	token.TrackingTokenID = st.useID("tracking_tokens", token.TrackingTokenID)
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	st.tracking[token.TrackingTokenID] = *token
+	return nil
+}
+
+type memorySurveys struct{ s *MemoryStore }
+
+func (r memorySurveys) Get(ctx context.Context, id uint) (*entities.Survey, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	survey, ok := st.surveys[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &survey, nil
+}
+
+func (r memorySurveys) ListSent(ctx context.Context, from, to time.Time) ([]entities.Survey, error) {
+	return r.list(func(s entities.Survey) bool {
+		return !s.CreatedAt.Before(from) && s.CreatedAt.Before(to)
+	}), nil
+}
+
+func (r memorySurveys) ListPending(ctx context.Context, issueID uint, now time.Time) ([]entities.Survey, error) {
+	return r.list(func(s entities.Survey) bool {
+		return (issueID == 0 || s.IssueID == issueID) && s.Pending(now)
+	}), nil
+}
+
+// list returns the surveys matching keep ordered by ID
+func (r memorySurveys) list(keep func(entities.Survey) bool) []entities.Survey {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	surveys := []entities.Survey{}
+	for _, id := range slices.Sorted(maps.Keys(st.surveys)) {
+		if survey := st.surveys[id]; keep(survey) {
+			surveys = append(surveys, survey)
+		}
+	}
+	return surveys
+}
+
+func (r memorySurveys) Create(ctx context.Context, survey *entities.Survey) error {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	if _, ok := st.issues[survey.IssueID]; !ok {
+		return foreignKeyError("surveys", "issue_id", survey.IssueID)
+	}
+	if survey.OfficerID != nil {
+		if _, ok := st.officers[*survey.OfficerID]; !ok {
+			return foreignKeyError("surveys", "officer_id", *survey.OfficerID)
+		}
+	}
+	survey.SurveyID = st.useID("surveys", survey.SurveyID)
+	if survey.CreatedAt.IsZero() {
+		survey.CreatedAt = time.Now()
+	}
+	st.surveys[survey.SurveyID] = *survey
+	return nil
+}
+
+func (r memorySurveys) Respond(ctx context.Context, id uint, rating int, feedback string, at time.Time) (bool, error) {
+	st := r.s.lock()
+	defer r.s.unlock()
+
+	survey, ok := st.surveys[id]
+	if !ok || survey.RespondedAt != nil {
+		return false, nil
+	}
+	survey.Rating = &rating
+	survey.Feedback = feedback
+	survey.RespondedAt = &at
+	st.surveys[id] = survey
+	return true, nil
+}
@@ -0,0 +1,243 @@
+// Package repositories defines the storage interfaces used by the HTTP
+// handlers together with a GORM implementation for production and an
+// in-memory implementation for tests.
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"issue-tracking/entities"
+)
+
+// ErrNotFound is returned when the requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// UpdateIssueError and RecordHistoryError tag failures inside ApplyChange so
+// callers can report which step went wrong
+type UpdateIssueError struct{ Err error }
+type RecordHistoryError struct{ Err error }
+
+func (e UpdateIssueError) Error() string   { return e.Err.Error() }
+func (e UpdateIssueError) Unwrap() error   { return e.Err }
+func (e RecordHistoryError) Error() string { return e.Err.Error() }
+func (e RecordHistoryError) Unwrap() error { return e.Err }
+
+// Store groups the repositories of one database
+type Store interface {
+	Issues() IssueRepository
+	Comments() CommentRepository
+	Statuses() StatusRepository
+	Users() UserRepository
+	Officers() OfficerRepository
+	Labels() LabelRepository
+	IdempotencyKeys() IdempotencyRepository
+	RateLimits() RateLimitRepository
+	APIKeys() APIKeyRepository
+	Identities() IdentityRepository
+	TrackingTokens() TrackingTokenRepository
+	Surveys() SurveyRepository
+
+	// Transaction runs fn with repositories bound to one transaction and
+	// rolls everything back when fn returns an error. Nested calls roll
+	// back only their own changes.
+	Transaction(ctx context.Context, fn func(tx Store) error) error
+}
+
+// IssueFilter narrows an issue list. Zero values do not filter.
+type IssueFilter struct {
+	StatusCode string
+	Priority   string
+	AssigneeID *uint
+	ReporterID *uint
+	Label      string
+
+	// AfterID and Limit page through List: only issues with a greater ID
+	// are matched, and List returns at most Limit of them
+	AfterID uint
+	Limit   int
+}
+
+// IssueChange describes a single update to an issue. NewStatusID is nil when
+// the status is left unchanged, the assignee is only written when
+// SetAssignee is true and Label is attached when set. With SurveyTTL set, a
+// change moving the issue from an open status to a terminal one also
+// creates a satisfaction survey, open for SurveyTTL and credited to the
+// assignee after the change.
+type IssueChange struct {
+	NewStatusID *uint
+	SetAssignee bool
+	AssigneeID  *uint
+	Priority    string
+	Label       *entities.Label
+	Comment     string
+	ChangedBy   uint
+	SurveyTTL   time.Duration
+}
+
+// IssueRepository stores issues and their status history
+type IssueRepository interface {
+	// List returns the matching issues with reporter, assignee, status,
+	// comments (with their users) and labels
+	List(ctx context.Context, filter IssueFilter) ([]entities.Issue, error)
+	// ListIDs returns up to limit matching issue IDs in ascending order
+	ListIDs(ctx context.Context, filter IssueFilter, limit int) ([]uint, error)
+	// Batches calls fn with the matching issues, size at a time, with
+	// reporter, assignee and status
+	Batches(ctx context.Context, filter IssueFilter, size int, fn func([]entities.Issue) error) error
+	// Find returns the matching issues without relations, ordered by ID
+	Find(ctx context.Context, filter IssueFilter) ([]entities.Issue, error)
+	// Get returns one issue with every relation
+	Get(ctx context.Context, id uint) (*entities.Issue, error)
+	// ListByIDs returns the issues with the given IDs without relations;
+	// missing IDs are skipped
+	ListByIDs(ctx context.Context, ids []uint) ([]entities.Issue, error)
+	Create(ctx context.Context, issue *entities.Issue) error
+	// CreateBatch inserts issues atomically
+	CreateBatch(ctx context.Context, issues []entities.Issue) error
+	Update(ctx context.Context, issue *entities.Issue) error
+	Delete(ctx context.Context, id uint) error
+	// ApplyChange locks the issue, applies the change and records exactly
+	// one status history entry and any survey, atomically
+	ApplyChange(ctx context.Context, id uint, change IssueChange) error
+	// ListActive returns the issues created before until whose status may
+	// have changed at or after since: those created since then, in a
+	// non-terminal status or with a status change since then. They have no
+	// relations and are ordered by ID.
+	ListActive(ctx context.Context, since, until time.Time) ([]entities.Issue, error)
+	// ActiveHistory returns every status change of the issues ListActive
+	// returns, ordered by issue and time
+	ActiveHistory(ctx context.Context, since, until time.Time) ([]entities.IssueStatusHistory, error)
+	// CountByStatus counts the issues created before until per status
+	CountByStatus(ctx context.Context, until time.Time) (map[uint]int, error)
+	// HistoryByIssues returns the status changes of the given issues ordered
+	// by issue and time
+	HistoryByIssues(ctx context.Context, issueIDs []uint) ([]entities.IssueStatusHistory, error)
+}
+
+// CommentRepository stores issue comments
+type CommentRepository interface {
+	// ListByIssue returns the comments of an issue with their users, newest first
+	ListByIssue(ctx context.Context, issueID uint) ([]entities.Comment, error)
+	// ListByIssues returns the comments of the given issues without
+	// relations, ordered by ID
+	ListByIssues(ctx context.Context, issueIDs []uint) ([]entities.Comment, error)
+	// Get returns one comment with its user and issue
+	Get(ctx context.Context, id uint) (*entities.Comment, error)
+	Create(ctx context.Context, comment *entities.Comment) error
+	Update(ctx context.Context, comment *entities.Comment) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// StatusRepository stores issue statuses
+type StatusRepository interface {
+	// List returns statuses ordered by display order, only active ones when
+	// activeOnly is set
+	List(ctx context.Context, activeOnly bool) ([]entities.IssueStatus, error)
+	Get(ctx context.Context, id uint) (*entities.IssueStatus, error)
+	// ListByIDs returns the statuses with the given IDs; missing IDs are skipped
+	ListByIDs(ctx context.Context, ids []uint) ([]entities.IssueStatus, error)
+	// FindByName matches the status code or display name, ignoring case
+	FindByName(ctx context.Context, name string) (*entities.IssueStatus, error)
+	Create(ctx context.Context, status *entities.IssueStatus) error
+}
+
+// UserRepository stores users who report issues
+type UserRepository interface {
+	Get(ctx context.Context, id uint) (*entities.User, error)
+	// ListByIDs returns the users with the given IDs; missing IDs are skipped
+	ListByIDs(ctx context.Context, ids []uint) ([]entities.User, error)
+	// FindByName matches the full name, ignoring case
+	FindByName(ctx context.Context, name string) (*entities.User, error)
+	Create(ctx context.Context, user *entities.User) error
+}
+
+// OfficerRepository stores officers who handle issues
+type OfficerRepository interface {
+	List(ctx context.Context) ([]entities.Officer, error)
+	Get(ctx context.Context, id uint) (*entities.Officer, error)
+	// ListByIDs returns the officers with the given IDs; missing IDs are skipped
+	ListByIDs(ctx context.Context, ids []uint) ([]entities.Officer, error)
+	// FindByName matches the full name, ignoring case
+	FindByName(ctx context.Context, name string) (*entities.Officer, error)
+	Create(ctx context.Context, officer *entities.Officer) error
+}
+
+// LabelRepository stores issue labels
+type LabelRepository interface {
+	// FindOrCreate loads the label with label.Name, creating it if missing
+	FindOrCreate(ctx context.Context, label *entities.Label) error
+	// ListByIssues returns the labels of the given issues by name, keyed by
+	// issue ID
+	ListByIssues(ctx context.Context, issueIDs []uint) (map[uint][]entities.Label, error)
+}
+
+// IdempotencyRepository stores responses to requests made with an
+// Idempotency-Key header
+type IdempotencyRepository interface {
+	// Claim drops an expired record for the key and inserts record. It
+	// returns false when another request already holds the key.
+	Claim(ctx context.Context, record *entities.IdempotencyKey) (bool, error)
+	Get(ctx context.Context, key string) (*entities.IdempotencyKey, error)
+	// Complete stores the response for a claimed key
+	Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+	// Release deletes a claimed key so the request can be retried
+	Release(ctx context.Context, key string) error
+	// DeleteExpired deletes records that expired before now
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// RateLimitRepository stores the token buckets of the rate limiter
+type RateLimitRepository interface {
+	// Update locks the bucket of key, lets fn change it and saves it, so
+	// concurrent updates of one bucket do not overwrite each other. A new
+	// bucket is passed with a zero UpdatedAt.
+	Update(ctx context.Context, key string, fn func(bucket *entities.RateLimitBucket)) error
+	// DeleteIdle deletes buckets last updated before before
+	DeleteIdle(ctx context.Context, before time.Time) (int64, error)
+}
+
+// APIKeyRepository stores the API keys of integrations
+type APIKeyRepository interface {
+	// List returns every key, revoked and expired ones included, by ID
+	List(ctx context.Context) ([]entities.APIKey, error)
+	Get(ctx context.Context, id uint) (*entities.APIKey, error)
+	// FindByPrefix returns the key with the identifying prefix
+	FindByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error)
+	Create(ctx context.Context, key *entities.APIKey) error
+	// Revoke sets the revocation time of a key that is not revoked yet
+	Revoke(ctx context.Context, id uint, at time.Time) error
+	// Touch records when a key was last used
+	Touch(ctx context.Context, id uint, at time.Time) error
+}
+
+// IdentityRepository stores the accounts signed in through OpenID Connect
+type IdentityRepository interface {
+	// FindBySubject returns the identity of an account at an issuer
+	FindBySubject(ctx context.Context, issuer, subject string) (*entities.Identity, error)
+	Create(ctx context.Context, identity *entities.Identity) error
+	Update(ctx context.Context, identity *entities.Identity) error
+}
+
+// TrackingTokenRepository stores the tokens people who reported on the
+// public portal follow their issues with
+type TrackingTokenRepository interface {
+	// FindByHash returns the token with the hash
+	FindByHash(ctx context.Context, hash string) (*entities.TrackingToken, error)
+	Create(ctx context.Context, token *entities.TrackingToken) error
+}
+
+// SurveyRepository stores the satisfaction surveys sent when issues close
+type SurveyRepository interface {
+	Get(ctx context.Context, id uint) (*entities.Survey, error)
+	// ListSent returns the surveys created in [from, to) ordered by ID
+	ListSent(ctx context.Context, from, to time.Time) ([]entities.Survey, error)
+	// ListPending returns the surveys of issueID, or of every issue when it
+	// is 0, that are not answered and expire after now, ordered by ID
+	ListPending(ctx context.Context, issueID uint, now time.Time) ([]entities.Survey, error)
+	Create(ctx context.Context, survey *entities.Survey) error
+	// Respond stores the rating and feedback of a survey that is not
+	// answered yet. It returns false when the survey was already answered.
+	Respond(ctx context.Context, id uint, rating int, feedback string, at time.Time) (bool, error)
+}
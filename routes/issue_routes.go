@@ -1,34 +1,245 @@
 package routes
 
 import (
+	"net/http"
+	"strings"
+	"time"
+
+	"issue-tracking/auth"
+	"issue-tracking/captcha"
+	"issue-tracking/config"
 	"issue-tracking/controllers"
+	"issue-tracking/graph"
+	"issue-tracking/metrics"
+	"issue-tracking/middlewares"
+	"issue-tracking/openapi"
+	"issue-tracking/repositories"
+	"issue-tracking/services"
+	"issue-tracking/tracing"
 	"issue-tracking/utils"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-// RegisterRoutes registers all API routes
-func RegisterRoutes(router *gin.Engine, db *gorm.DB) {
+const (
+	// oidcTimeout bounds each request to the identity provider
+	oidcTimeout = 10 * time.Second
+	// captchaTimeout bounds each request to the captcha provider
+	captchaTimeout = 5 * time.Second
+)
+
+// NewRateLimiter returns the rate limiter configured in cfg, nil when rate
+// limiting is disabled. Limiters keeping their buckets in memory count
+// requests on their own; those in the database share them.
+func NewRateLimiter(store repositories.Store, cfg *config.Config) *middlewares.RateLimiter {
+	if !cfg.RateLimit.Enabled {
+		return nil
+	}
+	var buckets repositories.RateLimitRepository = repositories.NewMemoryRateLimits()
+	if cfg.RateLimit.Store == "database" {
+		buckets = store.RateLimits()
+	}
+	return middlewares.NewRateLimiter(buckets,
+		middlewares.Budget{PerMinute: cfg.RateLimit.ReadPerMinute, Burst: cfg.RateLimit.ReadBurst},
+		middlewares.Budget{PerMinute: cfg.RateLimit.WritePerMinute, Burst: cfg.RateLimit.WriteBurst},
+	)
+}
+
+// RegisterRoutes registers all API routes. Changes to issues go through
+// issues, which may be shared with the gRPC server; with CSAT enabled in cfg
+// it is made to send surveys when issues close. Optional endpoints are
+// only registered when their feature is enabled in cfg. Every route on the
+// router, including those registered before, must be described in operations.
+// It returns the OpenAPI document the requests are validated against.
+func RegisterRoutes(router *gin.Engine, store repositories.Store, issues *services.IssueService, cfg *config.Config) *openapi.Spec {
+	// Record request metrics for every route registered below; it runs
+	// outside the recovery middleware so panics are counted as 500s
+	router.Use(metrics.Middleware())
+	if cfg.Features.Metrics {
+		router.GET("/metrics", metrics.Handler())
+	}
+
 	// Add recovery middleware
 	router.Use(utils.RecoverPanic())
 
+	// The probes and metrics registered so far take neither API keys nor
+	// rate limits
+	exempt := map[string]bool{}
+	for _, route := range router.Routes() {
+		exempt[route.Method+" "+route.Path] = true
+	}
+
+	// Callers without credentials get the anonymous scopes; keys and ID
+	// tokens authenticated below add their own
+	router.Use(middlewares.AnonymousScopes(cfg.AnonymousScopes()))
+
+	// Authenticate API keys before rate limiting so each key has its own
+	// budget
+	apiKeys := services.NewAPIKeyService(store)
+	router.Use(middlewares.APIKeyAuth(apiKeys))
+
+	// People signed in at the identity provider send their ID token instead
+	var identities *services.IdentityService
+	if cfg.OIDC.Enabled {
+		httpClient := tracing.NewHTTPClient()
+		httpClient.Timeout = oidcTimeout
+		provider := auth.NewOIDCProvider(auth.OIDCConfig{
+			IssuerURL:     cfg.OIDC.IssuerURL,
+			ClientID:      cfg.OIDC.ClientID,
+			ClientSecret:  cfg.OIDC.ClientSecret,
+			RedirectURL:   cfg.OIDC.RedirectURL,
+			Scopes:        cfg.OIDC.Scopes,
+			GroupsClaim:   cfg.OIDC.GroupsClaim,
+			AdminGroups:   cfg.OIDC.AdminGroups,
+			OfficerGroups: cfg.OIDC.OfficerGroups,
+		}, httpClient)
+		identities = services.NewIdentityService(store, provider)
+		router.Use(middlewares.TokenAuth(identities))
+	}
+
+	// Throttle each client on the routes registered below
+	if limiter := NewRateLimiter(store, cfg); limiter != nil {
+		router.Use(middlewares.RateLimit(limiter))
+	}
+
+	// Reject requests that do not match the OpenAPI document before they
+	// reach the handlers; the document is built once all routes exist
+	spec := openapi.New("Issue Tracking API", "1.0")
+	router.Use(spec.ValidateRequests())
+	router.GET("/openapi.json", spec.Handler)
+	if cfg.Features.Docs {
+		router.GET("/docs/*filepath", openapi.SwaggerUI("/openapi.json"))
+	}
+
 	// Initialize controllers
-	issueController := controllers.NewIssueController(db)
-	commentController := controllers.NewCommentController(db)
+	issueController := controllers.NewIssueController(store, issues)
+	commentController := controllers.NewCommentController(store, issues)
 
-	// Issues routes
-	issues := router.Group("/api/issues")
+	// Retried POSTs carrying the same Idempotency-Key get the original response
+	idempotency := middlewares.Idempotency(store.IdempotencyKeys(), cfg.Idempotency.Window.Duration)
+
+	// Issues routes; the issue service checks the scopes of API keys, the
+	// routes that bypass it check them here
+	issueRoutes := router.Group("/api/issues")
 	{
-		issues.POST("", issueController.CreateIssue)
-		issues.GET("", issueController.GetAllIssues)
-		issues.GET("/:id", issueController.GetIssue)
-		issues.PATCH("/:id/status", issueController.UpdateIssueStatus)
-		issues.POST("/:id/comment", commentController.CreateComment)
+		issueRoutes.POST("", idempotency, issueController.CreateIssue)
+		issueRoutes.GET("", issueController.GetAllIssues)
+		issueRoutes.GET("/:id", issueController.GetIssue)
+		issueRoutes.PATCH("/:id/status", issueController.UpdateIssueStatus)
+		issueRoutes.POST("/:id/comment", idempotency, commentController.CreateComment)
+
+		if cfg.Features.Bulk {
+			issueRoutes.POST("/bulk", middlewares.RequireScope(auth.ScopeIssuesWrite), controllers.NewBulkController(store, issues).BulkUpdateIssues)
+		}
+		if cfg.Features.Import {
+			issueRoutes.POST("/import", middlewares.RequireScope(auth.ScopeIssuesWrite), controllers.NewImportController(issues).ImportIssues)
+		}
+		if cfg.Features.Export {
+			issueRoutes.GET("/export", middlewares.RequireScope(auth.ScopeIssuesRead), controllers.NewExportController(store).ExportIssues)
+		}
+	}
+
+	if cfg.Features.Reports {
+		reportController := controllers.NewReportController(store)
+		reports := router.Group("/api/reports", middlewares.RequireScope(auth.ScopeReportsRead))
+		{
+			reports.GET("/throughput", reportController.GetThroughput)
+			reports.GET("/backlog", reportController.GetBacklog)
+			reports.GET("/time-in-status", reportController.GetTimeInStatus)
+			reports.GET("/time-to-close", reportController.GetTimeToClose)
+			reports.GET("/breakdown", reportController.GetBreakdown)
+			reports.GET("/cumulative-flow", reportController.GetCumulativeFlow)
+			reports.GET("/lead-time", reportController.GetLeadTime)
+			reports.GET("/cycle-time", reportController.GetCycleTime)
+			reports.GET("/aging", reportController.GetAging)
+			if cfg.CSAT.Enabled {
+				reports.GET("/csat", reportController.GetCSAT)
+			}
+		}
+	}
+
+	// Satisfaction surveys, answered through signed links without an account
+	var surveys *services.SurveyService
+	if cfg.CSAT.Enabled {
+		issues.SendSurveys(cfg.CSAT.LinkTTL.Duration)
+		surveys = services.NewSurveyService(store, cfg.CSAT.Secret, cfg.CSAT.SurveyURL)
+		surveyController := controllers.NewSurveyController(surveys)
+		router.GET("/api/surveys/:id", surveyController.GetSurvey)
+		router.POST("/api/surveys/:id", surveyController.AnswerSurvey)
 	}
 
+	router.GET("/api/statuses", controllers.NewStatusController(store).GetAllStatuses)
+
 	officer := router.Group("/api/officers")
 	{
-		officer.GET("", controllers.NewOfficerController(db).GetAllOfficers)
+		officer.GET("", controllers.NewOfficerController(store).GetAllOfficers)
+	}
+
+	// Public portal for people without an account; their tracking token
+	// is checked by the portal service
+	if cfg.Portal.Enabled {
+		httpClient := tracing.NewHTTPClient()
+		httpClient.Timeout = captchaTimeout
+		verifier, err := captcha.New(cfg.Portal.Captcha.Provider, cfg.Portal.Captcha.Secret, cfg.Portal.Captcha.VerifyURL, httpClient)
+		if err != nil {
+			panic(err)
+		}
+		portal := services.NewPortalService(store, issues, services.PortalOptions{
+			Status:  cfg.Portal.Status,
+			Captcha: verifier,
+			Spam:    services.SpamRules{MaxLinks: cfg.Portal.MaxLinks, BlockedTerms: cfg.Portal.BlockedTerms},
+			Surveys: surveys,
+		})
+		portalController := controllers.NewPortalController(portal)
+		public := router.Group("/api/public")
+		{
+			// Not idempotent: a stored response would keep the tracking
+			// token in plain text
+			public.POST("/issues", portalController.Report)
+			public.GET("/tracking", portalController.Track)
+			public.POST("/tracking/comments", portalController.Comment)
+		}
+	}
+
+	if cfg.Features.GraphQL {
+		limits := graph.Limits{MaxDepth: cfg.GraphQL.MaxDepth, MaxComplexity: cfg.GraphQL.MaxComplexity}
+		server, err := graph.NewServer(store, issues, limits)
+		if err != nil {
+			panic(err)
+		}
+		// Mutations check their scopes in the issue service
+		router.POST("/graphql", middlewares.RequireScope(auth.ScopeIssuesRead), server.Handle)
+		router.GET("/graphql", middlewares.RequireScope(auth.ScopeIssuesRead), server.Handle)
+	}
+
+	// Sign-in at the identity provider, for browsers
+	if identities != nil {
+		secure := strings.HasPrefix(cfg.OIDC.RedirectURL, "https://")
+		authController := controllers.NewAuthController(identities, cfg.OIDC.CookieSecret, secure)
+		router.GET("/auth/login", authController.Login)
+		router.GET("/auth/callback", authController.Callback)
+		router.GET("/api/me", authController.Me)
+	}
+
+	// Managing API keys takes a key with the admin scope or an admin's ID
+	// token; the first key is created with the create-api-key command
+	apiKeyController := controllers.NewAPIKeyController(apiKeys)
+	admin := router.Group("/api/admin", middlewares.RequireCaller(auth.ScopeAdmin))
+	{
+		admin.GET("/api-keys", apiKeyController.GetAllAPIKeys)
+		admin.POST("/api-keys", apiKeyController.CreateAPIKey)
+		admin.DELETE("/api-keys/:id", apiKeyController.RevokeAPIKey)
+		if surveys != nil {
+			admin.GET("/surveys", controllers.NewSurveyController(surveys).GetPendingSurveys)
+		}
+	}
+
+	documented := withError(operations, exempt, http.StatusUnauthorized)
+	if cfg.RateLimit.Enabled {
+		documented = withError(documented, exempt, http.StatusTooManyRequests)
+	}
+	if err := spec.Build(router.Routes(), documented); err != nil {
+		panic(err)
 	}
+	return spec
 }
@@ -0,0 +1,289 @@
+package routes
+
+import (
+	"issue-tracking/analytics"
+	"issue-tracking/controllers"
+	"issue-tracking/entities"
+	"issue-tracking/graph"
+	"issue-tracking/importer"
+	"issue-tracking/openapi"
+	"issue-tracking/services"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Parameters shared by several operations
+var (
+	issueFilterParams = []openapi.Param{
+		{Name: "status", Description: "Status code", Schema: openapi3.NewStringSchema()},
+		{Name: "priority", Schema: openapi3.NewStringSchema().WithEnum("low", "medium", "high", "critical")},
+		{Name: "assignee_id", Schema: openapi3.NewIntegerSchema().WithMin(0)},
+		{Name: "reporter_id", Schema: openapi3.NewIntegerSchema().WithMin(0)},
+		{Name: "label", Description: "Label name", Schema: openapi3.NewStringSchema()},
+	}
+	reportRangeParams = []openapi.Param{
+		{Name: "from", Description: "Start date (2006-01-02) or RFC 3339 timestamp, 30 days before to by default", Schema: openapi3.NewStringSchema()},
+		{Name: "to", Description: "Inclusive end date (2006-01-02) or RFC 3339 timestamp, now by default", Schema: openapi3.NewStringSchema()},
+		{Name: "bucket", Schema: openapi3.NewStringSchema().WithEnum(analytics.BucketDay, analytics.BucketWeek, analytics.BucketMonth)},
+		{Name: "tz", Description: "IANA time zone of dates and buckets, UTC by default", Schema: openapi3.NewStringSchema()},
+	}
+	idempotencyKeyParam = openapi.Param{
+		Name:        "Idempotency-Key",
+		In:          openapi3.ParameterInHeader,
+		Description: "Retries with the same key get the original response",
+		Schema:      openapi3.NewStringSchema().WithMaxLength(255),
+	}
+	trackingTokenParam = openapi.Param{
+		Name:        controllers.TrackingTokenHeader,
+		In:          openapi3.ParameterInHeader,
+		Description: "Tracking token returned when the issue was reported",
+		Schema:      openapi3.NewStringSchema(),
+		Required:    true,
+	}
+	surveySignatureParam = openapi.Param{
+		Name:        "signature",
+		Description: "Signature from the survey link",
+		Schema:      openapi3.NewStringSchema(),
+		Required:    true,
+	}
+)
+
+// operations describes every route registered by main and RegisterRoutes,
+// keyed by method and gin path
+var operations = map[string]openapi.Operation{
+	"GET /livez": {
+		Summary: "Liveness probe", Tag: "health",
+		Response: controllers.HealthResponse{}, Unwrapped: true,
+	},
+	"GET /readyz": {
+		Summary: "Readiness probe", Description: "Checks the database and pending migrations.", Tag: "health",
+		Response: controllers.HealthResponse{}, Unwrapped: true, Errors: []int{503},
+	},
+	"GET /health": {
+		Summary: "Readiness probe", Description: "Alias of /readyz.", Tag: "health",
+		Response: controllers.HealthResponse{}, Unwrapped: true, Errors: []int{503},
+	},
+	"GET /metrics": {
+		Summary: "Prometheus metrics", Tag: "health",
+		Produces: []string{"text/plain"},
+	},
+	"GET /openapi.json": {
+		Summary: "This document", Tag: "docs",
+		Produces: []string{"application/json"},
+	},
+	"GET /docs/*filepath": {Hidden: true},
+
+	"POST /api/issues": {
+		Summary: "Create an issue", Tag: "issues",
+		Params: []openapi.Param{idempotencyKeyParam},
+		Body:   entities.Issue{}, Status: 201, Response: entities.Issue{},
+		Errors: []int{400, 403, 409, 422},
+	},
+	"GET /api/issues": {
+		Summary: "List issues", Tag: "issues",
+		Description: "Issues are ordered by ID. With limit they are returned a page at a time; " +
+			"pass the last ID of a page as after_id to get the next one.",
+		Params: append([]openapi.Param{
+			{Name: "limit", Description: "Page size", Schema: openapi3.NewIntegerSchema().WithMin(1).WithMax(controllers.MaxIssuePageSize)},
+			{Name: "after_id", Description: "Return issues with a greater ID", Schema: openapi3.NewIntegerSchema().WithMin(0)},
+		}, issueFilterParams...),
+		Response: []entities.Issue{}, Errors: []int{400, 403},
+	},
+	"GET /api/issues/:id": {
+		Summary: "Get an issue", Tag: "issues",
+		Response: entities.Issue{}, Errors: []int{400, 403, 404},
+	},
+	"PATCH /api/issues/:id/status": {
+		Summary: "Change the status of an issue", Description: "Records the change in the status history.", Tag: "issues",
+		Body: controllers.StatusUpdateRequest{}, Response: entities.Issue{},
+		Errors: []int{400, 403, 404},
+	},
+	"POST /api/issues/:id/comment": {
+		Summary: "Comment on an issue", Tag: "issues",
+		Params: []openapi.Param{idempotencyKeyParam},
+		Body:   controllers.CommentRequest{}, Status: 201, Response: entities.Comment{},
+		Errors: []int{400, 403, 404, 409, 422},
+	},
+	"POST /api/issues/bulk": {
+		Summary: "Apply one operation to many issues", Tag: "issues",
+		Description: "Selects issues by issue_ids or by filter, a query string as accepted by GET /api/issues. " +
+			"Operations: change_status, assign, set_priority, add_label, close. Modes: transactional (default) or best_effort.",
+		Body: controllers.BulkRequest{}, Response: controllers.BulkResult{},
+		Errors: []int{400, 403, 422},
+	},
+	"POST /api/issues/import": {
+		Summary: "Import issues from CSV or JSON lines", Tag: "issues",
+		Description: "The file is sent in the file field of a multipart form or as the request body. " +
+			"Responds 201 when issues were imported and 200 for a dry run.",
+		Params: []openapi.Param{
+			{Name: "format", Schema: openapi3.NewStringSchema().WithEnum(importer.FormatCSV, importer.FormatJSONL)},
+			{Name: "mapping", Description: "Column mapping as source=field,source=field", Schema: openapi3.NewStringSchema()},
+			{Name: "batch_size", Schema: openapi3.NewIntegerSchema().WithMin(1)},
+			{Name: "dry_run", Schema: openapi3.NewBoolSchema()},
+			{Name: "create_missing_users", Schema: openapi3.NewBoolSchema()},
+		},
+		Upload: "file", Status: 201, Response: importer.Result{},
+		Errors: []int{400, 403},
+	},
+	"GET /api/issues/export": {
+		Summary: "Export the filtered issue list", Tag: "issues",
+		Params: append([]openapi.Param{
+			{Name: "format", Schema: openapi3.NewStringSchema().WithEnum("csv", "xlsx")},
+			{Name: "columns", Description: "Comma-separated columns to export", Schema: openapi3.NewStringSchema()},
+			{Name: "bom", Description: "Start CSV with a UTF-8 byte order mark, true by default", Schema: openapi3.NewBoolSchema()},
+		}, issueFilterParams...),
+		Produces: []string{"text/csv", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		Errors:   []int{400, 403},
+	},
+	"GET /api/statuses": {
+		Summary: "List active statuses in display order", Tag: "statuses",
+		Response: []entities.IssueStatus{},
+	},
+	"GET /api/officers": {
+		Summary: "List officers", Tag: "officers",
+		Response: []entities.Officer{},
+	},
+
+	"POST /api/public/issues": {
+		Summary: "Report an issue without an account", Tag: "portal",
+		Description: "Creates a reporter from the name and contact details, at least an email or a phone number, and returns " +
+			"the tracking token to follow the issue with; it is only returned in this response. Send the captcha " +
+			"provider's response in captcha when a captcha is configured and leave website empty. Spam is rejected with 422.",
+		Body: controllers.PortalReportRequest{}, Status: 201, Response: services.TrackedIssue{},
+		Errors: []int{400, 422, 502},
+	},
+	"GET /api/public/tracking": {
+		Summary: "Follow a reported issue", Tag: "portal",
+		Params:   []openapi.Param{trackingTokenParam},
+		Response: services.PublicIssue{}, Errors: []int{400, 404},
+	},
+	"POST /api/public/tracking/comments": {
+		Summary: "Comment on a reported issue", Tag: "portal",
+		Params: []openapi.Param{trackingTokenParam},
+		Body:   controllers.PortalCommentRequest{}, Status: 201, Response: services.PublicComment{},
+		Errors: []int{400, 404, 422, 502},
+	},
+
+	"GET /api/surveys/:id": {
+		Summary: "Get a satisfaction survey", Tag: "surveys",
+		Description: "Takes the survey ID and signature from the link sent to the reporter when the issue closed.",
+		Params:      []openapi.Param{surveySignatureParam},
+		Response:    services.PublicSurvey{}, Errors: []int{400, 404},
+	},
+	"POST /api/surveys/:id": {
+		Summary: "Answer a satisfaction survey", Tag: "surveys",
+		Description: "Rates the handling of the issue from 1 to 5 with optional feedback. A survey is answered once: " +
+			"409 when it already was, 410 once the link expired.",
+		Params: []openapi.Param{surveySignatureParam},
+		Body:   controllers.SurveyAnswerRequest{}, Response: services.PublicSurvey{},
+		Errors: []int{400, 404, 409, 410},
+	},
+
+	"POST /graphql": {
+		Summary: "Run a GraphQL query or mutation", Tag: "graphql",
+		Description: "Responds 400 with errors when the request is rejected before it runs, " +
+			"such as for a syntax error or a query over the depth or complexity limit.",
+		Body: graph.Request{}, Response: graph.Response{}, Unwrapped: true,
+		Errors: []int{400, 403},
+	},
+	"GET /graphql": {
+		Summary: "Run a GraphQL query", Tag: "graphql",
+		Params: []openapi.Param{
+			{Name: "query", Schema: openapi3.NewStringSchema(), Required: true},
+			{Name: "operationName", Schema: openapi3.NewStringSchema()},
+			{Name: "variables", Description: "Variables as a JSON object", Schema: openapi3.NewStringSchema()},
+		},
+		Response: graph.Response{}, Unwrapped: true,
+		Errors: []int{400, 403},
+	},
+
+	"GET /api/reports/throughput":     report("Issues created and closed per bucket", []analytics.ThroughputPoint{}),
+	"GET /api/reports/backlog":        report("Open issues at the end of each bucket", []analytics.BacklogPoint{}),
+	"GET /api/reports/time-in-status": report("Time spent in each status", []analytics.StatusDuration{}),
+	"GET /api/reports/time-to-close":  report("Time from creation to final close", analytics.DurationStats{}),
+	"GET /api/reports/breakdown": withParams(report("Issues created in the period by priority, assignee or status", []analytics.BreakdownRow{}),
+		openapi.Param{Name: "by", Schema: openapi3.NewStringSchema().WithEnum(analytics.ByPriority, analytics.ByAssignee, analytics.ByStatus)}),
+	"GET /api/reports/cumulative-flow": report("Issue count per status at the end of each bucket", analytics.CumulativeFlow{}),
+	"GET /api/reports/lead-time":       report("Lead time distribution", analytics.Distribution{}),
+	"GET /api/reports/cycle-time": withParams(report("Cycle time distribution", analytics.Distribution{}),
+		openapi.Param{Name: "start_status", Description: "Comma-separated status codes that mark the start of work", Schema: openapi3.NewStringSchema()}),
+	"GET /api/reports/csat": report("Satisfaction scores of surveys sent in the period, overall, per officer and per bucket", analytics.CSATReport{}),
+	"GET /api/reports/aging": {
+		Summary: "Age of work in progress per status", Tag: "reports",
+		Response: controllers.AgingResponse{Report: []analytics.AgingStatus{}},
+		Errors:   []int{403},
+	},
+
+	"GET /auth/login": {
+		Summary: "Sign in at the identity provider", Tag: "auth", Status: 302,
+		Description: "Redirects the browser to the OpenID Connect provider, which sends it back to /auth/callback.",
+		Errors:      []int{502},
+	},
+	"GET /auth/callback": {
+		Summary: "Finish signing in", Tag: "auth",
+		Description: "Where the identity provider sends the browser back. Responds with the ID token to send as " +
+			"\"Authorization: Bearer\"; the person's user, and officer for officers and admins, are created on first sign-in.",
+		Params: []openapi.Param{
+			{Name: "code", Schema: openapi3.NewStringSchema()},
+			{Name: "state", Schema: openapi3.NewStringSchema()},
+			{Name: "error", Schema: openapi3.NewStringSchema()},
+			{Name: "error_description", Schema: openapi3.NewStringSchema()},
+		},
+		Response: services.SignIn{}, Errors: []int{400, 502},
+	},
+	"GET /api/me": {
+		Summary: "Get the signed-in identity", Tag: "auth",
+		Response: entities.Identity{},
+	},
+
+	"GET /api/admin/api-keys": {
+		Summary: "List API keys", Description: "Requires an API key with the admin scope or an admin's ID token.", Tag: "admin",
+		Response: []entities.APIKey{}, Errors: []int{403},
+	},
+	"POST /api/admin/api-keys": {
+		Summary: "Create an API key", Tag: "admin",
+		Description: "Requires an API key with the admin scope or an admin's ID token. The key is only returned in this response. " +
+			"Scopes: issues:read, issues:write, comments:write, reports:read, admin.",
+		Body: controllers.APIKeyRequest{}, Status: 201, Response: services.CreatedAPIKey{},
+		Errors: []int{400, 403},
+	},
+	"DELETE /api/admin/api-keys/:id": {
+		Summary: "Revoke an API key", Description: "Requires an API key with the admin scope or an admin's ID token.", Tag: "admin",
+		Response: entities.APIKey{}, Errors: []int{400, 403, 404},
+	},
+	"GET /api/admin/surveys": {
+		Summary: "List surveys waiting for an answer", Tag: "admin",
+		Description: "Requires an API key with the admin scope or an admin's ID token. Each survey comes with its signed " +
+			"link and the contact details of the reporter to send it to.",
+		Response: []services.PendingSurvey{}, Errors: []int{403},
+	},
+}
+
+// report describes a report over a time range
+func report(summary string, data interface{}) openapi.Operation {
+	return openapi.Operation{
+		Summary: summary, Tag: "reports",
+		Params:   reportRangeParams,
+		Response: controllers.ReportResponse{Report: data},
+		Errors:   []int{400, 403},
+	}
+}
+
+// withParams adds parameters to op
+func withParams(op openapi.Operation, params ...openapi.Param) openapi.Operation {
+	op.Params = append(append([]openapi.Param{}, op.Params...), params...)
+	return op
+}
+
+// withError returns a copy of ops where every operation but the exempt ones
+// also returns the error status code
+func withError(ops map[string]openapi.Operation, exempt map[string]bool, code int) map[string]openapi.Operation {
+	out := make(map[string]openapi.Operation, len(ops))
+	for key, op := range ops {
+		if !exempt[key] {
+			op.Errors = append(append([]int{}, op.Errors...), code)
+		}
+		out[key] = op
+	}
+	return out
+}
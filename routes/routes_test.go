@@ -0,0 +1,1081 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"issue-tracking/analytics"
+	"issue-tracking/auth"
+	"issue-tracking/auth/oidctest"
+	"issue-tracking/config"
+	"issue-tracking/database/dbtest"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/routes"
+	"issue-tracking/services"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+// backend opens an empty store for one test
+type backend struct {
+	name string
+	open func(t *testing.T) repositories.Store
+}
+
+// backends returns the in-memory store and a GORM store for every database
+// driver under test
+func backends() []backend {
+	list := []backend{{"memory", func(*testing.T) repositories.Store { return repositories.NewMemoryStore() }}}
+	for _, driver := range dbtest.Drivers() {
+		driver := driver
+		list = append(list, backend{driver, func(t *testing.T) repositories.Store {
+			return repositories.NewGormStore(dbtest.Open(t, driver))
+		}})
+	}
+	return list
+}
+
+// newTestRouter registers every route against the store opened by b,
+// holding three statuses, two users, two officers and three issues:
+//
+//	1 "Login page fails"   open         high   reporter 1, assignee 1, label bug
+//	2 "Export question"    in-progress  low    reporter 2
+//	3 "Old request"        closed       medium reporter 1
+func newTestRouter(t *testing.T, b backend) (*gin.Engine, repositories.Store) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := b.open(t)
+
+	statuses := []entities.IssueStatus{
+		{StatusCode: "open", DisplayName: "Open", Color: "#2196F3", DisplayOrder: 1, IsActive: true},
+		{StatusCode: "in-progress", DisplayName: "In Progress", Color: "#FF9800", DisplayOrder: 2, IsActive: true},
+		{StatusCode: "closed", DisplayName: "Closed", Color: "#4CAF50", DisplayOrder: 3, IsActive: true, IsTerminal: true},
+	}
+	for i := range statuses {
+		if err := store.Statuses().Create(ctx, &statuses[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"John Doe", "Alice Johnson"} {
+		if err := store.Users().Create(ctx, &entities.User{FullName: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"Jane Smith", "Bob Brown"} {
+		if err := store.Officers().Create(ctx, &entities.Officer{FullName: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assignee := uint(1)
+	issues := []entities.Issue{
+		{ReporterID: 1, AssigneeID: &assignee, StatusID: 1, Title: "Login page fails", Priority: "high"},
+		{ReporterID: 2, StatusID: 2, Title: "Export question", Priority: "low"},
+		{ReporterID: 1, StatusID: 3, Title: "Old request", Priority: "medium"},
+	}
+	for i := range issues {
+		if err := store.Issues().Create(ctx, &issues[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	label := entities.Label{Name: "bug"}
+	if err := store.Labels().FindOrCreate(ctx, &label); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Issues().ApplyChange(ctx, 1, repositories.IssueChange{Label: &label, ChangedBy: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Portal.Enabled = true
+	router := gin.New()
+	routes.RegisterRoutes(router, store, services.NewIssueService(store), cfg)
+	return router, store
+}
+
+// serve sends one request through the router
+func serve(router http.Handler, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decode unmarshals the data field of a success response
+func decode(t *testing.T, w *httptest.ResponseRecorder, data interface{}) {
+	t.Helper()
+	envelope := struct {
+		Data interface{} `json:"data"`
+	}{data}
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("invalid JSON response: %v: %s", err, w.Body)
+	}
+}
+
+// issueIDs returns the IDs of the issues in a list response
+func issueIDs(t *testing.T, w *httptest.ResponseRecorder) []uint {
+	t.Helper()
+	var issues []entities.Issue
+	decode(t, w, &issues)
+	ids := make([]uint, len(issues))
+	for i, issue := range issues {
+		ids[i] = issue.IssueID
+	}
+	return ids
+}
+
+// wantIDs checks the issue IDs of a list response
+func wantIDs(want ...uint) func(*testing.T, http.Handler, *httptest.ResponseRecorder) {
+	return func(t *testing.T, _ http.Handler, w *httptest.ResponseRecorder) {
+		got := issueIDs(t, w)
+		if len(got) != len(want) {
+			t.Fatalf("issue IDs = %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("issue IDs = %v, want %v", got, want)
+			}
+		}
+	}
+}
+
+// wantIssueStatus checks the status code of an issue after the request
+func wantIssueStatus(issueID string, code string) func(*testing.T, http.Handler, *httptest.ResponseRecorder) {
+	return func(t *testing.T, router http.Handler, _ *httptest.ResponseRecorder) {
+		w := serve(router, http.MethodGet, "/api/issues/"+issueID, "", nil)
+		var issue entities.Issue
+		decode(t, w, &issue)
+		if issue.Status.StatusCode != code {
+			t.Errorf("issue %s status = %q, want %q", issueID, issue.Status.StatusCode, code)
+		}
+	}
+}
+
+// wantBody checks that the response body contains every string
+func wantBody(parts ...string) func(*testing.T, http.Handler, *httptest.ResponseRecorder) {
+	return func(t *testing.T, _ http.Handler, w *httptest.ResponseRecorder) {
+		for _, part := range parts {
+			if !strings.Contains(w.Body.String(), part) {
+				t.Errorf("response does not contain %q: %s", part, w.Body)
+			}
+		}
+	}
+}
+
+type routeTest struct {
+	name   string
+	method string
+	path   string
+	body   string
+	status int
+	check  func(t *testing.T, router http.Handler, w *httptest.ResponseRecorder)
+}
+
+var routeTests = []routeTest{
+	// GET /api/issues
+	{name: "list all issues", method: "GET", path: "/api/issues", status: 200, check: wantIDs(1, 2, 3)},
+	{name: "filter by status", method: "GET", path: "/api/issues?status=open", status: 200, check: wantIDs(1)},
+	{name: "filter by priority", method: "GET", path: "/api/issues?priority=low", status: 200, check: wantIDs(2)},
+	{name: "filter by assignee", method: "GET", path: "/api/issues?assignee_id=1", status: 200, check: wantIDs(1)},
+	{name: "filter by reporter", method: "GET", path: "/api/issues?reporter_id=1", status: 200, check: wantIDs(1, 3)},
+	{name: "filter by label", method: "GET", path: "/api/issues?label=bug", status: 200, check: wantIDs(1)},
+	{name: "filters combine", method: "GET", path: "/api/issues?reporter_id=1&status=closed", status: 200, check: wantIDs(3)},
+	{name: "no match", method: "GET", path: "/api/issues?status=unknown", status: 200, check: wantBody(`"data":[]`)},
+	{name: "invalid priority filter", method: "GET", path: "/api/issues?priority=urgent", status: 400},
+	{name: "invalid assignee filter", method: "GET", path: "/api/issues?assignee_id=x", status: 400},
+	{name: "first page", method: "GET", path: "/api/issues?limit=2", status: 200, check: wantIDs(1, 2)},
+	{name: "next page", method: "GET", path: "/api/issues?limit=2&after_id=2", status: 200, check: wantIDs(3)},
+	{name: "page with filter", method: "GET", path: "/api/issues?reporter_id=1&limit=1&after_id=1", status: 200, check: wantIDs(3)},
+	{name: "page too large", method: "GET", path: "/api/issues?limit=501", status: 400},
+
+	// GET /api/issues/:id
+	{name: "get issue", method: "GET", path: "/api/issues/1", status: 200,
+		check: wantBody(`"title":"Login page fails"`, `"full_name":"Jane Smith"`, `"name":"bug"`, `"status_history":[`)},
+	{name: "get missing issue", method: "GET", path: "/api/issues/99", status: 404},
+	{name: "get issue with invalid ID", method: "GET", path: "/api/issues/abc", status: 400,
+		check: wantBody(`"field":"id"`, "id must be an integer")},
+
+	// POST /api/issues
+	{name: "create issue", method: "POST", path: "/api/issues", status: 201,
+		body:  `{"reporter_id":2,"status_id":1,"assignee_id":2,"title":"Printer offline","priority":"critical"}`,
+		check: wantBody(`"issue_id":4`, `"full_name":"Alice Johnson"`, `"full_name":"Bob Brown"`, `"status_code":"open"`)},
+	{name: "create issue failing validation", method: "POST", path: "/api/issues", status: 400,
+		body:  `{"reporter_id":1,"status_id":1,"title":"x","priority":"someday"}`,
+		check: wantBody("Validation failed", "title must be at least 3 characters", "priority must be one of: low medium high critical")},
+	{name: "create issue without required fields", method: "POST", path: "/api/issues", status: 400,
+		body: `{"title":"Printer offline"}`, check: wantBody("reporter_id is required", "status_id is required", "priority is required")},
+	{name: "create issue with wrong type", method: "POST", path: "/api/issues", status: 400,
+		body: `{"reporter_id":"1","status_id":1,"title":"Printer offline","priority":"low"}`, check: wantBody("reporter_id must be an integer")},
+	{name: "create issue with unknown reporter", method: "POST", path: "/api/issues", status: 400,
+		body: `{"reporter_id":9,"status_id":1,"title":"Printer offline","priority":"low"}`, check: wantBody("Reporter not found")},
+	{name: "create issue with unknown status", method: "POST", path: "/api/issues", status: 400,
+		body: `{"reporter_id":1,"status_id":9,"title":"Printer offline","priority":"low"}`, check: wantBody("Status not found")},
+	{name: "create issue with unknown assignee", method: "POST", path: "/api/issues", status: 400,
+		body: `{"reporter_id":1,"status_id":1,"assignee_id":9,"title":"Printer offline","priority":"low"}`, check: wantBody("Assignee not found")},
+	{name: "create issue with malformed JSON", method: "POST", path: "/api/issues", body: `{`, status: 400},
+
+	// PATCH /api/issues/:id/status
+	{name: "change status", method: "PATCH", path: "/api/issues/2/status", status: 200,
+		body: `{"new_status_id":3,"comment":"answered"}`, check: wantIssueStatus("2", "closed")},
+	{name: "change status and assignee", method: "PATCH", path: "/api/issues/2/status", status: 200,
+		body: `{"new_status_id":2,"assignee_id":2}`, check: wantBody(`"full_name":"Bob Brown"`)},
+	{name: "change status keeps assignee", method: "PATCH", path: "/api/issues/1/status", status: 200,
+		body: `{"new_status_id":2}`, check: wantBody(`"full_name":"Jane Smith"`)},
+	{name: "change to unknown status", method: "PATCH", path: "/api/issues/1/status", status: 400,
+		body: `{"new_status_id":9}`, check: wantBody("Invalid status")},
+	{name: "change status with unknown assignee", method: "PATCH", path: "/api/issues/1/status", status: 400,
+		body: `{"new_status_id":2,"assignee_id":9}`, check: wantBody("Assignee not found")},
+	{name: "change status of missing issue", method: "PATCH", path: "/api/issues/99/status", status: 404,
+		body: `{"new_status_id":2}`},
+	{name: "change status without status", method: "PATCH", path: "/api/issues/1/status", status: 400, body: `{}`},
+
+	// POST /api/issues/:id/comment
+	{name: "add comment", method: "POST", path: "/api/issues/1/comment", status: 201,
+		body: `{"user_id":2,"content":"Same here"}`, check: wantBody(`"content":"Same here"`, `"full_name":"Alice Johnson"`)},
+	{name: "add comment by unknown user", method: "POST", path: "/api/issues/1/comment", status: 400,
+		body: `{"user_id":9,"content":"Same here"}`, check: wantBody("User not found")},
+	{name: "add comment to missing issue", method: "POST", path: "/api/issues/99/comment", status: 404,
+		body: `{"user_id":1,"content":"Same here"}`},
+	{name: "add empty comment", method: "POST", path: "/api/issues/1/comment", status: 400, body: `{"user_id":1}`},
+
+	// POST /api/issues/bulk
+	{name: "bulk change status", method: "POST", path: "/api/issues/bulk", status: 200,
+		body: `{"issue_ids":[1,2],"operation":"change_status","status_id":2}`, check: wantBody(`"succeeded":2`)},
+	{name: "bulk assign by filter", method: "POST", path: "/api/issues/bulk", status: 200,
+		body: `{"filter":"reporter_id=1","operation":"assign","assignee_id":2}`, check: wantBody(`"total":2`, `"succeeded":2`)},
+	{name: "bulk set priority", method: "POST", path: "/api/issues/bulk", status: 200,
+		body: `{"issue_ids":[2],"operation":"set_priority","priority":"critical"}`, check: wantBody(`"succeeded":1`)},
+	{name: "bulk add label", method: "POST", path: "/api/issues/bulk", status: 200,
+		body: `{"issue_ids":[2,3],"operation":"add_label","label":"bug"}`,
+		check: func(t *testing.T, router http.Handler, _ *httptest.ResponseRecorder) {
+			wantIDs(1, 2, 3)(t, router, serve(router, "GET", "/api/issues?label=bug", "", nil))
+		}},
+	{name: "bulk close", method: "POST", path: "/api/issues/bulk", status: 200,
+		body: `{"issue_ids":[1],"operation":"close"}`, check: wantIssueStatus("1", "closed")},
+	{name: "bulk best effort reports missing issues", method: "POST", path: "/api/issues/bulk", status: 200,
+		body:  `{"issue_ids":[1,99],"operation":"close","mode":"best_effort"}`,
+		check: wantBody(`"succeeded":1`, `"failed":1`, `"issue not found"`)},
+	{name: "bulk transactional rolls back", method: "POST", path: "/api/issues/bulk", status: 422,
+		body: `{"issue_ids":[1,99],"operation":"close"}`, check: wantIssueStatus("1", "open")},
+	{name: "bulk unknown operation", method: "POST", path: "/api/issues/bulk", status: 400,
+		body: `{"issue_ids":[1],"operation":"archive"}`},
+	{name: "bulk needs one selection", method: "POST", path: "/api/issues/bulk", status: 400,
+		body: `{"issue_ids":[1],"filter":"status=open","operation":"close"}`},
+
+	// POST /api/issues/import
+	{name: "import CSV", method: "POST", path: "/api/issues/import", status: 201,
+		body:  "title,priority,status,reporter,assignee\nVPN drops,high,open,John Doe,Bob Brown\nNew laptop,low,In Progress,Alice Johnson,\n",
+		check: wantBody(`"imported":2`)},
+	{name: "import dry run", method: "POST", path: "/api/issues/import?dry_run=true", status: 200,
+		body:  "title,priority,status,reporter\nVPN drops,high,open,Nobody\n",
+		check: wantBody(`"dry_run":true`, `"failed":1`, `user \"Nobody\" not found`)},
+	{name: "import JSON lines creating users", method: "POST", path: "/api/issues/import?format=jsonl&create_missing_users=true", status: 201,
+		body:  `{"title":"VPN drops","priority":"high","status_id":1,"reporter":"New Person"}`,
+		check: wantBody(`"imported":1`, `"created_users":["New Person"]`)},
+	{name: "import malformed JSON lines", method: "POST", path: "/api/issues/import?format=jsonl", status: 400, body: `{`},
+	{name: "import unknown format", method: "POST", path: "/api/issues/import?format=xml", status: 400, body: `<issues/>`},
+
+	// GET /api/issues/export
+	{name: "export CSV", method: "GET", path: "/api/issues/export?status=open", status: 200,
+		check: wantBody("Issue ID,Title,Priority,Status,Reporter,Assignee", "1,Login page fails,high,Open,John Doe,Jane Smith")},
+	{name: "export selected columns", method: "GET", path: "/api/issues/export?columns=issue_id,status_code&bom=false", status: 200,
+		check: func(t *testing.T, _ http.Handler, w *httptest.ResponseRecorder) {
+			want := "Issue ID,Status Code\n1,open\n2,in-progress\n3,closed\n"
+			if w.Body.String() != want {
+				t.Errorf("CSV = %q, want %q", w.Body, want)
+			}
+		}},
+	{name: "export XLSX", method: "GET", path: "/api/issues/export?format=xlsx", status: 200,
+		check: func(t *testing.T, _ http.Handler, w *httptest.ResponseRecorder) {
+			if !strings.HasPrefix(w.Body.String(), "PK") {
+				t.Errorf("XLSX export is not a zip file")
+			}
+		}},
+	{name: "export unknown format", method: "GET", path: "/api/issues/export?format=pdf", status: 400},
+	{name: "export unknown column", method: "GET", path: "/api/issues/export?columns=secret", status: 400},
+
+	// GET /api/reports/*
+	{name: "throughput report", method: "GET", path: "/api/reports/throughput?bucket=week", status: 200, check: wantBody(`"created":3`)},
+	{name: "backlog report", method: "GET", path: "/api/reports/backlog", status: 200, check: wantBody(`"report":[`)},
+	{name: "time in status report", method: "GET", path: "/api/reports/time-in-status", status: 200, check: wantBody(`"report":`)},
+	{name: "time to close report", method: "GET", path: "/api/reports/time-to-close", status: 200, check: wantBody(`"report":`)},
+	{name: "breakdown report", method: "GET", path: "/api/reports/breakdown?by=assignee", status: 200, check: wantBody("Jane Smith")},
+	{name: "cumulative flow report", method: "GET", path: "/api/reports/cumulative-flow?bucket=month", status: 200, check: wantBody(`"series":[`)},
+	{name: "lead time report", method: "GET", path: "/api/reports/lead-time", status: 200, check: wantBody(`"report":`)},
+	{name: "cycle time report", method: "GET", path: "/api/reports/cycle-time?start_status=in-progress", status: 200, check: wantBody(`"report":`)},
+	{name: "aging report", method: "GET", path: "/api/reports/aging", status: 200, check: wantBody(`"report":`)},
+	{name: "invalid report range", method: "GET", path: "/api/reports/throughput?from=yesterday", status: 400},
+	{name: "report range with too many buckets", method: "GET", path: "/api/reports/throughput?from=0001-01-01&to=9999-12-31&bucket=day", status: 400,
+		check: wantBody("more than 1000 buckets")},
+	{name: "invalid breakdown", method: "GET", path: "/api/reports/breakdown?by=color", status: 400},
+	{name: "unknown cycle time start status", method: "GET", path: "/api/reports/cycle-time?start_status=review", status: 400},
+
+	// Lookups and metrics
+	{name: "list statuses", method: "GET", path: "/api/statuses", status: 200,
+		check: wantBody(`"status_code":"open"`, `"status_code":"in-progress"`, `"status_code":"closed"`)},
+	{name: "list officers", method: "GET", path: "/api/officers", status: 200,
+		check: wantBody(`"full_name":"Jane Smith"`, `"full_name":"Bob Brown"`)},
+	{name: "metrics", method: "GET", path: "/metrics", status: 200, check: wantBody("# TYPE")},
+
+	// Public portal
+	{name: "report on the portal", method: "POST", path: "/api/public/issues", status: 201,
+		body:  `{"name":"Maria Lopez","email":"maria@example.com","title":"Streetlight out","description":"On Elm Street since Monday"}`,
+		check: wantBody(`"tracking_token":"trk_`, `"reference":4`, `"status":{"code":"open","name":"Open","closed":false}`, `"comments":[]`)},
+	{name: "report on the portal without contact details", method: "POST", path: "/api/public/issues", status: 400,
+		body: `{"name":"Maria Lopez","title":"Streetlight out"}`, check: wantBody("Email or Phone is required")},
+	{name: "report on the portal with a bad email", method: "POST", path: "/api/public/issues", status: 400,
+		body: `{"name":"Maria Lopez","email":"maria","title":"Streetlight out"}`, check: wantBody("Email must be a valid email")},
+	{name: "report on the portal with the honeypot filled in", method: "POST", path: "/api/public/issues", status: 422,
+		body: `{"name":"Maria Lopez","phone":"555-0100","title":"Streetlight out","website":"http://spam.example.com"}`, check: wantBody("Submission rejected")},
+	{name: "report on the portal with many links", method: "POST", path: "/api/public/issues", status: 422,
+		body: `{"name":"Cheap Pills","phone":"555-0100","title":"Great offer","description":"https://a.example.com https://b.example.com www.c.example.com"}`},
+	{name: "report on the portal in capitals", method: "POST", path: "/api/public/issues", status: 422,
+		body: `{"name":"Maria Lopez","phone":"555-0100","title":"WHY IS NOBODY FIXING THIS LIGHT"}`},
+	{name: "follow a report without a token", method: "GET", path: "/api/public/tracking", status: 400},
+	{name: "comment on a report without a token", method: "POST", path: "/api/public/tracking/comments", status: 400,
+		body: `{"content":"Still broken"}`},
+
+	// GraphQL
+	{name: "GraphQL nested query", method: "POST", path: "/graphql",
+		body:   `{"query":"{ issues(reporterId: 1) { id reporter { fullName } assignee { fullName } status { code } labels { name } history { newStatus { code } } } }"}`,
+		status: 200, check: wantBody(`"id":1`, `"id":3`, `"fullName":"John Doe"`, `"assignee":{"fullName":"Jane Smith"}`, `"labels":[{"name":"bug"}]`, `"history":[{"newStatus":{"code":"open"}}]`)},
+	{name: "GraphQL page", method: "POST", path: "/graphql",
+		body:   `{"query":"query($after: Int) { issues(limit: 1, afterId: $after) { id } }","variables":{"after":1}}`,
+		status: 200, check: wantBody(`{"data":{"issues":[{"id":2}]}}`)},
+	{name: "GraphQL create issue", method: "POST", path: "/graphql",
+		body:   `{"query":"mutation { createIssue(input: {reporterId: 2, statusId: 1, title: \"Printer offline\", priority: high}) { id title reporter { fullName } status { code } } }"}`,
+		status: 200, check: wantBody(`"id":4`, `"reporter":{"fullName":"Alice Johnson"}`, `"status":{"code":"open"}`)},
+	{name: "GraphQL create issue with unknown reporter", method: "POST", path: "/graphql",
+		body:   `{"query":"mutation { createIssue(input: {reporterId: 9, statusId: 1, title: \"Printer offline\", priority: high}) { id } }"}`,
+		status: 200, check: wantBody(`"message":"Reporter not found: invalid reporter_id"`, `"status":400`)},
+	{name: "GraphQL transition issue", method: "POST", path: "/graphql",
+		body:   `{"query":"mutation { transitionIssue(id: 2, statusId: 3, comment: \"Answered\") { status { code } history { comment } } }"}`,
+		status: 200, check: wantIssueStatus("2", "closed")},
+	{name: "GraphQL add comment", method: "POST", path: "/graphql",
+		body:   `{"query":"mutation { addComment(issueId: 1, userId: 2, content: \"Same here\") { content user { fullName } issue { comments { content } } } }"}`,
+		status: 200, check: wantBody(`"user":{"fullName":"Alice Johnson"}`, `"comments":[{"content":"Same here"}]`)},
+	{name: "GraphQL query over GET", method: "GET", path: "/graphql?query=%7Bstatuses%7Bcode%7D%7D", status: 200,
+		check: wantBody(`{"data":{"statuses":[{"code":"open"},{"code":"in-progress"},{"code":"closed"}]}}`)},
+	{name: "GraphQL mutation over GET", method: "GET", path: "/graphql?query=mutation%7BtransitionIssue(id:1,statusId:3)%7Bid%7D%7D", status: 400,
+		check: wantBody("mutations must be sent with POST")},
+	{name: "GraphQL syntax error", method: "POST", path: "/graphql", body: `{"query":"{ issues { id }"}`, status: 400},
+	{name: "GraphQL unknown field", method: "POST", path: "/graphql", body: `{"query":"{ issues { secret } }"}`, status: 400},
+	{name: "GraphQL query too deep", method: "POST", path: "/graphql",
+		body:   `{"query":"{ issues { comments { issue { comments { issue { comments { issue { comments { issue { comments { id } } } } } } } } } } }"}`,
+		status: 400, check: wantBody("query depth 11 exceeds the limit of 10")},
+	{name: "GraphQL query too complex", method: "POST", path: "/graphql",
+		body:   `{"query":"{ issues(limit: 500) { comments { id content createdAt user { fullName } } } }"}`,
+		status: 400, check: wantBody("query complexity")},
+	{name: "GraphQL missing query", method: "POST", path: "/graphql", body: `{}`, status: 400, check: wantBody("Validation failed")},
+
+	// API keys
+	{name: "list API keys without a key", method: "GET", path: "/api/admin/api-keys", status: 401, check: wantBody("an API key or ID token is required")},
+	{name: "create API key without a key", method: "POST", path: "/api/admin/api-keys", status: 401,
+		body: `{"name":"Monitoring","scopes":["issues:write"]}`},
+	{name: "revoke API key without a key", method: "DELETE", path: "/api/admin/api-keys/1", status: 401},
+
+	// Documentation
+	{name: "OpenAPI document", method: "GET", path: "/openapi.json", status: 200,
+		check: wantBody(`"openapi":"3.0.3"`, `"/api/issues/{id}/status"`)},
+	{name: "Swagger UI", method: "GET", path: "/docs/", status: 200, check: wantBody("swagger-ui")},
+	{name: "Swagger UI loads the document", method: "GET", path: "/docs/swagger-initializer.js", status: 200,
+		check: wantBody(`"/openapi.json"`)},
+}
+
+func TestRoutes(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			for _, tt := range routeTests {
+				t.Run(tt.name, func(t *testing.T) {
+					router, _ := newTestRouter(t, b)
+					w := serve(router, tt.method, tt.path, tt.body, nil)
+					if w.Code != tt.status {
+						t.Fatalf("%s %s = %d, want %d: %s", tt.method, tt.path, w.Code, tt.status, w.Body)
+					}
+					if tt.check != nil {
+						tt.check(t, router, w)
+					}
+				})
+			}
+		})
+	}
+}
+
+// TestRoutesCovered fails when a route is registered without a test case
+func TestRoutesCovered(t *testing.T) {
+	router, _ := newTestRouter(t, backends()[0])
+
+	tested := map[string]bool{}
+	for _, tt := range routeTests {
+		tested[tt.method+" "+strings.SplitN(tt.path, "?", 2)[0]] = true
+	}
+	for _, route := range router.Routes() {
+		covered := false
+		for key := range tested {
+			method, path, _ := strings.Cut(key, " ")
+			if method == route.Method && matchRoute(route.Path, path) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			t.Errorf("no test for %s %s", route.Method, route.Path)
+		}
+	}
+}
+
+// matchRoute reports whether path matches a gin route pattern
+func matchRoute(pattern, path string) bool {
+	want := strings.Split(pattern, "/")
+	got := strings.Split(path, "/")
+	for i := range want {
+		if strings.HasPrefix(want[i], "*") {
+			return len(got) >= i
+		}
+		if i >= len(got) || !strings.HasPrefix(want[i], ":") && want[i] != got[i] {
+			return false
+		}
+	}
+	return len(want) == len(got)
+}
+
+func TestIdempotentCreate(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) { testIdempotentCreate(t, b) })
+	}
+}
+
+func testIdempotentCreate(t *testing.T, b backend) {
+	router, _ := newTestRouter(t, b)
+	body := `{"reporter_id":1,"status_id":1,"title":"Printer offline","priority":"low"}`
+	header := http.Header{"Idempotency-Key": {"create-printer"}}
+
+	first := serve(router, http.MethodPost, "/api/issues", body, header)
+	if first.Code != 201 {
+		t.Fatalf("first request = %d: %s", first.Code, first.Body)
+	}
+
+	retry := serve(router, http.MethodPost, "/api/issues", body, header)
+	if retry.Code != 201 || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry = %d replayed=%q, want replayed 201", retry.Code, retry.Header().Get("Idempotent-Replayed"))
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("replayed body differs:\n%s\n%s", retry.Body, first.Body)
+	}
+	if ids := issueIDs(t, serve(router, http.MethodGet, "/api/issues?reporter_id=1&priority=low", "", nil)); len(ids) != 1 {
+		t.Errorf("retry created another issue: %v", ids)
+	}
+
+	reused := serve(router, http.MethodPost, "/api/issues", strings.Replace(body, "Printer", "Scanner", 1), header)
+	if reused.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key = %d, want 422", reused.Code)
+	}
+}
+
+func TestIdempotentComment(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) { testIdempotentComment(t, b) })
+	}
+}
+
+func testIdempotentComment(t *testing.T, b backend) {
+	router, store := newTestRouter(t, b)
+	body := `{"user_id":1,"content":"Any update?"}`
+	header := http.Header{"Idempotency-Key": {"comment-1"}}
+
+	for i := 0; i < 2; i++ {
+		if w := serve(router, http.MethodPost, "/api/issues/1/comment", body, header); w.Code != 201 {
+			t.Fatalf("request %d = %d: %s", i+1, w.Code, w.Body)
+		}
+	}
+	comments, err := store.Comments().ListByIssue(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 1 {
+		t.Errorf("got %d comments, want 1", len(comments))
+	}
+}
+
+// TestOpenAPIMatchesRoutes fails when the served document and the router
+// disagree on which operations exist
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	router, _ := newTestRouter(t, backends()[0])
+
+	w := serve(router, http.MethodGet, "/openapi.json", "", nil)
+	if w.Code != 200 {
+		t.Fatalf("GET /openapi.json = %d: %s", w.Code, w.Body)
+	}
+	doc, err := openapi3.NewLoader().LoadFromData(w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+
+	documented := map[string]bool{}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		if strings.Contains(route.Path, "*") {
+			continue // Swagger UI files
+		}
+		// Gin writes path parameters as :id, OpenAPI as {id}
+		segments := strings.Split(route.Path, "/")
+		for i, segment := range segments {
+			if name, ok := strings.CutPrefix(segment, ":"); ok {
+				segments[i] = "{" + name + "}"
+			}
+		}
+		registered[route.Method+" "+strings.Join(segments, "/")] = true
+	}
+
+	for key := range registered {
+		if !documented[key] {
+			t.Errorf("%s is not in the document", key)
+		}
+	}
+	for key := range documented {
+		if !registered[key] {
+			t.Errorf("%s is documented but not registered", key)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) { testRateLimit(t, b) })
+	}
+}
+
+// testRateLimit runs two replicas keeping their buckets in one store: a
+// client's write budget is shared by both, while its reads and other
+// clients are counted separately
+func testRateLimit(t *testing.T, b backend) {
+	_, store := newTestRouter(t, b)
+	cfg := config.Default()
+	cfg.RateLimit.Enabled = true
+	cfg.RateLimit.Store = "database"
+	cfg.RateLimit.WritePerMinute = 1
+	cfg.RateLimit.WriteBurst = 2
+	replicas := make([]*gin.Engine, 2)
+	for i := range replicas {
+		replicas[i] = gin.New()
+		routes.RegisterRoutes(replicas[i], store, services.NewIssueService(store), cfg)
+	}
+
+	body := `{"user_id":1,"content":"Any update?"}`
+	for i, router := range replicas {
+		w := serve(router, http.MethodPost, "/api/issues/1/comment", body, nil)
+		if w.Code != 201 {
+			t.Fatalf("request %d = %d: %s", i+1, w.Code, w.Body)
+		}
+		if got, want := w.Header().Get("RateLimit-Remaining"), strconv.Itoa(1-i); got != want {
+			t.Errorf("request %d RateLimit-Remaining = %q, want %q", i+1, got, want)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d RateLimit-Limit = %q, want 2", i+1, got)
+		}
+	}
+
+	limited := serve(replicas[0], http.MethodPost, "/api/issues/1/comment", body, nil)
+	if limited.Code != http.StatusTooManyRequests || !strings.Contains(limited.Body.String(), "Too many requests") {
+		t.Fatalf("third write = %d: %s", limited.Code, limited.Body)
+	}
+	if retry := limited.Header().Get("Retry-After"); retry == "" || retry == "0" {
+		t.Errorf("Retry-After = %q", retry)
+	}
+
+	if w := serve(replicas[1], http.MethodGet, "/api/issues/1", "", nil); w.Code != 200 {
+		t.Errorf("read after writes ran out = %d, want 200", w.Code)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/issues/1/comment", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.7:4321"
+	w := httptest.NewRecorder()
+	replicas[1].ServeHTTP(w, req)
+	if w.Code != 201 {
+		t.Errorf("write from another client = %d, want 201", w.Code)
+	}
+	if w := serve(replicas[1], http.MethodGet, "/metrics", "", nil); w.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("metrics are rate limited")
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) { testAPIKeys(t, b) })
+	}
+}
+
+// testAPIKeys creates an integration key with an admin key, opens an issue
+// with it and checks its scopes are enforced until it is revoked
+func testAPIKeys(t *testing.T, b backend) {
+	_, store := newTestRouter(t, b)
+	cfg := config.Default()
+	cfg.Auth.AnonymousScopes = []string{auth.ScopeIssuesRead}
+	router := gin.New()
+	routes.RegisterRoutes(router, store, services.NewIssueService(store), cfg)
+	admin, err := services.NewAPIKeyService(store).Create(context.Background(), services.NewAPIKey{Name: "Admin", Scopes: []string{auth.ScopeAdmin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminHeader := http.Header{"Authorization": {"Bearer " + admin.Key}}
+
+	w := serve(router, http.MethodPost, "/api/admin/api-keys", `{"name":"Monitoring","scopes":["issues:write"]}`, adminHeader)
+	if w.Code != 201 {
+		t.Fatalf("create key = %d: %s", w.Code, w.Body)
+	}
+	var created services.CreatedAPIKey
+	decode(t, w, &created)
+	if !strings.HasPrefix(created.Key, created.Prefix+"_") || strings.Contains(w.Body.String(), auth.HashAPIKey(created.Key)) {
+		t.Fatalf("created key %q does not start with %q or leaks its hash", created.Key, created.Prefix)
+	}
+	if w := serve(router, http.MethodPost, "/api/admin/api-keys", `{"name":"Bad","scopes":["everything"]}`, adminHeader); w.Code != 400 {
+		t.Errorf("create key with unknown scope = %d, want 400: %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/admin/api-keys", "", http.Header{"X-Api-Key": {created.Key}}); w.Code != 403 {
+		t.Errorf("list keys without admin scope = %d, want 403", w.Code)
+	}
+
+	key := http.Header{"X-Api-Key": {created.Key}}
+	w = serve(router, http.MethodPost, "/api/issues", `{"reporter_id":1,"status_id":1,"title":"Disk almost full","priority":"high"}`, key)
+	if w.Code != 201 {
+		t.Fatalf("create issue with key = %d: %s", w.Code, w.Body)
+	}
+	wantBody(`"source":"integration"`, `"api_key_id":`+strconv.Itoa(int(created.APIKeyID)))(t, router, w)
+	if w := serve(router, http.MethodGet, "/api/issues/1", "", nil); !strings.Contains(w.Body.String(), `"source":"user"`) {
+		t.Errorf("issue created without a key: %s", w.Body)
+	}
+	for _, req := range []struct{ method, path, body string }{
+		{http.MethodPost, "/api/issues/1/comment", `{"user_id":1,"content":"Disk cleaned"}`},
+		{http.MethodGet, "/api/reports/backlog", ""},
+	} {
+		if w := serve(router, req.method, req.path, req.body, key); w.Code != 403 {
+			t.Errorf("%s %s without scope = %d, want 403: %s", req.method, req.path, w.Code, w.Body)
+		}
+		if w := serve(router, req.method, req.path, req.body, nil); w.Code != 401 {
+			t.Errorf("%s %s without a key = %d, want 401: %s", req.method, req.path, w.Code, w.Body)
+		}
+	}
+	// A key gets what anonymous callers get, whatever its scopes
+	for _, path := range []string{"/api/issues", "/graphql?query=%7B%20statuses%20%7B%20code%20%7D%20%7D"} {
+		if w := serve(router, http.MethodGet, path, "", key); w.Code != 200 {
+			t.Errorf("GET %s with a key lacking the anonymous scope = %d, want 200: %s", path, w.Code, w.Body)
+		}
+	}
+
+	w = serve(router, http.MethodGet, "/api/admin/api-keys", "", adminHeader)
+	wantBody(`"name":"Monitoring"`, `"last_used_at":`)(t, router, w)
+
+	path := "/api/admin/api-keys/" + strconv.Itoa(int(created.APIKeyID))
+	if w := serve(router, http.MethodDelete, path, "", adminHeader); w.Code != 200 || !strings.Contains(w.Body.String(), `"revoked_at":`) {
+		t.Fatalf("revoke key = %d: %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/statuses", "", key); w.Code != 401 {
+		t.Errorf("request with revoked key = %d, want 401", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "/api/statuses", "", http.Header{"X-Api-Key": {created.Prefix + "_wrong"}}); w.Code != 401 {
+		t.Errorf("request with wrong secret = %d, want 401", w.Code)
+	}
+	if w := serve(router, http.MethodDelete, "/api/admin/api-keys/99", "", adminHeader); w.Code != 404 {
+		t.Errorf("revoke missing key = %d, want 404", w.Code)
+	}
+}
+
+func TestOIDC(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) { testOIDC(t, b) })
+	}
+}
+
+// testOIDC signs an officer in through a local provider, then checks that
+// ID tokens carry the scopes of the role their groups map to
+func testOIDC(t *testing.T, b backend) {
+	_, store := newTestRouter(t, b)
+	issuer := oidctest.NewIssuer(t, "issue-tracking")
+	cfg := config.Default()
+	cfg.OIDC.Enabled = true
+	cfg.OIDC.IssuerURL = issuer.URL
+	cfg.OIDC.ClientID = "issue-tracking"
+	cfg.OIDC.RedirectURL = "http://api.test/auth/callback"
+	cfg.OIDC.AdminGroups = []string{"it-admins"}
+	cfg.OIDC.OfficerGroups = []string{"helpdesk"}
+	cfg.OIDC.CookieSecret = strings.Repeat("s", 32)
+	router := gin.New()
+	routes.RegisterRoutes(router, store, services.NewIssueService(store), cfg)
+
+	issuer.SignInAs(oidctest.Account{Subject: "u-1", Name: "Carol White", Email: "carol@example.com", Groups: []string{"helpdesk"}})
+	login := serve(router, http.MethodGet, "/auth/login", "", nil)
+	if login.Code != http.StatusFound {
+		t.Fatalf("GET /auth/login = %d: %s", login.Code, login.Body)
+	}
+	cookie := login.Result().Cookies()[0]
+	if !cookie.HttpOnly || cookie.Path != "/auth" {
+		t.Errorf("sign-in cookie = %+v", cookie)
+	}
+
+	// The provider signs Carol in and sends her browser back
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(login.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	back, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || back.Path != "/auth/callback" {
+		t.Fatalf("provider redirected to %q", resp.Header.Get("Location"))
+	}
+	callback := back.RequestURI()
+
+	if w := serve(router, http.MethodGet, callback, "", nil); w.Code != 400 {
+		t.Errorf("callback without the cookie = %d, want 400", w.Code)
+	}
+	forged := strings.Replace(callback, "state=", "state=x", 1)
+	if w := serve(router, http.MethodGet, forged, "", http.Header{"Cookie": {cookie.String()}}); w.Code != 400 {
+		t.Errorf("callback with another state = %d, want 400", w.Code)
+	}
+
+	// The forged callback did not use up the code
+	w := serve(router, http.MethodGet, callback, "", http.Header{"Cookie": {cookie.String()}})
+	if w.Code != 200 {
+		t.Fatalf("callback = %d: %s", w.Code, w.Body)
+	}
+	var signIn services.SignIn
+	decode(t, w, &signIn)
+	if signIn.Identity.Role != entities.RoleOfficer || signIn.Identity.OfficerID == nil || signIn.Identity.Name != "Carol White" {
+		t.Fatalf("signed in as %+v", signIn.Identity)
+	}
+	officer := http.Header{"Authorization": {"Bearer " + signIn.IDToken}}
+
+	wantBody(`"subject":"u-1"`, `"role":"officer"`)(t, router, serve(router, http.MethodGet, "/api/me", "", officer))
+	if w := serve(router, http.MethodGet, "/api/officers", "", nil); !strings.Contains(w.Body.String(), `"full_name":"Carol White"`) {
+		t.Errorf("officer was not provisioned: %s", w.Body)
+	}
+	w = serve(router, http.MethodPatch, "/api/issues/2/status", `{"new_status_id":1}`, officer)
+	wantBody(`"changed_by":`+strconv.Itoa(int(*signIn.Identity.OfficerID)))(t, router, serve(router, http.MethodGet, "/api/issues/2", "", officer))
+	if w.Code != 200 {
+		t.Errorf("change status as officer = %d: %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/reports/backlog", "", officer); w.Code != 200 {
+		t.Errorf("report as officer = %d, want 200", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "/api/admin/api-keys", "", officer); w.Code != 403 {
+		t.Errorf("admin route as officer = %d, want 403", w.Code)
+	}
+
+	user := http.Header{"Authorization": {"Bearer " + issuer.IDToken(oidctest.Account{Subject: "u-2", Name: "Dan Green"}, "")}}
+	if w := serve(router, http.MethodGet, "/api/reports/backlog", "", user); w.Code != 403 || !strings.Contains(w.Body.String(), "the user role") {
+		t.Errorf("report as user = %d, want 403: %s", w.Code, w.Body)
+	}
+	// Without a token the report is no more open than to the user role
+	if w := serve(router, http.MethodGet, "/api/reports/backlog", "", nil); w.Code != 401 || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("anonymous report with OIDC = %d, want 401: %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/issues", "", nil); w.Code != 401 {
+		t.Errorf("anonymous list issues with OIDC = %d, want 401", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "/api/issues", "", user); w.Code != 200 {
+		t.Errorf("list issues as user = %d, want 200", w.Code)
+	}
+
+	admin := http.Header{"Authorization": {"Bearer " + issuer.IDToken(oidctest.Account{Subject: "u-3", Name: "Eve Black", Groups: []string{"it-admins"}}, "")}}
+	if w := serve(router, http.MethodGet, "/api/admin/api-keys", "", admin); w.Code != 200 {
+		t.Errorf("admin route as admin = %d, want 200: %s", w.Code, w.Body)
+	}
+
+	expired := issuer.Sign(map[string]interface{}{"iss": issuer.URL, "sub": "u-1", "aud": "issue-tracking", "exp": time.Now().Add(-time.Hour).Unix()})
+	w = serve(router, http.MethodGet, "/api/issues", "", http.Header{"Authorization": {"Bearer " + expired}})
+	if w.Code != 401 || !strings.Contains(w.Header().Get("WWW-Authenticate"), "invalid_token") {
+		t.Errorf("expired token = %d, WWW-Authenticate %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+	if w := serve(router, http.MethodGet, "/api/me", "", nil); w.Code != 401 {
+		t.Errorf("GET /api/me without a token = %d, want 401", w.Code)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(serve(router, http.MethodGet, "/openapi.json", "", nil).Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Errorf("invalid document: %v", err)
+	}
+	if doc.Paths.Find("/auth/callback") == nil || doc.Paths.Find("/api/me") == nil {
+		t.Error("sign-in routes are not documented")
+	}
+}
+
+func TestPortal(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) { testPortal(t, b) })
+	}
+}
+
+// testPortal reports an issue on the portal behind a captcha and follows it
+// with its tracking token while officers work on it
+func testPortal(t *testing.T, b backend) {
+	_, store := newTestRouter(t, b)
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		json.NewEncoder(w).Encode(map[string]bool{"success": r.PostForm.Get("response") == "solved"})
+	}))
+	defer provider.Close()
+	cfg := config.Default()
+	cfg.Portal.Enabled = true
+	cfg.Portal.Captcha = config.CaptchaConfig{Provider: "turnstile", Secret: "site-secret", VerifyURL: provider.URL}
+	cfg.Portal.BlockedTerms = []string{"casino"}
+	router := gin.New()
+	routes.RegisterRoutes(router, store, services.NewIssueService(store), cfg)
+
+	report := `{"name":"Maria Lopez","phone":"555-0100","title":"Streetlight out","captcha":"%s"}`
+	if w := serve(router, http.MethodPost, "/api/public/issues", fmt.Sprintf(report, "guessed"), nil); w.Code != 400 || !strings.Contains(w.Body.String(), "Captcha verification failed") {
+		t.Fatalf("report with unsolved captcha = %d: %s", w.Code, w.Body)
+	}
+	w := serve(router, http.MethodPost, "/api/public/issues", fmt.Sprintf(report, "solved"), nil)
+	if w.Code != 201 {
+		t.Fatalf("report = %d: %s", w.Code, w.Body)
+	}
+	var tracked services.TrackedIssue
+	decode(t, w, &tracked)
+
+	// Staff see the reporter and how the issue came in
+	wantBody(`"source":"portal"`, `"full_name":"Maria Lopez"`, `"phone":"555-0100"`)(t, router,
+		serve(router, http.MethodGet, "/api/issues/"+strconv.Itoa(int(tracked.Issue.Reference)), "", nil))
+
+	token := http.Header{"X-Tracking-Token": {tracked.TrackingToken}}
+	comment := `{"content":"%s","captcha":"solved"}`
+	if w := serve(router, http.MethodPost, "/api/public/tracking/comments", fmt.Sprintf(comment, "It is near the school"), token); w.Code != 201 {
+		t.Fatalf("comment = %d: %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodPost, "/api/public/tracking/comments", fmt.Sprintf(comment, "Visit our CASINO"), token); w.Code != 422 {
+		t.Errorf("comment with a blocked term = %d, want 422", w.Code)
+	}
+	path := "/api/issues/" + strconv.Itoa(int(tracked.Issue.Reference))
+	serve(router, http.MethodPost, path+"/comment", `{"user_id":1,"content":"Crew sent"}`, nil)
+	serve(router, http.MethodPatch, path+"/status", `{"new_status_id":3}`, nil)
+
+	w = serve(router, http.MethodGet, "/api/public/tracking", "", token)
+	if w.Code != 200 {
+		t.Fatalf("follow = %d: %s", w.Code, w.Body)
+	}
+	var issue services.PublicIssue
+	decode(t, w, &issue)
+	if !issue.Status.Closed || len(issue.Comments) != 2 || !issue.Comments[0].FromReporter || issue.Comments[1].FromReporter {
+		t.Errorf("followed issue = %+v", issue)
+	}
+	if strings.Contains(w.Body.String(), "555-0100") || strings.Contains(w.Body.String(), "John Doe") {
+		t.Errorf("public issue shows contact details or staff names: %s", w.Body)
+	}
+
+	for _, wrong := range []string{tracked.TrackingToken + "x", "itk_" + tracked.TrackingToken[4:]} {
+		if w := serve(router, http.MethodGet, "/api/public/tracking", "", http.Header{"X-Tracking-Token": {wrong}}); w.Code != 404 {
+			t.Errorf("follow with token %q = %d, want 404", wrong, w.Code)
+		}
+	}
+
+	// Reports are not stored for replay, which would keep the token in
+	// plain text
+	retried := http.Header{"Idempotency-Key": {"report-1"}}
+	for i := 0; i < 2; i++ {
+		w := serve(router, http.MethodPost, "/api/public/issues", fmt.Sprintf(report, "solved"), retried)
+		if w.Code != 201 || w.Header().Get("Idempotent-Replayed") != "" {
+			t.Errorf("report %d with an Idempotency-Key = %d %v", i+1, w.Code, w.Header())
+		}
+	}
+	if count, err := store.IdempotencyKeys().DeleteExpired(context.Background(), time.Now().Add(48*time.Hour)); err != nil || count != 0 {
+		t.Errorf("stored %d responses to reports (%v)", count, err)
+	}
+}
+
+func TestCSAT(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) { testCSAT(t, b) })
+	}
+}
+
+// testCSAT closes issues, answers the survey of one through its signed link
+// and checks it is only answered once and shows up in the CSAT report
+// TestSurveysSentOnce closes one issue from many requests at once and
+// others in bulk: each issue that closes gets exactly one survey
+func TestSurveysSentOnce(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) { testSurveysSentOnce(t, b) })
+	}
+}
+
+func testSurveysSentOnce(t *testing.T, b backend) {
+	_, store := newTestRouter(t, b)
+	issues := services.NewIssueService(store)
+	issues.SendSurveys(time.Hour)
+	router := gin.New()
+	routes.RegisterRoutes(router, store, issues, config.Default())
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serve(router, http.MethodPatch, "/api/issues/2/status", `{"new_status_id":3}`, nil)
+		}()
+	}
+	wg.Wait()
+	// Issue 3 is closed already
+	w := serve(router, http.MethodPost, "/api/issues/bulk", `{"issue_ids":[1,3],"operation":"change_status","status_id":3}`, nil)
+	wantBody(`"succeeded":2`)(t, router, w)
+
+	surveys, err := store.Surveys().ListSent(context.Background(), time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(surveys) != 2 || surveys[0].IssueID != 2 || surveys[1].IssueID != 1 || surveys[1].OfficerID == nil || *surveys[1].OfficerID != 1 {
+		t.Errorf("surveys = %+v, want one for issue 2 and one for issue 1", surveys)
+	}
+}
+
+func testCSAT(t *testing.T, b backend) {
+	_, store := newTestRouter(t, b)
+	cfg := config.Default()
+	cfg.Portal.Enabled = true
+	cfg.CSAT = config.CSATConfig{
+		Enabled:   true,
+		SurveyURL: "https://support.example.com/survey",
+		Secret:    "0123456789abcdef0123456789abcdef",
+		LinkTTL:   config.Duration{Duration: 24 * time.Hour},
+	}
+	router := gin.New()
+	routes.RegisterRoutes(router, store, services.NewIssueService(store), cfg)
+	admin, err := services.NewAPIKeyService(store).Create(context.Background(), services.NewAPIKey{Name: "Admin", Scopes: []string{auth.ScopeAdmin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminHeader := http.Header{"Authorization": {"Bearer " + admin.Key}}
+
+	w := serve(router, http.MethodPost, "/api/public/issues", `{"name":"Maria Lopez","email":"maria@example.com","title":"Streetlight out"}`, nil)
+	if w.Code != 201 {
+		t.Fatalf("report = %d: %s", w.Code, w.Body)
+	}
+	var tracked services.TrackedIssue
+	decode(t, w, &tracked)
+	token := http.Header{"X-Tracking-Token": {tracked.TrackingToken}}
+	path := "/api/issues/" + strconv.Itoa(int(tracked.Issue.Reference))
+
+	// Closing sends a survey; moving between closed statuses or to an open
+	// one does not
+	serve(router, http.MethodPatch, path+"/status", `{"new_status_id":2,"assignee_id":2}`, nil)
+	if w := serve(router, http.MethodPatch, path+"/status", `{"new_status_id":3}`, nil); w.Code != 200 {
+		t.Fatalf("close = %d: %s", w.Code, w.Body)
+	}
+	serve(router, http.MethodPatch, "/api/issues/3/status", `{"new_status_id":3}`, nil)
+	serve(router, http.MethodPatch, "/api/issues/2/status", `{"new_status_id":1}`, nil)
+
+	w = serve(router, http.MethodGet, "/api/admin/surveys", "", adminHeader)
+	if w.Code != 200 {
+		t.Fatalf("pending surveys = %d: %s", w.Code, w.Body)
+	}
+	var pending []services.PendingSurvey
+	decode(t, w, &pending)
+	if len(pending) != 1 || pending[0].IssueID != tracked.Issue.Reference || pending[0].OfficerID == nil || *pending[0].OfficerID != 2 ||
+		pending[0].Reporter.Email != "maria@example.com" {
+		t.Fatalf("pending surveys = %+v", pending)
+	}
+
+	// The reporter finds the link on the portal too
+	var issue services.PublicIssue
+	decode(t, serve(router, http.MethodGet, "/api/public/tracking", "", token), &issue)
+	if issue.SurveyURL != pending[0].Link {
+		t.Fatalf("survey_url = %q, want %q", issue.SurveyURL, pending[0].Link)
+	}
+	link, err := url.Parse(issue.SurveyURL)
+	if err != nil || link.Host != "support.example.com" {
+		t.Fatalf("survey link %q", issue.SurveyURL)
+	}
+	surveyPath := "/api/surveys/" + link.Query().Get("survey")
+	signature := "?signature=" + url.QueryEscape(link.Query().Get("signature"))
+
+	if w := serve(router, http.MethodGet, surveyPath+"?signature=forged", "", nil); w.Code != 404 {
+		t.Errorf("survey with a forged signature = %d, want 404", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "/api/surveys/99"+signature, "", nil); w.Code != 404 {
+		t.Errorf("other survey with the signature = %d, want 404", w.Code)
+	}
+	wantBody(`"title":"Streetlight out"`, `"answered":false`)(t, router, serve(router, http.MethodGet, surveyPath+signature, "", nil))
+
+	if w := serve(router, http.MethodPost, surveyPath+signature, `{"rating":6}`, nil); w.Code != 400 {
+		t.Errorf("rating 6 = %d, want 400", w.Code)
+	}
+	w = serve(router, http.MethodPost, surveyPath+signature, `{"rating":5,"feedback":"Fixed the same day"}`, nil)
+	if w.Code != 200 {
+		t.Fatalf("answer = %d: %s", w.Code, w.Body)
+	}
+	wantBody(`"answered":true`, `"rating":5`)(t, router, w)
+	if w := serve(router, http.MethodPost, surveyPath+signature, `{"rating":1}`, nil); w.Code != 409 {
+		t.Errorf("second answer = %d, want 409", w.Code)
+	}
+	issue = services.PublicIssue{}
+	decode(t, serve(router, http.MethodGet, "/api/public/tracking", "", token), &issue)
+	if issue.SurveyURL != "" {
+		t.Errorf("answered survey still linked: %q", issue.SurveyURL)
+	}
+
+	// Issue 1 closes without an answer, and a survey expires
+	serve(router, http.MethodPatch, "/api/issues/1/status", `{"new_status_id":3}`, nil)
+	expired := entities.Survey{IssueID: 3, ExpiresAt: time.Now().Add(-time.Hour)}
+	if err := store.Surveys().Create(context.Background(), &expired); err != nil {
+		t.Fatal(err)
+	}
+	link, _ = url.Parse(services.NewSurveyService(store, cfg.CSAT.Secret, cfg.CSAT.SurveyURL).Link(expired.SurveyID))
+	if w := serve(router, http.MethodPost, "/api/surveys/"+link.Query().Get("survey")+"?"+link.RawQuery, `{"rating":3}`, nil); w.Code != 410 {
+		t.Errorf("answer to an expired survey = %d, want 410: %s", w.Code, w.Body)
+	}
+
+	w = serve(router, http.MethodGet, "/api/reports/csat", "", nil)
+	if w.Code != 200 {
+		t.Fatalf("CSAT report = %d: %s", w.Code, w.Body)
+	}
+	var report struct {
+		Report analytics.CSATReport `json:"report"`
+	}
+	decode(t, w, &report)
+	overall := report.Report.Overall
+	if overall.Sent != 3 || overall.Answered != 1 || overall.CSAT != 100 || overall.AverageRating != 5 {
+		t.Errorf("overall = %+v", overall)
+	}
+	officers := report.Report.Officers
+	if len(officers) != 3 || officers[1].Label != "Bob Brown" || officers[1].CSAT != 100 || officers[0].Answered != 0 {
+		t.Errorf("officers = %+v", officers)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(serve(router, http.MethodGet, "/openapi.json", "", nil).Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Errorf("invalid document: %v", err)
+	}
+	if doc.Paths.Find("/api/surveys/{id}") == nil || doc.Paths.Find("/api/reports/csat") == nil {
+		t.Error("survey routes are not documented")
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"issue-tracking/seeds"
+)
+
+// runSeedCommand implements `issue-tracking seed [-file FILE]`
+func runSeedCommand(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	file := fs.String("file", "", "YAML or JSON seed file (default: the built-in statuses, labels, users and officers)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: issue-tracking seed [flags]")
+		fs.PrintDefaults()
+	}
+	cfg := loadConfig(fs, args)
+
+	// Read the file before connecting so mistakes are reported quickly
+	data, err := seeds.Default()
+	if *file != "" {
+		data, err = seeds.ReadFile(*file)
+	}
+	if err != nil {
+		return err
+	}
+
+	db, shutdown, err := setup(cfg, os.Stderr, cfg.Database.AutoMigrate)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	result, err := seeds.Apply(db, data)
+	if err != nil {
+		return err
+	}
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	return nil
+}
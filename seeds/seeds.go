@@ -0,0 +1,199 @@
+// Package seeds loads reference data (statuses, labels, users and officers)
+// from a YAML or JSON file. Seeding is idempotent: existing rows are matched
+// by their natural key and left untouched.
+package seeds
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+
+	"issue-tracking/entities"
+	"issue-tracking/utils"
+
+	"github.com/goccy/go-yaml"
+	"gorm.io/gorm"
+)
+
+//go:embed default.yaml
+var defaultData []byte
+
+// Status is a seeded issue status, matched by code
+type Status struct {
+	StatusCode   string `yaml:"status_code"`
+	DisplayName  string `yaml:"display_name"`
+	Description  string `yaml:"description"`
+	Color        string `yaml:"color"`
+	DisplayOrder int    `yaml:"display_order"`
+	Terminal     bool   `yaml:"terminal"`
+}
+
+// Label is a seeded label, matched by name
+type Label struct {
+	Name  string `yaml:"name"`
+	Color string `yaml:"color"`
+}
+
+// Person is a seeded user or officer, matched by full name
+type Person struct {
+	FullName string `yaml:"full_name"`
+}
+
+// Data is the content of a seed file
+type Data struct {
+	Statuses []Status `yaml:"statuses"`
+	Labels   []Label  `yaml:"labels"`
+	Users    []Person `yaml:"users"`
+	Officers []Person `yaml:"officers"`
+}
+
+// Counts reports how many rows of one kind were created or already present
+type Counts struct {
+	Created  int `json:"created"`
+	Existing int `json:"existing"`
+}
+
+// Result reports what a seed run did
+type Result struct {
+	Statuses Counts `json:"statuses"`
+	Labels   Counts `json:"labels"`
+	Users    Counts `json:"users"`
+	Officers Counts `json:"officers"`
+}
+
+// Default returns the seed data embedded in the binary
+func Default() (*Data, error) {
+	return Parse(defaultData)
+}
+
+// ReadFile reads and validates a seed file
+func ReadFile(path string) (*Data, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
+
+// Parse decodes YAML or JSON seed data, rejecting unknown keys, and checks
+// every row against the entity validation rules
+func Parse(content []byte) (*Data, error) {
+	var data Data
+	if err := yaml.UnmarshalWithOptions(content, &data, yaml.Strict()); err != nil {
+		return nil, err
+	}
+
+	statusCodes := map[string]bool{}
+	for i, s := range data.Statuses {
+		if statusCodes[s.StatusCode] {
+			return nil, fmt.Errorf("statuses[%d]: duplicate status_code %q", i, s.StatusCode)
+		}
+		statusCodes[s.StatusCode] = true
+		if err := validate(s.entity()); err != nil {
+			return nil, fmt.Errorf("statuses[%d]: %w", i, err)
+		}
+	}
+	for i, l := range data.Labels {
+		if err := validate(l.entity()); err != nil {
+			return nil, fmt.Errorf("labels[%d]: %w", i, err)
+		}
+	}
+	for i, p := range data.Users {
+		if err := validate(entities.User{FullName: p.FullName}); err != nil {
+			return nil, fmt.Errorf("users[%d]: %w", i, err)
+		}
+	}
+	for i, p := range data.Officers {
+		if err := validate(entities.Officer{FullName: p.FullName}); err != nil {
+			return nil, fmt.Errorf("officers[%d]: %w", i, err)
+		}
+	}
+	return &data, nil
+}
+
+// Apply inserts every row that does not exist yet in one transaction
+func Apply(db *gorm.DB, data *Data) (*Result, error) {
+	var result Result
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, s := range data.Statuses {
+			status := s.entity()
+			if err := insertMissing(tx, &result.Statuses, &status, "status_code = ?", s.StatusCode); err != nil {
+				return fmt.Errorf("seed status %s: %w", s.StatusCode, err)
+			}
+		}
+		for _, l := range data.Labels {
+			label := l.entity()
+			if err := insertMissing(tx, &result.Labels, &label, "name = ?", l.Name); err != nil {
+				return fmt.Errorf("seed label %s: %w", l.Name, err)
+			}
+		}
+		for _, p := range data.Users {
+			user := entities.User{FullName: p.FullName}
+			if err := insertMissing(tx, &result.Users, &user, "full_name = ? AND deleted_at IS NULL", p.FullName); err != nil {
+				return fmt.Errorf("seed user %s: %w", p.FullName, err)
+			}
+		}
+		for _, p := range data.Officers {
+			officer := entities.Officer{FullName: p.FullName}
+			if err := insertMissing(tx, &result.Officers, &officer, "full_name = ? AND deleted_at IS NULL", p.FullName); err != nil {
+				return fmt.Errorf("seed officer %s: %w", p.FullName, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// insertMissing creates value unless a row matching the query exists and
+// records the outcome in c
+func insertMissing(tx *gorm.DB, c *Counts, value interface{}, query string, args ...interface{}) error {
+	var existing int64
+	if err := tx.Model(value).Where(query, args...).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		c.Existing++
+		return nil
+	}
+	if err := tx.Create(value).Error; err != nil {
+		return err
+	}
+	c.Created++
+	return nil
+}
+
+func (s Status) entity() entities.IssueStatus {
+	return entities.IssueStatus{
+		StatusCode:   s.StatusCode,
+		DisplayName:  s.DisplayName,
+		Description:  s.Description,
+		Color:        s.Color,
+		DisplayOrder: s.DisplayOrder,
+		IsActive:     true,
+		IsTerminal:   s.Terminal,
+	}
+}
+
+func (l Label) entity() entities.Label {
+	label := entities.Label{Name: l.Name, Color: l.Color}
+	if label.Color == "" {
+		label.Color = "#808080"
+	}
+	return label
+}
+
+// validate applies the entity's validate tags
+func validate(entity interface{}) error {
+	if errs := utils.ValidateStruct(entity); len(errs) > 0 {
+		return errors.New(errs[0].Message)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package seeds
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultSeedIsValid(t *testing.T) {
+	data, err := Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Statuses) == 0 || len(data.Users) == 0 || len(data.Officers) == 0 {
+		t.Fatalf("default seed is missing rows: %+v", data)
+	}
+	terminal := 0
+	for _, s := range data.Statuses {
+		if s.Terminal {
+			terminal++
+		}
+	}
+	if terminal == 0 {
+		t.Error("default seed needs a terminal status so issues can be closed")
+	}
+}
+
+func TestParseRejectsInvalidSeeds(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown key", "statuses:\n  - code: open\n", "unknown field"},
+		{"bad color", "statuses:\n  - {status_code: open, display_name: Open, color: red}\n", "statuses[0]: Color"},
+		{"duplicate status", "statuses:\n  - {status_code: open, display_name: Open, color: \"#FF0000\"}\n  - {status_code: open, display_name: Again, color: \"#FF0000\"}\n", "duplicate"},
+		{"short name", "officers:\n  - full_name: J\n", "officers[0]: FullName"},
+		{"json", `{"labels": [{"name": ""}]}`, "labels[0]: Name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"issue-tracking/auth"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/utils"
+)
+
+// lastUsedResolution is how stale the last use of a key may be before it is
+// written again, so busy integrations do not write on every request
+const lastUsedResolution = time.Minute
+
+// NewAPIKey describes a key to create
+type NewAPIKey struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// CreatedAPIKey is a new key together with the key itself, which is only
+// ever returned here
+type CreatedAPIKey struct {
+	entities.APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyService creates, revokes and authenticates the API keys of
+// integrations
+type APIKeyService struct {
+	store repositories.Store
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(store repositories.Store) *APIKeyService {
+	return &APIKeyService{store: store}
+}
+
+// List returns every key, revoked and expired ones included
+func (s *APIKeyService) List(ctx context.Context) ([]entities.APIKey, error) {
+	keys, err := s.store.APIKeys().List(ctx)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch API keys", nil)
+	}
+	if keys == nil {
+		keys = []entities.APIKey{}
+	}
+	return keys, nil
+}
+
+// Create validates and stores a new key and returns it with the key itself
+func (s *APIKeyService) Create(ctx context.Context, req NewAPIKey) (*CreatedAPIKey, error) {
+	record := entities.APIKey{Name: strings.TrimSpace(req.Name), Scopes: req.Scopes, ExpiresAt: req.ExpiresAt}
+	fields := utils.ValidateStruct(record)
+	for _, scope := range req.Scopes {
+		if !auth.KnownScope(scope) {
+			fields = append(fields, utils.ValidationError{
+				Field:   "Scopes",
+				Message: fmt.Sprintf("Scopes must be one of: %s", strings.Join(auth.Scopes, " ")),
+			})
+			break
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		fields = append(fields, utils.ValidationError{Field: "ExpiresAt", Message: "ExpiresAt must be in the future"})
+	}
+	if len(fields) > 0 {
+		return nil, validationError(fields)
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to generate API key", nil)
+	}
+	record.Prefix = prefix
+	record.KeyHash = hash
+	if err := s.store.APIKeys().Create(ctx, &record); err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to create API key", err.Error())
+	}
+	return &CreatedAPIKey{APIKey: record, Key: key}, nil
+}
+
+// Revoke stops a key from being used and returns it
+func (s *APIKeyService) Revoke(ctx context.Context, id uint) (*entities.APIKey, error) {
+	if err := s.store.APIKeys().Revoke(ctx, id, time.Now()); err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to revoke API key", err.Error())
+	}
+	key, err := s.store.APIKeys().Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, newError(http.StatusNotFound, "API key not found", nil)
+		}
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch API key", nil)
+	}
+	return key, nil
+}
+
+// Authenticate returns the active key matching key and records its use.
+// Unknown, expired and revoked keys are rejected with 401.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*entities.APIKey, error) {
+	invalid := newError(http.StatusUnauthorized, "Invalid API key", nil)
+	prefix, ok := auth.APIKeyPrefixOf(key)
+	if !ok {
+		return nil, invalid
+	}
+	record, err := s.store.APIKeys().FindByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, invalid
+		}
+		return nil, newError(http.StatusInternalServerError, "Failed to check API key", nil)
+	}
+	if !auth.MatchAPIKey(key, record.KeyHash) {
+		return nil, invalid
+	}
+
+	now := time.Now()
+	switch {
+	case record.RevokedAt != nil:
+		return nil, newError(http.StatusUnauthorized, "Invalid API key", "the API key was revoked")
+	case !record.Active(now):
+		return nil, newError(http.StatusUnauthorized, "Invalid API key", "the API key expired")
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= lastUsedResolution {
+		// A failed write only loses the last use, not the request
+		if err := s.store.APIKeys().Touch(ctx, record.APIKeyID, now); err != nil {
+			slog.WarnContext(ctx, "failed to record API key use", slog.String("prefix", record.Prefix), slog.Any("error", err))
+		} else {
+			record.LastUsedAt = &now
+		}
+	}
+	return record, nil
+}
+
+// authorize rejects callers whose API key or role was not granted scope,
+// and anonymous callers when anonymous callers are not granted it
+func authorize(ctx context.Context, scope string) error {
+	if auth.Allowed(ctx, scope) {
+		return nil
+	}
+	if auth.Anonymous(ctx) {
+		return newError(http.StatusUnauthorized, "Unauthorized", auth.Denial(ctx, scope))
+	}
+	return newError(http.StatusForbidden, "Forbidden", auth.Denial(ctx, scope))
+}
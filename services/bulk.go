@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"issue-tracking/auth"
+	"issue-tracking/entities"
+	"issue-tracking/importer"
+	"issue-tracking/repositories"
+)
+
+// ErrRolledBack is reported for the issues of an atomic bulk change that
+// were undone or skipped because another issue failed
+var ErrRolledBack = errors.New("rolled back")
+
+// BulkChange applies one change to many issues and returns the outcome for
+// each, in order. When atomic is set the first failure undoes every change
+// and the other issues report ErrRolledBack; otherwise each issue is
+// changed on its own. A label without an ID is found or created by name,
+// within the transaction when atomic. Issues it closes get a survey like
+// single changes.
+// Events are published for the changes that were kept.
+func (s *IssueService) BulkChange(ctx context.Context, ids []uint, change repositories.IssueChange, atomic bool) ([]error, error) {
+	if err := authorize(ctx, auth.ScopeIssuesWrite); err != nil {
+		return nil, err
+	}
+	change.SurveyTTL = s.surveyTTL
+
+	errs := make([]error, len(ids))
+	if !atomic {
+		if err := resolveLabel(ctx, s.store, &change); err != nil {
+			return nil, err
+		}
+		for i, id := range ids {
+			errs[i] = s.store.Issues().ApplyChange(ctx, id, change)
+		}
+	} else {
+		err := s.store.Transaction(ctx, func(tx repositories.Store) error {
+			// A new label is rolled back with the changes
+			if err := resolveLabel(ctx, tx, &change); err != nil {
+				return err
+			}
+			for i, id := range ids {
+				// Savepoint keeps the transaction usable after a failed item
+				errs[i] = tx.Transaction(ctx, func(itemTx repositories.Store) error {
+					return itemTx.Issues().ApplyChange(ctx, id, change)
+				})
+				if errs[i] != nil {
+					return ErrRolledBack
+				}
+			}
+			return nil
+		})
+		if errors.Is(err, ErrRolledBack) {
+			for i := range errs {
+				if errs[i] == nil {
+					errs[i] = ErrRolledBack
+				}
+			}
+			return errs, nil
+		}
+		if err != nil {
+			return nil, newError(http.StatusInternalServerError, "Bulk operation failed", err.Error())
+		}
+	}
+
+	var changed []uint
+	for i, id := range ids {
+		if errs[i] == nil {
+			changed = append(changed, id)
+		}
+	}
+	typ := EventIssueUpdated
+	if change.NewStatusID != nil {
+		typ = EventIssueStatusChanged
+	}
+	s.publishIssues(ctx, typ, changed)
+	return errs, nil
+}
+
+// resolveLabel finds or creates the label of change by name in store when
+// it has no ID yet
+func resolveLabel(ctx context.Context, store repositories.Store, change *repositories.IssueChange) error {
+	if change.Label == nil || change.Label.LabelID != 0 {
+		return nil
+	}
+	label := *change.Label
+	if err := store.Labels().FindOrCreate(ctx, &label); err != nil {
+		return newError(http.StatusInternalServerError, "Failed to resolve label", err.Error())
+	}
+	change.Label = &label
+	return nil
+}
+
+// Import reads issues from r as described by opts and publishes a created
+// event for every issue committed. Errors about the request are *Error;
+// failures while importing come from the importer with a partial result.
+func (s *IssueService) Import(ctx context.Context, r io.Reader, opts importer.Options) (*importer.Result, error) {
+	if err := authorize(ctx, auth.ScopeIssuesWrite); err != nil {
+		return nil, err
+	}
+	opts.Committed = func(issues []entities.Issue) {
+		ids := make([]uint, len(issues))
+		for i, issue := range issues {
+			ids[i] = issue.IssueID
+		}
+		s.publishIssues(ctx, EventIssueCreated, ids)
+	}
+	im, err := importer.New(s.store, opts)
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, "Invalid format", err.Error())
+	}
+	return im.Run(ctx, r)
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"issue-tracking/entities"
+)
+
+// EventType is what happened to an issue
+type EventType string
+
+// Event types
+const (
+	EventIssueCreated       EventType = "issue.created"
+	EventIssueStatusChanged EventType = "issue.status_changed"
+	EventIssueCommented     EventType = "issue.commented"
+	// EventIssueUpdated is any other change, such as a new priority or
+	// assignee
+	EventIssueUpdated EventType = "issue.updated"
+	EventIssueDeleted EventType = "issue.deleted"
+)
+
+// eventBuffer is how many events a subscriber may fall behind by before it
+// is dropped. It holds a whole bulk request or default import batch, which
+// publish one event per issue at once.
+const eventBuffer = 1024
+
+// Event is a change made through the issue service
+type Event struct {
+	Type       EventType
+	IssueID    uint
+	OccurredAt time.Time
+	// Issue is the issue after the change, or before it for
+	// EventIssueDeleted. Its relations are loaded except for
+	// EventIssueCommented.
+	Issue *entities.Issue
+	// Comment is set for EventIssueCommented
+	Comment *entities.Comment
+}
+
+// Events fans the events of an issue service out to subscribers. Publishing
+// never blocks: a subscriber whose buffer is full is dropped and its channel
+// closed, so it must subscribe again and reload what it missed.
+type Events struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+// NewEvents creates an event broker without subscribers
+func NewEvents() *Events {
+	return &Events{subs: map[chan Event]struct{}{}}
+}
+
+// Subscribe returns a channel receiving the events published from now on
+// and a function that ends the subscription
+func (e *Events) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBuffer)
+	e.mu.Lock()
+	e.subs[ch] = struct{}{}
+	e.mu.Unlock()
+
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if _, ok := e.subs[ch]; ok {
+			delete(e.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// watched reports whether anyone is subscribed, so events that are costly
+// to build can be skipped
+func (e *Events) watched() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.subs) > 0
+}
+
+// publish sends event to every subscriber
+func (e *Events) publish(event Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subs {
+		select {
+		case ch <- event:
+		default:
+			delete(e.subs, ch)
+			close(ch)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"issue-tracking/auth"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+)
+
+const (
+	// maxNameLength is the length of the name columns of users, officers
+	// and identities
+	maxNameLength = 255
+
+	// signInTimeout is how long a person has to sign in at the provider
+	signInTimeout = 10 * time.Minute
+)
+
+// IdentityService signs people in with ID tokens of the OpenID Connect
+// provider. The user and, for officers and admins, the officer a person
+// acts as are created the first time they sign in.
+type IdentityService struct {
+	store    repositories.Store
+	provider *auth.OIDCProvider
+}
+
+// NewIdentityService creates a new identity service
+func NewIdentityService(store repositories.Store, provider *auth.OIDCProvider) *IdentityService {
+	return &IdentityService{store: store, provider: provider}
+}
+
+// SignIn is a finished sign-in: the ID token to send as a bearer token and
+// the identity it signs in as
+type SignIn struct {
+	IDToken   string            `json:"id_token"`
+	TokenType string            `json:"token_type"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	Identity  entities.Identity `json:"identity"`
+}
+
+// StartSignIn returns the URL of the provider's sign-in page and the state
+// to keep until the provider redirects back
+func (s *IdentityService) StartSignIn(ctx context.Context) (string, *auth.LoginState, error) {
+	state := auth.LoginState{Expires: time.Now().Add(signInTimeout)}
+	for _, value := range []*string{&state.State, &state.Nonce, &state.Verifier} {
+		token, err := auth.RandomToken()
+		if err != nil {
+			return "", nil, newError(http.StatusInternalServerError, "Failed to start sign-in", nil)
+		}
+		*value = token
+	}
+	url, err := s.provider.AuthCodeURL(ctx, state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to start sign-in", slog.Any("error", err))
+		return "", nil, newError(http.StatusBadGateway, "Identity provider unavailable", nil)
+	}
+	return url, &state, nil
+}
+
+// FinishSignIn trades the code the provider redirected back with for an ID
+// token, checks that it belongs to the sign-in of state and records the
+// sign-in
+func (s *IdentityService) FinishSignIn(ctx context.Context, state *auth.LoginState, code string) (*SignIn, error) {
+	token, err := s.provider.Exchange(ctx, code, state.Verifier)
+	if err != nil {
+		return nil, newError(http.StatusUnauthorized, "Sign-in failed", err.Error())
+	}
+	claims, err := s.verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Nonce != state.Nonce {
+		return nil, newError(http.StatusUnauthorized, "Invalid ID token", "the nonce does not match the sign-in")
+	}
+	identity, err := s.provision(ctx, claims, true)
+	if err != nil {
+		return nil, err
+	}
+	return &SignIn{IDToken: token, TokenType: "Bearer", ExpiresAt: claims.ExpiresAt, Identity: *identity}, nil
+}
+
+// Authenticate returns the identity of the ID token sent with a request.
+// Tokens the provider handed to other apps of the same client are accepted
+// too, so their holders are provisioned like at sign-in.
+func (s *IdentityService) Authenticate(ctx context.Context, token string) (*entities.Identity, error) {
+	claims, err := s.verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.provision(ctx, claims, false)
+}
+
+// verify checks an ID token, rejecting bad tokens with 401
+func (s *IdentityService) verify(ctx context.Context, token string) (*auth.Claims, error) {
+	claims, err := s.provider.Verify(ctx, token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, newError(http.StatusUnauthorized, "Invalid ID token", err.Error())
+		}
+		slog.ErrorContext(ctx, "failed to verify ID token", slog.Any("error", err))
+		return nil, newError(http.StatusBadGateway, "Identity provider unavailable", nil)
+	}
+	return claims, nil
+}
+
+// provision returns the identity of claims, creating it with its user and
+// officer when it is new and following changes of name, email and role.
+// login records the time of a sign-in.
+func (s *IdentityService) provision(ctx context.Context, claims *auth.Claims, login bool) (*entities.Identity, error) {
+	profile := entities.Identity{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   claims.Email,
+		Name:    displayName(claims),
+		Role:    s.provider.Role(claims),
+	}
+
+	var identity *entities.Identity
+	created := false
+	err := s.store.Transaction(ctx, func(tx repositories.Store) error {
+		existing, err := tx.Identities().FindBySubject(ctx, profile.Issuer, profile.Subject)
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			identity, created = &profile, true
+			return s.create(ctx, tx, identity)
+		case err != nil:
+			return err
+		}
+
+		identity = existing
+		changed := login
+		if identity.Name != profile.Name || identity.Email != profile.Email || identity.Role != profile.Role {
+			identity.Name, identity.Email, identity.Role = profile.Name, profile.Email, profile.Role
+			changed = true
+		}
+		// People who became officers get an officer; those who stopped
+		// keep theirs, as the issue history refers to it
+		if identity.HandlesIssues() && identity.OfficerID == nil {
+			officer := entities.Officer{FullName: identity.Name}
+			if err := tx.Officers().Create(ctx, &officer); err != nil {
+				return err
+			}
+			identity.OfficerID = &officer.OfficerID
+			changed = true
+		}
+		if login {
+			identity.LastLoginAt = time.Now()
+		}
+		if !changed {
+			return nil
+		}
+		return tx.Identities().Update(ctx, identity)
+	})
+	if err != nil {
+		// Another request may have provisioned the same person first
+		if created {
+			if existing, findErr := s.store.Identities().FindBySubject(ctx, profile.Issuer, profile.Subject); findErr == nil {
+				return existing, nil
+			}
+		}
+		return nil, newError(http.StatusInternalServerError, "Failed to sign in", err.Error())
+	}
+	return identity, nil
+}
+
+// create stores a new identity with the user it reports issues as and, for
+// officers and admins, the officer it handles them as
+func (s *IdentityService) create(ctx context.Context, tx repositories.Store, identity *entities.Identity) error {
+	user := entities.User{FullName: identity.Name}
+	if err := tx.Users().Create(ctx, &user); err != nil {
+		return err
+	}
+	identity.UserID = user.UserID
+	if identity.HandlesIssues() {
+		officer := entities.Officer{FullName: identity.Name}
+		if err := tx.Officers().Create(ctx, &officer); err != nil {
+			return err
+		}
+		identity.OfficerID = &officer.OfficerID
+	}
+	identity.LastLoginAt = time.Now()
+	return tx.Identities().Create(ctx, identity)
+}
+
+// displayName returns the name of claims as a valid full name of a user
+func displayName(claims *auth.Claims) string {
+	name := strings.TrimSpace(claims.Name)
+	if utf8.RuneCountInString(name) < 2 {
+		name = claims.Subject
+	}
+	if utf8.RuneCountInString(name) < 2 {
+		name = "User " + name
+	}
+	if runes := []rune(name); len(runes) > maxNameLength {
+		name = string(runes[:maxNameLength])
+	}
+	return name
+}
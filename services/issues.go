@@ -0,0 +1,348 @@
+// Package services holds the issue tracking rules shared by the APIs: the
+// checks made before an issue is created, transitioned or commented on and
+// the errors reported when they fail.
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"issue-tracking/auth"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/utils"
+)
+
+// Error is a failure reported to the client. Status is the HTTP status the
+// REST API responds with; Message and Details form the error body.
+type Error struct {
+	Status  int
+	Message string
+	Details interface{}
+}
+
+func (e *Error) Error() string {
+	if details, ok := e.Details.(string); ok && details != "" {
+		return e.Message + ": " + details
+	}
+	return e.Message
+}
+
+// ValidationErrors returns the fields of a "Validation failed" error
+func (e *Error) ValidationErrors() []utils.ValidationError {
+	fields, _ := e.Details.([]utils.ValidationError)
+	return fields
+}
+
+func newError(status int, message string, details interface{}) *Error {
+	return &Error{Status: status, Message: message, Details: details}
+}
+
+// validationError reports the failed validation rules of a struct
+func validationError(fields []utils.ValidationError) *Error {
+	return newError(http.StatusBadRequest, "Validation failed", fields)
+}
+
+// StatusChange is a transition of an issue to another status
+type StatusChange struct {
+	NewStatusID uint
+	// AssigneeID reassigns the issue when set and leaves it unchanged otherwise
+	AssigneeID *uint
+	Comment    string
+	ChangedBy  uint
+}
+
+// IssueService creates, transitions and comments on issues and publishes
+// an event for each change. The APIs share one service so a client watching
+// the events sees the changes made through any of them.
+type IssueService struct {
+	store  repositories.Store
+	events *Events
+	// surveyTTL is how long the satisfaction survey created when an issue
+	// closes stays open, 0 when no surveys are sent
+	surveyTTL time.Duration
+}
+
+// NewIssueService creates a new issue service
+func NewIssueService(store repositories.Store) *IssueService {
+	return &IssueService{store: store, events: NewEvents()}
+}
+
+// SendSurveys makes the service create a satisfaction survey, open for ttl,
+// whenever an issue moves from an open status to a terminal one. It must be
+// called before the service is used.
+func (s *IssueService) SendSurveys(ttl time.Duration) {
+	s.surveyTTL = ttl
+}
+
+// Events returns the broker the service publishes its changes to
+func (s *IssueService) Events() *Events {
+	return s.events
+}
+
+// List returns the issues matching filter with their relations
+func (s *IssueService) List(ctx context.Context, filter repositories.IssueFilter) ([]entities.Issue, error) {
+	if err := authorize(ctx, auth.ScopeIssuesRead); err != nil {
+		return nil, err
+	}
+	issues, err := s.store.Issues().List(ctx, filter)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch issues", nil)
+	}
+	if issues == nil {
+		issues = []entities.Issue{}
+	}
+	return issues, nil
+}
+
+// Get returns one issue with every relation
+func (s *IssueService) Get(ctx context.Context, id uint) (*entities.Issue, error) {
+	if err := authorize(ctx, auth.ScopeIssuesRead); err != nil {
+		return nil, err
+	}
+	return s.get(ctx, id)
+}
+
+// get returns one issue without checking the caller may read it
+func (s *IssueService) get(ctx context.Context, id uint) (*entities.Issue, error) {
+	issue, err := s.store.Issues().Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, newError(http.StatusNotFound, "Issue not found", nil)
+		}
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch issue", nil)
+	}
+	return issue, nil
+}
+
+// Create validates and stores a new issue and returns it with its relations.
+// Issues created with an API key are recorded as reported by its
+// integration.
+func (s *IssueService) Create(ctx context.Context, issue entities.Issue) (*entities.Issue, error) {
+	if err := authorize(ctx, auth.ScopeIssuesWrite); err != nil {
+		return nil, err
+	}
+	issue.Source, issue.APIKeyID = entities.IssueSourceUser, nil
+	if key := auth.APIKeyFrom(ctx); key != nil {
+		issue.Source, issue.APIKeyID = entities.IssueSourceIntegration, &key.APIKeyID
+	}
+
+	if fields := utils.ValidateStruct(issue); len(fields) > 0 {
+		return nil, validationError(fields)
+	}
+
+	// Validate reporter exists
+	if _, err := s.store.Users().Get(ctx, issue.ReporterID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, newError(http.StatusBadRequest, "Reporter not found", "invalid reporter_id")
+		}
+		return nil, newError(http.StatusInternalServerError, "Failed to validate reporter", nil)
+	}
+
+	// Validate status exists
+	if _, err := s.store.Statuses().Get(ctx, issue.StatusID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, newError(http.StatusBadRequest, "Status not found", "invalid status_id")
+		}
+		return nil, newError(http.StatusInternalServerError, "Failed to validate status", nil)
+	}
+
+	// Validate assignee if provided
+	if issue.AssigneeID != nil {
+		if err := s.checkAssignee(ctx, *issue.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.store.Issues().Create(ctx, &issue); err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to create issue", err.Error())
+	}
+
+	// Reload with relations
+	created, err := s.store.Issues().Get(ctx, issue.IssueID)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch created issue", nil)
+	}
+	s.events.publish(Event{Type: EventIssueCreated, IssueID: created.IssueID, OccurredAt: time.Now(), Issue: created})
+	return created, nil
+}
+
+// ChangeStatus moves an issue to another status, recording the change in
+// its history, and returns the updated issue with its relations
+func (s *IssueService) ChangeStatus(ctx context.Context, id uint, req StatusChange) (*entities.Issue, error) {
+	if err := authorize(ctx, auth.ScopeIssuesWrite); err != nil {
+		return nil, err
+	}
+	// Validate new status exists
+	if _, err := s.store.Statuses().Get(ctx, req.NewStatusID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, newError(http.StatusBadRequest, "Invalid status", "status_id does not exist")
+		}
+		return nil, newError(http.StatusInternalServerError, "Failed to validate status", nil)
+	}
+
+	change := repositories.IssueChange{
+		NewStatusID: &req.NewStatusID,
+		Comment:     req.Comment,
+		ChangedBy:   req.ChangedBy,
+		SurveyTTL:   s.surveyTTL,
+	}
+	// Signed-in officers are recorded as making the change themselves
+	if officerID, ok := auth.OfficerID(ctx); ok {
+		change.ChangedBy = officerID
+	}
+
+	// Validate assignee if provided; it is left unchanged otherwise
+	if req.AssigneeID != nil {
+		if err := s.checkAssignee(ctx, *req.AssigneeID); err != nil {
+			return nil, err
+		}
+		change.SetAssignee = true
+		change.AssigneeID = req.AssigneeID
+	}
+
+	if err := s.store.Issues().ApplyChange(ctx, id, change); err != nil {
+		var updateErr repositories.UpdateIssueError
+		var historyErr repositories.RecordHistoryError
+		switch {
+		case errors.As(err, &updateErr):
+			return nil, newError(http.StatusInternalServerError, "Failed to update status", updateErr.Error())
+		case errors.As(err, &historyErr):
+			return nil, newError(http.StatusInternalServerError, "Failed to record status history", historyErr.Error())
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, newError(http.StatusNotFound, "Issue not found", nil)
+		default:
+			return nil, newError(http.StatusInternalServerError, "Failed to fetch issue", nil)
+		}
+	}
+
+	// Return updated issue with relations
+	issue, err := s.store.Issues().Get(ctx, id)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch updated issue", nil)
+	}
+	s.events.publish(Event{Type: EventIssueStatusChanged, IssueID: id, OccurredAt: time.Now(), Issue: issue})
+	return issue, nil
+}
+
+// Update validates and stores the edited fields of an existing issue and
+// returns it with its relations
+func (s *IssueService) Update(ctx context.Context, issue entities.Issue) (*entities.Issue, error) {
+	if err := authorize(ctx, auth.ScopeIssuesWrite); err != nil {
+		return nil, err
+	}
+	if fields := utils.ValidateStruct(issue); len(fields) > 0 {
+		return nil, validationError(fields)
+	}
+
+	if err := s.store.Issues().Update(ctx, &issue); err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to update issue", err.Error())
+	}
+
+	// Reload with relations
+	updated, err := s.store.Issues().Get(ctx, issue.IssueID)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch updated issue", nil)
+	}
+	s.events.publish(Event{Type: EventIssueUpdated, IssueID: updated.IssueID, OccurredAt: time.Now(), Issue: updated})
+	return updated, nil
+}
+
+// Delete removes an issue with its comments and history
+func (s *IssueService) Delete(ctx context.Context, id uint) error {
+	if err := authorize(ctx, auth.ScopeIssuesWrite); err != nil {
+		return err
+	}
+	issue, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Issues().Delete(ctx, id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return newError(http.StatusNotFound, "Issue not found", nil)
+		}
+		return newError(http.StatusInternalServerError, "Failed to delete issue", err.Error())
+	}
+	s.events.publish(Event{Type: EventIssueDeleted, IssueID: id, OccurredAt: time.Now(), Issue: issue})
+	return nil
+}
+
+// publishIssues publishes an event of type typ for each issue, loaded with
+// its relations. Nothing is loaded while no one is subscribed; issues that
+// cannot be loaded, e.g. because they were deleted since, are skipped.
+func (s *IssueService) publishIssues(ctx context.Context, typ EventType, ids []uint) {
+	if len(ids) == 0 || !s.events.watched() {
+		return
+	}
+	for _, id := range ids {
+		issue, err := s.store.Issues().Get(ctx, id)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to load issue for event",
+				slog.Uint64("issue_id", uint64(id)), slog.Any("error", err))
+			continue
+		}
+		s.events.publish(Event{Type: typ, IssueID: id, OccurredAt: time.Now(), Issue: issue})
+	}
+}
+
+// Comment adds a comment by a user to an issue and returns it with its user
+// and issue
+func (s *IssueService) Comment(ctx context.Context, issueID, userID uint, content string) (*entities.Comment, error) {
+	if err := authorize(ctx, auth.ScopeCommentsWrite); err != nil {
+		return nil, err
+	}
+	return s.comment(ctx, issueID, userID, content)
+}
+
+// comment adds a comment without checking the caller may comment
+func (s *IssueService) comment(ctx context.Context, issueID, userID uint, content string) (*entities.Comment, error) {
+	// Validate the issue exists
+	if _, err := s.get(ctx, issueID); err != nil {
+		return nil, err
+	}
+
+	// Validate UserID
+	if _, err := s.store.Users().Get(ctx, userID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, newError(http.StatusBadRequest, "User not found", "invalid user_id")
+		}
+		return nil, newError(http.StatusInternalServerError, "Failed to validate user", nil)
+	}
+
+	comment := entities.Comment{
+		IssueID: issueID,
+		UserID:  userID,
+		Content: content,
+	}
+
+	// Validate the comment
+	if fields := utils.ValidateStruct(comment); len(fields) > 0 {
+		return nil, validationError(fields)
+	}
+
+	if err := s.store.Comments().Create(ctx, &comment); err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to create comment", err.Error())
+	}
+
+	// Reload with user and issue info
+	created, err := s.store.Comments().Get(ctx, comment.CommentID)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch created comment", nil)
+	}
+	s.events.publish(Event{Type: EventIssueCommented, IssueID: issueID, OccurredAt: time.Now(), Issue: &created.Issue, Comment: created})
+	return created, nil
+}
+
+// checkAssignee validates that an officer exists
+func (s *IssueService) checkAssignee(ctx context.Context, id uint) error {
+	if _, err := s.store.Officers().Get(ctx, id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return newError(http.StatusBadRequest, "Assignee not found", "invalid assignee_id")
+		}
+		return newError(http.StatusInternalServerError, "Failed to validate assignee", nil)
+	}
+	return nil
+}
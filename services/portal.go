@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"issue-tracking/captcha"
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/utils"
+)
+
+// portalPriority is the priority of issues reported on the portal; officers
+// raise it while triaging
+const portalPriority = "medium"
+
+// PortalService lets people without an account report issues on the public
+// portal. Each reporter gets a user with the contact details they gave and a
+// tracking token, which is all they need to follow and comment on the issue.
+type PortalService struct {
+	store   repositories.Store
+	issues  *IssueService
+	status  string
+	captcha captcha.Verifier
+	spam    SpamRules
+	surveys *SurveyService
+}
+
+// PortalOptions configures the portal
+type PortalOptions struct {
+	// Status is the code of the status reported issues start in
+	Status  string
+	Captcha captcha.Verifier
+	Spam    SpamRules
+	// Surveys links closed issues to their satisfaction survey, nil when
+	// no surveys are sent
+	Surveys *SurveyService
+}
+
+// NewPortalService creates a new portal service. Issues are created and
+// commented on through issues so their events are published.
+func NewPortalService(store repositories.Store, issues *IssueService, options PortalOptions) *PortalService {
+	verifier := options.Captcha
+	if verifier == nil {
+		verifier = captcha.None{}
+	}
+	return &PortalService{store: store, issues: issues, status: options.Status, captcha: verifier, spam: options.Spam, surveys: options.Surveys}
+}
+
+// Submission holds what every portal form sends besides its content
+type Submission struct {
+	// Captcha is the response of the captcha provider's widget
+	Captcha  string
+	RemoteIP string
+	// Honeypot is a form field hidden from people; bots fill it in
+	Honeypot string
+}
+
+// PortalReport is an issue reported on the portal. Reporters leave an email
+// address, a phone number or both.
+type PortalReport struct {
+	Submission
+	Name        string `validate:"required,min=2,max=255"`
+	Email       string `validate:"required_without=Phone,omitempty,email,max=255"`
+	Phone       string `validate:"omitempty,min=5,max=50"`
+	Title       string `validate:"required,min=3,max=255"`
+	Description string `validate:"max=5000"`
+}
+
+// PublicIssue is what the reporter of an issue sees with its tracking token:
+// no assignee, history or contact details
+type PublicIssue struct {
+	Reference   uint            `json:"reference"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Status      PublicStatus    `json:"status"`
+	Comments    []PublicComment `json:"comments"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	// SurveyURL links to the satisfaction survey of a closed issue while it
+	// waits for an answer
+	SurveyURL string `json:"survey_url,omitempty"`
+}
+
+// PublicStatus is the status of a public issue
+type PublicStatus struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// Closed is set once the issue reached a terminal status
+	Closed bool `json:"closed"`
+}
+
+// PublicComment is a comment on a public issue. Comments by others than the
+// reporter do not name their author.
+type PublicComment struct {
+	Content      string    `json:"content"`
+	FromReporter bool      `json:"from_reporter"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TrackedIssue is a reported issue with its tracking token, which is only
+// ever returned here
+type TrackedIssue struct {
+	TrackingToken string      `json:"tracking_token"`
+	Issue         PublicIssue `json:"issue"`
+}
+
+// Report checks a report for spam and stores it with its reporter and
+// tracking token
+func (s *PortalService) Report(ctx context.Context, report PortalReport) (*TrackedIssue, error) {
+	report.Name = strings.TrimSpace(report.Name)
+	report.Email = strings.TrimSpace(report.Email)
+	report.Phone = strings.TrimSpace(report.Phone)
+	report.Title = strings.TrimSpace(report.Title)
+	if fields := utils.ValidateStruct(report); len(fields) > 0 {
+		return nil, validationError(fields)
+	}
+	if err := s.screen(ctx, report.Submission, report.Title, report.Description); err != nil {
+		return nil, err
+	}
+
+	status, err := s.store.Statuses().FindByName(ctx, s.status)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to find the status of new reports", err.Error())
+	}
+	token, hash, err := newTrackingToken()
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to create tracking token", nil)
+	}
+
+	issue := entities.Issue{
+		StatusID:    status.StatusID,
+		Title:       report.Title,
+		Description: report.Description,
+		Priority:    portalPriority,
+		Source:      entities.IssueSourcePortal,
+	}
+	err = s.store.Transaction(ctx, func(tx repositories.Store) error {
+		reporter := entities.User{FullName: report.Name, Email: report.Email, Phone: report.Phone}
+		if err := tx.Users().Create(ctx, &reporter); err != nil {
+			return err
+		}
+		issue.ReporterID = reporter.UserID
+		if err := tx.Issues().Create(ctx, &issue); err != nil {
+			return err
+		}
+		return tx.TrackingTokens().Create(ctx, &entities.TrackingToken{IssueID: issue.IssueID, TokenHash: hash})
+	})
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to create issue", err.Error())
+	}
+
+	created, err := s.issues.get(ctx, issue.IssueID)
+	if err != nil {
+		return nil, err
+	}
+	s.issues.events.publish(Event{Type: EventIssueCreated, IssueID: created.IssueID, OccurredAt: time.Now(), Issue: created})
+	return &TrackedIssue{TrackingToken: token, Issue: publicIssue(created)}, nil
+}
+
+// Track returns the issue of a tracking token
+func (s *PortalService) Track(ctx context.Context, token string) (*PublicIssue, error) {
+	issue, err := s.tracked(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	public := publicIssue(issue)
+	if s.surveys != nil && issue.Status.IsTerminal {
+		if public.SurveyURL, err = s.surveys.PendingLink(ctx, issue.IssueID); err != nil {
+			return nil, err
+		}
+	}
+	return &public, nil
+}
+
+// Comment adds a comment by the reporter to the issue of a tracking token
+func (s *PortalService) Comment(ctx context.Context, token, content string, submission Submission) (*PublicComment, error) {
+	issue, err := s.tracked(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.screen(ctx, submission, content); err != nil {
+		return nil, err
+	}
+	comment, err := s.issues.comment(ctx, issue.IssueID, issue.ReporterID, content)
+	if err != nil {
+		return nil, err
+	}
+	return &PublicComment{Content: comment.Content, FromReporter: true, CreatedAt: comment.CreatedAt}, nil
+}
+
+// tracked returns the issue of a tracking token. Malformed and unknown
+// tokens are both reported as a missing issue.
+func (s *PortalService) tracked(ctx context.Context, token string) (*entities.Issue, error) {
+	if !strings.HasPrefix(token, entities.TrackingTokenPrefix) {
+		return nil, newError(http.StatusNotFound, "Issue not found", nil)
+	}
+	tracking, err := s.store.TrackingTokens().FindByHash(ctx, hashTrackingToken(token))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, newError(http.StatusNotFound, "Issue not found", nil)
+		}
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch issue", nil)
+	}
+	return s.issues.get(ctx, tracking.IssueID)
+}
+
+// screen rejects submissions that look like spam, then those whose captcha
+// was not solved
+func (s *PortalService) screen(ctx context.Context, submission Submission, texts ...string) error {
+	reason := s.spam.check(texts...)
+	if submission.Honeypot != "" {
+		reason = "honeypot field filled in"
+	}
+	if reason != "" {
+		slog.WarnContext(ctx, "rejected portal submission as spam",
+			slog.String("reason", reason), slog.String("remote_ip", submission.RemoteIP))
+		return newError(http.StatusUnprocessableEntity, "Submission rejected", "the submission looks like spam")
+	}
+
+	if err := s.captcha.Verify(ctx, submission.Captcha, submission.RemoteIP); err != nil {
+		if errors.Is(err, captcha.ErrFailed) {
+			return newError(http.StatusBadRequest, "Captcha verification failed", err.Error())
+		}
+		slog.ErrorContext(ctx, "failed to verify captcha", slog.Any("error", err))
+		return newError(http.StatusBadGateway, "Captcha provider unavailable", nil)
+	}
+	return nil
+}
+
+// publicIssue returns the public view of an issue loaded with its status
+// and comments
+func publicIssue(issue *entities.Issue) PublicIssue {
+	public := PublicIssue{
+		Reference:   issue.IssueID,
+		Title:       issue.Title,
+		Description: issue.Description,
+		Status: PublicStatus{
+			Code:   issue.Status.StatusCode,
+			Name:   issue.Status.DisplayName,
+			Closed: issue.Status.IsTerminal,
+		},
+		Comments:  make([]PublicComment, 0, len(issue.Comments)),
+		CreatedAt: issue.CreatedAt,
+		UpdatedAt: issue.UpdatedAt,
+	}
+	for _, comment := range issue.Comments {
+		public.Comments = append(public.Comments, PublicComment{
+			Content:      comment.Content,
+			FromReporter: comment.UserID == issue.ReporterID,
+			CreatedAt:    comment.CreatedAt,
+		})
+	}
+	return public
+}
+
+// newTrackingToken returns a new token of the form trk_<secret> and the hash
+// stored instead of it
+func newTrackingToken() (token, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	token = entities.TrackingTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return token, hashTrackingToken(token), nil
+}
+
+// hashTrackingToken returns the hex SHA-256 of a token. The token has 256
+// random bits, so no slow hash is needed.
+func hashTrackingToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxRepeatedRune is the longest run of one letter, "!" or "?" a text
+	// may contain
+	maxRepeatedRune = 10
+
+	// minShoutingLetters is how many letters a text needs before it is
+	// judged on its capitals, so short texts like "VPN down" pass
+	minShoutingLetters = 20
+)
+
+// linkPattern matches the start of a link
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)`)
+
+// SpamRules are the heuristics reports and comments on the public portal are
+// checked with. They catch the bulk of automated spam; the captcha and the
+// rate limits catch most of the rest.
+type SpamRules struct {
+	// MaxLinks is the most links the texts of one submission may contain
+	MaxLinks int
+	// BlockedTerms mark a submission as spam wherever they appear, ignoring
+	// case
+	BlockedTerms []string
+}
+
+// check returns why the texts of one submission look like spam, or "" when
+// they do not
+func (r SpamRules) check(texts ...string) string {
+	all := strings.Join(texts, "\n")
+	if n := len(linkPattern.FindAllStringIndex(all, -1)); n > r.MaxLinks {
+		return fmt.Sprintf("%d links", n)
+	}
+	lower := strings.ToLower(all)
+	for _, term := range r.BlockedTerms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term != "" && strings.Contains(lower, term) {
+			return fmt.Sprintf("blocked term %q", term)
+		}
+	}
+
+	for _, text := range texts {
+		var letters, upper, run int
+		var last rune
+		for _, c := range text {
+			if c == last && (unicode.IsLetter(c) || c == '!' || c == '?') {
+				run++
+				if run > maxRepeatedRune {
+					return fmt.Sprintf("%q repeated", c)
+				}
+			} else {
+				last, run = c, 1
+			}
+			if unicode.IsLetter(c) {
+				letters++
+				if unicode.IsUpper(c) {
+					upper++
+				}
+			}
+		}
+		// Nine in ten letters in capitals is shouting
+		if letters >= minShoutingLetters && upper*10 >= letters*9 {
+			return "written in capitals"
+		}
+	}
+	return ""
+}
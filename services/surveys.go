@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"issue-tracking/entities"
+	"issue-tracking/repositories"
+	"issue-tracking/utils"
+)
+
+// SurveyService lets reporters answer the satisfaction surveys sent when
+// their issues close. Survey links carry a signature of the survey ID, which
+// is all a reporter needs to answer.
+type SurveyService struct {
+	store     repositories.Store
+	secret    []byte
+	surveyURL string
+}
+
+// NewSurveyService creates a new survey service. Links point at surveyURL
+// and are signed with secret.
+func NewSurveyService(store repositories.Store, secret, surveyURL string) *SurveyService {
+	return &SurveyService{store: store, secret: []byte(secret), surveyURL: surveyURL}
+}
+
+// SurveyAnswer is a reporter's answer to a survey
+type SurveyAnswer struct {
+	Rating   int    `validate:"required,min=1,max=5"`
+	Feedback string `validate:"max=2000"`
+}
+
+// PublicSurvey is what the reporter sees with a survey link
+type PublicSurvey struct {
+	SurveyID uint `json:"survey_id"`
+	// Reference and Title identify the issue the survey is about
+	Reference   uint       `json:"reference"`
+	Title       string     `json:"title"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	Answered    bool       `json:"answered"`
+	Rating      *int       `json:"rating,omitempty"`
+	Feedback    string     `json:"feedback,omitempty"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+}
+
+// PendingSurvey is a survey waiting for an answer, with the link and contact
+// details needed to send it to the reporter
+type PendingSurvey struct {
+	entities.Survey
+	Link     string        `json:"link"`
+	Title    string        `json:"title"`
+	Reporter entities.User `json:"reporter"`
+}
+
+// Get returns the survey of a signed link
+func (s *SurveyService) Get(ctx context.Context, id uint, signature string) (*PublicSurvey, error) {
+	survey, err := s.signed(ctx, id, signature)
+	if err != nil {
+		return nil, err
+	}
+	return s.public(ctx, survey)
+}
+
+// Respond stores the answer to the survey of a signed link. Each survey is
+// answered once, before it expires.
+func (s *SurveyService) Respond(ctx context.Context, id uint, signature string, answer SurveyAnswer) (*PublicSurvey, error) {
+	survey, err := s.signed(ctx, id, signature)
+	if err != nil {
+		return nil, err
+	}
+	if fields := utils.ValidateStruct(answer); len(fields) > 0 {
+		return nil, validationError(fields)
+	}
+
+	now := time.Now()
+	if survey.RespondedAt != nil {
+		return nil, newError(http.StatusConflict, "Survey already answered", nil)
+	}
+	if !survey.Pending(now) {
+		return nil, newError(http.StatusGone, "Survey expired", nil)
+	}
+	answered, err := s.store.Surveys().Respond(ctx, id, answer.Rating, answer.Feedback, now)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to store answer", err.Error())
+	}
+	// Another request answered it since it was read
+	if !answered {
+		return nil, newError(http.StatusConflict, "Survey already answered", nil)
+	}
+
+	survey, err = s.store.Surveys().Get(ctx, id)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch survey", nil)
+	}
+	return s.public(ctx, survey)
+}
+
+// Pending returns the surveys waiting for an answer with their links
+func (s *SurveyService) Pending(ctx context.Context) ([]PendingSurvey, error) {
+	surveys, err := s.store.Surveys().ListPending(ctx, 0, time.Now())
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch surveys", nil)
+	}
+
+	issueIDs := make([]uint, 0, len(surveys))
+	for _, survey := range surveys {
+		issueIDs = append(issueIDs, survey.IssueID)
+	}
+	issues, err := s.store.Issues().ListByIDs(ctx, issueIDs)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch issues", nil)
+	}
+	byID := make(map[uint]entities.Issue, len(issues))
+	userIDs := make([]uint, 0, len(issues))
+	for _, issue := range issues {
+		byID[issue.IssueID] = issue
+		userIDs = append(userIDs, issue.ReporterID)
+	}
+	users, err := s.store.Users().ListByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch reporters", nil)
+	}
+	reporters := make(map[uint]entities.User, len(users))
+	for _, user := range users {
+		reporters[user.UserID] = user
+	}
+
+	pending := make([]PendingSurvey, 0, len(surveys))
+	for _, survey := range surveys {
+		issue := byID[survey.IssueID]
+		pending = append(pending, PendingSurvey{
+			Survey:   survey,
+			Link:     s.Link(survey.SurveyID),
+			Title:    issue.Title,
+			Reporter: reporters[issue.ReporterID],
+		})
+	}
+	return pending, nil
+}
+
+// PendingLink returns the link to the survey of an issue waiting for an
+// answer, or "" when there is none
+func (s *SurveyService) PendingLink(ctx context.Context, issueID uint) (string, error) {
+	surveys, err := s.store.Surveys().ListPending(ctx, issueID, time.Now())
+	if err != nil {
+		return "", newError(http.StatusInternalServerError, "Failed to fetch surveys", nil)
+	}
+	if len(surveys) == 0 {
+		return "", nil
+	}
+	return s.Link(surveys[len(surveys)-1].SurveyID), nil
+}
+
+// Link returns the signed link to a survey
+func (s *SurveyService) Link(id uint) string {
+	link, err := url.Parse(s.surveyURL)
+	if err != nil {
+		link = &url.URL{Path: s.surveyURL}
+	}
+	query := link.Query()
+	query.Set("survey", strconv.FormatUint(uint64(id), 10))
+	query.Set("signature", s.sign(id))
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// signed returns the survey of a link. Bad signatures and unknown surveys
+// are both reported as a missing survey.
+func (s *SurveyService) signed(ctx context.Context, id uint, signature string) (*entities.Survey, error) {
+	if !hmac.Equal([]byte(signature), []byte(s.sign(id))) {
+		return nil, newError(http.StatusNotFound, "Survey not found", nil)
+	}
+	survey, err := s.store.Surveys().Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, newError(http.StatusNotFound, "Survey not found", nil)
+		}
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch survey", nil)
+	}
+	return survey, nil
+}
+
+// public returns the reporter's view of a survey
+func (s *SurveyService) public(ctx context.Context, survey *entities.Survey) (*PublicSurvey, error) {
+	issues, err := s.store.Issues().ListByIDs(ctx, []uint{survey.IssueID})
+	if err != nil || len(issues) == 0 {
+		return nil, newError(http.StatusInternalServerError, "Failed to fetch issue", nil)
+	}
+	return &PublicSurvey{
+		SurveyID:    survey.SurveyID,
+		Reference:   survey.IssueID,
+		Title:       issues[0].Title,
+		ExpiresAt:   survey.ExpiresAt,
+		Answered:    survey.RespondedAt != nil,
+		Rating:      survey.Rating,
+		Feedback:    survey.Feedback,
+		RespondedAt: survey.RespondedAt,
+	}, nil
+}
+
+// sign returns the base64url HMAC-SHA256 of a survey ID
+func (s *SurveyService) sign(id uint) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("survey:" + strconv.FormatUint(uint64(id), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,120 @@
+// Package tracing sets up OpenTelemetry tracing for the API: one span per HTTP
+// request or gRPC call, one per SQL statement, and W3C trace context
+// propagation on incoming requests and outgoing HTTP calls.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"google.golang.org/grpc"
+)
+
+// Supported values of OTEL_TRACES_EXPORTER
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// DefaultServiceName is reported when OTEL_SERVICE_NAME is not set
+const DefaultServiceName = "issue-tracking"
+
+// instrumentationName identifies spans created by this package
+const instrumentationName = "issue-tracking/tracing"
+
+// Setup installs the global tracer provider and W3C propagators. The exporter
+// is chosen by OTEL_TRACES_EXPORTER (none, stdout or otlp); the OTLP exporter
+// reads the standard OTEL_EXPORTER_OTLP_* variables. Trace context is
+// propagated even when no exporter is configured. The returned function
+// flushes pending spans and must be called before exit.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporterName := strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER"))
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch exporterName {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout, "console":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown OTEL_TRACES_EXPORTER %q, must be one of: %s %s %s",
+			exporterName, ExporterNone, ExporterStdout, ExporterOTLP)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s exporter: %w", exporterName, err)
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("build resource: %w", err)
+	}
+
+	// The sampler follows OTEL_TRACES_SAMPLER and defaults to honouring the
+	// caller's sampling decision
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Middleware starts a server span for every request, named after the matched
+// route, continuing the trace from an incoming traceparent header
+func Middleware() gin.HandlerFunc {
+	return otelgin.Middleware(DefaultServiceName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+		// Prometheus scrapes and health checks would drown out real traffic
+		return !untraced[c.FullPath()]
+	}))
+}
+
+// GRPCServerOption starts a server span for every gRPC call except health
+// checks, continuing the trace from the incoming metadata
+func GRPCServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck()))))
+}
+
+// untraced lists the routes polled by monitoring
+var untraced = map[string]bool{
+	"/metrics": true,
+	"/health":  true,
+	"/livez":   true,
+	"/readyz":  true,
+}
+
+// NewHTTPClient returns a client for outgoing calls such as webhooks. Each
+// request gets a client span and carries the current trace in a traceparent
+// header.
+func NewHTTPClient() *http.Client {
+	return &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"issue-tracking/entities"
+	"issue-tracking/utils"
+)
+
+// runCreateUserCommand implements `issue-tracking create-user NAME`
+func runCreateUserCommand(args []string) error {
+	return createPerson("create-user", args, func(name string) (interface{}, func() uint) {
+		user := &entities.User{FullName: name}
+		return user, func() uint { return user.UserID }
+	})
+}
+
+// runCreateOfficerCommand implements `issue-tracking create-officer NAME`
+func runCreateOfficerCommand(args []string) error {
+	return createPerson("create-officer", args, func(name string) (interface{}, func() uint) {
+		officer := &entities.Officer{FullName: name}
+		return officer, func() uint { return officer.OfficerID }
+	})
+}
+
+// createPerson validates and inserts the user or officer built by newRow and
+// prints its ID so scripts can capture it
+func createPerson(name string, args []string, newRow func(fullName string) (interface{}, func() uint)) error {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: issue-tracking %s [flags] \"FULL NAME\"\n", name)
+		fs.PrintDefaults()
+	}
+	cfg := loadConfig(fs, args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one full name")
+	}
+
+	row, id := newRow(fs.Arg(0))
+	if validationErrors := utils.ValidateStruct(row); len(validationErrors) > 0 {
+		return fmt.Errorf("%s", validationErrors[0].Message)
+	}
+
+	db, shutdown, err := setup(cfg, os.Stderr, cfg.Database.AutoMigrate)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	if err := db.Create(row).Error; err != nil {
+		return err
+	}
+	fmt.Println(id())
+	return nil
+}
@@ -2,7 +2,12 @@ package utils
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
+
+	"issue-tracking/logging"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -10,9 +15,10 @@ import (
 
 // ErrorResponse represents a standardized error response
 type ErrorResponse struct {
-	Status  int         `json:"status"`
-	Message string      `json:"message"`
-	Details interface{} `json:"details,omitempty"`
+	Status    int         `json:"status"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
 // SuccessResponse represents a standardized success response
@@ -27,12 +33,21 @@ type ValidationError struct {
 	Message string `json:"message"`
 }
 
-// RespondError responds with an error
+// RespondError responds with an error carrying the request ID, and logs
+// server errors
 func RespondError(c *gin.Context, statusCode int, message string, details interface{}) {
+	ctx := c.Request.Context()
+	if statusCode >= http.StatusInternalServerError {
+		slog.ErrorContext(ctx, message, slog.Int("status", statusCode), slog.Any("details", details))
+	} else {
+		slog.DebugContext(ctx, message, slog.Int("status", statusCode), slog.Any("details", details))
+	}
+
 	c.JSON(statusCode, ErrorResponse{
-		Status:  statusCode,
-		Message: message,
-		Details: details,
+		Status:    statusCode,
+		Message:   message,
+		Details:   details,
+		RequestID: logging.RequestID(ctx),
 	})
 }
 
@@ -47,9 +62,10 @@ func RespondSuccess(c *gin.Context, statusCode int, data interface{}) {
 // RespondValidationError responds with validation errors
 func RespondValidationError(c *gin.Context, errors []ValidationError) {
 	c.JSON(http.StatusBadRequest, ErrorResponse{
-		Status:  http.StatusBadRequest,
-		Message: "Validation failed",
-		Details: errors,
+		Status:    http.StatusBadRequest,
+		Message:   "Validation failed",
+		Details:   errors,
+		RequestID: logging.RequestID(c.Request.Context()),
 	})
 }
 
@@ -88,14 +104,26 @@ func getValidationMessage(err validator.FieldError) string {
 		return fmt.Sprintf("%s must be exactly %s characters", err.Field(), err.Param())
 	case "oneof":
 		return fmt.Sprintf("%s must be one of: %s", err.Field(), err.Param())
+	case "required_without":
+		return fmt.Sprintf("%s or %s is required", err.Field(), err.Param())
 	default:
 		return fmt.Sprintf("%s is invalid", err.Field())
 	}
 }
 
-// RecoverPanic middleware to recover from panics
+// RecoverPanic middleware to recover from panics. The panic value and stack
+// trace are logged together with the request ID.
 func RecoverPanic() gin.HandlerFunc {
-	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered interface{}) {
+		// Handlers abort to drop a connection whose response was cut short
+		if recovered == http.ErrAbortHandler {
+			panic(recovered)
+		}
+		slog.ErrorContext(c.Request.Context(), "panic recovered",
+			slog.Any("panic", recovered),
+			slog.String("stack", string(debug.Stack())),
+		)
+
 		if err, ok := recovered.(string); ok {
 			RespondError(c, http.StatusInternalServerError, "Internal Server Error", err)
 		} else {